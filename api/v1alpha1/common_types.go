@@ -66,6 +66,155 @@ type UpstreamConfig struct {
 	// +optional
 	// +kubebuilder:default="30s"
 	Timeout string `json:"timeout,omitempty"`
+
+	// hosts are weighted instances to balance across. When set, they replace
+	// host/port as the endpoints; host is still used for TLS SNI and port
+	// for hosts that do not set one.
+	// +optional
+	Hosts []UpstreamHost `json:"hosts,omitempty"`
+
+	// tls configures upstream certificate verification and the client
+	// certificate for mTLS; used when scheme is https.
+	// +optional
+	TLS *UpstreamTLS `json:"tls,omitempty"`
+
+	// subsets enables subset load balancing over the metadata of hosts.
+	// +optional
+	Subsets *UpstreamSubsets `json:"subsets,omitempty"`
+
+	// fallback is a secondary upstream that receives requests when this
+	// one has no healthy hosts or a request to it fails.
+	// +optional
+	Fallback *UpstreamFallback `json:"fallback,omitempty"`
+
+	// connection tunes the connections opened to the upstream hosts.
+	// +optional
+	Connection *UpstreamConnection `json:"connection,omitempty"`
+}
+
+// UpstreamConnection limits the connections to an upstream's hosts.
+type UpstreamConnection struct {
+	// maxConnectionsPerHost caps the connections opened to each host.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConnectionsPerHost uint32 `json:"maxConnectionsPerHost,omitempty"`
+
+	// maxRequestsPerConnection is the number of requests after which a
+	// connection is closed and replaced; unlimited when unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxRequestsPerConnection uint32 `json:"maxRequestsPerConnection,omitempty"`
+}
+
+// UpstreamFallback is the secondary upstream of an API.
+type UpstreamFallback struct {
+	// host is the hostname or IP of the fallback service.
+	// +required
+	Host string `json:"host"`
+
+	// port is the port of the fallback service.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint32 `json:"port"`
+
+	// scheme is the protocol scheme (http or https).
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+
+	// tls configures certificate verification and the client certificate
+	// for mTLS; used when scheme is https.
+	// +optional
+	TLS *UpstreamTLS `json:"tls,omitempty"`
+}
+
+// UpstreamSubsets splits an upstream's hosts into subsets by their
+// metadata and pins the deployment's routes to one of them.
+type UpstreamSubsets struct {
+	// selectors are the metadata key sets to build subsets for,
+	// e.g. [["zone"], ["version", "zone"]].
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Selectors [][]string `json:"selectors"`
+
+	// match is the metadata routes select hosts by. Requests fall back to
+	// any host when no host matches.
+	// +optional
+	Match map[string]string `json:"match,omitempty"`
+}
+
+// UpstreamHost is one weighted instance of a multi-host upstream.
+type UpstreamHost struct {
+	// host is the hostname or IP of the instance.
+	// +required
+	Host string `json:"host"`
+
+	// port is the port of the instance; defaults to the upstream port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint32 `json:"port,omitempty"`
+
+	// weight is the relative load balancing weight (default 1).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Weight uint32 `json:"weight,omitempty"`
+
+	// metadata labels the instance for subset load balancing.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// UpstreamOverride replaces individual fields of an API's upstream. Unset
+// fields keep the value from the next level down.
+type UpstreamOverride struct {
+	// host is the hostname or IP of the upstream service.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// port is the port of the upstream service.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint32 `json:"port,omitempty"`
+
+	// scheme is the protocol scheme (http or https).
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+
+	// timeout is the request timeout (e.g., "30s", "5m").
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// tls replaces the upstream TLS settings as a whole.
+	// +optional
+	TLS *UpstreamTLS `json:"tls,omitempty"`
+}
+
+// UpstreamTLS configures how upstream certificates are verified and which
+// client certificate is presented for mTLS.
+type UpstreamTLS struct {
+	// caPath is the CA bundle to trust; defaults to the system CA bundle.
+	// +optional
+	CAPath string `json:"caPath,omitempty"`
+
+	// certPath is the client certificate presented to the upstream.
+	// +optional
+	CertPath string `json:"certPath,omitempty"`
+
+	// keyPath is the private key of the client certificate.
+	// +optional
+	KeyPath string `json:"keyPath,omitempty"`
+
+	// spiffeIDs are the SPIFFE IDs accepted as the upstream's URI SAN.
+	// +optional
+	SPIFFEIDs []string `json:"spiffeIDs,omitempty"`
+
+	// dnsNames are the DNS names accepted as the upstream's DNS SAN.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
 }
 
 // RoutingConfig defines route matching behavior for an API.
@@ -84,6 +233,26 @@ type RoutingConfig struct {
 	// +optional
 	// +kubebuilder:validation:Enum=round-robin;least-request;random;consistent-hash;locality-aware
 	LoadBalancing string `json:"loadBalancing,omitempty"`
+
+	// groupByTag splits endpoints into one virtual host per first OpenAPI
+	// tag, each served on a "<tag>." subdomain of the deployment's domains.
+	// Untagged endpoints stay in the default virtual host. On a TLS
+	// listener with an exact hostname the subdomains would not match the
+	// SNI filter chain, so all endpoints stay in the default virtual host.
+	// +optional
+	GroupByTag bool `json:"groupByTag,omitempty"`
+
+	// queryParams are query parameters every route of the API matches on,
+	// e.g. {"version": "2"} to serve only requests with ?version=2. An empty
+	// value matches any value of a parameter that is present.
+	// +optional
+	QueryParams map[string]string `json:"queryParams,omitempty"`
+
+	// virtualHost names a virtual host shared with other APIs on the same
+	// environment. APIs naming the same one compose into a single virtual
+	// host, each contributing the routes under its own context.
+	// +optional
+	VirtualHost string `json:"virtualHost,omitempty"`
 }
 
 // PolicyInstance represents an attached policy with its configuration.
@@ -144,9 +313,10 @@ type StrategyConfig struct {
 
 // DeploymentStrategyConfig configures the deployment strategy.
 type DeploymentStrategyConfig struct {
-	// type is the deployment strategy: basic, canary, blue-green.
+	// type is the deployment strategy: basic, canary, blue-green,
+	// dynamic-forward-proxy.
 	// +required
-	// +kubebuilder:validation:Enum=basic;canary;blue-green
+	// +kubebuilder:validation:Enum=basic;canary;blue-green;dynamic-forward-proxy
 	Type string `json:"type"`
 
 	// canary holds canary-specific configuration.
@@ -156,14 +326,63 @@ type DeploymentStrategyConfig struct {
 	// blueGreen holds blue-green-specific configuration.
 	// +optional
 	BlueGreen *BlueGreenConfig `json:"blueGreen,omitempty"`
+
+	// dynamicForwardProxy holds dynamic-forward-proxy-specific configuration.
+	// +optional
+	DynamicForwardProxy *DynamicForwardProxyConfig `json:"dynamicForwardProxy,omitempty"`
+}
+
+// DynamicForwardProxyConfig defines dynamic forward proxy settings.
+type DynamicForwardProxyConfig struct {
+	// allowedHosts are the upstream hosts requests may be proxied to: exact
+	// hostnames, or "*.example.com" for any subdomain. Requests naming any
+	// other host are not routed.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	AllowedHosts []string `json:"allowedHosts"`
+
+	// hostHeader names a request header holding the upstream host[:port].
+	// Defaults to the Host header.
+	// +optional
+	HostHeader string `json:"hostHeader,omitempty"`
 }
 
 // CanaryConfig defines canary deployment settings.
 type CanaryConfig struct {
+	// baselineVersion is the stable version. Defaults to the API's
+	// version.
+	// +optional
+	BaselineVersion string `json:"baselineVersion,omitempty"`
+
+	// canaryVersion is the version being tested. Defaults to
+	// "canary".
+	// +optional
+	CanaryVersion string `json:"canaryVersion,omitempty"`
+
 	// canaryWeight is the percentage of traffic routed to the canary (0-100).
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
 	CanaryWeight int `json:"canaryWeight,omitempty"`
+
+	// upstream serves the canary version. host is required; unset fields
+	// are taken from the deployment's upstream.
+	// +required
+	Upstream *UpstreamOverride `json:"upstream"`
+
+	// sticky pins each client to the version it was first sent to: the
+	// first response sets a cookie naming the version, and requests
+	// carrying it skip the weighted split.
+	// +optional
+	Sticky bool `json:"sticky,omitempty"`
+
+	// stickyCookie names the pinning cookie. Defaults to "flowc-canary".
+	// +optional
+	StickyCookie string `json:"stickyCookie,omitempty"`
+
+	// stickyTTL is how long the pinning cookie lasts (e.g., "24h").
+	// Unset makes it a session cookie.
+	// +optional
+	StickyTTL string `json:"stickyTTL,omitempty"`
 }
 
 // BlueGreenConfig defines blue-green deployment settings.
@@ -211,6 +430,37 @@ type LoadBalancingStrategyConfig struct {
 	// healthCheck configures active health checking.
 	// +optional
 	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
+
+	// healthyPanicThreshold is the percentage of healthy hosts below which
+	// Envoy routes to all hosts regardless of health (default 50). 0
+	// disables panic routing, so requests fail fast when no host is healthy.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	HealthyPanicThreshold *int32 `json:"healthyPanicThreshold,omitempty"`
+
+	// slowStart ramps traffic to newly added endpoints up gradually
+	// (round-robin and least-request only).
+	// +optional
+	SlowStart *SlowStartConfig `json:"slowStart,omitempty"`
+}
+
+// SlowStartConfig configures the warm-up window of new endpoints.
+type SlowStartConfig struct {
+	// window is how long a new endpoint takes to reach its full weight (e.g., "60s").
+	// +required
+	Window string `json:"window"`
+
+	// minWeightPercent is the share of its weight a new endpoint starts at (default 10).
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinWeightPercent int32 `json:"minWeightPercent,omitempty"`
+
+	// aggression shapes the ramp as a decimal (e.g., "1.5"); 1.0 (default) is linear, higher sends more traffic early.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Aggression string `json:"aggression,omitempty"`
 }
 
 // HealthCheckConfig configures health checking.
@@ -254,6 +504,18 @@ type RetryStrategyConfig struct {
 	// perTryTimeout is the timeout per retry attempt (e.g., "2s").
 	// +optional
 	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+
+	// hostPredicates steer retries away from the hosts (previous_hosts) or priority levels
+	// (previous_priorities) already tried. Defaults to previous_hosts.
+	// +optional
+	// +kubebuilder:validation:items:Enum=previous_hosts;previous_priorities
+	HostPredicates []string `json:"hostPredicates,omitempty"`
+
+	// hostSelectionMaxAttempts is how many hosts a retry may pick before settling for one the
+	// predicates reject (default 3).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	HostSelectionMaxAttempts int64 `json:"hostSelectionMaxAttempts,omitempty"`
 }
 
 // RateLimitStrategyConfig configures rate limiting.
@@ -279,7 +541,10 @@ type ObservabilityStrategyConfig struct {
 	AccessLogs *AccessLogsConfig `json:"accessLogs,omitempty"`
 }
 
-// AccessLogsConfig configures access logging.
+// AccessLogsConfig configures access logging. In a gateway's defaults it
+// enables the access log of each of the gateway's listeners, whose entries
+// include the upstream cluster and host, Envoy's response flags and the
+// request and response durations.
 type AccessLogsConfig struct {
 	// enabled activates access logging.
 	// +optional
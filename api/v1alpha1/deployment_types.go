@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,6 +32,45 @@ type DeploymentSpec struct {
 	// strategy overrides API/gateway defaults for this deployment.
 	// +optional
 	Strategy *StrategyConfig `json:"strategy,omitempty"`
+	// filters enables opt-in HTTP filters on the target listener for this deployment.
+	// +optional
+	Filters *DeploymentFilters `json:"filters,omitempty"`
+	// upstream overrides the API's upstream (and any gateway default) for this deployment.
+	// +optional
+	Upstream *UpstreamOverride `json:"upstream,omitempty"`
+	// mirror shadows a percentage of the deployment's requests to a second upstream.
+	// +optional
+	Mirror *MirrorConfig `json:"mirror,omitempty"`
+	// extraRouteConfig is raw Envoy Route protobuf-JSON merged into every route of the deployment.
+	// Only honoured when the control plane enables the envoy_overrides feature.
+	// +optional
+	ExtraRouteConfig *apiextensionsv1.JSON `json:"extraRouteConfig,omitempty"`
+	// extraClusterConfig is raw Envoy Cluster protobuf-JSON merged into every cluster of the deployment.
+	// Only honoured when the control plane enables the envoy_overrides feature.
+	// +optional
+	ExtraClusterConfig *apiextensionsv1.JSON `json:"extraClusterConfig,omitempty"`
+}
+
+// MirrorConfig configures request mirroring (shadowing). Mirrored requests
+// are sent fire-and-forget; their responses are discarded.
+type MirrorConfig struct {
+	// host is the hostname or IP of the mirror upstream.
+	// +required
+	Host string `json:"host"`
+	// port is the port of the mirror upstream.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint32 `json:"port"`
+	// scheme is the protocol scheme (http or https).
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// percentage is the percentage of requests mirrored (0-100).
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percentage int `json:"percentage,omitempty"`
 }
 
 // DeploymentGatewayRef identifies the target gateway and listener for a deployment.
@@ -43,6 +83,113 @@ type DeploymentGatewayRef struct {
 	Listener string `json:"listener,omitempty"`
 }
 
+// DeploymentFilters holds the opt-in HTTP filters a deployment contributes to
+// the HTTP connection manager of its target listener.
+type DeploymentFilters struct {
+	// grpcJsonTranscoder enables gRPC-JSON transcoding. Only valid for APIs with apiType grpc.
+	// +optional
+	GRPCJSONTranscoder *GRPCJSONTranscoderConfig `json:"grpcJsonTranscoder,omitempty"`
+	// admissionControl sheds load when the upstream success rate drops below a threshold.
+	// +optional
+	AdmissionControl *AdmissionControlConfig `json:"admissionControl,omitempty"`
+	// cache enables response caching in an in-memory cache.
+	// +optional
+	Cache *CacheConfig `json:"cache,omitempty"`
+	// corsFromSpec derives per-route CORS policies from the OPTIONS operations declared in the API spec.
+	// +optional
+	CORSFromSpec bool `json:"corsFromSpec,omitempty"`
+	// corsMaxAge is the Access-Control-Max-Age, in seconds, of the CORS policies whose
+	// OPTIONS responses declare none, so browsers cache preflight results.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CORSMaxAge int32 `json:"corsMaxAge,omitempty"`
+	// requiredHeadersFromSpec rejects requests missing a header parameter the API spec declares
+	// required with 400, rather than forwarding them to the upstream.
+	// +optional
+	RequiredHeadersFromSpec bool `json:"requiredHeadersFromSpec,omitempty"`
+	// decompressor decompresses compressed request bodies before they reach the upstream.
+	// +optional
+	Decompressor *DecompressorConfig `json:"decompressor,omitempty"`
+	// buffer buffers each request's full body before forwarding it, for upstreams that need
+	// the whole body up front (e.g. to verify a signature). Requests are streamed when unset.
+	// +optional
+	Buffer bool `json:"buffer,omitempty"`
+	// bufferMaxRequestBytes is the largest request body buffered, in bytes; larger requests are
+	// rejected with 413 (default 1 MiB). Only used with buffer.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	BufferMaxRequestBytes int32 `json:"bufferMaxRequestBytes,omitempty"`
+}
+
+// GRPCJSONTranscoderConfig configures the grpc_json_transcoder HTTP filter.
+type GRPCJSONTranscoderConfig struct {
+	// protoDescriptorBin is the serialized FileDescriptorSet for the services (base64-encoded).
+	// +required
+	ProtoDescriptorBin []byte `json:"protoDescriptorBin"`
+	// services lists the fully-qualified gRPC service names to transcode.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Services []string `json:"services"`
+	// autoMapping maps POST /<package>.<Service>/<Method> for methods without HTTP annotations.
+	// +optional
+	AutoMapping bool `json:"autoMapping,omitempty"`
+	// ignoreUnknownQueryParameters ignores query parameters that do not map to request fields.
+	// +optional
+	IgnoreUnknownQueryParameters bool `json:"ignoreUnknownQueryParameters,omitempty"`
+}
+
+// AdmissionControlConfig configures the admission_control HTTP filter.
+type AdmissionControlConfig struct {
+	// successRateThreshold is the success rate percentage below which requests are rejected.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SuccessRateThreshold int32 `json:"successRateThreshold,omitempty"`
+	// samplingWindow is the sliding window over which the success rate is measured (e.g., "30s").
+	// +optional
+	SamplingWindow string `json:"samplingWindow,omitempty"`
+}
+
+// CacheConfig configures the HTTP cache filter.
+type CacheConfig struct {
+	// ttl is how long responses are cached (e.g., "60s").
+	// +required
+	TTL string `json:"ttl"`
+	// methods are the request methods whose responses may be cached (default GET).
+	// +optional
+	// +kubebuilder:validation:items:Enum=GET;HEAD
+	Methods []string `json:"methods,omitempty"`
+	// varyHeaders are the request headers responses may vary on. They are
+	// added to the Vary header of cacheable responses, so the cache keys
+	// responses on them.
+	// +optional
+	VaryHeaders []string `json:"varyHeaders,omitempty"`
+}
+
+// DecompressorConfig configures request body decompression.
+type DecompressorConfig struct {
+	// algorithms are the request content encodings to decompress (default gzip).
+	// +optional
+	// +kubebuilder:validation:items:Enum=gzip;brotli
+	Algorithms []string `json:"algorithms,omitempty"`
+}
+
+// Deployment phases.
+const (
+	DeploymentPhasePending   = "Pending"
+	DeploymentPhaseDeploying = "Deploying"
+	DeploymentPhaseDeployed  = "Deployed"
+	DeploymentPhaseFailed    = "Failed"
+)
+
+// DeploymentPhases lists the phases a Deployment can report.
+var DeploymentPhases = []string{
+	DeploymentPhasePending,
+	DeploymentPhaseDeploying,
+	DeploymentPhaseDeployed,
+	DeploymentPhaseFailed,
+}
+
 // DeploymentStatus defines the observed state of Deployment.
 type DeploymentStatus struct {
 	// phase is the current lifecycle phase: Pending, Deploying, Deployed, Failed.
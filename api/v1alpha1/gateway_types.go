@@ -25,9 +25,27 @@ type GatewaySpec struct {
 	// nodeId is the Envoy node ID for xDS; must be unique across gateways.
 	// +required
 	NodeID string `json:"nodeId"`
+	// description is a human-readable summary of the gateway.
+	// +optional
+	Description string `json:"description,omitempty"`
 	// defaults are optional strategy defaults for APIs deployed to this gateway.
 	// +optional
 	Defaults *StrategyConfig `json:"defaults,omitempty"`
+	// upstreams are per-API upstream defaults for this gateway, keyed by API name.
+	// They take precedence over the API's upstream; a deployment's upstream
+	// takes precedence over both.
+	// +optional
+	Upstreams map[string]UpstreamOverride `json:"upstreams,omitempty"`
+	// ttl makes the gateway ephemeral (e.g. a preview environment): once
+	// this long (e.g. "24h") has passed since its creation and no Envoy is
+	// connected to its node, the gateway is deleted along with its listeners,
+	// deployments and gateway policies, and its xDS snapshot dropped.
+	// +optional
+	TTL string `json:"ttl,omitempty"`
+	// autoHTTPSRedirect sets httpsRedirect on every plaintext "data"
+	// listener of the gateway when it also has a listener with tls.
+	// +optional
+	AutoHTTPSRedirect bool `json:"autoHTTPSRedirect,omitempty"`
 }
 
 // GatewayStatus defines the observed state of Gateway.
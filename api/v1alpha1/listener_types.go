@@ -45,6 +45,223 @@ type ListenerSpec struct {
 	// http2 enables HTTP/2 on the listener.
 	// +optional
 	HTTP2 bool `json:"http2,omitempty"`
+	// http2Options tunes HTTP/2 flow control. Setting it enables HTTP/2
+	// like http2.
+	// +optional
+	HTTP2Options *HTTP2Options `json:"http2Options,omitempty"`
+	// httpsRedirect answers requests made over plain HTTP (":scheme" http)
+	// with a 301 redirect to https, on the port of the listener with tls
+	// serving the same routes, or else of the gateway's first listener with
+	// tls, or else 443. It is typically set on a plaintext listener sharing
+	// a tls listener's routes through routesFrom.
+	// +optional
+	HTTPSRedirect bool `json:"httpsRedirect,omitempty"`
+	// kind selects what the listener serves. "data" (the default) carries
+	// API traffic; "admin/stats" exposes /stats and /ready only, is not a
+	// deployment target and ignores hostnames and tls.
+	// +optional
+	// +kubebuilder:validation:Enum=data;admin/stats
+	Kind string `json:"kind,omitempty"`
+	// listenerFilters are listener filters to run on every connection, in
+	// addition to the tls_inspector a listener with tls gets anyway.
+	// +optional
+	// +kubebuilder:validation:items:Enum=proxy_protocol;original_dst;tls_inspector;http_inspector
+	ListenerFilters []string `json:"listenerFilters,omitempty"`
+	// allowedMethods restricts the HTTP methods served on the listener,
+	// e.g. GET and HEAD for a read-only environment. Requests with other
+	// methods get 405 Method Not Allowed. Empty allows every method.
+	// +optional
+	// +kubebuilder:validation:items:Enum=GET;HEAD;POST;PUT;PATCH;DELETE;OPTIONS;TRACE;CONNECT
+	AllowedMethods []string `json:"allowedMethods,omitempty"`
+	// stats configures where an "admin/stats" listener sends its traffic.
+	// +optional
+	Stats *StatsListenerConfig `json:"stats,omitempty"`
+	// hcm configures the HTTP connection manager of a "data" listener.
+	// +optional
+	HCM *HCMOptions `json:"hcm,omitempty"`
+	// tap captures matching requests and responses with Envoy's tap filter
+	// for debugging. Ignored on listeners of production environments (see
+	// EnvironmentLabel).
+	// +optional
+	Tap *TapConfig `json:"tap,omitempty"`
+	// authFailureResponse replaces the 401 and 403 replies Envoy sends when
+	// an authentication or authorization filter (jwt_authn, ext_authz)
+	// rejects a request. Upstream responses are passed through unchanged.
+	// +optional
+	AuthFailureResponse *AuthFailureResponse `json:"authFailureResponse,omitempty"`
+	// localReply replaces the bodies of other replies Envoy generates
+	// itself, such as 404 for no matching route or 503 for no healthy
+	// upstream, on every hostname of the listener.
+	// +optional
+	LocalReply *LocalReplyConfig `json:"localReply,omitempty"`
+	// routesFrom names another "data" listener of the same gateway whose
+	// environment this listener also serves, e.g. the second port of a
+	// blue/green pair. Its filter chains use that listener's hostnames and
+	// route configurations, which both listeners share; its own hostnames
+	// are ignored, and deployments targeting it land on that listener.
+	// +optional
+	RoutesFrom string `json:"routesFrom,omitempty"`
+	// trafficSplit sends a share of the requests for this listener's
+	// hostnames to another environment of the same gateway serving them,
+	// e.g. while migrating between two backend stacks.
+	// +optional
+	TrafficSplit *TrafficSplit `json:"trafficSplit,omitempty"`
+}
+
+// TrafficSplit weights the routes of an environment between its own
+// clusters and those of another environment. A route is split when the
+// other environment has a route with the same match on a hostname both
+// serve; the route keeps its own settings, such as rewrites and timeouts,
+// for both. Other routes are not split.
+type TrafficSplit struct {
+	// listener is the "data" listener of the other environment.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Listener string `json:"listener"`
+	// weight is the percentage of requests sent to the other environment.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Weight uint32 `json:"weight"`
+}
+
+// LocalReplyConfig maps local replies to custom bodies.
+type LocalReplyConfig struct {
+	// mappings are tried in order; the first matching the reply's status
+	// applies.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Mappings []LocalReplyMapping `json:"mappings"`
+}
+
+// LocalReplyMapping is the body of local replies with a status.
+type LocalReplyMapping struct {
+	// status is an exact status code ("503") or a class of them ("5xx").
+	// +required
+	// +kubebuilder:validation:Pattern=`^[1-5]([0-9][0-9]|xx)$`
+	Status string `json:"status"`
+	// contentType of body (default "text/plain").
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+	// body is a template in which Envoy command operators such as
+	// %RESPONSE_CODE% and %LOCAL_REPLY_BODY% are substituted.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Body string `json:"body"`
+}
+
+// AuthFailureResponse is the response sent for rejected requests.
+type AuthFailureResponse struct {
+	// statusCode replaces the rejection's status. Unset keeps 401 or 403.
+	// +optional
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	StatusCode uint32 `json:"statusCode,omitempty"`
+	// contentType of body (default "text/plain").
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+	// body is sent as is.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Body string `json:"body"`
+}
+
+// TapConfig configures the tap HTTP filter of a "data" listener.
+type TapConfig struct {
+	// output selects where taps go: "admin" (the default) streams them to
+	// clients of Envoy's /tap admin endpoint, which supply the match in
+	// their request; "streamed" writes every matching request to a file
+	// per tap under pathPrefix.
+	// +optional
+	// +kubebuilder:validation:Enum=admin;streamed
+	Output string `json:"output,omitempty"`
+	// match limits "streamed" taps to matching requests. Without it every
+	// request is tapped.
+	// +optional
+	Match *TapMatch `json:"match,omitempty"`
+	// pathPrefix is the file path prefix of "streamed" taps
+	// (default "/tmp/flowc_tap_<listener>").
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// TapMatch selects the requests a tap captures.
+type TapMatch struct {
+	// requestHeaders are headers a request must carry, with exactly these
+	// values, to be tapped.
+	// +optional
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+}
+
+// HTTP2Options configures HTTP/2 flow-control windows and stream limits.
+// Unset sizes keep Envoy's default of 256 MiB.
+type HTTP2Options struct {
+	// initialStreamWindowSize is the initial flow-control window of each
+	// stream, in bytes.
+	// +optional
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	InitialStreamWindowSize uint32 `json:"initialStreamWindowSize,omitempty"`
+	// initialConnectionWindowSize is the initial flow-control window of
+	// each connection, in bytes.
+	// +optional
+	// +kubebuilder:validation:Minimum=65535
+	// +kubebuilder:validation:Maximum=2147483647
+	InitialConnectionWindowSize uint32 `json:"initialConnectionWindowSize,omitempty"`
+	// maxConcurrentStreams caps the streams a client may have open at
+	// once on a connection. Unset keeps Envoy's default of 2147483647.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2147483647
+	MaxConcurrentStreams uint32 `json:"maxConcurrentStreams,omitempty"`
+}
+
+// HCMOptions configures how the HTTP connection manager derives the
+// client address and how long downstream connections live.
+type HCMOptions struct {
+	// useRemoteAddress uses the downstream connection's address, rather
+	// than X-Forwarded-For, as the client address.
+	// +optional
+	UseRemoteAddress bool `json:"useRemoteAddress,omitempty"`
+	// xffNumTrustedHops is the number of trusted proxies in front of Envoy
+	// when deriving the client address from X-Forwarded-For.
+	// +optional
+	XFFNumTrustedHops uint32 `json:"xffNumTrustedHops,omitempty"`
+	// maxConnectionDuration drains a downstream connection once it has
+	// been open this long (e.g., "10m"), so clients behind an L4 load
+	// balancer reconnect and spread out instead of staying pinned to one
+	// Envoy. Unset keeps connections open indefinitely.
+	// +optional
+	MaxConnectionDuration string `json:"maxConnectionDuration,omitempty"`
+	// maxRequestsPerConnection drains a downstream connection after it
+	// has served this many requests. Unset does not limit them.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxRequestsPerConnection uint32 `json:"maxRequestsPerConnection,omitempty"`
+}
+
+// Listener kinds.
+const (
+	ListenerKindData       = "data"
+	ListenerKindAdminStats = "admin/stats"
+)
+
+// EnvironmentLabel names the environment a Listener (or every listener of
+// a Gateway) serves. Listeners labeled "prod" or "production", directly
+// or through their gateway, never get a tap filter.
+const EnvironmentLabel = "flowc.io/environment"
+
+// StatsListenerConfig defines the backend of an "admin/stats" listener.
+type StatsListenerConfig struct {
+	// address of Envoy's admin interface or a stats sink
+	// (default "127.0.0.1").
+	// +optional
+	Address string `json:"address,omitempty"`
+	// port of Envoy's admin interface or a stats sink (default 9901).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint32 `json:"port,omitempty"`
 }
 
 // ListenerStatus defines the observed state of Listener.
@@ -324,11 +324,11 @@ func (in *APIRateLimitConfig) DeepCopy() *APIRateLimitConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APISpec) DeepCopyInto(out *APISpec) {
 	*out = *in
-	out.Upstream = in.Upstream
+	in.Upstream.DeepCopyInto(&out.Upstream)
 	if in.Routing != nil {
 		in, out := &in.Routing, &out.Routing
 		*out = new(RoutingConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.PolicyChain != nil {
 		in, out := &in.PolicyChain, &out.PolicyChain
@@ -391,6 +391,36 @@ func (in *AccessLogsConfig) DeepCopy() *AccessLogsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionControlConfig) DeepCopyInto(out *AdmissionControlConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionControlConfig.
+func (in *AdmissionControlConfig) DeepCopy() *AdmissionControlConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionControlConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthFailureResponse) DeepCopyInto(out *AuthFailureResponse) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthFailureResponse.
+func (in *AuthFailureResponse) DeepCopy() *AuthFailureResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthFailureResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthNProviderRef) DeepCopyInto(out *AuthNProviderRef) {
 	*out = *in
@@ -698,9 +728,39 @@ func (in *CORSConfig) DeepCopy() *CORSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
+	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VaryHeaders != nil {
+		in, out := &in.VaryHeaders, &out.VaryHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheConfig.
+func (in *CacheConfig) DeepCopy() *CacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryConfig) DeepCopyInto(out *CanaryConfig) {
 	*out = *in
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamOverride)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryConfig.
@@ -768,6 +828,26 @@ func (in *CustomFilter) DeepCopy() *CustomFilter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecompressorConfig) DeepCopyInto(out *DecompressorConfig) {
+	*out = *in
+	if in.Algorithms != nil {
+		in, out := &in.Algorithms, &out.Algorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecompressorConfig.
+func (in *DecompressorConfig) DeepCopy() *DecompressorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DecompressorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Deployment) DeepCopyInto(out *Deployment) {
 	*out = *in
@@ -795,6 +875,41 @@ func (in *Deployment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentFilters) DeepCopyInto(out *DeploymentFilters) {
+	*out = *in
+	if in.GRPCJSONTranscoder != nil {
+		in, out := &in.GRPCJSONTranscoder, &out.GRPCJSONTranscoder
+		*out = new(GRPCJSONTranscoderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdmissionControl != nil {
+		in, out := &in.AdmissionControl, &out.AdmissionControl
+		*out = new(AdmissionControlConfig)
+		**out = **in
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(CacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Decompressor != nil {
+		in, out := &in.Decompressor, &out.Decompressor
+		*out = new(DecompressorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentFilters.
+func (in *DeploymentFilters) DeepCopy() *DeploymentFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentGatewayRef) DeepCopyInto(out *DeploymentGatewayRef) {
 	*out = *in
@@ -851,6 +966,31 @@ func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
 		*out = new(StrategyConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(DeploymentFilters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upstream != nil {
+		in, out := &in.Upstream, &out.Upstream
+		*out = new(UpstreamOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(MirrorConfig)
+		**out = **in
+	}
+	if in.ExtraRouteConfig != nil {
+		in, out := &in.ExtraRouteConfig, &out.ExtraRouteConfig
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraClusterConfig != nil {
+		in, out := &in.ExtraClusterConfig, &out.ExtraClusterConfig
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
@@ -891,13 +1031,18 @@ func (in *DeploymentStrategyConfig) DeepCopyInto(out *DeploymentStrategyConfig)
 	if in.Canary != nil {
 		in, out := &in.Canary, &out.Canary
 		*out = new(CanaryConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.BlueGreen != nil {
 		in, out := &in.BlueGreen, &out.BlueGreen
 		*out = new(BlueGreenConfig)
 		**out = **in
 	}
+	if in.DynamicForwardProxy != nil {
+		in, out := &in.DynamicForwardProxy, &out.DynamicForwardProxy
+		*out = new(DynamicForwardProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStrategyConfig.
@@ -910,6 +1055,26 @@ func (in *DeploymentStrategyConfig) DeepCopy() *DeploymentStrategyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicForwardProxyConfig) DeepCopyInto(out *DynamicForwardProxyConfig) {
+	*out = *in
+	if in.AllowedHosts != nil {
+		in, out := &in.AllowedHosts, &out.AllowedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicForwardProxyConfig.
+func (in *DynamicForwardProxyConfig) DeepCopy() *DynamicForwardProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicForwardProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EntryPolicyConfig) DeepCopyInto(out *EntryPolicyConfig) {
 	*out = *in
@@ -935,6 +1100,31 @@ func (in *EntryPolicyConfig) DeepCopy() *EntryPolicyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCJSONTranscoderConfig) DeepCopyInto(out *GRPCJSONTranscoderConfig) {
+	*out = *in
+	if in.ProtoDescriptorBin != nil {
+		in, out := &in.ProtoDescriptorBin, &out.ProtoDescriptorBin
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCJSONTranscoderConfig.
+func (in *GRPCJSONTranscoderConfig) DeepCopy() *GRPCJSONTranscoderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCJSONTranscoderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Gateway) DeepCopyInto(out *Gateway) {
 	*out = *in
@@ -1189,6 +1379,13 @@ func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 		*out = new(StrategyConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make(map[string]UpstreamOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
@@ -1223,6 +1420,36 @@ func (in *GatewayStatus) DeepCopy() *GatewayStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HCMOptions) DeepCopyInto(out *HCMOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HCMOptions.
+func (in *HCMOptions) DeepCopy() *HCMOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(HCMOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP2Options) DeepCopyInto(out *HTTP2Options) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP2Options.
+func (in *HTTP2Options) DeepCopy() *HTTP2Options {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP2Options)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheckConfig) DeepCopyInto(out *HealthCheckConfig) {
 	*out = *in
@@ -1380,6 +1607,51 @@ func (in *ListenerSpec) DeepCopyInto(out *ListenerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HTTP2Options != nil {
+		in, out := &in.HTTP2Options, &out.HTTP2Options
+		*out = new(HTTP2Options)
+		**out = **in
+	}
+	if in.ListenerFilters != nil {
+		in, out := &in.ListenerFilters, &out.ListenerFilters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedMethods != nil {
+		in, out := &in.AllowedMethods, &out.AllowedMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(StatsListenerConfig)
+		**out = **in
+	}
+	if in.HCM != nil {
+		in, out := &in.HCM, &out.HCM
+		*out = new(HCMOptions)
+		**out = **in
+	}
+	if in.Tap != nil {
+		in, out := &in.Tap, &out.Tap
+		*out = new(TapConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuthFailureResponse != nil {
+		in, out := &in.AuthFailureResponse, &out.AuthFailureResponse
+		*out = new(AuthFailureResponse)
+		**out = **in
+	}
+	if in.LocalReply != nil {
+		in, out := &in.LocalReply, &out.LocalReply
+		*out = new(LocalReplyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrafficSplit != nil {
+		in, out := &in.TrafficSplit, &out.TrafficSplit
+		*out = new(TrafficSplit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerSpec.
@@ -1422,6 +1694,16 @@ func (in *LoadBalancingStrategyConfig) DeepCopyInto(out *LoadBalancingStrategyCo
 		*out = new(HealthCheckConfig)
 		**out = **in
 	}
+	if in.HealthyPanicThreshold != nil {
+		in, out := &in.HealthyPanicThreshold, &out.HealthyPanicThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SlowStart != nil {
+		in, out := &in.SlowStart, &out.SlowStart
+		*out = new(SlowStartConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingStrategyConfig.
@@ -1434,6 +1716,41 @@ func (in *LoadBalancingStrategyConfig) DeepCopy() *LoadBalancingStrategyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalReplyConfig) DeepCopyInto(out *LocalReplyConfig) {
+	*out = *in
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]LocalReplyMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalReplyConfig.
+func (in *LocalReplyConfig) DeepCopy() *LocalReplyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalReplyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalReplyMapping) DeepCopyInto(out *LocalReplyMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalReplyMapping.
+func (in *LocalReplyMapping) DeepCopy() *LocalReplyMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalReplyMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservabilityConfig) DeepCopyInto(out *ObservabilityConfig) {
 	*out = *in
@@ -1454,6 +1771,21 @@ func (in *ObservabilityConfig) DeepCopy() *ObservabilityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorConfig) DeepCopyInto(out *MirrorConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorConfig.
+func (in *MirrorConfig) DeepCopy() *MirrorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservabilityStrategyConfig) DeepCopyInto(out *ObservabilityStrategyConfig) {
 	*out = *in
@@ -1729,6 +2061,11 @@ func (in *ResponseTransform) DeepCopy() *ResponseTransform {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetryStrategyConfig) DeepCopyInto(out *RetryStrategyConfig) {
 	*out = *in
+	if in.HostPredicates != nil {
+		in, out := &in.HostPredicates, &out.HostPredicates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryStrategyConfig.
@@ -1759,6 +2096,13 @@ func (in *RouteMatchStrategyConfig) DeepCopy() *RouteMatchStrategyConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoutingConfig) DeepCopyInto(out *RoutingConfig) {
 	*out = *in
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingConfig.
@@ -1771,6 +2115,36 @@ func (in *RoutingConfig) DeepCopy() *RoutingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowStartConfig) DeepCopyInto(out *SlowStartConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowStartConfig.
+func (in *SlowStartConfig) DeepCopy() *SlowStartConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowStartConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatsListenerConfig) DeepCopyInto(out *StatsListenerConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatsListenerConfig.
+func (in *StatsListenerConfig) DeepCopy() *StatsListenerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StatsListenerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StrategyConfig) DeepCopyInto(out *StrategyConfig) {
 	*out = *in
@@ -1792,7 +2166,7 @@ func (in *StrategyConfig) DeepCopyInto(out *StrategyConfig) {
 	if in.Retry != nil {
 		in, out := &in.Retry, &out.Retry
 		*out = new(RetryStrategyConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.RateLimit != nil {
 		in, out := &in.RateLimit, &out.RateLimit
@@ -1836,6 +2210,48 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TapConfig) DeepCopyInto(out *TapConfig) {
+	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(TapMatch)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TapConfig.
+func (in *TapConfig) DeepCopy() *TapConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TapConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TapMatch) DeepCopyInto(out *TapMatch) {
+	*out = *in
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TapMatch.
+func (in *TapMatch) DeepCopy() *TapMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TapMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeoutConfig) DeepCopyInto(out *TimeoutConfig) {
 	*out = *in
@@ -1851,6 +2267,21 @@ func (in *TimeoutConfig) DeepCopy() *TimeoutConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplit) DeepCopyInto(out *TrafficSplit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplit.
+func (in *TrafficSplit) DeepCopy() *TrafficSplit {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransformationConfig) DeepCopyInto(out *TransformationConfig) {
 	*out = *in
@@ -1879,6 +2310,33 @@ func (in *TransformationConfig) DeepCopy() *TransformationConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamConfig) DeepCopyInto(out *UpstreamConfig) {
 	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]UpstreamHost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(UpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subsets != nil {
+		in, out := &in.Subsets, &out.Subsets
+		*out = new(UpstreamSubsets)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(UpstreamFallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(UpstreamConnection)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamConfig.
@@ -1890,3 +2348,138 @@ func (in *UpstreamConfig) DeepCopy() *UpstreamConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamConnection) DeepCopyInto(out *UpstreamConnection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamConnection.
+func (in *UpstreamConnection) DeepCopy() *UpstreamConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamFallback) DeepCopyInto(out *UpstreamFallback) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(UpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamFallback.
+func (in *UpstreamFallback) DeepCopy() *UpstreamFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamHost) DeepCopyInto(out *UpstreamHost) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamHost.
+func (in *UpstreamHost) DeepCopy() *UpstreamHost {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamOverride) DeepCopyInto(out *UpstreamOverride) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(UpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamOverride.
+func (in *UpstreamOverride) DeepCopy() *UpstreamOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamSubsets) DeepCopyInto(out *UpstreamSubsets) {
+	*out = *in
+	if in.Selectors != nil {
+		in, out := &in.Selectors, &out.Selectors
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamSubsets.
+func (in *UpstreamSubsets) DeepCopy() *UpstreamSubsets {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamSubsets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamTLS) DeepCopyInto(out *UpstreamTLS) {
+	*out = *in
+	if in.SPIFFEIDs != nil {
+		in, out := &in.SPIFFEIDs, &out.SPIFFEIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamTLS.
+func (in *UpstreamTLS) DeepCopy() *UpstreamTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamTLS)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest"
+	"github.com/flowc-labs/flowc/pkg/bundle"
+	"github.com/flowc-labs/flowc/pkg/logger"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+const bundleUsage = `Usage: flowc bundle [-o output.zip] [directory]
+
+Packages flowc.yaml and the API specification in directory (default ".")
+into a bundle ZIP, validating both as the control plane would on upload.
+`
+
+// runBundle implements "flowc bundle" and returns the exit code.
+func runBundle(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, bundleUsage, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	output := fs.String("o", "", "bundle file to write (default <directory name>.zip)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	if err := packageBundle(dir, *output, stdout); err != nil {
+		_, _ = fmt.Fprintf(stderr, "flowc bundle: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// packageBundle writes the bundle of dir to output and reports what it
+// contains on stdout.
+func packageBundle(dir, output string, stdout io.Writer) error {
+	flowcYAML, specFile, err := findBundleFiles(dir)
+	if err != nil {
+		return err
+	}
+	specData, err := os.ReadFile(filepath.Join(dir, specFile))
+	if err != nil {
+		return err
+	}
+	zipData, err := bundle.CreateZip(flowcYAML, specData, specFile)
+	if err != nil {
+		return err
+	}
+
+	log := logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel)
+	result := rest.NewValidateHandler(log).ValidateBundle(context.Background(), zipData)
+	var problems []string
+	for _, d := range result.Diagnostics {
+		msg := fmt.Sprintf("%s %s: %s", d.Severity, d.Stage, d.Message)
+		if d.Pointer != "" {
+			msg += " (at " + d.Pointer + ")"
+		}
+		if d.Severity == rest.SeverityError {
+			problems = append(problems, msg)
+		} else {
+			_, _ = fmt.Fprintln(stdout, msg)
+		}
+	}
+	if !result.Valid {
+		return fmt.Errorf("invalid bundle:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	if output == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		output = filepath.Base(abs) + ".zip"
+	}
+	if err := os.WriteFile(output, zipData, 0o644); err != nil {
+		return err
+	}
+
+	var meta types.FlowCMetadata
+	_ = yaml.Unmarshal(flowcYAML, &meta) // validated above
+	apiType := meta.APIType
+	if apiType == "" {
+		apiType = bundle.DetectAPIType(specFile)
+	}
+	_, _ = fmt.Fprintf(stdout, "API type: %s\nFiles:\n  %s\n  %s\nWrote %s\n", apiType, bundle.FlowCFileName, specFile, output)
+	return nil
+}
+
+// findBundleFiles reads dir's flowc.yaml (or flowc.yml) and picks its
+// spec file: the one flowc.yaml names in spec_file, or else the only
+// spec file in dir.
+func findBundleFiles(dir string) (flowcYAML []byte, specFile string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	var specs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch name := e.Name(); {
+		case name == bundle.FlowCFileName || name == "flowc.yml":
+			if flowcYAML == nil {
+				if flowcYAML, err = os.ReadFile(filepath.Join(dir, name)); err != nil {
+					return nil, "", err
+				}
+			}
+		case bundle.IsSpecFile(name):
+			specs = append(specs, name)
+		}
+	}
+	if flowcYAML == nil {
+		return nil, "", fmt.Errorf("%s not found in %s", bundle.FlowCFileName, dir)
+	}
+
+	var meta types.FlowCMetadata
+	if err := yaml.Unmarshal(flowcYAML, &meta); err != nil {
+		return nil, "", fmt.Errorf("parse %s: %w", bundle.FlowCFileName, err)
+	}
+	if meta.SpecFile != "" {
+		if !slices.Contains(specs, meta.SpecFile) {
+			return nil, "", fmt.Errorf("spec_file %s is not a specification file in %s", meta.SpecFile, dir)
+		}
+		return flowcYAML, meta.SpecFile, nil
+	}
+	switch len(specs) {
+	case 0:
+		return nil, "", fmt.Errorf("no API specification file in %s (supported: openapi.yaml, *.proto, *.graphql, asyncapi.yaml)", dir)
+	case 1:
+		return flowcYAML, specs[0], nil
+	default:
+		return nil, "", fmt.Errorf("several specification files in %s (%s); set spec_file in %s", dir, strings.Join(specs, ", "), bundle.FlowCFileName)
+	}
+}
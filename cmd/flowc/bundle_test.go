@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flowc-labs/flowc/pkg/bundle"
+)
+
+const bundleFlowCYAML = `name: petstore
+version: v1
+context: /petstore
+upstream:
+  host: petstore.local
+  port: 8080
+`
+
+const bundleOpenAPIYAML = `openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBundleCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"flowc.yaml":   bundleFlowCYAML,
+		"openapi.yaml": bundleOpenAPIYAML,
+		"README.md":    "# petstore\n",
+	})
+	output := filepath.Join(t.TempDir(), "petstore.zip")
+
+	var stdout, stderr bytes.Buffer
+	if code := runBundle([]string{"-o", output, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr: %s", code, stderr.String())
+	}
+	for _, want := range []string{"API type: rest", "flowc.yaml", "openapi.yaml", "Wrote " + output} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output %q does not mention %q", stdout.String(), want)
+		}
+	}
+
+	zipData, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("bundle not written: %v", err)
+	}
+	files, err := bundle.ListFiles(zipData)
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	slices.Sort(files)
+	if !slices.Equal(files, []string{"flowc.yaml", "openapi.yaml"}) {
+		t.Errorf("bundle files = %v, want flowc.yaml and openapi.yaml", files)
+	}
+}
+
+func TestBundleCommandRejectsInvalidBundle(t *testing.T) {
+	dir := t.TempDir()
+	// flowc.yaml without an upstream
+	writeFiles(t, dir, map[string]string{
+		"flowc.yaml":   "name: petstore\nversion: v1\ncontext: /petstore\n",
+		"openapi.yaml": bundleOpenAPIYAML,
+	})
+	output := filepath.Join(t.TempDir(), "petstore.zip")
+
+	var stdout, stderr bytes.Buffer
+	if code := runBundle([]string{"-o", output, dir}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "invalid bundle") {
+		t.Errorf("stderr = %q, want the validation errors", stderr.String())
+	}
+	if _, err := os.Stat(output); err == nil {
+		t.Error("invalid bundle was written")
+	}
+}
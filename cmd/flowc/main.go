@@ -15,20 +15,30 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
 	"github.com/flowc-labs/flowc/internal/flowc/config"
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
 	"github.com/flowc-labs/flowc/internal/flowc/httpsrv"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/janitor"
 	k8sprovider "github.com/flowc-labs/flowc/internal/flowc/providers/kubernetes"
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest"
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest/loader"
 	"github.com/flowc-labs/flowc/internal/flowc/reconciler"
 	"github.com/flowc-labs/flowc/internal/flowc/store"
 	k8sstore "github.com/flowc-labs/flowc/internal/flowc/store/kubernetes"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/server"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
 func main() {
-	// Create logger
+	if len(os.Args) > 1 && os.Args[1] == "bundle" {
+		os.Exit(runBundle(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	// Bootstrap logger until the logging config is loaded
 	log := logger.NewDefaultEnvoyLogger()
 	log.Info("Starting FlowC XDS Control Plane...")
 
@@ -39,6 +49,12 @@ func main() {
 		log.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	// Replace the bootstrap logger with the configured one
+	log, err = cfg.Logging.NewLogger()
+	if err != nil {
+		logger.NewDefaultEnvoyLogger().WithError(err).Fatal("Failed to create logger")
+	}
+
 	// Log configuration details
 	log.WithFields(map[string]any{
 		"api_port":              cfg.Server.APIPort,
@@ -67,6 +83,32 @@ func main() {
 	}
 	defer storeCleanup()
 
+	// Record every change made to the store in the audit log
+	auditSink, err := buildAuditSink(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create audit log")
+	}
+	resourceStore = audit.NewStore(resourceStore, audit.NewLogger(auditSink, log))
+
+	bundleStore, err := buildBundleStore(cfg)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create bundle store")
+	}
+
+	if cfg.Store.SeedFile != "" {
+		log.WithFields(map[string]any{
+			"seed_file": cfg.Store.SeedFile,
+			"strict":    cfg.Store.SeedStrict,
+		}).Info("Seeding resource store")
+		err := rest.Seed(ctx,
+			rest.NewResourceHandler(resourceStore, log),
+			rest.NewUploadHandler(resourceStore, bundleStore, log),
+			cfg.Store.SeedFile, cfg.Store.SeedStrict, log)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to seed resource store")
+		}
+	}
+
 	// Create XDS server with configuration
 	log.WithFields(map[string]any{
 		"port": cfg.Server.XDSPort,
@@ -78,16 +120,47 @@ func main() {
 		cfg.GetKeepaliveTimeout(),
 		cfg.GetKeepaliveMinTime(),
 		cfg.XDS.GRPC.KeepalivePermitWithoutStream,
+		server.GRPCLimits{
+			MaxConcurrentStreams: uint32(cfg.XDS.GRPC.MaxConcurrentStreams),
+			MaxSendMsgSize:       cfg.XDS.GRPC.MaxSendMsgSize,
+			MaxRecvMsgSize:       cfg.XDS.GRPC.MaxRecvMsgSize,
+		},
 		log,
 	)
 
 	// Create configuration manager
 	log.Info("Creating configuration manager")
 	configManager := cache.NewConfigManager(xdsServer.GetCache(), xdsServer.GetLogger())
+	limits := cfg.XDS.ResourceLimits
+	configManager.SetResourceLimits(cache.ResourceLimits{
+		Clusters:  limits.MaxClusters,
+		Endpoints: limits.MaxEndpoints,
+		Listeners: limits.MaxListeners,
+		Routes:    limits.MaxRoutes,
+		Total:     limits.MaxTotal,
+	})
+	if err := setConsistencyModes(configManager, &cfg.XDS); err != nil {
+		log.WithError(err).Fatal("Invalid snapshot consistency mode")
+	}
 
 	// Create reconciler (watches store, drives xDS translation)
 	log.Info("Creating reconciler")
-	rec := reconciler.NewReconciler(resourceStore, configManager, ir.DefaultParserRegistry(), log)
+	defaultListener := dispatch.DefaultListener{
+		Name:     cfg.XDS.DefaultEnvironmentName,
+		Hostname: cfg.XDS.DefaultEnvironmentHostname,
+		Port:     uint32(cfg.XDS.DefaultListenerPort),
+	}
+	translatorOptions := translator.DefaultTranslatorOptions()
+	translatorOptions.AllowEnvoyOverrides = cfg.Features.EnvoyOverrides
+	rec := reconciler.NewReconciler(resourceStore, configManager, ir.DefaultParserRegistry(), defaultListener, log)
+	rec.SetTranslatorOptions(translatorOptions)
+	drift := dispatch.NewDriftDetector(resourceStore, configManager, ir.DefaultParserRegistry(), defaultListener, log)
+	drift.SetOptions(translatorOptions)
+	routes := dispatch.NewRouteInspector(resourceStore, configManager, defaultListener, log)
+	endpoints := dispatch.NewEndpointInspector(resourceStore, configManager, ir.DefaultParserRegistry(), defaultListener, log)
+	endpoints.SetOptions(translatorOptions)
+	describer := dispatch.NewDeploymentDescriber(resourceStore, ir.DefaultParserRegistry(), defaultListener, log)
+	describer.SetOptions(translatorOptions)
 
 	go func() {
 		<-sigChan
@@ -101,15 +174,27 @@ func main() {
 		"port": cfg.Server.APIPort,
 	}).Info("Creating REST API server")
 
-	restAPIServer := httpsrv.NewServer(
-		cfg.Server.APIPort,
-		cfg.Server.XDSPort,
-		cfg.GetServerReadTimeout(),
-		cfg.GetServerWriteTimeout(),
-		cfg.GetServerIdleTimeout(),
-		resourceStore,
-		log,
-	)
+	restAPIServer := httpsrv.NewServer(resourceStore, httpsrv.Options{
+		Port:          cfg.Server.APIPort,
+		XDSPort:       cfg.Server.XDSPort,
+		ReservedPorts: cfg.Server.ReservedPorts,
+		ReadTimeout:   cfg.GetServerReadTimeout(),
+		WriteTimeout:  cfg.GetServerWriteTimeout(),
+		IdleTimeout:   cfg.GetServerIdleTimeout(),
+		Bundles:       bundleStore,
+		Nodes:         configManager,
+		Streams:       xdsServer.Streams(),
+		Drift:         drift,
+		Routes:        routes,
+		Endpoints:     endpoints,
+		Describer:     describer,
+		DeployLimiter: rest.NewDeployRateLimiter(cfg.Server.DeployRateLimit.Rate, cfg.Server.DeployRateLimit.Burst),
+		Parses:        loader.NewParseLimiter(cfg.Server.MaxConcurrentParses),
+		Audit:         auditSink,
+	}, log)
+	if cfg.Server.RequestIDPrefix != "" {
+		restAPIServer.SetIDGenerator(httpsrv.NewSequentialIDGenerator(cfg.Server.RequestIDPrefix))
+	}
 
 	// Start the XDS server in a goroutine
 	log.Info("Starting XDS server...")
@@ -127,6 +212,9 @@ func main() {
 		}
 	}()
 
+	// Remove ephemeral gateways once their ttl has elapsed
+	go janitor.New(resourceStore, xdsServer.Streams(), log).Run(ctx, cfg.GetGatewayGCInterval())
+
 	// Start the REST API server in a goroutine
 	log.Info("Starting REST API server...")
 	go func() {
@@ -179,6 +267,49 @@ func buildStore(ctx context.Context, cfg *config.Config, log *logger.EnvoyLogger
 	}
 }
 
+// buildAuditSink appends the audit log to cfg.Server.AuditLogFile when it
+// is set, and keeps it in memory otherwise.
+func buildAuditSink(cfg *config.Config) (audit.Sink, error) {
+	if cfg.Server.AuditLogFile == "" {
+		return audit.NewMemorySink(), nil
+	}
+	return audit.NewFileSink(cfg.Server.AuditLogFile)
+}
+
+// buildBundleStore keeps uploaded bundles on disk when cfg.Store.BundleDir
+// is set, and in memory otherwise, cfg.Store.BundleHistory per deployment.
+func buildBundleStore(cfg *config.Config) (store.BundleStore, error) {
+	if cfg.Store.BundleDir == "" {
+		s := store.NewMemoryBundleStore()
+		s.SetHistory(cfg.Store.BundleHistory)
+		return s, nil
+	}
+	s, err := store.NewFileBundleStore(cfg.Store.BundleDir)
+	if err != nil {
+		return nil, err
+	}
+	s.SetHistory(cfg.Store.BundleHistory)
+	return s, nil
+}
+
+// setConsistencyModes applies the configured snapshot consistency modes,
+// globally and per node.
+func setConsistencyModes(cm *cache.ConfigManager, cfg *config.XDSConfig) error {
+	mode, err := cache.ParseConsistencyMode(cfg.ConsistencyMode)
+	if err != nil {
+		return err
+	}
+	cm.SetConsistencyMode(mode)
+	for node, name := range cfg.NodeConsistencyModes {
+		mode, err := cache.ParseConsistencyMode(name)
+		if err != nil {
+			return fmt.Errorf("node %s: %w", node, err)
+		}
+		cm.SetNodeConsistencyMode(node, mode)
+	}
+	return nil
+}
+
 // buildK8sStore stands up a ctrl.Manager (which owns the informer cache),
 // wires the K8sStore to it, optionally registers CRD controllers, and starts
 // the manager. Returns after the cache has performed its initial list-watch.
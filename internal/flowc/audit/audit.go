@@ -0,0 +1,145 @@
+// Package audit keeps an append-only trail of the changes made to the
+// resource store: who created, updated or deleted which resource, and
+// when.
+//
+// Entries are recorded by Store, a store.Store that wraps the real one,
+// after each successful write, so every writer (REST handlers, bundle
+// uploads, seeding, the gateway janitor) is covered. They go to a Sink,
+// kept in memory or appended to a file.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// Action is the kind of change an entry records.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// SystemActor is the actor of changes made outside of a request, such as
+// seeding and garbage collection.
+const SystemActor = "system"
+
+// Entry is one audited change.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	Action   Action    `json:"action"`
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Revision int64     `json:"revision,omitempty"`
+
+	// Changes are the fields an update changed.
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Kind   string
+	Name   string
+	Actor  string
+	Action Action
+
+	// Since and Until bound the entry time, inclusive.
+	Since time.Time
+	Until time.Time
+
+	// Limit keeps only the most recent Limit matching entries.
+	Limit int
+}
+
+// Matches reports whether e is selected by f, ignoring Limit.
+func (f Filter) Matches(e Entry) bool {
+	switch {
+	case f.Kind != "" && e.Kind != f.Kind,
+		f.Name != "" && e.Name != f.Name,
+		f.Actor != "" && e.Actor != f.Actor,
+		f.Action != "" && e.Action != f.Action,
+		!f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Sink stores audit entries.
+type Sink interface {
+	// Append adds e after the entries already stored.
+	Append(e Entry) error
+
+	// Query returns the entries f selects, oldest first.
+	Query(f Filter) ([]Entry, error)
+}
+
+// applyLimit keeps the last limit entries; limit <= 0 keeps all.
+func applyLimit(entries []Entry, limit int) []Entry {
+	if limit > 0 && len(entries) > limit {
+		return entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming actor as the one making the
+// changes done with it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor ctx names, or SystemActor.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// Logger records audit entries to a sink.
+type Logger struct {
+	sink Sink
+	log  *logger.EnvoyLogger
+	now  func() time.Time
+}
+
+// NewLogger returns a logger recording to sink. log reports entries that
+// could not be recorded and may be nil.
+func NewLogger(sink Sink, log *logger.EnvoyLogger) *Logger {
+	return &Logger{sink: sink, log: log, now: time.Now}
+}
+
+// Sink returns the sink the logger records to.
+func (l *Logger) Sink() Sink {
+	return l.sink
+}
+
+// Record records that the actor of ctx applied action to the resource
+// key, leaving it at revision (0 for deletions), with the fields changes
+// an update changed. The change has already happened, so a failure to
+// record it is logged rather than returned.
+func (l *Logger) Record(ctx context.Context, action Action, key store.ResourceKey, revision int64, changes []FieldChange) {
+	entry := Entry{
+		Time:     l.now().UTC(),
+		Actor:    ActorFromContext(ctx),
+		Action:   action,
+		Kind:     key.Kind,
+		Name:     key.Name,
+		Revision: revision,
+		Changes:  changes,
+	}
+	if err := l.sink.Append(entry); err != nil && l.log != nil {
+		l.log.WithContext(ctx).WithError(err).WithFields(map[string]any{
+			"action":   string(action),
+			"resource": key.String(),
+		}).Error("Failed to record audit entry")
+	}
+}
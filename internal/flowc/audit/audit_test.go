@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func gateway(name string) *store.StoredResource {
+	return &store.StoredResource{
+		Meta:     store.StoreMeta{Kind: "Gateway", Name: name},
+		SpecJSON: json.RawMessage(`{"nodeId":"` + name + `"}`),
+	}
+}
+
+func TestGatewayCreateIsAudited(t *testing.T) {
+	sink := NewMemorySink()
+	s := NewStore(store.NewMemoryStore(), NewLogger(sink, nil))
+	ctx := WithActor(context.Background(), "alice")
+
+	if _, err := s.Put(ctx, gateway("edge"), store.PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	moved := gateway("edge")
+	moved.SpecJSON = json.RawMessage(`{"nodeId":"edge-2"}`)
+	if _, err := s.Put(context.Background(), moved, store.PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(ctx, store.ResourceKey{Kind: "Gateway", Name: "edge"}, store.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	entries, err := sink.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	want := []Entry{
+		{Actor: "alice", Action: ActionCreate, Kind: "Gateway", Name: "edge", Revision: 1},
+		{Actor: SystemActor, Action: ActionUpdate, Kind: "Gateway", Name: "edge", Revision: 2,
+			Changes: []FieldChange{{Field: "spec.nodeId", Old: "edge", New: "edge-2"}}},
+		{Actor: "alice", Action: ActionDelete, Kind: "Gateway", Name: "edge"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		got := entries[i]
+		if got.Time.IsZero() {
+			t.Errorf("entry %d has no time", i)
+		}
+		got.Time = time.Time{}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("entry %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	created, _ := sink.Query(Filter{Action: ActionCreate, Actor: "alice"})
+	if len(created) != 1 || created[0].Name != "edge" {
+		t.Errorf("create entries by alice = %+v, want the edge gateway", created)
+	}
+}
+
+// racingStore moves gateway "edge" to node "raced" ahead of the first
+// Put made through it, as a concurrent writer would.
+type racingStore struct {
+	store.Store
+	raced bool
+}
+
+func (s *racingStore) Put(ctx context.Context, res *store.StoredResource, opts store.PutOptions) (*store.StoredResource, error) {
+	if !s.raced {
+		s.raced = true
+		raced := gateway("edge")
+		raced.SpecJSON = json.RawMessage(`{"nodeId":"raced"}`)
+		if _, err := s.Store.Put(ctx, raced, store.PutOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	return s.Store.Put(ctx, res, opts)
+}
+
+func TestUpdateChangesMatchRecordedRevision(t *testing.T) {
+	inner := store.NewMemoryStore()
+	if _, err := inner.Put(context.Background(), gateway("edge"), store.PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	sink := NewMemorySink()
+	s := NewStore(&racingStore{Store: inner}, NewLogger(sink, nil))
+
+	moved := gateway("edge")
+	moved.SpecJSON = json.RawMessage(`{"nodeId":"edge-2"}`)
+	out, err := s.Put(context.Background(), moved, store.PutOptions{})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entries, _ := sink.Query(Filter{})
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1: %+v", len(entries), entries)
+	}
+	want := []FieldChange{{Field: "spec.nodeId", Old: "raced", New: "edge-2"}}
+	if entries[0].Revision != out.Meta.Revision || !reflect.DeepEqual(entries[0].Changes, want) {
+		t.Errorf("entry = revision %d changes %+v, want revision %d changes %+v",
+			entries[0].Revision, entries[0].Changes, out.Meta.Revision, want)
+	}
+}
+
+func TestFailedWriteIsNotAudited(t *testing.T) {
+	sink := NewMemorySink()
+	s := NewStore(store.NewMemoryStore(), NewLogger(sink, nil))
+
+	if err := s.Delete(context.Background(), store.ResourceKey{Kind: "Gateway", Name: "missing"}, store.DeleteOptions{}); err == nil {
+		t.Fatal("Delete of a missing gateway succeeded")
+	}
+	if entries, _ := sink.Query(Filter{}); len(entries) != 0 {
+		t.Errorf("got entries %+v, want none", entries)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, name := range []string{"a", "b", "c"} {
+		if err := sink.Append(Entry{Time: base.Add(time.Duration(i) * time.Minute), Actor: "bob", Action: ActionCreate, Kind: "Listener", Name: name}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A reopened sink appends to the same trail.
+	sink, err = NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+	if err := sink.Append(Entry{Time: base.Add(time.Hour), Actor: "bob", Action: ActionDelete, Kind: "Listener", Name: "a"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	entries, err := sink.Query(Filter{Action: ActionCreate, Since: base.Add(time.Minute), Limit: 1})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "c" {
+		t.Errorf("entries = %+v, want only the create of c", entries)
+	}
+	all, _ := sink.Query(Filter{})
+	if len(all) != 4 {
+		t.Errorf("got %d entries, want 4", len(all))
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// FieldChange is one field that differs between two revisions of a
+// resource. Old or New is omitted when the field was added or removed.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// Diff reports the top-level spec fields ("spec.<field>", in
+// name order) and then the labels ("metadata.labels") that differ between
+// two revisions.
+func Diff(old, updated *store.StoredResource) []FieldChange {
+	var changes []FieldChange
+
+	oldSpec, newSpec := decodeSpecFields(old.SpecJSON), decodeSpecFields(updated.SpecJSON)
+	keys := slices.Sorted(maps.Keys(oldSpec))
+	for k := range newSpec {
+		if _, ok := oldSpec[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if !reflect.DeepEqual(oldSpec[k], newSpec[k]) {
+			changes = append(changes, FieldChange{Field: "spec." + k, Old: oldSpec[k], New: newSpec[k]})
+		}
+	}
+
+	if !maps.Equal(old.Meta.Labels, updated.Meta.Labels) {
+		changes = append(changes, FieldChange{Field: "metadata.labels", Old: old.Meta.Labels, New: updated.Meta.Labels})
+	}
+	return changes
+}
+
+func decodeSpecFields(specJSON json.RawMessage) map[string]any {
+	fields := map[string]any{}
+	_ = json.Unmarshal(specJSON, &fields)
+	return fields
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultMemoryCapacity is the number of entries a MemorySink keeps.
+const DefaultMemoryCapacity = 10000
+
+// MemorySink keeps the most recent entries in memory. Older entries are
+// dropped once it holds its capacity.
+type MemorySink struct {
+	mu       sync.RWMutex
+	capacity int
+	entries  []Entry
+}
+
+// NewMemorySink returns a sink keeping DefaultMemoryCapacity entries.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{capacity: DefaultMemoryCapacity}
+}
+
+func (s *MemorySink) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.capacity {
+		s.entries = append(s.entries[:0], s.entries[len(s.entries)-s.capacity+1:]...)
+	}
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *MemorySink) Query(f Filter) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Entry
+	for _, e := range s.entries {
+		if f.Matches(e) {
+			out = append(out, e)
+		}
+	}
+	return applyLimit(out, f.Limit), nil
+}
+
+// FileSink appends entries to a file, one JSON object per line. The file
+// is never rewritten, so it keeps the full trail across restarts.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileSink opens (creating it if needed) the audit file at path for
+// appending.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &FileSink{path: path, file: f}, nil
+}
+
+func (s *FileSink) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Query reads the file back. Lines that are not valid entries, such as
+// one cut short by a crash, are skipped.
+func (s *FileSink) Query(f Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var out []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if f.Matches(e) {
+			out = append(out, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return applyLimit(out, f.Limit), nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// Store is a store.Store that records every successful Put and Delete
+// made through it.
+type Store struct {
+	store.Store
+	audit *Logger
+}
+
+// NewStore wraps s, recording its changes with l.
+func NewStore(s store.Store, l *Logger) *Store {
+	return &Store{Store: s, audit: l}
+}
+
+// putAttempts bounds the retries of a Put that lost a race with another
+// writer between reading the previous revision and writing.
+const putAttempts = 3
+
+// Put writes res and records it as a create when no resource existed
+// under its key, and as an update, with the fields it changed, otherwise.
+// The write expects the revision the changes were computed against, so
+// the recorded changes are exactly those between the two revisions; when
+// the caller expects no particular revision, a write that loses a race is
+// retried against the new one.
+func (s *Store) Put(ctx context.Context, res *store.StoredResource, opts store.PutOptions) (*store.StoredResource, error) {
+	for attempt := 1; ; attempt++ {
+		previous, err := s.Store.Get(ctx, res.Key())
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+		pinned := opts
+		if previous != nil && pinned.ExpectedRevision == 0 {
+			pinned.ExpectedRevision = previous.Meta.Revision
+		}
+		out, err := s.Store.Put(ctx, res, pinned)
+		if errors.Is(err, store.ErrRevisionConflict) && opts.ExpectedRevision == 0 && attempt < putAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if previous == nil {
+			s.audit.Record(ctx, ActionCreate, out.Key(), out.Meta.Revision, nil)
+		} else {
+			s.audit.Record(ctx, ActionUpdate, out.Key(), out.Meta.Revision, Diff(previous, out))
+		}
+		return out, nil
+	}
+}
+
+// Delete deletes key and records the deletion.
+func (s *Store) Delete(ctx context.Context, key store.ResourceKey, opts store.DeleteOptions) error {
+	if err := s.Store.Delete(ctx, key, opts); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, ActionDelete, key, 0, nil)
+	return nil
+}
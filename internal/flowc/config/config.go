@@ -42,6 +42,23 @@ type StoreConfig struct {
 
 	// Kubernetes contains settings applied when Backend == "kubernetes".
 	Kubernetes KubernetesStoreConfig `yaml:"kubernetes" json:"kubernetes"`
+
+	// BundleDir is the directory uploaded ZIP bundles are kept in, one
+	// file per deployment. When empty, bundles are kept in memory.
+	BundleDir string `yaml:"bundle_dir" json:"bundle_dir"`
+
+	// BundleHistory is the number of bundles kept per deployment, the
+	// current one included. Deployments can be rolled back to the
+	// previous bundle when it is at least 2.
+	BundleHistory int `yaml:"bundle_history" json:"bundle_history"`
+
+	// SeedFile is an optional YAML manifest of resources and bundles
+	// written to the store at startup.
+	SeedFile string `yaml:"seed_file" json:"seed_file"`
+
+	// SeedStrict aborts startup when any seed item fails. By default
+	// failed items are logged and skipped.
+	SeedStrict bool `yaml:"seed_strict" json:"seed_strict"`
 }
 
 // KubernetesStoreConfig configures the K8s-backed store.
@@ -143,6 +160,41 @@ type ServerConfig struct {
 
 	// Graceful shutdown timeout
 	ShutdownTimeout string `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+
+	// Per-gateway throttling of deploy operations
+	DeployRateLimit DeployRateLimitConfig `yaml:"deploy_rate_limit" json:"deploy_rate_limit"`
+
+	// Bundles parsed at once by uploads and validation; more wait their
+	// turn. -1 removes the limit
+	MaxConcurrentParses int `yaml:"max_concurrent_parses" json:"max_concurrent_parses"`
+
+	// How often gateways whose ttl has elapsed are looked for and removed
+	GatewayGCInterval string `yaml:"gateway_gc_interval" json:"gateway_gc_interval"`
+
+	// File the audit log of resource changes is appended to. When empty,
+	// the most recent entries are kept in memory
+	AuditLogFile string `yaml:"audit_log_file" json:"audit_log_file"`
+
+	// Ports gateway listeners may not use, besides api_port and xds_port
+	// which are always reserved
+	ReservedPorts []int `yaml:"reserved_ports" json:"reserved_ports"`
+
+	// Prefix of the sequential IDs (prefix-1, prefix-2, ...) given to
+	// requests without an X-Request-ID header. When empty, random UUIDs
+	// are used
+	RequestIDPrefix string `yaml:"request_id_prefix" json:"request_id_prefix"`
+}
+
+// DeployRateLimitConfig throttles deploy operations (Deployment writes,
+// canary weight changes and deploying bundle uploads) per target gateway
+// with a token bucket. Requests over the limit get HTTP 429. A zero rate
+// disables throttling.
+type DeployRateLimitConfig struct {
+	// Deploys per second refilled into each gateway's bucket
+	Rate float64 `yaml:"rate" json:"rate"`
+
+	// Deploys a gateway may make in quick succession (minimum 1)
+	Burst int `yaml:"burst" json:"burst"`
 }
 
 // XDSConfig contains XDS server configuration
@@ -153,11 +205,35 @@ type XDSConfig struct {
 	// Default node ID for testing
 	DefaultNodeID string `yaml:"default_node_id" json:"default_node_id"`
 
+	// Name and hostname of the default environment, the listener used for
+	// gateways that have no listeners of their own
+	DefaultEnvironmentName     string `yaml:"default_environment_name" json:"default_environment_name"`
+	DefaultEnvironmentHostname string `yaml:"default_environment_hostname" json:"default_environment_hostname"`
+
 	// Snapshot cache configuration
 	SnapshotCache SnapshotCacheConfig `yaml:"snapshot_cache" json:"snapshot_cache"`
 
 	// gRPC server configuration
 	GRPC GRPCConfig `yaml:"grpc" json:"grpc"`
+
+	// Per-node snapshot size limits
+	ResourceLimits ResourceLimitsConfig `yaml:"resource_limits" json:"resource_limits"`
+
+	// What is done with snapshots referencing resources they do not hold:
+	// "strict" rejects them, "best-effort" installs them with a warning.
+	// NodeConsistencyModes overrides ConsistencyMode per node ID.
+	ConsistencyMode      string            `yaml:"consistency_mode" json:"consistency_mode"`
+	NodeConsistencyModes map[string]string `yaml:"node_consistency_modes,omitempty" json:"node_consistency_modes,omitempty"`
+}
+
+// ResourceLimitsConfig caps the xDS resources pushed to a single node.
+// Deploys that would exceed a limit are rejected. Zero means unlimited.
+type ResourceLimitsConfig struct {
+	MaxClusters  int `yaml:"max_clusters" json:"max_clusters"`
+	MaxEndpoints int `yaml:"max_endpoints" json:"max_endpoints"`
+	MaxListeners int `yaml:"max_listeners" json:"max_listeners"`
+	MaxRoutes    int `yaml:"max_routes" json:"max_routes"`
+	MaxTotal     int `yaml:"max_total" json:"max_total"`
 }
 
 // SnapshotCacheConfig contains snapshot cache settings
@@ -179,6 +255,16 @@ type GRPCConfig struct {
 
 	// Allow keepalive pings without active streams
 	KeepalivePermitWithoutStream bool `yaml:"keepalive_permit_without_stream" json:"keepalive_permit_without_stream"`
+
+	// Maximum concurrent streams per Envoy connection (0 = unlimited)
+	MaxConcurrentStreams int `yaml:"max_concurrent_streams" json:"max_concurrent_streams"`
+
+	// Largest message, in bytes, sent to Envoy; a snapshot larger than
+	// this cannot be delivered
+	MaxSendMsgSize int `yaml:"max_send_msg_size" json:"max_send_msg_size"`
+
+	// Largest message, in bytes, accepted from Envoy
+	MaxRecvMsgSize int `yaml:"max_recv_msg_size" json:"max_recv_msg_size"`
 }
 
 // LoggingConfig contains logging configuration
@@ -200,6 +286,17 @@ type LoggingConfig struct {
 
 	// Enable stack traces for errors
 	EnableStacktrace bool `yaml:"enable_stacktrace" json:"enable_stacktrace"`
+
+	// Sampling of repeated identical messages (disabled when nil)
+	Sampling *LogSamplingConfig `yaml:"sampling,omitempty" json:"sampling,omitempty"`
+}
+
+// LogSamplingConfig logs the first First occurrences of a message per
+// Interval, then every Thereafter-th one
+type LogSamplingConfig struct {
+	Interval   string `yaml:"interval" json:"interval"` // e.g., "1s"
+	First      int    `yaml:"first" json:"first"`
+	Thereafter int    `yaml:"thereafter" json:"thereafter"`
 }
 
 // FeaturesConfig contains feature flags
@@ -218,6 +315,10 @@ type FeaturesConfig struct {
 
 	// Enable rate limiting
 	RateLimiting bool `yaml:"rate_limiting" json:"rate_limiting"`
+
+	// Let deployments merge raw Envoy route/cluster config into the
+	// generated resources (extra_route_config / extra_cluster_config)
+	EnvoyOverrides bool `yaml:"envoy_overrides" json:"envoy_overrides"`
 }
 
 // Load loads configuration from a YAML file
@@ -246,14 +347,12 @@ func Load(configPath string) (*Config, error) {
 	// Merge with defaults
 	config = *mergeWithDefaults(&config)
 
-	// Validate configuration
+	// Apply environment variable overrides, then validate the result
+	applyEnvOverrides(&config)
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Apply environment variable overrides
-	applyEnvOverrides(&config)
-
 	return &config, nil
 }
 
@@ -267,14 +366,12 @@ func LoadFromData(data []byte) (*Config, error) {
 	// Merge with defaults
 	config = *mergeWithDefaults(&config)
 
-	// Validate configuration
+	// Apply environment variable overrides, then validate the result
+	applyEnvOverrides(&config)
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Apply environment variable overrides
-	applyEnvOverrides(&config)
-
 	return &config, nil
 }
 
@@ -330,6 +427,12 @@ func mergeWithDefaults(config *Config) *Config {
 	if config.Server.ShutdownTimeout == "" {
 		config.Server.ShutdownTimeout = defaults.Server.ShutdownTimeout
 	}
+	if config.Server.GatewayGCInterval == "" {
+		config.Server.GatewayGCInterval = defaults.Server.GatewayGCInterval
+	}
+	if config.Server.MaxConcurrentParses == 0 {
+		config.Server.MaxConcurrentParses = defaults.Server.MaxConcurrentParses
+	}
 	// GracefulShutdown defaults to true
 	if !config.Server.GracefulShutdown {
 		config.Server.GracefulShutdown = defaults.Server.GracefulShutdown
@@ -342,6 +445,12 @@ func mergeWithDefaults(config *Config) *Config {
 	if config.XDS.DefaultNodeID == "" {
 		config.XDS.DefaultNodeID = defaults.XDS.DefaultNodeID
 	}
+	if config.XDS.DefaultEnvironmentName == "" {
+		config.XDS.DefaultEnvironmentName = defaults.XDS.DefaultEnvironmentName
+	}
+	if config.XDS.DefaultEnvironmentHostname == "" {
+		config.XDS.DefaultEnvironmentHostname = defaults.XDS.DefaultEnvironmentHostname
+	}
 	if config.XDS.GRPC.KeepaliveTime == "" {
 		config.XDS.GRPC.KeepaliveTime = defaults.XDS.GRPC.KeepaliveTime
 	}
@@ -356,6 +465,13 @@ func mergeWithDefaults(config *Config) *Config {
 		config.XDS.SnapshotCache.ADS = defaults.XDS.SnapshotCache.ADS
 	}
 	// GRPC keepalive permit without stream defaults to true
+	if config.XDS.GRPC.MaxSendMsgSize == 0 {
+		config.XDS.GRPC.MaxSendMsgSize = defaults.XDS.GRPC.MaxSendMsgSize
+	}
+	if config.XDS.GRPC.MaxRecvMsgSize == 0 {
+		config.XDS.GRPC.MaxRecvMsgSize = defaults.XDS.GRPC.MaxRecvMsgSize
+	}
+
 	if !config.XDS.GRPC.KeepalivePermitWithoutStream {
 		config.XDS.GRPC.KeepalivePermitWithoutStream = defaults.XDS.GRPC.KeepalivePermitWithoutStream
 	}
@@ -381,6 +497,9 @@ func mergeWithDefaults(config *Config) *Config {
 	if config.Store.Kubernetes.Namespace == "" {
 		config.Store.Kubernetes.Namespace = defaults.Store.Kubernetes.Namespace
 	}
+	if config.Store.BundleHistory == 0 {
+		config.Store.BundleHistory = defaults.Store.BundleHistory
+	}
 
 	// Controller defaults
 	if config.Controller.Namespace == "" {
@@ -450,6 +569,15 @@ func (c *Config) GetShutdownTimeout() time.Duration {
 	return duration
 }
 
+// GetGatewayGCInterval returns parsed gateway garbage collection interval
+func (c *Config) GetGatewayGCInterval() time.Duration {
+	duration, err := time.ParseDuration(c.Server.GatewayGCInterval)
+	if err != nil || duration <= 0 {
+		return time.Minute // fallback
+	}
+	return duration
+}
+
 // GetKeepaliveTime returns parsed keepalive time
 func (c *Config) GetKeepaliveTime() time.Duration {
 	duration, err := time.ParseDuration(c.XDS.GRPC.KeepaliveTime)
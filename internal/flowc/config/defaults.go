@@ -6,17 +6,22 @@ import "github.com/flowc-labs/flowc/pkg/types"
 func Default() *Config {
 	return &Config{
 		Server: ServerConfig{
-			APIPort:          8080,
-			XDSPort:          18000,
-			ReadTimeout:      "30s",
-			WriteTimeout:     "30s",
-			IdleTimeout:      "60s",
-			GracefulShutdown: true,
-			ShutdownTimeout:  "10s",
+			APIPort:             8080,
+			XDSPort:             18000,
+			ReadTimeout:         "30s",
+			WriteTimeout:        "30s",
+			IdleTimeout:         "60s",
+			GracefulShutdown:    true,
+			ShutdownTimeout:     "10s",
+			GatewayGCInterval:   "1m",
+			MaxConcurrentParses: 4,
 		},
 		XDS: XDSConfig{
 			DefaultListenerPort: 10000,
 			DefaultNodeID:       "test-envoy-node",
+			// Match dispatch.DefaultEnvironmentName/Hostname
+			DefaultEnvironmentName:     "default",
+			DefaultEnvironmentHostname: "*",
 			SnapshotCache: SnapshotCacheConfig{
 				ADS: true,
 			},
@@ -25,7 +30,12 @@ func Default() *Config {
 				KeepaliveTimeout:             "5s",
 				KeepaliveMinTime:             "5s",
 				KeepalivePermitWithoutStream: true,
+				// Large enough for any snapshot; 4 MiB (gRPC's default)
+				// for Envoy's small DiscoveryRequests
+				MaxSendMsgSize: 2147483647,
+				MaxRecvMsgSize: 4 << 20,
 			},
+			ConsistencyMode: "strict",
 		},
 		DefaultStrategy: &types.StrategyConfig{
 			Deployment: &types.DeploymentStrategyConfig{
@@ -42,7 +52,6 @@ func Default() *Config {
 			Retry: &types.RetryStrategyConfig{
 				Type:          "conservative",
 				MaxRetries:    1,
-				RetryOn:       "5xx,reset",
 				PerTryTimeout: "5s",
 			},
 			RateLimit: &types.RateLimitStrategyConfig{
@@ -76,12 +85,15 @@ func Default() *Config {
 			Metrics:             false,
 			Tracing:             false,
 			RateLimiting:        false,
+			EnvoyOverrides:      false,
 		},
 		Store: StoreConfig{
 			Backend: StoreBackendMemory,
 			Kubernetes: KubernetesStoreConfig{
 				Namespace: "default",
 			},
+			// Match store.DefaultBundleHistory
+			BundleHistory: 2,
 		},
 		Controller: ControllerConfig{
 			Enabled:   false,
@@ -60,6 +60,14 @@ func applyXDSEnvOverrides(xds *XDSConfig) {
 		xds.DefaultNodeID = val
 	}
 
+	if val := os.Getenv("FLOWC_DEFAULT_ENVIRONMENT_NAME"); val != "" {
+		xds.DefaultEnvironmentName = val
+	}
+
+	if val := os.Getenv("FLOWC_DEFAULT_ENVIRONMENT_HOSTNAME"); val != "" {
+		xds.DefaultEnvironmentHostname = val
+	}
+
 	if val := os.Getenv("FLOWC_XDS_ADS"); val != "" {
 		if enabled, err := strconv.ParseBool(val); err == nil {
 			xds.SnapshotCache.ADS = enabled
@@ -83,6 +91,24 @@ func applyXDSEnvOverrides(xds *XDSConfig) {
 			xds.GRPC.KeepalivePermitWithoutStream = enabled
 		}
 	}
+
+	if val := os.Getenv("FLOWC_GRPC_MAX_CONCURRENT_STREAMS"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			xds.GRPC.MaxConcurrentStreams = n
+		}
+	}
+
+	if val := os.Getenv("FLOWC_GRPC_MAX_SEND_MSG_SIZE"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			xds.GRPC.MaxSendMsgSize = n
+		}
+	}
+
+	if val := os.Getenv("FLOWC_GRPC_MAX_RECV_MSG_SIZE"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			xds.GRPC.MaxRecvMsgSize = n
+		}
+	}
 }
 
 func applyLoggingEnvOverrides(logging *LoggingConfig) {
@@ -147,4 +173,10 @@ func applyFeatureEnvOverrides(features *FeaturesConfig) {
 			features.RateLimiting = enabled
 		}
 	}
+
+	if val := os.Getenv("FLOWC_FEATURE_ENVOY_OVERRIDES"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			features.EnvoyOverrides = enabled
+		}
+	}
 }
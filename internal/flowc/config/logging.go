@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// NewLogger builds the logger the logging config describes: its level,
+// its format (json or text), its output (stdout, stderr or a file
+// appended to) and its sampling.
+func (l *LoggingConfig) NewLogger() (*logger.EnvoyLogger, error) {
+	level, err := logger.ParseLevel(l.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var loggerType logger.LoggerType
+	switch strings.ToLower(l.Format) {
+	case "json":
+		loggerType = logger.JSONLogger
+	case "text":
+		loggerType = logger.TextLogger
+	default:
+		return nil, fmt.Errorf("unknown log format %q", l.Format)
+	}
+
+	var output io.Writer
+	switch l.Output {
+	case "", "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		file, err := os.OpenFile(l.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open log output: %w", err)
+		}
+		output = file
+	}
+
+	var sampling *logger.SamplingConfig
+	if s := l.Sampling; s != nil {
+		interval, err := time.ParseDuration(s.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling interval: %w", err)
+		}
+		sampling = &logger.SamplingConfig{Interval: interval, First: s.First, Thereafter: s.Thereafter}
+	}
+
+	return logger.NewLogger(&logger.LoggerConfig{
+		Type:     loggerType,
+		Level:    level,
+		Output:   output,
+		Sampling: sampling,
+	}), nil
+}
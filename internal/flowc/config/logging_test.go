@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestLoggingConfigNewLogger(t *testing.T) {
+	cfg, err := LoadFromData([]byte("logging:\n  level: debug\n  format: text\n"))
+	if err != nil {
+		t.Fatalf("LoadFromData: %v", err)
+	}
+	log, err := cfg.Logging.NewLogger()
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	if !log.IsDebugEnabled() {
+		t.Errorf("logger level = %v, want debug enabled", log.GetLevel())
+	}
+
+	log, err = Default().Logging.NewLogger()
+	if err != nil {
+		t.Fatalf("NewLogger with defaults: %v", err)
+	}
+	if log.IsDebugEnabled() || log.GetLevel() != logger.InfoLevel {
+		t.Errorf("default logger level = %v, want INFO", log.GetLevel())
+	}
+
+	bad := LoggingConfig{Level: "verbose", Format: "json", Output: "stdout"}
+	if _, err := bad.NewLogger(); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	missing := LoggingConfig{Level: "info", Format: "json", Output: filepath.Join(t.TempDir(), "missing", "flowc.log")}
+	if _, err := missing.NewLogger(); err == nil {
+		t.Error("expected an error for an output file in a missing directory")
+	}
+}
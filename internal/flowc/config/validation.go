@@ -1,35 +1,41 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"slices"
 	"strings"
 	"time"
 )
 
-// Validate validates the configuration
+// Validate validates the configuration. Every problem found is reported,
+// not just the first: the returned error joins one error per problem,
+// each prefixed with the section it belongs to.
 func (c *Config) Validate() error {
-	// Validate server config
-	if err := c.Server.Validate(); err != nil {
-		return fmt.Errorf("server config: %w", err)
-	}
+	var errs []error
+	errs = append(errs, prefixErrors("server config", c.Server.Validate())...)
+	errs = append(errs, prefixErrors("xds config", c.XDS.Validate())...)
+	errs = append(errs, prefixErrors("logging config", c.Logging.Validate())...)
+	errs = append(errs, prefixErrors("store config", c.Store.Validate())...)
+	return errors.Join(errs...)
+}
 
-	// Validate XDS config
-	if err := c.XDS.Validate(); err != nil {
-		return fmt.Errorf("xds config: %w", err)
+// prefixErrors splits err into the errors it joins, prefixing each.
+func prefixErrors(prefix string, err error) []error {
+	if err == nil {
+		return nil
 	}
-
-	// Validate logging config
-	if err := c.Logging.Validate(); err != nil {
-		return fmt.Errorf("logging config: %w", err)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{fmt.Errorf("%s: %w", prefix, err)}
 	}
-
-	// Validate store config
-	if err := c.Store.Validate(); err != nil {
-		return fmt.Errorf("store config: %w", err)
+	var out []error
+	for _, e := range joined.Unwrap() {
+		out = append(out, prefixErrors(prefix, e)...)
 	}
-
-	return nil
+	return out
 }
 
 // Validate validates store configuration.
@@ -38,107 +44,167 @@ func (s *StoreConfig) Validate() error {
 	if !contains(validBackends, s.Backend) {
 		return fmt.Errorf("invalid backend: %q (must be one of: %s)", s.Backend, strings.Join(validBackends, ", "))
 	}
+	if s.BundleHistory < 0 {
+		return fmt.Errorf("invalid bundle_history: %d (must be at least 1)", s.BundleHistory)
+	}
 	return nil
 }
 
 // Validate validates server configuration
 func (s *ServerConfig) Validate() error {
-	if s.APIPort < 1 || s.APIPort > 65535 {
-		return fmt.Errorf("invalid api_port: %d (must be between 1-65535)", s.APIPort)
-	}
-
-	if s.XDSPort < 1 || s.XDSPort > 65535 {
-		return fmt.Errorf("invalid xds_port: %d (must be between 1-65535)", s.XDSPort)
-	}
-
+	var errs []error
+	errs = append(errs, validatePort(s.APIPort, "api_port"))
+	errs = append(errs, validatePort(s.XDSPort, "xds_port"))
 	if s.APIPort == s.XDSPort {
-		return fmt.Errorf("api_port and xds_port cannot be the same: %d", s.APIPort)
+		errs = append(errs, fmt.Errorf("api_port and xds_port cannot be the same: %d", s.APIPort))
+	}
+	for _, port := range s.ReservedPorts {
+		errs = append(errs, validatePort(port, "reserved_ports entry"))
 	}
 
 	// Validate timeouts
-	if err := validateDuration(s.ReadTimeout, "read_timeout"); err != nil {
-		return err
-	}
-	if err := validateDuration(s.WriteTimeout, "write_timeout"); err != nil {
-		return err
+	errs = append(errs, validateDuration(s.ReadTimeout, "read_timeout"))
+	errs = append(errs, validateDuration(s.WriteTimeout, "write_timeout"))
+	errs = append(errs, validateDuration(s.IdleTimeout, "idle_timeout"))
+	errs = append(errs, validateDuration(s.ShutdownTimeout, "shutdown_timeout"))
+	errs = append(errs, validateDuration(s.GatewayGCInterval, "gateway_gc_interval"))
+
+	if s.DeployRateLimit.Rate < 0 {
+		errs = append(errs, fmt.Errorf("invalid deploy_rate_limit.rate: %g (must be 0 for unlimited or positive)", s.DeployRateLimit.Rate))
 	}
-	if err := validateDuration(s.IdleTimeout, "idle_timeout"); err != nil {
-		return err
+	if s.DeployRateLimit.Burst < 0 {
+		errs = append(errs, fmt.Errorf("invalid deploy_rate_limit.burst: %d (must not be negative)", s.DeployRateLimit.Burst))
 	}
-	if err := validateDuration(s.ShutdownTimeout, "shutdown_timeout"); err != nil {
-		return err
+	if s.MaxConcurrentParses < -1 {
+		errs = append(errs, fmt.Errorf("invalid max_concurrent_parses: %d (must be -1 for unlimited or positive)", s.MaxConcurrentParses))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // Validate validates XDS configuration
 func (x *XDSConfig) Validate() error {
-	if x.DefaultListenerPort < 1 || x.DefaultListenerPort > 65535 {
-		return fmt.Errorf("invalid default_listener_port: %d (must be between 1-65535)", x.DefaultListenerPort)
-	}
+	var errs []error
+	errs = append(errs, validatePort(x.DefaultListenerPort, "default_listener_port"))
 
 	if x.DefaultNodeID == "" {
-		return fmt.Errorf("default_node_id cannot be empty")
+		errs = append(errs, fmt.Errorf("default_node_id cannot be empty"))
 	}
 
 	// Validate gRPC config
-	if err := x.GRPC.Validate(); err != nil {
-		return fmt.Errorf("grpc: %w", err)
+	errs = append(errs, prefixErrors("grpc", x.GRPC.Validate())...)
+	errs = append(errs, prefixErrors("resource_limits", x.ResourceLimits.Validate())...)
+
+	errs = append(errs, validateConsistencyMode(x.ConsistencyMode, "consistency_mode"))
+	for _, node := range slices.Sorted(maps.Keys(x.NodeConsistencyModes)) {
+		errs = append(errs, validateConsistencyMode(x.NodeConsistencyModes[node], "node_consistency_modes."+node))
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// validateConsistencyMode validates a snapshot consistency mode ("" is
+// strict)
+func validateConsistencyMode(mode, field string) error {
+	switch mode {
+	case "", "strict", "best-effort":
+		return nil
+	default:
+		return fmt.Errorf("invalid %s: %q (must be strict or best-effort)", field, mode)
+	}
+}
+
+// Validate validates per-node resource limits
+func (r *ResourceLimitsConfig) Validate() error {
+	limits := map[string]int{
+		"max_clusters":  r.MaxClusters,
+		"max_endpoints": r.MaxEndpoints,
+		"max_listeners": r.MaxListeners,
+		"max_routes":    r.MaxRoutes,
+		"max_total":     r.MaxTotal,
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(limits)) {
+		if v := limits[name]; v < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s: %d (must be 0 for unlimited or positive)", name, v))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Validate validates gRPC configuration
 func (g *GRPCConfig) Validate() error {
-	if err := validateDuration(g.KeepaliveTime, "keepalive_time"); err != nil {
-		return err
+	errs := []error{
+		validateDuration(g.KeepaliveTime, "keepalive_time"),
+		validateDuration(g.KeepaliveTimeout, "keepalive_timeout"),
+		validateDuration(g.KeepaliveMinTime, "keepalive_min_time"),
 	}
-	if err := validateDuration(g.KeepaliveTimeout, "keepalive_timeout"); err != nil {
-		return err
+	if g.MaxConcurrentStreams < 0 || g.MaxConcurrentStreams > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("invalid max_concurrent_streams: %d (must be 0 for unlimited or positive)", g.MaxConcurrentStreams))
 	}
-	if err := validateDuration(g.KeepaliveMinTime, "keepalive_min_time"); err != nil {
-		return err
+	if g.MaxSendMsgSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid max_send_msg_size: %d (must be positive)", g.MaxSendMsgSize))
 	}
-
-	return nil
+	if g.MaxRecvMsgSize < 0 {
+		errs = append(errs, fmt.Errorf("invalid max_recv_msg_size: %d (must be positive)", g.MaxRecvMsgSize))
+	}
+	return errors.Join(errs...)
 }
 
 // Validate validates logging configuration
 func (l *LoggingConfig) Validate() error {
+	var errs []error
+
 	// Validate log level
 	validLevels := []string{"debug", "info", "warn", "error"}
 	level := strings.ToLower(l.Level)
 	if !contains(validLevels, level) {
-		return fmt.Errorf("invalid log level: %s (must be one of: %s)", l.Level, strings.Join(validLevels, ", "))
+		errs = append(errs, fmt.Errorf("invalid log level: %s (must be one of: %s)", l.Level, strings.Join(validLevels, ", ")))
 	}
 
 	// Validate log format
 	validFormats := []string{"json", "text"}
 	format := strings.ToLower(l.Format)
 	if !contains(validFormats, format) {
-		return fmt.Errorf("invalid log format: %s (must be one of: %s)", l.Format, strings.Join(validFormats, ", "))
+		errs = append(errs, fmt.Errorf("invalid log format: %s (must be one of: %s)", l.Format, strings.Join(validFormats, ", ")))
 	}
 
 	// Validate output (must be stdout, stderr, or a valid file path)
 	if l.Output == "" {
-		return fmt.Errorf("log output cannot be empty")
+		errs = append(errs, fmt.Errorf("log output cannot be empty"))
+	}
+
+	if s := l.Sampling; s != nil {
+		errs = append(errs, validateDuration(s.Interval, "sampling interval"))
+		if s.First < 0 || s.Thereafter < 0 {
+			errs = append(errs, fmt.Errorf("invalid sampling: first and thereafter cannot be negative"))
+		}
 	}
 
+	return errors.Join(errs...)
+}
+
+// validatePort checks that port is a usable TCP port
+func validatePort(port int, fieldName string) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid %s: %d (must be between 1-65535)", fieldName, port)
+	}
 	return nil
 }
 
-// validateDuration validates a duration string
+// validateDuration validates a duration string, which must be positive
 func validateDuration(duration string, fieldName string) error {
 	if duration == "" {
 		return fmt.Errorf("%s cannot be empty", fieldName)
 	}
 
-	_, err := time.ParseDuration(duration)
+	d, err := time.ParseDuration(duration)
 	if err != nil {
 		return fmt.Errorf("invalid %s: %s (must be a valid duration like '30s', '5m', '1h')", fieldName, duration)
 	}
+	if d <= 0 {
+		return fmt.Errorf("invalid %s: %s (must be positive)", fieldName, duration)
+	}
 
 	return nil
 }
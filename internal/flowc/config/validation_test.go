@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("default config is invalid: %v", err)
+	}
+
+	files, err := filepath.Glob("../../../config/flowc-config.*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if _, err := Load(f); err != nil {
+			t.Errorf("%s: %v", f, err)
+		}
+	}
+}
+
+func TestValidatePortOutOfRange(t *testing.T) {
+	_, err := LoadFromData([]byte("server:\n  api_port: 70000\n"))
+	if err == nil {
+		t.Fatal("expected an error for api_port 70000")
+	}
+	if !strings.Contains(err.Error(), "server config: invalid api_port: 70000") {
+		t.Errorf("error = %q, want it to name server api_port", err)
+	}
+}
+
+func TestValidateDuplicatePorts(t *testing.T) {
+	cfg := Default()
+	cfg.Server.APIPort = 18000
+	cfg.Server.XDSPort = 18000
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "api_port and xds_port cannot be the same: 18000") {
+		t.Errorf("error = %v, want api_port and xds_port reported as the same", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Server.ReadTimeout = "-5s"
+	cfg.XDS.DefaultListenerPort = 0
+	cfg.Logging.Level = "verbose"
+	cfg.Store.Backend = "etcd"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"server config: invalid read_timeout: -5s (must be positive)",
+		"xds config: invalid default_listener_port: 0",
+		"logging config: invalid log level: verbose",
+		`store config: invalid backend: "etcd"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+}
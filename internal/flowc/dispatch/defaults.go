@@ -0,0 +1,137 @@
+package dispatch
+
+import (
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+)
+
+// Fallbacks for the default environment: the listener synthesized for a
+// gateway that has no Listener resources.
+const (
+	DefaultEnvironmentName     = "default"
+	DefaultEnvironmentHostname = "*"
+	DefaultListenerPort        = 10000
+)
+
+// DefaultListener configures the default environment. Zero fields fall
+// back to the constants above.
+type DefaultListener struct {
+	Name     string
+	Hostname string
+	Port     uint32
+}
+
+// listener returns the synthesized Listener for gateway gwName.
+func (d DefaultListener) listener(gwName string) *flowcv1alpha1.Listener {
+	if d.Name == "" {
+		d.Name = DefaultEnvironmentName
+	}
+	if d.Hostname == "" {
+		d.Hostname = DefaultEnvironmentHostname
+	}
+	if d.Port == 0 {
+		d.Port = DefaultListenerPort
+	}
+	l := &flowcv1alpha1.Listener{Spec: flowcv1alpha1.ListenerSpec{
+		GatewayRef: gwName,
+		Port:       d.Port,
+		Hostnames:  []string{d.Hostname},
+	}}
+	l.Name = d.Name
+	return l
+}
+
+// Fallbacks for an "admin/stats" listener's backend: Envoy's admin
+// interface as configured by the generated bootstrap.
+const (
+	DefaultStatsAddress = "127.0.0.1"
+	DefaultStatsPort    = 9901
+)
+
+// listenersForGateway returns the gateway's data Listeners, or the default
+// environment's listener when it has none.
+func listenersForGateway(idx *index.Indexer, gwName string, defaults DefaultListener) []*flowcv1alpha1.Listener {
+	var listeners []*flowcv1alpha1.Listener
+	for _, l := range idx.ListenersForGateway(gwName) {
+		if !isStatsListener(l) {
+			listeners = append(listeners, l)
+		}
+	}
+	if len(listeners) > 0 {
+		return listeners
+	}
+	return []*flowcv1alpha1.Listener{defaults.listener(gwName)}
+}
+
+// statsListenersForGateway returns the gateway's "admin/stats" Listeners.
+func statsListenersForGateway(idx *index.Indexer, gwName string) []*flowcv1alpha1.Listener {
+	var listeners []*flowcv1alpha1.Listener
+	for _, l := range idx.ListenersForGateway(gwName) {
+		if isStatsListener(l) {
+			listeners = append(listeners, l)
+		}
+	}
+	return listeners
+}
+
+func isStatsListener(l *flowcv1alpha1.Listener) bool {
+	return l.Spec.Kind == flowcv1alpha1.ListenerKindAdminStats
+}
+
+// routesListener returns the listener whose route configurations l's
+// filter chains use: the one l's routesFrom names, or l itself. An
+// unusable routesFrom (unknown, on another gateway, an "admin/stats"
+// listener or itself linked) is ignored.
+func routesListener(idx *index.Indexer, l *flowcv1alpha1.Listener) *flowcv1alpha1.Listener {
+	if l.Spec.RoutesFrom == "" || l.Spec.RoutesFrom == l.Name {
+		return l
+	}
+	src, ok := idx.GetListener(l.Spec.RoutesFrom)
+	if !ok || src.Spec.GatewayRef != l.Spec.GatewayRef || isStatsListener(src) || src.Spec.RoutesFrom != "" {
+		return l
+	}
+	return src
+}
+
+// httpsRedirectPort returns the port requests made over plain HTTP to the
+// routes of listener src are redirected to with https, or 0 when no
+// plaintext listener serving them redirects, through its httpsRedirect or
+// the gateway's autoHTTPSRedirect. The port is that of a listener with
+// tls serving the same routes, or else of the gateway's first listener
+// with tls, or else 443.
+func httpsRedirectPort(idx *index.Indexer, gw *flowcv1alpha1.Gateway, src *flowcv1alpha1.Listener, defaults DefaultListener) uint32 {
+	listeners := listenersForGateway(idx, gw.Name, defaults)
+	var firstTLS, sharedTLS *flowcv1alpha1.Listener
+	for _, l := range listeners {
+		if l.Spec.TLS == nil {
+			continue
+		}
+		if firstTLS == nil {
+			firstTLS = l
+		}
+		if sharedTLS == nil && routesListener(idx, l).Name == src.Name {
+			sharedTLS = l
+		}
+	}
+
+	redirects := false
+	for _, l := range listeners {
+		if l.Spec.TLS != nil || routesListener(idx, l).Name != src.Name {
+			continue
+		}
+		if l.Spec.HTTPSRedirect || (gw.Spec.AutoHTTPSRedirect && firstTLS != nil) {
+			redirects = true
+			break
+		}
+	}
+	switch {
+	case !redirects:
+		return 0
+	case sharedTLS != nil:
+		return sharedTLS.Spec.Port
+	case firstTLS != nil:
+		return firstTLS.Spec.Port
+	default:
+		return 443
+	}
+}
@@ -3,7 +3,11 @@ package dispatch
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
 	"github.com/flowc-labs/flowc/internal/flowc/index"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
@@ -12,19 +16,31 @@ import (
 )
 
 // DeploymentTranslator handles surgical, per-deployment xDS updates.
-// On Put it translates the single deployment, merges its
-// clusters/endpoints/routes into the snapshot via cache.DeployAPI, and
-// records the resulting names in the indexer's ownership map. On Delete
-// it reads the recorded names and removes them via cache.UnDeployAPI.
+// On Put it translates the single deployment, publishes only the
+// clusters/endpoints/routes that differ from the snapshot's via
+// cache.UpdateAPI, and records the resulting names in the indexer's
+// ownership map. On Delete
+// it reads the recorded names and removes them via cache.BulkUpdate.
 //
 // Listeners are never touched here — they're rebuilt by GatewayTranslator
-// in response to Listener events.
+// in response to Listener events. The exception is a deployment that
+// contributes HCM filters (or used to): those live inside the listener,
+// so the deployment's gateway gets a full rebuild instead. The same goes
+// for a deployment whose route config (one per environment) is shared
+// with another deployment, since only a rebuild sees all their routes,
+// and for any deployment on a gateway whose environments split traffic
+// between them.
+//
+// Publishing holds the node's deploy lock, so deploys to the same node
+// serialize and never merge into a stale snapshot.
 type DeploymentTranslator struct {
-	indexer *index.Indexer
-	cache   *cache.ConfigManager
-	parsers *ir.ParserRegistry
-	options *translator.TranslatorOptions
-	log     *logger.EnvoyLogger
+	indexer  *index.Indexer
+	cache    *cache.ConfigManager
+	parsers  *ir.ParserRegistry
+	options  *translator.TranslatorOptions
+	defaults DefaultListener
+	gateways *GatewayTranslator
+	log      *logger.EnvoyLogger
 }
 
 // NewDeploymentTranslator constructs the translator with all
@@ -34,17 +50,28 @@ func NewDeploymentTranslator(
 	idx *index.Indexer,
 	cm *cache.ConfigManager,
 	parsers *ir.ParserRegistry,
+	defaults DefaultListener,
 	log *logger.EnvoyLogger,
 ) *DeploymentTranslator {
 	return &DeploymentTranslator{
-		indexer: idx,
-		cache:   cm,
-		parsers: parsers,
-		options: translator.DefaultTranslatorOptions(),
-		log:     log,
+		indexer:  idx,
+		cache:    cm,
+		parsers:  parsers,
+		options:  translator.DefaultTranslatorOptions(),
+		defaults: defaults,
+		gateways: NewGatewayTranslator(idx, cm, parsers, defaults, log),
+		log:      log,
 	}
 }
 
+// SetOptions replaces the translator options, including those of the
+// gateway rebuilds it falls back to. Call it before the translator is
+// registered with a running dispatcher.
+func (t *DeploymentTranslator) SetOptions(options *translator.TranslatorOptions) {
+	t.options = options
+	t.gateways.SetOptions(options)
+}
+
 // Kind returns the dispatch kind name.
 func (t *DeploymentTranslator) Kind() string { return "Deployment" }
 
@@ -69,7 +96,7 @@ func (t *DeploymentTranslator) handlePut(ctx context.Context, task index.Affecte
 		return nil
 	}
 
-	xds, err := translateOne(ctx, dep, t.indexer, t.parsers, t.options, t.log)
+	xds, err := translateOne(ctx, dep, t.indexer, t.parsers, t.options, t.defaults, t.log)
 	if err != nil {
 		return fmt.Errorf("translate deployment %q: %w", task.Name, err)
 	}
@@ -80,13 +107,41 @@ func (t *DeploymentTranslator) handlePut(ctx context.Context, task index.Affecte
 	}
 	nodeID := gw.Spec.NodeID
 
+	unlock := t.cache.LockNode(nodeID)
+
+	// HCM filters are part of the listener, which only the gateway
+	// translator builds. Rebuild the whole gateway when this deployment
+	// adds filters or previously had some that may need removing, when
+	// another deployment's routes live in the same route config, or when
+	// routes of the gateway are split across environments.
+	prevNode, prev, _ := t.indexer.OwnershipForDeployment(task.Name)
+	if len(xds.HTTPFilters) > 0 || len(prev.HTTPFilters) > 0 ||
+		t.routesShared(nodeID, task.Name, resourceNamesFromXDS(xds).Routes) ||
+		hasTrafficSplit(t.indexer, gw.Name) {
+		unlock()
+		return t.gateways.Translate(ctx, index.AffectedTask{Kind: t.gateways.Kind(), Name: gw.Name})
+	}
+	defer unlock()
+
 	cd := &cache.APIDeployment{
 		Clusters:  xds.Clusters,
 		Endpoints: xds.Endpoints,
 		Routes:    xds.Routes,
 		// Listeners deliberately omitted — gateway-translator owns them.
 	}
-	if err := t.cache.DeployAPI(nodeID, cd); err != nil {
+	// Resources of the previous deploy to this node that the update no
+	// longer produces are removed. Route configs the deployment left are
+	// still referenced by their listener, so they become placeholders,
+	// as on delete.
+	if prevNode != nodeID {
+		prev = cache.ResourceNames{}
+	}
+	for _, r := range prev.Routes {
+		if !slices.ContainsFunc(xds.Routes, func(rc *routev3.RouteConfiguration) bool { return rc.Name == r }) {
+			cd.Routes = append(cd.Routes, placeholderRouteConfig(r, routeConfigHostname(r)))
+		}
+	}
+	if _, err := t.cache.UpdateAPI(nodeID, prev, cd); err != nil {
 		return fmt.Errorf("deploy %q to xDS cache: %w", task.Name, err)
 	}
 
@@ -98,14 +153,83 @@ func (t *DeploymentTranslator) handlePut(ctx context.Context, task index.Affecte
 // using the names recorded at last successful deploy. If no ownership is
 // recorded (deployment never deployed, or cleanup already happened via
 // gateway delete), this is a no-op.
-func (t *DeploymentTranslator) handleDelete(_ context.Context, task index.AffectedTask) error {
+func (t *DeploymentTranslator) handleDelete(ctx context.Context, task index.AffectedTask) error {
 	nodeID, names, ok := t.indexer.OwnershipForDeployment(task.Name)
 	if !ok {
 		return nil
 	}
-	if err := t.cache.UnDeployAPI(nodeID, names); err != nil {
+	unlock := t.cache.LockNode(nodeID)
+
+	// Removing a shared route config would drop the other deployments'
+	// routes with it, and removing clusters could leave another
+	// environment's split routes pointing at them; rebuild the gateway
+	// without this deployment instead.
+	if t.routesShared(nodeID, task.Name, names.Routes) || t.nodeSplitsTraffic(nodeID) {
+		t.indexer.ClearOwnership(nodeID, task.Name)
+		unlock()
+		return t.rebuildNode(ctx, nodeID)
+	}
+	// The environment's listener still references the deployment's route
+	// configs, so they are swapped for placeholders in the same snapshot
+	// that drops its clusters and endpoints.
+	placeholders := &cache.APIDeployment{}
+	for _, r := range names.Routes {
+		placeholders.Routes = append(placeholders.Routes, placeholderRouteConfig(r, routeConfigHostname(r)))
+	}
+	if err := t.cache.BulkUpdate(nodeID, placeholders, names); err != nil {
+		unlock()
 		return fmt.Errorf("undeploy %q from xDS cache: %w", task.Name, err)
 	}
 	t.indexer.ClearOwnership(nodeID, task.Name)
+	unlock()
+
+	// Filters the deployment contributed are still baked into the
+	// listener; rebuild its gateway so they're dropped.
+	if len(names.HTTPFilters) > 0 {
+		return t.rebuildNode(ctx, nodeID)
+	}
+	return nil
+}
+
+// rebuildNode runs a full rebuild of the gateway serving nodeID.
+func (t *DeploymentTranslator) rebuildNode(ctx context.Context, nodeID string) error {
+	for _, gw := range t.indexer.Gateways() {
+		if gw.Spec.NodeID == nodeID {
+			return t.gateways.Translate(ctx, index.AffectedTask{Kind: t.gateways.Kind(), Name: gw.Name})
+		}
+	}
 	return nil
 }
+
+// nodeSplitsTraffic reports whether the gateway serving nodeID splits
+// traffic between its environments.
+func (t *DeploymentTranslator) nodeSplitsTraffic(nodeID string) bool {
+	return slices.ContainsFunc(t.indexer.Gateways(), func(gw *flowcv1alpha1.Gateway) bool {
+		return gw.Spec.NodeID == nodeID && hasTrafficSplit(t.indexer, gw.Name)
+	})
+}
+
+// routesShared reports whether a deployment other than depName published
+// any of the named route configs to nodeID.
+func (t *DeploymentTranslator) routesShared(nodeID, depName string, routes []string) bool {
+	for _, gw := range t.indexer.Gateways() {
+		if gw.Spec.NodeID != nodeID {
+			continue
+		}
+		for _, other := range t.indexer.DeploymentsForGateway(gw.Name) {
+			if other.Name == depName {
+				continue
+			}
+			owned, ok := t.indexer.GetOwnership(nodeID, other.Name)
+			if !ok {
+				continue
+			}
+			for _, r := range routes {
+				if slices.Contains(owned.Routes, r) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
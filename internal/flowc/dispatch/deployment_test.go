@@ -0,0 +1,198 @@
+package dispatch
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func deleteSpec(idx *index.Indexer, kind, name string) {
+	idx.Apply(store.WatchEvent{
+		Type:     store.WatchEventDelete,
+		Resource: &store.StoredResource{Meta: store.StoreMeta{Kind: kind, Name: name}},
+	})
+}
+
+// newEdgeTranslator publishes gateway "edge" (listeners only, as on
+// startup), then adds an API and a Deployment per name without
+// translating them.
+func newEdgeTranslator(t *testing.T, idx *index.Indexer, names []string) *DeploymentTranslator {
+	t.Helper()
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	dt := NewDeploymentTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := dt.gateways.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("gateway rebuild: %v", err)
+	}
+	for _, name := range names {
+		applySpec(t, idx, "API", name, flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  "/" + name,
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: name + ".local", Port: 8080},
+		})
+		applySpec(t, idx, "Deployment", name, flowcv1alpha1.DeploymentSpec{
+			APIRef:  name,
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+		})
+	}
+	return dt
+}
+
+func TestConcurrentDeploysToOneEnvironment(t *testing.T) {
+	apis := []string{"pets", "users", "orders"}
+
+	for range 20 {
+		idx := index.New(nil)
+		applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+		applySpec(t, idx, "Listener", "http", flowcv1alpha1.ListenerSpec{GatewayRef: "edge", Port: 10000})
+		dt := newEdgeTranslator(t, idx, apis)
+		cm := dt.cache
+
+		var wg sync.WaitGroup
+		for _, name := range apis {
+			wg.Go(func() {
+				if err := dt.Translate(context.Background(), index.AffectedTask{Kind: "Deployment", Name: name}); err != nil {
+					t.Errorf("deploy %s: %v", name, err)
+				}
+			})
+		}
+		wg.Wait()
+
+		snap, err := cm.GetSnapshot("edge-node")
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		res, ok := snap.GetResources(resourcev3.RouteType)["route_http_*"]
+		if !ok {
+			t.Fatalf("route config missing; routes: %v", snap.GetResources(resourcev3.RouteType))
+		}
+		rc := res.(*routev3.RouteConfiguration)
+		if len(rc.VirtualHosts) != 1 {
+			t.Fatalf("got %d virtual hosts, want 1 shared by all deployments", len(rc.VirtualHosts))
+		}
+		var prefixes []string
+		for _, r := range rc.VirtualHosts[0].Routes {
+			prefixes = append(prefixes, r.GetMatch().GetPathSeparatedPrefix())
+		}
+		for _, name := range apis {
+			if !slices.Contains(prefixes, "/"+name) {
+				t.Fatalf("route for %s lost; got %v", name, prefixes)
+			}
+		}
+	}
+}
+
+func TestDeleteKeepsSharedRoutes(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	dt := newEdgeTranslator(t, idx, []string{"pets", "users"})
+	cm := dt.cache
+	for _, name := range []string{"pets", "users"} {
+		if err := dt.Translate(context.Background(), index.AffectedTask{Kind: "Deployment", Name: name}); err != nil {
+			t.Fatalf("deploy %s: %v", name, err)
+		}
+	}
+
+	deleteSpec(idx, "Deployment", "pets")
+	if err := dt.Translate(context.Background(), index.AffectedTask{Kind: "Deployment", Name: "pets", Deletion: true}); err != nil {
+		t.Fatalf("delete pets: %v", err)
+	}
+
+	snap, err := cm.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	rc := snap.GetResources(resourcev3.RouteType)["route_default_*"].(*routev3.RouteConfiguration)
+	routes := rc.VirtualHosts[0].Routes
+	if len(routes) != 1 || routes[0].GetMatch().GetPathSeparatedPrefix() != "/users" {
+		t.Errorf("routes after deleting pets = %v, want only /users", routes)
+	}
+}
+
+func TestDeleteRemovesExactlyRecordedResources(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	applySpec(t, idx, "Listener", "blue", flowcv1alpha1.ListenerSpec{GatewayRef: "edge", Port: 10000})
+	applySpec(t, idx, "Listener", "green", flowcv1alpha1.ListenerSpec{GatewayRef: "edge", Port: 10001})
+	dt := newEdgeTranslator(t, idx, nil)
+	applySpec(t, idx, "API", "pets", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/pets",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "pets.local", Port: 8080},
+	})
+	// Two deployments of one API, one per environment.
+	for _, env := range []string{"blue", "green"} {
+		applySpec(t, idx, "Deployment", "pets-"+env, flowcv1alpha1.DeploymentSpec{
+			APIRef:  "pets",
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: env},
+		})
+		if err := dt.Translate(context.Background(), index.AffectedTask{Kind: "Deployment", Name: "pets-" + env}); err != nil {
+			t.Fatalf("deploy pets-%s: %v", env, err)
+		}
+	}
+
+	names := func(snap *cachev3.Snapshot) map[string][]string {
+		out := map[string][]string{}
+		for _, typ := range []resourcev3.Type{resourcev3.ClusterType, resourcev3.RouteType} {
+			for name := range snap.GetResources(typ) {
+				out[typ] = append(out[typ], name)
+			}
+			slices.Sort(out[typ])
+		}
+		return out
+	}
+	before, err := dt.cache.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	_, owned, ok := idx.OwnershipForDeployment("pets-blue")
+	if !ok || len(owned.Clusters) == 0 {
+		t.Fatalf("no clusters recorded for pets-blue: %+v", owned)
+	}
+	_, other, _ := idx.OwnershipForDeployment("pets-green")
+	for _, c := range owned.Clusters {
+		if slices.Contains(other.Clusters, c) {
+			t.Fatalf("cluster %q is recorded for both deployments", c)
+		}
+	}
+
+	deleteSpec(idx, "Deployment", "pets-blue")
+	if err := dt.Translate(context.Background(), index.AffectedTask{Kind: "Deployment", Name: "pets-blue", Deletion: true}); err != nil {
+		t.Fatalf("delete pets-blue: %v", err)
+	}
+	after, err := dt.cache.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	want := names(before)
+	want[resourcev3.ClusterType] = slices.DeleteFunc(want[resourcev3.ClusterType], func(n string) bool {
+		return slices.Contains(owned.Clusters, n)
+	})
+	got := names(after)
+	if !slices.Equal(got[resourcev3.ClusterType], want[resourcev3.ClusterType]) {
+		t.Errorf("clusters after delete = %v, want %v", got[resourcev3.ClusterType], want[resourcev3.ClusterType])
+	}
+	for _, r := range owned.Routes {
+		if rc, ok := after.GetResources(resourcev3.RouteType)[r]; ok && len(rc.(*routev3.RouteConfiguration).GetVirtualHosts()[0].GetRoutes()) > 0 {
+			t.Errorf("route config %q still routes after delete", r)
+		}
+	}
+	for _, r := range other.Routes {
+		if !slices.Contains(got[resourcev3.RouteType], r) {
+			t.Errorf("route config %q of pets-green removed", r)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
+	"github.com/flowc-labs/flowc/pkg/logger"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+// DeploymentTarget is where a deployment is served: its gateway's node
+// and the environment (Listener) it resolves to.
+type DeploymentTarget struct {
+	API     string `json:"api"`
+	Gateway string `json:"gateway"`
+	NodeID  string `json:"nodeId,omitempty"`
+	// Environment is the Listener serving the deployment, and Listener
+	// the name of its xDS listener.
+	Environment string   `json:"environment,omitempty"`
+	Listener    string   `json:"listener,omitempty"`
+	Port        uint32   `json:"port,omitempty"`
+	Hostnames   []string `json:"hostnames,omitempty"`
+}
+
+// DescribedResources names the xDS resources a deployment translates to.
+type DescribedResources struct {
+	Clusters    []string `json:"clusters"`
+	Endpoints   []string `json:"endpoints"`
+	Routes      []string `json:"routes"`
+	HTTPFilters []string `json:"httpFilters,omitempty"`
+}
+
+// DeploymentDescription gathers what is known about a deployment: its
+// stored record, where it is served, the strategy config it resolves to
+// and the resources it translates to. Error explains why the later parts
+// are missing when the deployment cannot be resolved or translated.
+type DeploymentDescription struct {
+	Metadata  store.StoreMeta                 `json:"metadata"`
+	Spec      flowcv1alpha1.DeploymentSpec    `json:"spec"`
+	Status    *flowcv1alpha1.DeploymentStatus `json:"status,omitempty"`
+	Target    DeploymentTarget                `json:"target"`
+	Strategy  *types.StrategyConfig           `json:"strategy,omitempty"`
+	Resources *DescribedResources             `json:"resources,omitempty"`
+	Error     string                          `json:"error,omitempty"`
+}
+
+// DeploymentDescriber describes deployments. Like DriftDetector it is
+// read-only and translates with an indexer built afresh from the store
+// for every request.
+type DeploymentDescriber struct {
+	store    store.Store
+	parsers  *ir.ParserRegistry
+	defaults DefaultListener
+	options  *translator.TranslatorOptions
+	log      *logger.EnvoyLogger
+}
+
+// NewDeploymentDescriber constructs a describer. parsers, defaults and
+// the translator options (see SetOptions) must match the reconciler's
+// for the description to match what is served.
+func NewDeploymentDescriber(s store.Store, parsers *ir.ParserRegistry, defaults DefaultListener, log *logger.EnvoyLogger) *DeploymentDescriber {
+	return &DeploymentDescriber{
+		store:    s,
+		parsers:  parsers,
+		defaults: defaults,
+		options:  translator.DefaultTranslatorOptions(),
+		log:      log,
+	}
+}
+
+// SetOptions replaces the translator options deployments are translated
+// with.
+func (d *DeploymentDescriber) SetOptions(options *translator.TranslatorOptions) {
+	d.options = options
+}
+
+// DescribeDeployment describes the named deployment. store.ErrNotFound
+// is returned when it does not exist; a deployment that is not ready or
+// fails to translate is still described, as far as it resolves.
+func (d *DeploymentDescriber) DescribeDeployment(ctx context.Context, name string) (*DeploymentDescription, error) {
+	res, err := d.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: name})
+	if err != nil {
+		return nil, err
+	}
+	out := &DeploymentDescription{Metadata: res.Meta}
+	if err := json.Unmarshal(res.SpecJSON, &out.Spec); err != nil {
+		return nil, fmt.Errorf("decode deployment spec: %w", err)
+	}
+	if len(res.StatusJSON) > 0 {
+		var status flowcv1alpha1.DeploymentStatus
+		if err := json.Unmarshal(res.StatusJSON, &status); err != nil {
+			return nil, fmt.Errorf("decode deployment status: %w", err)
+		}
+		out.Status = &status
+	}
+	out.Target = DeploymentTarget{
+		API:         out.Spec.APIRef,
+		Gateway:     out.Spec.Gateway.Name,
+		Environment: out.Spec.Gateway.Listener,
+	}
+
+	idx := index.New(d.log)
+	if err := idx.Bootstrap(ctx, d.store); err != nil {
+		return nil, fmt.Errorf("bootstrap indexer: %w", err)
+	}
+	dep, ok := idx.GetDeployment(name)
+	if !ok {
+		out.Error = fmt.Sprintf("deployment %q is not ready", name)
+		return out, nil
+	}
+	api, ok := idx.GetAPI(dep.Spec.APIRef)
+	if !ok {
+		out.Error = fmt.Sprintf("API %q is not ready", dep.Spec.APIRef)
+		return out, nil
+	}
+	gw, ok := idx.GetGateway(dep.Spec.Gateway.Name)
+	if !ok {
+		out.Error = fmt.Sprintf("gateway %q is not ready", dep.Spec.Gateway.Name)
+		return out, nil
+	}
+	out.Target.NodeID = gw.Spec.NodeID
+	out.Strategy = resolveStrategy(dep, api, gw, d.log)
+
+	l, err := resolveListener(dep, idx, gw, d.defaults)
+	if err != nil {
+		out.Error = err.Error()
+		return out, nil
+	}
+	out.Target.Environment = l.Name
+	out.Target.Listener = fmt.Sprintf("listener_%d", l.Spec.Port)
+	out.Target.Port = l.Spec.Port
+	out.Target.Hostnames = l.Spec.Hostnames
+
+	xds, err := translateOne(ctx, dep, idx, d.parsers, d.options, d.defaults, d.log)
+	if err != nil {
+		out.Error = err.Error()
+		return out, nil
+	}
+	names := resourceNamesFromXDS(xds)
+	out.Resources = &DescribedResources{
+		Clusters:    names.Clusters,
+		Endpoints:   names.Endpoints,
+		Routes:      names.Routes,
+		HTTPFilters: names.HTTPFilters,
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func TestDescribeDeployment(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{
+		NodeID: "edge-node",
+		Defaults: &flowcv1alpha1.StrategyConfig{
+			Retry: &flowcv1alpha1.RetryStrategyConfig{Type: "aggressive"},
+		},
+	})
+	putSpec(t, s, "Listener", "prod", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8080,
+		Hostnames:  []string{"api.example.com"},
+	})
+	putSpec(t, s, "API", "pets", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/pets",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "pets.local", Port: 8080},
+	})
+	putSpec(t, s, "Deployment", "pets", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "pets",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	})
+
+	d := NewDeploymentDescriber(s, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	desc, err := d.DescribeDeployment(context.Background(), "pets")
+	if err != nil {
+		t.Fatalf("DescribeDeployment: %v", err)
+	}
+	if desc.Error != "" {
+		t.Fatalf("error = %q, want none", desc.Error)
+	}
+	if desc.Metadata.Name != "pets" || desc.Spec.APIRef != "pets" {
+		t.Errorf("record = %+v %+v, want deployment pets of API pets", desc.Metadata, desc.Spec)
+	}
+	want := DeploymentTarget{API: "pets", Gateway: "edge", NodeID: "edge-node", Environment: "prod", Listener: "listener_8080", Port: 8080}
+	if got := desc.Target; got.API != want.API || got.Gateway != want.Gateway || got.NodeID != want.NodeID ||
+		got.Environment != want.Environment || got.Listener != want.Listener || got.Port != want.Port {
+		t.Errorf("target = %+v, want %+v", got, want)
+	}
+	// The retry preset comes from the gateway's defaults.
+	if desc.Strategy == nil || desc.Strategy.Retry == nil || desc.Strategy.Retry.Type != "aggressive" {
+		t.Errorf("resolved strategy = %+v, want the gateway's aggressive retry", desc.Strategy)
+	}
+	if desc.Resources == nil || len(desc.Resources.Clusters) == 0 || len(desc.Resources.Routes) != 1 || desc.Resources.Routes[0] != "route_prod_api.example.com" {
+		t.Errorf("resources = %+v, want the deployment's clusters and route_prod_api.example.com", desc.Resources)
+	}
+
+	// A deployment whose API is missing is described as far as it goes.
+	putSpec(t, s, "Deployment", "owners", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "owners",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	})
+	desc, err = d.DescribeDeployment(context.Background(), "owners")
+	if err != nil {
+		t.Fatalf("DescribeDeployment: %v", err)
+	}
+	if desc.Error == "" || desc.Target.Gateway != "edge" || desc.Resources != nil {
+		t.Errorf("description = %+v, want the edge target and an error without resources", desc)
+	}
+
+	if _, err := d.DescribeDeployment(context.Background(), "vets"); err != store.ErrNotFound {
+		t.Errorf("unknown deployment: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,183 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	cachetypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// ErrNotTranslatable is returned by DetectDrift when the deployment or one
+// of its dependencies is not ready, or the deployment fails to translate.
+var ErrNotTranslatable = errors.New("deployment cannot be translated")
+
+// Drift statuses of a resource.
+const (
+	// DriftChanged marks a live resource that differs from the translation.
+	DriftChanged = "changed"
+	// DriftMissing marks a translated resource absent from the live snapshot.
+	DriftMissing = "missing"
+)
+
+// ResourceDrift is one xDS resource whose live version is out of date.
+type ResourceDrift struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// DriftReport compares a fresh translation of a deployment with the
+// snapshot its gateway's node is serving.
+type DriftReport struct {
+	Deployment string `json:"deployment"`
+	Gateway    string `json:"gateway"`
+	NodeID     string `json:"nodeId"`
+	Drifted    bool   `json:"drifted"`
+	// Resources lists drifted resources by type ("clusters", "endpoints",
+	// "routes", "listeners"). Types without drift are omitted.
+	Resources map[string][]ResourceDrift `json:"resources"`
+}
+
+// DriftDetector re-translates deployments from the store with the running
+// translator and diffs the result against the live xDS snapshot. It is
+// read-only: neither the store nor the cache is modified, and the
+// indexer it translates from is built afresh for every check so it never
+// shares state with the reconciler's.
+type DriftDetector struct {
+	store    store.Store
+	cache    *cache.ConfigManager
+	parsers  *ir.ParserRegistry
+	defaults DefaultListener
+	options  *translator.TranslatorOptions
+	log      *logger.EnvoyLogger
+}
+
+// NewDriftDetector constructs a detector. parsers, defaults and the
+// translator options (see SetOptions) must match the reconciler's, or
+// every deployment will report drift.
+func NewDriftDetector(
+	s store.Store,
+	cm *cache.ConfigManager,
+	parsers *ir.ParserRegistry,
+	defaults DefaultListener,
+	log *logger.EnvoyLogger,
+) *DriftDetector {
+	return &DriftDetector{
+		store:    s,
+		cache:    cm,
+		parsers:  parsers,
+		defaults: defaults,
+		options:  translator.DefaultTranslatorOptions(),
+		log:      log,
+	}
+}
+
+// SetOptions replaces the translator options deployments are
+// re-translated with.
+func (d *DriftDetector) SetOptions(options *translator.TranslatorOptions) {
+	d.options = options
+}
+
+// DetectDrift translates the named deployment as part of a full rebuild
+// of its gateway and reports which of its clusters, endpoints and route
+// configs differ from, or are missing in, the node's live snapshot.
+// Listeners are compared too when the deployment contributes HCM filters
+// to them. Resources the live snapshot holds but the translation no
+// longer produces are not reported.
+func (d *DriftDetector) DetectDrift(ctx context.Context, deployment string) (*DriftReport, error) {
+	if _, err := d.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: deployment}); err != nil {
+		return nil, err
+	}
+
+	idx := index.New(d.log)
+	if err := idx.Bootstrap(ctx, d.store); err != nil {
+		return nil, fmt.Errorf("bootstrap indexer: %w", err)
+	}
+	dep, ok := idx.GetDeployment(deployment)
+	if !ok {
+		return nil, fmt.Errorf("%w: deployment %q is not ready", ErrNotTranslatable, deployment)
+	}
+	gw, ok := idx.GetGateway(dep.Spec.Gateway.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: gateway %q is not ready", ErrNotTranslatable, dep.Spec.Gateway.Name)
+	}
+
+	gt := NewGatewayTranslator(idx, d.cache, d.parsers, d.defaults, d.log)
+	gt.SetOptions(d.options)
+	// buildSnapshot only logs per-deployment failures; surface this one.
+	if _, err := translateOne(ctx, dep, idx, d.parsers, d.options, d.defaults, d.log); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotTranslatable, err)
+	}
+	want, perDepNames := gt.buildSnapshot(ctx, gw.Name)
+	names := perDepNames[deployment]
+
+	live := map[resourcev3.Type]map[string]cachetypes.Resource{}
+	if snap, err := d.cache.GetSnapshot(gw.Spec.NodeID); err == nil {
+		for _, typ := range []resourcev3.Type{resourcev3.ClusterType, resourcev3.EndpointType, resourcev3.RouteType, resourcev3.ListenerType} {
+			live[typ] = snap.GetResources(typ)
+		}
+	}
+
+	report := &DriftReport{
+		Deployment: deployment,
+		Gateway:    gw.Name,
+		NodeID:     gw.Spec.NodeID,
+		Resources:  map[string][]ResourceDrift{},
+	}
+	report.add("clusters", diffResources(want.Clusters, names.Clusters, live[resourcev3.ClusterType], (*clusterv3.Cluster).GetName))
+	report.add("endpoints", diffResources(want.Endpoints, names.Endpoints, live[resourcev3.EndpointType], (*endpointv3.ClusterLoadAssignment).GetClusterName))
+	report.add("routes", diffResources(want.Routes, names.Routes, live[resourcev3.RouteType], (*routev3.RouteConfiguration).GetName))
+	if len(names.HTTPFilters) > 0 {
+		var listeners []string
+		for _, l := range want.Listeners {
+			listeners = append(listeners, l.Name)
+		}
+		report.add("listeners", diffResources(want.Listeners, listeners, live[resourcev3.ListenerType], (*listenerv3.Listener).GetName))
+	}
+	return report, nil
+}
+
+// add records the drifted resources of one type.
+func (r *DriftReport) add(typ string, drift []ResourceDrift) {
+	if len(drift) == 0 {
+		return
+	}
+	r.Resources[typ] = drift
+	r.Drifted = true
+}
+
+// diffResources compares the wanted resources named in names with their
+// live counterparts, returning the drifted ones sorted by name.
+func diffResources[T proto.Message](want []T, names []string, live map[string]cachetypes.Resource, name func(T) string) []ResourceDrift {
+	var drift []ResourceDrift
+	for _, w := range want {
+		n := name(w)
+		if !slices.Contains(names, n) {
+			continue
+		}
+		l, ok := live[n]
+		switch {
+		case !ok:
+			drift = append(drift, ResourceDrift{Name: n, Status: DriftMissing})
+		case !proto.Equal(w, l):
+			drift = append(drift, ResourceDrift{Name: n, Status: DriftChanged})
+		}
+	}
+	slices.SortFunc(drift, func(a, b ResourceDrift) int { return strings.Compare(a.Name, b.Name) })
+	return drift
+}
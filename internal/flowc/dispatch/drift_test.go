@@ -0,0 +1,95 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func putSpec(t *testing.T, s store.Store, kind, name string, spec any) {
+	t.Helper()
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal %s/%s: %v", kind, name, err)
+	}
+	if _, err := s.Put(context.Background(), &store.StoredResource{
+		Meta:     store.StoreMeta{Kind: kind, Name: name},
+		SpecJSON: data,
+	}, store.PutOptions{}); err != nil {
+		t.Fatalf("put %s/%s: %v", kind, name, err)
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	putSpec(t, s, "API", "pets", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/pets",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "pets.local", Port: 8080},
+	})
+	putSpec(t, s, "Deployment", "pets", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "pets",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	})
+
+	// Publish the live snapshot the way the reconciler does.
+	idx := index.New(nil)
+	if err := idx.Bootstrap(context.Background(), s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	report, err := NewDriftDetector(s, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil).
+		DetectDrift(context.Background(), "pets")
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+	if report.Drifted || len(report.Resources) != 0 {
+		t.Fatalf("unchanged translator reported drift: %+v", report.Resources)
+	}
+
+	// A control plane whose default environment hostname changed would
+	// publish the deployment's routes under a new route config.
+	report, err = NewDriftDetector(s, cm, ir.DefaultParserRegistry(), DefaultListener{Hostname: "api.example.com"}, nil).
+		DetectDrift(context.Background(), "pets")
+	if err != nil {
+		t.Fatalf("DetectDrift: %v", err)
+	}
+	if !report.Drifted || report.NodeID != "edge-node" {
+		t.Fatalf("report = %+v, want drift on edge-node", report)
+	}
+	routes := report.Resources["routes"]
+	if len(routes) != 1 || routes[0].Name != "route_default_api.example.com" || routes[0].Status != DriftMissing {
+		t.Errorf("route drift = %+v, want route_default_api.example.com missing", routes)
+	}
+	if clusters := report.Resources["clusters"]; len(clusters) != 0 {
+		t.Errorf("cluster drift = %+v, want none", clusters)
+	}
+
+	if _, err := NewDriftDetector(s, cm, nil, DefaultListener{}, nil).DetectDrift(context.Background(), "nope"); err != store.ErrNotFound {
+		t.Errorf("unknown deployment: err = %v, want ErrNotFound", err)
+	}
+	putSpec(t, s, "Deployment", "orphan", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "missing",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	})
+	if _, err := NewDriftDetector(s, cm, nil, DefaultListener{}, nil).DetectDrift(context.Background(), "orphan"); !errors.Is(err, ErrNotTranslatable) {
+		t.Errorf("deployment without API: err = %v, want ErrNotTranslatable", err)
+	}
+}
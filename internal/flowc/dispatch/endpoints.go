@@ -0,0 +1,175 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// Sources of a cluster's endpoints.
+const (
+	// EndpointSourceEDS marks endpoints served as a ClusterLoadAssignment
+	// resource of their own.
+	EndpointSourceEDS = "eds"
+	// EndpointSourceInline marks endpoints embedded in the cluster.
+	EndpointSourceInline = "inline"
+)
+
+// Endpoint is one upstream host a cluster is advertised with.
+type Endpoint struct {
+	Address string `json:"address"`
+	Port    uint32 `json:"port,omitempty"`
+	// Weight is the load balancing weight, if set.
+	Weight uint32 `json:"weight,omitempty"`
+	// Locality is "region/zone/subzone", if set.
+	Locality string `json:"locality,omitempty"`
+	Priority uint32 `json:"priority,omitempty"`
+	// HealthStatus is the health advertised for the host, if any, e.g.
+	// "healthy" or "draining".
+	HealthStatus string `json:"healthStatus,omitempty"`
+}
+
+// ClusterEndpoints lists the endpoints of one cluster.
+type ClusterEndpoints struct {
+	Cluster   string     `json:"cluster"`
+	Source    string     `json:"source"`
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// DeploymentEndpoints lists the endpoints a deployment's clusters are
+// advertised with in its gateway's live snapshot.
+type DeploymentEndpoints struct {
+	Deployment string             `json:"deployment"`
+	Gateway    string             `json:"gateway"`
+	NodeID     string             `json:"nodeId"`
+	Clusters   []ClusterEndpoints `json:"clusters"`
+}
+
+// EndpointInspector reads the endpoints of deployments' clusters from the
+// live xDS snapshot. Like DriftDetector it is read-only and translates
+// the deployment, to learn which clusters are its own, with an indexer
+// built afresh from the store for every request.
+type EndpointInspector struct {
+	store    store.Store
+	cache    *cache.ConfigManager
+	parsers  *ir.ParserRegistry
+	defaults DefaultListener
+	options  *translator.TranslatorOptions
+	log      *logger.EnvoyLogger
+}
+
+// NewEndpointInspector constructs an inspector. parsers, defaults and the
+// translator options (see SetOptions) must match the reconciler's for the
+// deployment's clusters to be found.
+func NewEndpointInspector(
+	s store.Store,
+	cm *cache.ConfigManager,
+	parsers *ir.ParserRegistry,
+	defaults DefaultListener,
+	log *logger.EnvoyLogger,
+) *EndpointInspector {
+	return &EndpointInspector{
+		store:    s,
+		cache:    cm,
+		parsers:  parsers,
+		defaults: defaults,
+		options:  translator.DefaultTranslatorOptions(),
+		log:      log,
+	}
+}
+
+// SetOptions replaces the translator options deployments are translated
+// with.
+func (e *EndpointInspector) SetOptions(options *translator.TranslatorOptions) {
+	e.options = options
+}
+
+// DeploymentEndpoints returns the endpoints of the named deployment's
+// clusters as its gateway's node is being served them: from the cluster's
+// EDS resource when it has one, or else from the cluster itself. Clusters
+// not in the live snapshot yet are left out; ErrNotServed is returned
+// when the node has no snapshot at all.
+func (e *EndpointInspector) DeploymentEndpoints(ctx context.Context, deployment string) (*DeploymentEndpoints, error) {
+	if _, err := e.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: deployment}); err != nil {
+		return nil, err
+	}
+
+	idx := index.New(e.log)
+	if err := idx.Bootstrap(ctx, e.store); err != nil {
+		return nil, fmt.Errorf("bootstrap indexer: %w", err)
+	}
+	dep, ok := idx.GetDeployment(deployment)
+	if !ok {
+		return nil, fmt.Errorf("%w: deployment %q is not ready", ErrNotTranslatable, deployment)
+	}
+	gw, ok := idx.GetGateway(dep.Spec.Gateway.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: gateway %q is not ready", ErrNotTranslatable, dep.Spec.Gateway.Name)
+	}
+	xds, err := translateOne(ctx, dep, idx, e.parsers, e.options, e.defaults, e.log)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotTranslatable, err)
+	}
+
+	snap, err := e.cache.GetSnapshot(gw.Spec.NodeID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: gateway %q has no snapshot", ErrNotServed, gw.Name)
+	}
+	clusters := snap.GetResources(resourcev3.ClusterType)
+	assignments := snap.GetResources(resourcev3.EndpointType)
+
+	out := &DeploymentEndpoints{
+		Deployment: deployment,
+		Gateway:    gw.Name,
+		NodeID:     gw.Spec.NodeID,
+		Clusters:   []ClusterEndpoints{},
+	}
+	for _, name := range resourceNamesFromXDS(xds).Clusters {
+		c, ok := clusters[name].(*clusterv3.Cluster)
+		if !ok {
+			continue
+		}
+		ce := ClusterEndpoints{Cluster: name, Source: EndpointSourceInline, Endpoints: []Endpoint{}}
+		cla := c.GetLoadAssignment()
+		if eds, ok := assignments[name].(*endpointv3.ClusterLoadAssignment); ok {
+			ce.Source, cla = EndpointSourceEDS, eds
+		}
+		for _, locality := range cla.GetEndpoints() {
+			for _, lb := range locality.GetLbEndpoints() {
+				ce.Endpoints = append(ce.Endpoints, endpointEntry(locality, lb))
+			}
+		}
+		out.Clusters = append(out.Clusters, ce)
+	}
+	return out, nil
+}
+
+// endpointEntry describes host lb of locality group locality.
+func endpointEntry(locality *endpointv3.LocalityLbEndpoints, lb *endpointv3.LbEndpoint) Endpoint {
+	sa := lb.GetEndpoint().GetAddress().GetSocketAddress()
+	e := Endpoint{
+		Address:  sa.GetAddress(),
+		Port:     sa.GetPortValue(),
+		Weight:   lb.GetLoadBalancingWeight().GetValue(),
+		Priority: locality.GetPriority(),
+	}
+	if l := locality.GetLocality(); l != nil {
+		e.Locality = strings.Join([]string{l.Region, l.Zone, l.SubZone}, "/")
+	}
+	if s := lb.GetHealthStatus(); s != corev3.HealthStatus_UNKNOWN {
+		e.HealthStatus = strings.ToLower(s.String())
+	}
+	return e
+}
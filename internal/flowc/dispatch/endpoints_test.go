@@ -0,0 +1,84 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestDeploymentEndpointsMatchUpstream(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	putSpec(t, s, "API", "pets", flowcv1alpha1.APISpec{
+		Version: "v1",
+		Context: "/pets",
+		Upstream: flowcv1alpha1.UpstreamConfig{
+			Host: "pets.local",
+			Port: 8080,
+			Hosts: []flowcv1alpha1.UpstreamHost{
+				{Host: "10.0.0.1", Weight: 3},
+				{Host: "10.0.0.2", Port: 9090, Weight: 1},
+			},
+		},
+	})
+	putSpec(t, s, "Deployment", "pets", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "pets",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	})
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	inspector := NewEndpointInspector(s, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if _, err := inspector.DeploymentEndpoints(context.Background(), "pets"); !errors.Is(err, ErrNotServed) {
+		t.Fatalf("before translation: err = %v, want ErrNotServed", err)
+	}
+
+	idx := index.New(nil)
+	if err := idx.Bootstrap(context.Background(), s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	got, err := inspector.DeploymentEndpoints(context.Background(), "pets")
+	if err != nil {
+		t.Fatalf("DeploymentEndpoints: %v", err)
+	}
+	if got.Gateway != "edge" || got.NodeID != "edge-node" {
+		t.Errorf("endpoints = %+v, want gateway edge on edge-node", got)
+	}
+	if len(got.Clusters) != 1 {
+		t.Fatalf("clusters = %+v, want the deployment's one cluster", got.Clusters)
+	}
+	c := got.Clusters[0]
+	if c.Source != EndpointSourceInline {
+		t.Errorf("source = %q, want %q", c.Source, EndpointSourceInline)
+	}
+	want := []Endpoint{
+		{Address: "10.0.0.1", Port: 8080, Weight: 3},
+		{Address: "10.0.0.2", Port: 9090, Weight: 1},
+	}
+	if len(c.Endpoints) != len(want) {
+		t.Fatalf("endpoints = %+v, want %+v", c.Endpoints, want)
+	}
+	for i := range want {
+		if c.Endpoints[i] != want[i] {
+			t.Errorf("endpoint %d = %+v, want %+v", i, c.Endpoints[i], want[i])
+		}
+	}
+
+	if _, err := inspector.DeploymentEndpoints(context.Background(), "owners"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown deployment: err = %v, want ErrNotFound", err)
+	}
+}
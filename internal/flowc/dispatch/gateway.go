@@ -3,13 +3,20 @@ package dispatch
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/proto"
+
 	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
 	"github.com/flowc-labs/flowc/internal/flowc/index"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	clusterbuilder "github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
 	listenerbuilder "github.com/flowc-labs/flowc/internal/flowc/xds/resources/listener"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
 	"github.com/flowc-labs/flowc/pkg/logger"
@@ -23,30 +30,40 @@ import (
 //
 // On Delete it clears the node's snapshot and ownership entries.
 type GatewayTranslator struct {
-	indexer *index.Indexer
-	cache   *cache.ConfigManager
-	parsers *ir.ParserRegistry
-	options *translator.TranslatorOptions
-	log     *logger.EnvoyLogger
+	indexer  *index.Indexer
+	cache    *cache.ConfigManager
+	parsers  *ir.ParserRegistry
+	options  *translator.TranslatorOptions
+	defaults DefaultListener
+	log      *logger.EnvoyLogger
 }
 
 // NewGatewayTranslator constructs the translator with all dependencies
-// injected.
+// injected. defaults describes the listener used for gateways that have
+// no Listener resources.
 func NewGatewayTranslator(
 	idx *index.Indexer,
 	cm *cache.ConfigManager,
 	parsers *ir.ParserRegistry,
+	defaults DefaultListener,
 	log *logger.EnvoyLogger,
 ) *GatewayTranslator {
 	return &GatewayTranslator{
-		indexer: idx,
-		cache:   cm,
-		parsers: parsers,
-		options: translator.DefaultTranslatorOptions(),
-		log:     log,
+		indexer:  idx,
+		cache:    cm,
+		parsers:  parsers,
+		options:  translator.DefaultTranslatorOptions(),
+		defaults: defaults,
+		log:      log,
 	}
 }
 
+// SetOptions replaces the translator options. Call it before the
+// translator is registered with a running dispatcher.
+func (t *GatewayTranslator) SetOptions(options *translator.TranslatorOptions) {
+	t.options = options
+}
+
 // Kind returns the dispatch kind name.
 func (t *GatewayTranslator) Kind() string { return "Gateway" }
 
@@ -70,22 +87,54 @@ func (t *GatewayTranslator) handlePut(ctx context.Context, task index.AffectedTa
 		return nil
 	}
 	nodeID := gw.Spec.NodeID
+	defer t.cache.LockNode(nodeID)()
+
+	snap, perDepNames := t.buildSnapshot(ctx, task.Name)
+	if err := t.cache.ReplaceSnapshot(nodeID, snap); err != nil {
+		return fmt.Errorf("replace snapshot for gateway %q: %w", task.Name, err)
+	}
+
+	// Replace ownership for this node atomically: clear then re-record.
+	// Old entries for deployments no longer on this gateway disappear.
+	t.indexer.ClearOwnershipForNode(nodeID)
+	for depName, names := range perDepNames {
+		t.indexer.RecordOwnership(nodeID, depName, names)
+	}
 
-	listeners := t.indexer.ListenersForGateway(task.Name)
-	deployments := t.indexer.DeploymentsForGateway(task.Name)
+	if t.log != nil {
+		t.log.WithFields(map[string]any{
+			"gateway":     task.Name,
+			"deployments": len(perDepNames),
+			"clusters":    len(snap.Clusters),
+			"routes":      len(snap.Routes),
+			"listeners":   len(snap.Listeners),
+		}).Info("Gateway snapshot rebuilt")
+	}
+	return nil
+}
+
+// buildSnapshot translates the full snapshot of gateway gwName from the
+// indexer, along with the resource names each translated deployment
+// contributed. It neither reads nor writes the cache.
+func (t *GatewayTranslator) buildSnapshot(ctx context.Context, gwName string) (*cache.Snapshot, map[string]cache.ResourceNames) {
+	listeners := listenersForGateway(t.indexer, gwName, t.defaults)
+	deployments := t.indexer.DeploymentsForGateway(gwName)
 
 	snap := &cache.Snapshot{}
 	perDepNames := make(map[string]cache.ResourceNames, len(deployments))
 	activeRoutes := make(map[string]struct{})
+	// HCM filters contributed by deployments, keyed by the route config
+	// name (== the filter chain they belong to).
+	filtersByRoute := make(map[string][]*hcmv3.HttpFilter)
 
 	for _, dep := range deployments {
-		xds, err := translateOne(ctx, dep, t.indexer, t.parsers, t.options, t.log)
+		xds, err := translateOne(ctx, dep, t.indexer, t.parsers, t.options, t.defaults, t.log)
 		if err != nil {
 			// Per-deployment failure: log and skip; the deployment
 			// will retry on its next Watch event.
 			if t.log != nil {
 				t.log.WithFields(map[string]any{
-					"gateway":    task.Name,
+					"gateway":    gwName,
 					"deployment": dep.Name,
 					"error":      err.Error(),
 				}).Error("Skipping deployment in gateway rebuild")
@@ -97,9 +146,12 @@ func (t *GatewayTranslator) handlePut(ctx context.Context, task index.AffectedTa
 		snap.Routes = append(snap.Routes, xds.Routes...)
 		for _, rc := range xds.Routes {
 			activeRoutes[rc.Name] = struct{}{}
+			filtersByRoute[rc.Name] = t.mergeHTTPFilters(filtersByRoute[rc.Name], xds.HTTPFilters, dep.Name)
 		}
 		perDepNames[dep.Name] = resourceNamesFromXDS(xds)
 	}
+	snap.Routes = mergeRouteConfigs(snap.Routes)
+	applyTrafficSplits(t.indexer, listeners, snap.Routes)
 
 	// Ensure every (listener, hostname) the listener layer will reference
 	// has a matching RouteConfiguration in the snapshot. Without this, the
@@ -109,13 +161,16 @@ func (t *GatewayTranslator) handlePut(ctx context.Context, task index.AffectedTa
 	// reject it. Real routes from later DeployAPI calls dedup by name
 	// onto these placeholders, so once a deployment's routes show up the
 	// placeholder is silently replaced.
+	// Listeners sharing an environment's routes (routesFrom) reference the
+	// same names, so they get one placeholder between them.
 	for _, l := range listeners {
-		hostnames := l.Spec.Hostnames
+		src := routesListener(t.indexer, l)
+		hostnames := src.Spec.Hostnames
 		if len(hostnames) == 0 {
 			hostnames = []string{"*"}
 		}
 		for _, hostname := range hostnames {
-			routeName := fmt.Sprintf("route_%s_%s", l.Name, hostname)
+			routeName := fmt.Sprintf("route_%s_%s", src.Name, hostname)
 			if _, ok := activeRoutes[routeName]; ok {
 				continue
 			}
@@ -124,29 +179,22 @@ func (t *GatewayTranslator) handlePut(ctx context.Context, task index.AffectedTa
 		}
 	}
 
-	snap.Listeners = t.buildListeners(listeners)
-
-	if err := t.cache.ReplaceSnapshot(nodeID, snap); err != nil {
-		return fmt.Errorf("replace snapshot for gateway %q: %w", task.Name, err)
-	}
-
-	// Replace ownership for this node atomically: clear then re-record.
-	// Old entries for deployments no longer on this gateway disappear.
-	t.indexer.ClearOwnershipForNode(nodeID)
-	for depName, names := range perDepNames {
-		t.indexer.RecordOwnership(nodeID, depName, names)
-	}
-
-	if t.log != nil {
-		t.log.WithFields(map[string]any{
-			"gateway":     task.Name,
-			"deployments": len(deployments),
-			"clusters":    len(snap.Clusters),
-			"routes":      len(snap.Routes),
-			"listeners":   len(snap.Listeners),
-		}).Info("Gateway snapshot rebuilt")
+	snap.Listeners = t.buildListeners(listeners, filtersByRoute)
+	for _, l := range statsListenersForGateway(t.indexer, gwName) {
+		xdsListener, statsCluster, err := buildStatsListener(l)
+		if err != nil {
+			if t.log != nil {
+				t.log.WithFields(map[string]any{
+					"listener": l.Name,
+					"error":    err.Error(),
+				}).Error("Failed to build stats listener")
+			}
+			continue
+		}
+		snap.Listeners = append(snap.Listeners, xdsListener)
+		snap.Clusters = append(snap.Clusters, statsCluster)
 	}
-	return nil
+	return snap, perDepNames
 }
 
 // handleDelete drops the node's snapshot and ownership entries. NodeID
@@ -157,6 +205,7 @@ func (t *GatewayTranslator) handleDelete(_ context.Context, task index.AffectedT
 		// Never knew the gateway (no NodeID captured); nothing to clear.
 		return nil
 	}
+	defer t.cache.LockNode(task.NodeID)()
 	t.cache.RemoveNode(task.NodeID)
 	t.indexer.ClearOwnershipForNode(task.NodeID)
 	return nil
@@ -170,20 +219,32 @@ func (t *GatewayTranslator) handleDelete(_ context.Context, task index.AffectedT
 // composite translator emits for routes (and what handlePut backfills
 // with placeholder route configs when no deployment supplies routes
 // yet — see the placeholder pass above).
-func (t *GatewayTranslator) buildListeners(listeners []*flowcv1alpha1.Listener) []*listenerv3.Listener {
+//
+// A listener with routesFrom gets the filter chains of the listener it
+// names, referencing the same route configs.
+//
+// filtersByRoute carries the deployment-contributed HCM filters for each
+// filter chain, keyed by its route config name.
+func (t *GatewayTranslator) buildListeners(
+	listeners []*flowcv1alpha1.Listener,
+	filtersByRoute map[string][]*hcmv3.HttpFilter,
+) []*listenerv3.Listener {
 	results := make([]*listenerv3.Listener, 0, len(listeners))
 	for _, l := range listeners {
-		hostnames := l.Spec.Hostnames
+		src := routesListener(t.indexer, l)
+		hostnames := src.Spec.Hostnames
 		if len(hostnames) == 0 {
 			hostnames = []string{"*"}
 		}
 
 		filterChains := make([]*listenerbuilder.FilterChainConfig, 0, len(hostnames))
 		for _, hostname := range hostnames {
+			routeName := fmt.Sprintf("route_%s_%s", src.Name, hostname)
 			filterChains = append(filterChains, &listenerbuilder.FilterChainConfig{
 				Name:            hostname,
 				Hostname:        hostname,
-				RouteConfigName: fmt.Sprintf("route_%s_%s", l.Name, hostname),
+				RouteConfigName: routeName,
+				Filters:         filtersByRoute[routeName],
 			})
 		}
 
@@ -193,11 +254,64 @@ func (t *GatewayTranslator) buildListeners(listeners []*flowcv1alpha1.Listener)
 		}
 
 		config := &listenerbuilder.ListenerConfig{
-			Name:         fmt.Sprintf("listener_%d", l.Spec.Port),
-			Port:         l.Spec.Port,
-			Address:      addr,
-			FilterChains: filterChains,
-			HTTP2:        l.Spec.HTTP2,
+			Name:            fmt.Sprintf("listener_%d", l.Spec.Port),
+			Port:            l.Spec.Port,
+			Address:         addr,
+			FilterChains:    filterChains,
+			HTTP2:           l.Spec.HTTP2,
+			ListenerFilters: l.Spec.ListenerFilters,
+		}
+		if o := l.Spec.HTTP2Options; o != nil {
+			config.HTTP2Options = &listenerbuilder.HTTP2Options{
+				InitialStreamWindowSize:     o.InitialStreamWindowSize,
+				InitialConnectionWindowSize: o.InitialConnectionWindowSize,
+				MaxConcurrentStreams:        o.MaxConcurrentStreams,
+			}
+		}
+		if h := l.Spec.HCM; h != nil {
+			config.HCM = &listenerbuilder.HCMOptions{
+				UseRemoteAddress:         h.UseRemoteAddress,
+				XFFNumTrustedHops:        h.XFFNumTrustedHops,
+				MaxConnectionDuration:    h.MaxConnectionDuration,
+				MaxRequestsPerConnection: h.MaxRequestsPerConnection,
+			}
+		}
+		if a := t.accessLogs(l.Spec.GatewayRef); a != nil && a.Enabled {
+			config.AccessLog = &listenerbuilder.AccessLogOptions{Format: a.Format, Path: a.Path}
+		}
+		if r := l.Spec.AuthFailureResponse; r != nil {
+			config.AuthFailureResponse = &listenerbuilder.AuthFailureResponse{
+				StatusCode:  r.StatusCode,
+				ContentType: r.ContentType,
+				Body:        r.Body,
+			}
+		}
+		if r := l.Spec.LocalReply; r != nil {
+			for _, m := range r.Mappings {
+				config.LocalReply = append(config.LocalReply, listenerbuilder.LocalReplyMapping{
+					Status:      m.Status,
+					ContentType: m.ContentType,
+					Body:        m.Body,
+				})
+			}
+		}
+		if tap := l.Spec.Tap; tap != nil {
+			if t.isProductionEnvironment(l) {
+				if t.log != nil {
+					t.log.WithFields(map[string]any{
+						"listener": l.Name,
+					}).Warn("Tap is not allowed on production environments; ignoring")
+				}
+			} else {
+				config.Tap = &listenerbuilder.TapOptions{
+					ConfigID:   l.Name,
+					Output:     tap.Output,
+					PathPrefix: tap.PathPrefix,
+				}
+				if tap.Match != nil {
+					config.Tap.RequestHeaders = tap.Match.RequestHeaders
+				}
+			}
 		}
 		xdsListener, err := listenerbuilder.CreateListenerWithFilterChains(config)
 		if err != nil {
@@ -214,6 +328,115 @@ func (t *GatewayTranslator) buildListeners(listeners []*flowcv1alpha1.Listener)
 	return results
 }
 
+// isProductionEnvironment reports whether l, or the gateway it belongs
+// to, carries a production EnvironmentLabel.
+func (t *GatewayTranslator) isProductionEnvironment(l *flowcv1alpha1.Listener) bool {
+	labels := []map[string]string{l.Labels}
+	if gw, ok := t.indexer.GetGateway(l.Spec.GatewayRef); ok {
+		labels = append(labels, gw.Labels)
+	}
+	for _, set := range labels {
+		switch strings.ToLower(set[flowcv1alpha1.EnvironmentLabel]) {
+		case "prod", "production":
+			return true
+		}
+	}
+	return false
+}
+
+// buildStatsListener constructs an "admin/stats" listener and the cluster
+// behind it. The listener routes /stats and /ready inline (no RDS) and has
+// no per-environment filter chains, so it never carries API traffic.
+func buildStatsListener(l *flowcv1alpha1.Listener) (*listenerv3.Listener, *clusterv3.Cluster, error) {
+	address, port := DefaultStatsAddress, uint32(DefaultStatsPort)
+	if s := l.Spec.Stats; s != nil {
+		if s.Address != "" {
+			address = s.Address
+		}
+		if s.Port != 0 {
+			port = s.Port
+		}
+	}
+	clusterName := fmt.Sprintf("stats_%s", l.Name)
+
+	xdsListener, err := listenerbuilder.CreateStatsListener(&listenerbuilder.StatsListenerConfig{
+		Name:        fmt.Sprintf("listener_%d", l.Spec.Port),
+		Port:        l.Spec.Port,
+		Address:     l.Spec.Address,
+		ClusterName: clusterName,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return xdsListener, clusterbuilder.CreateCluster(clusterName, address, port), nil
+}
+
+// mergeHTTPFilters appends a deployment's HCM filters to those already
+// collected for a filter chain. A filter chain can hold only one instance
+// of each filter, so the first deployment to contribute a given filter
+// name wins and later duplicates are dropped with a warning.
+func (t *GatewayTranslator) mergeHTTPFilters(existing, add []*hcmv3.HttpFilter, depName string) []*hcmv3.HttpFilter {
+	for _, f := range add {
+		if slices.ContainsFunc(existing, func(e *hcmv3.HttpFilter) bool { return e.Name == f.Name }) {
+			if t.log != nil {
+				t.log.WithFields(map[string]any{
+					"deployment": depName,
+					"filter":     f.Name,
+				}).Warn("HTTP filter already configured on filter chain by another deployment; ignoring")
+			}
+			continue
+		}
+		existing = append(existing, f)
+	}
+	return existing
+}
+
+// accessLogs returns the access log settings of gateway gwName's
+// defaults, which apply to all of its listeners, or nil when it has none.
+func (t *GatewayTranslator) accessLogs(gwName string) *flowcv1alpha1.AccessLogsConfig {
+	gw, ok := t.indexer.GetGateway(gwName)
+	if !ok || gw.Spec.Defaults == nil || gw.Spec.Defaults.Observability == nil {
+		return nil
+	}
+	return gw.Spec.Defaults.Observability.AccessLogs
+}
+
+// mergeRouteConfigs combines route configs of the same name. Route configs
+// are per environment, so every deployment on an environment emits one
+// with the same name; their virtual hosts are merged by name (deployments
+// sharing a virtual host name it alike) or else by domain set (Envoy
+// rejects a domain repeated across virtual hosts), and each merged host's
+// routes re-sorted by specificity. The first config and host seen keep
+// their names, domains and virtual host settings.
+func mergeRouteConfigs(configs []*routev3.RouteConfiguration) []*routev3.RouteConfiguration {
+	byName := make(map[string]*routev3.RouteConfiguration, len(configs))
+	merged := make([]*routev3.RouteConfiguration, 0, len(configs))
+	for _, rc := range configs {
+		existing, ok := byName[rc.Name]
+		if !ok {
+			rc = proto.Clone(rc).(*routev3.RouteConfiguration)
+			byName[rc.Name] = rc
+			merged = append(merged, rc)
+			continue
+		}
+		for _, vh := range rc.VirtualHosts {
+			i := slices.IndexFunc(existing.VirtualHosts, func(e *routev3.VirtualHost) bool {
+				return e.Name == vh.Name || slices.Equal(e.Domains, vh.Domains)
+			})
+			if i < 0 {
+				existing.VirtualHosts = append(existing.VirtualHosts, proto.Clone(vh).(*routev3.VirtualHost))
+				continue
+			}
+			target := existing.VirtualHosts[i]
+			for _, r := range vh.Routes {
+				target.Routes = append(target.Routes, proto.Clone(r).(*routev3.Route))
+			}
+			translator.SortRoutesBySpecificity(target.Routes)
+		}
+	}
+	return merged
+}
+
 // placeholderRouteConfig emits a RouteConfiguration with a single empty
 // VirtualHost. Used to satisfy snapshot.Consistent() when a Listener's
 // hostname has no deployment-emitted routes yet — every listener filter
@@ -231,3 +454,9 @@ func placeholderRouteConfig(name, domain string) *routev3.RouteConfiguration {
 		},
 	}
 }
+
+// routeConfigHostname returns the hostname a route config named
+// route_<listener>_<hostname> serves. Hostnames cannot contain "_".
+func routeConfigHostname(name string) string {
+	return name[strings.LastIndex(name, "_")+1:]
+}
@@ -0,0 +1,405 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	listenerbuilder "github.com/flowc-labs/flowc/internal/flowc/xds/resources/listener"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestGatewayStatsListener(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	applySpec(t, idx, "Listener", "https", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8443,
+		Hostnames:  []string{"api.example.com"},
+		TLS:        &flowcv1alpha1.TLSConfig{CertPath: "/certs/tls.crt", KeyPath: "/certs/tls.key"},
+	})
+	applySpec(t, idx, "Listener", "metrics", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       9100,
+		Kind:       flowcv1alpha1.ListenerKindAdminStats,
+	})
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	snap, err := cm.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	res, ok := snap.GetResources(resourcev3.ListenerType)["listener_9100"]
+	if !ok {
+		t.Fatalf("stats listener missing; listeners: %v", snap.GetResources(resourcev3.ListenerType))
+	}
+	stats := res.(*listenerv3.Listener)
+	if len(stats.FilterChains) != 1 || stats.FilterChains[0].GetFilterChainMatch() != nil {
+		t.Fatalf("stats listener filter chains = %v, want one chain without SNI matching", stats.FilterChains)
+	}
+	if len(stats.ListenerFilters) != 0 {
+		t.Errorf("stats listener has listener filters %v", stats.ListenerFilters)
+	}
+
+	var hcm hcmv3.HttpConnectionManager
+	if err := stats.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	routes := hcm.GetRouteConfig().GetVirtualHosts()[0].GetRoutes()
+	if len(routes) != 2 || routes[0].GetMatch().GetPrefix() != "/stats" || routes[1].GetMatch().GetPath() != "/ready" {
+		t.Fatalf("stats routes = %v, want /stats and /ready", routes)
+	}
+	clusterName := routes[0].GetRoute().GetCluster()
+	c, ok := snap.GetResources(resourcev3.ClusterType)[clusterName]
+	if !ok {
+		t.Fatalf("stats cluster %q missing from snapshot", clusterName)
+	}
+	if got := c.(*clusterv3.Cluster).GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()[0].
+		GetEndpoint().GetAddress().GetSocketAddress().GetPortValue(); got != DefaultStatsPort {
+		t.Errorf("stats cluster port = %d, want %d", got, DefaultStatsPort)
+	}
+
+	// The stats listener is not a deployment target: the data listener
+	// is still auto-resolved as the gateway's only one.
+	if listeners := listenersForGateway(idx, "edge", DefaultListener{}); len(listeners) != 1 || listeners[0].Name != "https" {
+		t.Errorf("data listeners = %v, want [https]", listeners)
+	}
+}
+
+func TestGatewayListenerPortChange(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	listener := flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       10000,
+		Hostnames:  []string{"staging.example.com", "prod.example.com"},
+	}
+	applySpec(t, idx, "Listener", "http", listener)
+	for _, name := range []string{"pets", "users"} {
+		applySpec(t, idx, "API", name, flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  "/" + name,
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: name + ".local", Port: 8080},
+		})
+		applySpec(t, idx, "Deployment", name, flowcv1alpha1.DeploymentSpec{
+			APIRef:  name,
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: "http"},
+		})
+	}
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	rebuild := func() *cachev3.Snapshot {
+		t.Helper()
+		if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		snap, err := cm.GetSnapshot("edge-node")
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		return snap
+	}
+	rebuild()
+
+	listener.Port = 10080
+	applySpec(t, idx, "Listener", "http", listener)
+	snap := rebuild()
+
+	listeners := snap.GetResources(resourcev3.ListenerType)
+	if _, ok := listeners["listener_10080"]; !ok {
+		t.Errorf("listener on the new port missing; listeners: %v", listeners)
+	}
+	if _, ok := listeners["listener_10000"]; ok {
+		t.Errorf("listener on the old port still in the snapshot")
+	}
+
+	// Both environments keep their route configs, and the deployments
+	// (which land on the first hostname) still resolve to the listener.
+	routes := snap.GetResources(resourcev3.RouteType)
+	for _, host := range listener.Hostnames {
+		if _, ok := routes["route_http_"+host]; !ok {
+			t.Errorf("route config for %s missing; routes: %v", host, routes)
+		}
+	}
+	rc, ok := routes["route_http_staging.example.com"].(*routev3.RouteConfiguration)
+	if !ok {
+		t.Fatal("staging route config missing")
+	}
+	var prefixes []string
+	for _, vh := range rc.GetVirtualHosts() {
+		for _, r := range vh.GetRoutes() {
+			prefixes = append(prefixes, r.GetMatch().GetPathSeparatedPrefix())
+		}
+	}
+	for _, want := range []string{"/pets", "/users"} {
+		if !slices.Contains(prefixes, want) {
+			t.Errorf("routes %v have no route for %s", prefixes, want)
+		}
+	}
+}
+
+func TestGatewayListenersShareEnvironmentRoutes(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	applySpec(t, idx, "Listener", "blue", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8080,
+		Hostnames:  []string{"api.example.com"},
+	})
+	applySpec(t, idx, "Listener", "green", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8081,
+		RoutesFrom: "blue",
+	})
+	applySpec(t, idx, "API", "pets", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/pets",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "pets.local", Port: 8080},
+	})
+	applySpec(t, idx, "Deployment", "pets", flowcv1alpha1.DeploymentSpec{
+		APIRef:  "pets",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: "green"},
+	})
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	snap, err := cm.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	const shared = "route_blue_api.example.com"
+	routes := snap.GetResources(resourcev3.RouteType)
+	if len(routes) != 1 {
+		t.Fatalf("got %d route configs, want only %s: %v", len(routes), shared, routes)
+	}
+	rc, ok := routes[shared].(*routev3.RouteConfiguration)
+	if !ok {
+		t.Fatalf("route config %s missing: %v", shared, routes)
+	}
+	if len(rc.GetVirtualHosts()) == 0 || len(rc.GetVirtualHosts()[0].GetRoutes()) == 0 {
+		t.Errorf("shared route config has no routes for the deployment: %v", rc)
+	}
+
+	listeners := snap.GetResources(resourcev3.ListenerType)
+	for _, name := range []string{"listener_8080", "listener_8081"} {
+		res, ok := listeners[name]
+		if !ok {
+			t.Fatalf("%s missing; listeners: %v", name, listeners)
+		}
+		var hcm hcmv3.HttpConnectionManager
+		if err := res.(*listenerv3.Listener).FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+			t.Fatalf("unmarshal HCM of %s: %v", name, err)
+		}
+		if got := hcm.GetRds().GetRouteConfigName(); got != shared {
+			t.Errorf("%s references route config %q, want %q", name, got, shared)
+		}
+	}
+}
+
+func TestGatewayAutoHTTPSRedirect(t *testing.T) {
+	for _, auto := range []bool{true, false} {
+		idx := index.New(nil)
+		applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node", AutoHTTPSRedirect: auto})
+		applySpec(t, idx, "Listener", "secure", flowcv1alpha1.ListenerSpec{
+			GatewayRef: "edge",
+			Port:       8443,
+			Hostnames:  []string{"shop.example.com"},
+			TLS:        &flowcv1alpha1.TLSConfig{CertPath: "/certs/tls.crt", KeyPath: "/certs/tls.key"},
+		})
+		applySpec(t, idx, "Listener", "plain", flowcv1alpha1.ListenerSpec{
+			GatewayRef: "edge",
+			Port:       8080,
+			RoutesFrom: "secure",
+		})
+		applySpec(t, idx, "API", "pets", flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  "/pets",
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: "pets.local", Port: 8080},
+		})
+		applySpec(t, idx, "Deployment", "pets", flowcv1alpha1.DeploymentSpec{
+			APIRef:  "pets",
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: "secure"},
+		})
+
+		cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+		gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+		if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		snap, err := cm.GetSnapshot("edge-node")
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		rc, ok := snap.GetResources(resourcev3.RouteType)["route_secure_shop.example.com"].(*routev3.RouteConfiguration)
+		if !ok || len(rc.GetVirtualHosts()) != 1 {
+			t.Fatalf("route config route_secure_shop.example.com missing or without a virtual host: %v", rc)
+		}
+
+		var redirects []*routev3.Route
+		for _, r := range rc.GetVirtualHosts()[0].GetRoutes() {
+			if r.GetRedirect() != nil {
+				redirects = append(redirects, r)
+			}
+		}
+		if !auto {
+			if len(redirects) != 0 {
+				t.Errorf("without autoHTTPSRedirect: got redirect routes %v", redirects)
+			}
+			continue
+		}
+		routes := rc.GetVirtualHosts()[0].GetRoutes()
+		if len(redirects) == 0 || routes[0].GetRedirect() == nil {
+			t.Fatalf("want a redirect route ahead of the others, got %v", routes)
+		}
+		r := redirects[0]
+		headers := r.GetMatch().GetHeaders()
+		if len(headers) != 1 || headers[0].GetName() != ":scheme" || headers[0].GetStringMatch().GetExact() != "http" {
+			t.Errorf("redirect route matches headers %v, want :scheme == http", headers)
+		}
+		redirect := r.GetRedirect()
+		if !redirect.GetHttpsRedirect() || redirect.GetPortRedirect() != 8443 || redirect.GetResponseCode() != routev3.RedirectAction_MOVED_PERMANENTLY {
+			t.Errorf("redirect = %v, want a 301 to https on port 8443", redirect)
+		}
+	}
+}
+
+func TestGatewaySharedVirtualHost(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	applySpec(t, idx, "Listener", "prod", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8080,
+		Hostnames:  []string{"shop.example.com"},
+	})
+	for _, name := range []string{"users", "orders"} {
+		applySpec(t, idx, "API", name, flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  "/" + name,
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: name + ".local", Port: 8080},
+			Routing:  &flowcv1alpha1.RoutingConfig{VirtualHost: "storefront"},
+		})
+		applySpec(t, idx, "Deployment", name, flowcv1alpha1.DeploymentSpec{
+			APIRef:  name,
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+		})
+	}
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	snap, err := cm.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	rc, ok := snap.GetResources(resourcev3.RouteType)["route_prod_shop.example.com"].(*routev3.RouteConfiguration)
+	if !ok {
+		t.Fatalf("route config route_prod_shop.example.com missing")
+	}
+	vhosts := rc.GetVirtualHosts()
+	if len(vhosts) != 1 || vhosts[0].GetName() != "storefront" {
+		t.Fatalf("virtual hosts = %v, want only storefront", vhosts)
+	}
+	var prefixes []string
+	for _, r := range vhosts[0].GetRoutes() {
+		prefixes = append(prefixes, r.GetMatch().GetPathSeparatedPrefix()+r.GetMatch().GetPrefix())
+	}
+	for _, want := range []string{"/users", "/orders"} {
+		if !slices.Contains(prefixes, want) {
+			t.Errorf("storefront routes %v have no %s route", prefixes, want)
+		}
+	}
+}
+
+func TestGatewayTapOnlyOutsideProduction(t *testing.T) {
+	idx := index.New(nil)
+	applyLabeled := func(kind, name string, labels map[string]string, spec any) {
+		t.Helper()
+		data, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatalf("marshal %s/%s: %v", kind, name, err)
+		}
+		idx.Apply(store.WatchEvent{
+			Type: store.WatchEventPut,
+			Resource: &store.StoredResource{
+				Meta:     store.StoreMeta{Kind: kind, Name: name, Labels: labels},
+				SpecJSON: data,
+			},
+		})
+	}
+	tap := &flowcv1alpha1.TapConfig{
+		Output: "streamed",
+		Match:  &flowcv1alpha1.TapMatch{RequestHeaders: map[string]string{"x-debug": "1"}},
+	}
+	prod := map[string]string{flowcv1alpha1.EnvironmentLabel: "production"}
+
+	applyLabeled("Gateway", "edge", nil, flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	applyLabeled("Listener", "staging", map[string]string{flowcv1alpha1.EnvironmentLabel: "staging"},
+		flowcv1alpha1.ListenerSpec{GatewayRef: "edge", Port: 8080, Tap: tap})
+	applyLabeled("Listener", "live", prod, flowcv1alpha1.ListenerSpec{GatewayRef: "edge", Port: 8081, Tap: tap})
+	// An unlabeled listener inherits production from its gateway.
+	applyLabeled("Gateway", "prod-edge", prod, flowcv1alpha1.GatewaySpec{NodeID: "prod-node"})
+	applyLabeled("Listener", "public", nil, flowcv1alpha1.ListenerSpec{GatewayRef: "prod-edge", Port: 8080, Tap: tap})
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	httpFilters := func(gw, nodeID, listener string) []string {
+		t.Helper()
+		if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: gw}); err != nil {
+			t.Fatalf("Translate %s: %v", gw, err)
+		}
+		snap, err := cm.GetSnapshot(nodeID)
+		if err != nil {
+			t.Fatalf("GetSnapshot %s: %v", nodeID, err)
+		}
+		res, ok := snap.GetResources(resourcev3.ListenerType)[listener]
+		if !ok {
+			t.Fatalf("%s missing from %s", listener, nodeID)
+		}
+		var hcm hcmv3.HttpConnectionManager
+		if err := res.(*listenerv3.Listener).FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+			t.Fatalf("unmarshal HCM: %v", err)
+		}
+		var names []string
+		for _, f := range hcm.HttpFilters {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	if got := httpFilters("edge", "edge-node", "listener_8080"); len(got) == 0 || got[0] != listenerbuilder.TapFilterName {
+		t.Errorf("staging listener filters = %v, want %s first", got, listenerbuilder.TapFilterName)
+	}
+	if got := httpFilters("edge", "edge-node", "listener_8081"); slices.Contains(got, listenerbuilder.TapFilterName) {
+		t.Errorf("production listener filters = %v, want no tap", got)
+	}
+	if got := httpFilters("prod-edge", "prod-node", "listener_8080"); slices.Contains(got, listenerbuilder.TapFilterName) {
+		t.Errorf("listener of production gateway filters = %v, want no tap", got)
+	}
+}
@@ -0,0 +1,240 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	cachetypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// ErrNotServed is returned by EnvironmentRoutes and ListenerResource when
+// the environment's listener is not in its gateway's live snapshot yet.
+var ErrNotServed = errors.New("environment is not being served")
+
+// ErrAmbiguousEnvironment is returned by EnvironmentRoutes when the name
+// of the default environment is shared by several gateways.
+var ErrAmbiguousEnvironment = errors.New("environment is ambiguous")
+
+// Route actions of a RouteEntry.
+const (
+	RouteActionRoute          = "route"
+	RouteActionRedirect       = "redirect"
+	RouteActionDirectResponse = "direct_response"
+)
+
+// RouteEntry is one route of an environment's route table.
+type RouteEntry struct {
+	// RouteConfig is the xDS route configuration holding the route.
+	RouteConfig string `json:"routeConfig"`
+	// Domains are the domains of the route's virtual host.
+	Domains []string `json:"domains"`
+	Name    string   `json:"name,omitempty"`
+	// PathMatch is how Path is matched: "prefix", "exact", "regex" or
+	// "path_separated_prefix".
+	PathMatch string `json:"pathMatch,omitempty"`
+	Path      string `json:"path,omitempty"`
+	// Method is the HTTP method the route is restricted to, if any.
+	Method string `json:"method,omitempty"`
+	Action string `json:"action"`
+	// Cluster is the upstream cluster; a weighted route lists its
+	// clusters in Clusters instead.
+	Cluster  string   `json:"cluster,omitempty"`
+	Clusters []string `json:"clusters,omitempty"`
+	// Rewrite describes the path rewrite applied before forwarding.
+	Rewrite string `json:"rewrite,omitempty"`
+}
+
+// EnvironmentRoutes is the route table an environment's listener is
+// serving, in the order Envoy matches it: by filter chain, then virtual
+// host, then route.
+type EnvironmentRoutes struct {
+	Environment string       `json:"environment"`
+	Gateway     string       `json:"gateway"`
+	NodeID      string       `json:"nodeId"`
+	Listener    string       `json:"listener"`
+	Routes      []RouteEntry `json:"routes"`
+}
+
+// RouteInspector reads environments' route tables from the live xDS
+// snapshot. Like DriftDetector it is read-only and resolves environments
+// with an indexer built afresh from the store for every request.
+type RouteInspector struct {
+	store    store.Store
+	cache    *cache.ConfigManager
+	defaults DefaultListener
+	log      *logger.EnvoyLogger
+}
+
+// NewRouteInspector constructs an inspector. defaults must match the
+// reconciler's for the default environment to be found.
+func NewRouteInspector(s store.Store, cm *cache.ConfigManager, defaults DefaultListener, log *logger.EnvoyLogger) *RouteInspector {
+	return &RouteInspector{store: s, cache: cm, defaults: defaults, log: log}
+}
+
+// EnvironmentRoutes returns the routes served by the named environment
+// across all deployments on it. The environment is a Listener, or the
+// default environment of the one gateway that has no data Listeners;
+// store.ErrNotFound is returned for anything else.
+func (r *RouteInspector) EnvironmentRoutes(ctx context.Context, environment string) (*EnvironmentRoutes, error) {
+	gw, live, snap, err := r.liveListener(ctx, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &EnvironmentRoutes{
+		Environment: environment,
+		Gateway:     gw.Name,
+		NodeID:      gw.Spec.NodeID,
+		Listener:    live.Name,
+		Routes:      []RouteEntry{},
+	}
+	routeConfigs := snap.GetResources(resourcev3.RouteType)
+
+	seen := map[string]bool{}
+	for _, fc := range live.FilterChains {
+		rc := chainRouteConfig(fc, routeConfigs)
+		if rc == nil || seen[rc.Name] {
+			continue
+		}
+		seen[rc.Name] = true
+		for _, vh := range rc.VirtualHosts {
+			for _, route := range vh.Routes {
+				out.Routes = append(out.Routes, routeEntry(rc.Name, vh, route))
+			}
+		}
+	}
+	return out, nil
+}
+
+// ListenerResource returns the xDS listener the named environment is
+// served by, as it is in the live snapshot of the environment's gateway.
+// Environments resolve as for EnvironmentRoutes.
+func (r *RouteInspector) ListenerResource(ctx context.Context, environment string) (*listenerv3.Listener, error) {
+	_, live, _, err := r.liveListener(ctx, environment)
+	return live, err
+}
+
+// liveListener resolves an environment and returns its gateway, its
+// listener in the gateway's snapshot, and the snapshot itself.
+func (r *RouteInspector) liveListener(ctx context.Context, environment string) (*flowcv1alpha1.Gateway, *listenerv3.Listener, *cachev3.Snapshot, error) {
+	idx := index.New(r.log)
+	if err := idx.Bootstrap(ctx, r.store); err != nil {
+		return nil, nil, nil, fmt.Errorf("bootstrap indexer: %w", err)
+	}
+	l, gw, err := r.environment(idx, environment)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	snap, err := r.cache.GetSnapshot(gw.Spec.NodeID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: gateway %q has no snapshot", ErrNotServed, gw.Name)
+	}
+	name := fmt.Sprintf("listener_%d", l.Spec.Port)
+	live, ok := snap.GetResources(resourcev3.ListenerType)[name].(*listenerv3.Listener)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: listener %q is not in the snapshot of gateway %q", ErrNotServed, name, gw.Name)
+	}
+	return gw, live, snap, nil
+}
+
+// environment resolves the Listener and Gateway of an environment.
+func (r *RouteInspector) environment(idx *index.Indexer, environment string) (*flowcv1alpha1.Listener, *flowcv1alpha1.Gateway, error) {
+	if l, ok := idx.GetListener(environment); ok {
+		gw, ok := idx.GetGateway(l.Spec.GatewayRef)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: gateway %q of environment %q does not exist", ErrNotServed, l.Spec.GatewayRef, environment)
+		}
+		return l, gw, nil
+	}
+
+	// Not a Listener, so only a synthesized default environment can match.
+	var found []*flowcv1alpha1.Listener
+	for _, gw := range idx.Gateways() {
+		if l := listenersForGateway(idx, gw.Name, r.defaults)[0]; l.Name == environment {
+			found = append(found, l)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil, store.ErrNotFound
+	case 1:
+		gw, _ := idx.GetGateway(found[0].Spec.GatewayRef)
+		return found[0], gw, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q is the default environment of %d gateways", ErrAmbiguousEnvironment, environment, len(found))
+	}
+}
+
+// chainRouteConfig returns the route configuration a filter chain's HTTP
+// connection manager uses, whether fetched over RDS or inline.
+func chainRouteConfig(fc *listenerv3.FilterChain, routeConfigs map[string]cachetypes.Resource) *routev3.RouteConfiguration {
+	for _, f := range fc.Filters {
+		var hcm hcmv3.HttpConnectionManager
+		if f.GetTypedConfig().UnmarshalTo(&hcm) != nil {
+			continue
+		}
+		if rc := hcm.GetRouteConfig(); rc != nil {
+			return rc
+		}
+		rc, _ := routeConfigs[hcm.GetRds().GetRouteConfigName()].(*routev3.RouteConfiguration)
+		return rc
+	}
+	return nil
+}
+
+// routeEntry describes one route of virtual host vh.
+func routeEntry(routeConfig string, vh *routev3.VirtualHost, route *routev3.Route) RouteEntry {
+	e := RouteEntry{RouteConfig: routeConfig, Domains: vh.Domains, Name: route.Name}
+
+	m := route.GetMatch()
+	switch spec := m.GetPathSpecifier().(type) {
+	case *routev3.RouteMatch_Prefix:
+		e.PathMatch, e.Path = "prefix", spec.Prefix
+	case *routev3.RouteMatch_Path:
+		e.PathMatch, e.Path = "exact", spec.Path
+	case *routev3.RouteMatch_SafeRegex:
+		e.PathMatch, e.Path = "regex", spec.SafeRegex.GetRegex()
+	case *routev3.RouteMatch_PathSeparatedPrefix:
+		e.PathMatch, e.Path = "path_separated_prefix", spec.PathSeparatedPrefix
+	}
+	for _, h := range m.GetHeaders() {
+		if h.GetName() == ":method" && !h.GetInvertMatch() {
+			e.Method = h.GetStringMatch().GetExact()
+		}
+	}
+
+	switch action := route.GetAction().(type) {
+	case *routev3.Route_Route:
+		e.Action = RouteActionRoute
+		if wc := action.Route.GetWeightedClusters(); wc != nil {
+			for _, c := range wc.Clusters {
+				e.Clusters = append(e.Clusters, c.Name)
+			}
+		} else {
+			e.Cluster = action.Route.GetCluster()
+		}
+		if rw := action.Route.GetRegexRewrite(); rw != nil {
+			e.Rewrite = fmt.Sprintf("%s -> %s", rw.GetPattern().GetRegex(), rw.GetSubstitution())
+		} else if p := action.Route.GetPrefixRewrite(); p != "" {
+			e.Rewrite = p
+		}
+	case *routev3.Route_Redirect:
+		e.Action = RouteActionRedirect
+	case *routev3.Route_DirectResponse:
+		e.Action = RouteActionDirectResponse
+	}
+	return e
+}
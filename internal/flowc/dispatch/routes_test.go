@@ -0,0 +1,123 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestEnvironmentRoutesInMatchOrder(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	putSpec(t, s, "Listener", "prod", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8080,
+		Hostnames:  []string{"api.example.com"},
+	})
+	// Deployed broadest first; the more specific admin API must still
+	// match ahead of it.
+	for _, api := range []struct{ name, context string }{
+		{"pets", "/pets"},
+		{"pets-admin", "/pets/admin"},
+	} {
+		putSpec(t, s, "API", api.name, flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  api.context,
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: api.name + ".local", Port: 8080},
+		})
+		putSpec(t, s, "Deployment", api.name, flowcv1alpha1.DeploymentSpec{
+			APIRef:  api.name,
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: "prod"},
+		})
+	}
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	inspector := NewRouteInspector(s, cm, DefaultListener{}, nil)
+	if _, err := inspector.EnvironmentRoutes(context.Background(), "prod"); !errors.Is(err, ErrNotServed) {
+		t.Fatalf("before translation: err = %v, want ErrNotServed", err)
+	}
+
+	idx := index.New(nil)
+	if err := idx.Bootstrap(context.Background(), s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	table, err := inspector.EnvironmentRoutes(context.Background(), "prod")
+	if err != nil {
+		t.Fatalf("EnvironmentRoutes: %v", err)
+	}
+	if table.Gateway != "edge" || table.NodeID != "edge-node" || table.Listener != "listener_8080" {
+		t.Errorf("table = %+v, want listener_8080 of edge on edge-node", table)
+	}
+	var paths []string
+	for _, r := range table.Routes {
+		paths = append(paths, r.Path)
+		if r.RouteConfig != "route_prod_api.example.com" || r.Action != RouteActionRoute || r.Rewrite == "" {
+			t.Errorf("route %+v, want a rewritten route in route_prod_api.example.com", r)
+		}
+	}
+	if len(paths) != 2 || paths[0] != "/pets/admin" || paths[1] != "/pets" {
+		t.Fatalf("paths = %v, want [/pets/admin /pets]", paths)
+	}
+	if table.Routes[0].Cluster == table.Routes[1].Cluster {
+		t.Errorf("both routes go to cluster %q", table.Routes[0].Cluster)
+	}
+
+	if _, err := inspector.EnvironmentRoutes(context.Background(), "staging"); err != store.ErrNotFound {
+		t.Errorf("unknown environment: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListenerResourceMatchesStoredListener(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	putSpec(t, s, "Listener", "prod", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8443,
+		Hostnames:  []string{"api.example.com"},
+	})
+
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	inspector := NewRouteInspector(s, cm, DefaultListener{}, nil)
+	if _, err := inspector.ListenerResource(context.Background(), "prod"); !errors.Is(err, ErrNotServed) {
+		t.Fatalf("before translation: err = %v, want ErrNotServed", err)
+	}
+
+	idx := index.New(nil)
+	if err := idx.Bootstrap(context.Background(), s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	l, err := inspector.ListenerResource(context.Background(), "prod")
+	if err != nil {
+		t.Fatalf("ListenerResource: %v", err)
+	}
+	if l.GetName() != "listener_8443" {
+		t.Errorf("name = %q, want listener_8443", l.GetName())
+	}
+	if port := l.GetAddress().GetSocketAddress().GetPortValue(); port != 8443 {
+		t.Errorf("port = %d, want 8443", port)
+	}
+
+	if _, err := inspector.ListenerResource(context.Background(), "staging"); err != store.ErrNotFound {
+		t.Errorf("unknown listener: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,171 @@
+package dispatch
+
+import (
+	"fmt"
+	"slices"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+)
+
+// splitListener returns the listener whose routes l's trafficSplit sends
+// requests to, resolved through its routesFrom, or nil when l has no
+// usable split: one naming an unknown listener, a listener of another
+// gateway, an "admin/stats" listener, or l's own routes.
+func splitListener(idx *index.Indexer, l *flowcv1alpha1.Listener) *flowcv1alpha1.Listener {
+	split := l.Spec.TrafficSplit
+	if split == nil || split.Weight == 0 || split.Weight >= 100 {
+		return nil
+	}
+	other, ok := idx.GetListener(split.Listener)
+	if !ok || other.Spec.GatewayRef != l.Spec.GatewayRef || isStatsListener(other) {
+		return nil
+	}
+	other = routesListener(idx, other)
+	if other.Name == routesListener(idx, l).Name {
+		return nil
+	}
+	return other
+}
+
+// hasTrafficSplit reports whether a listener of gateway gwName splits its
+// traffic with another environment. Such a gateway's route configs
+// depend on each other, so only full rebuilds keep them right.
+func hasTrafficSplit(idx *index.Indexer, gwName string) bool {
+	return slices.ContainsFunc(idx.ListenersForGateway(gwName), func(l *flowcv1alpha1.Listener) bool {
+		return splitListener(idx, l) != nil
+	})
+}
+
+// applyTrafficSplits weights the routes of every listener with a
+// trafficSplit between their clusters and those of the matching routes of
+// the other environment, on the hostnames both serve. routes must hold
+// the merged route configs of the gateway; they are modified in place.
+func applyTrafficSplits(idx *index.Indexer, listeners []*flowcv1alpha1.Listener, routes []*routev3.RouteConfiguration) {
+	// Other environments' routes are matched as translated, so splits
+	// between two listeners both ways don't compound.
+	byName := make(map[string]*routev3.RouteConfiguration, len(routes))
+	original := make(map[string]*routev3.RouteConfiguration, len(routes))
+	for _, rc := range routes {
+		byName[rc.Name] = rc
+		original[rc.Name] = proto.Clone(rc).(*routev3.RouteConfiguration)
+	}
+	for _, l := range listeners {
+		// A routesFrom listener serves another listener's routes; the
+		// split is that listener's to make.
+		if routesListener(idx, l) != l {
+			continue
+		}
+		other := splitListener(idx, l)
+		if other == nil {
+			continue
+		}
+		for _, hostname := range listenerHostnames(l) {
+			if !slices.Contains(listenerHostnames(other), hostname) {
+				continue
+			}
+			own := byName[fmt.Sprintf("route_%s_%s", l.Name, hostname)]
+			theirs := original[fmt.Sprintf("route_%s_%s", other.Name, hostname)]
+			if own == nil || theirs == nil {
+				continue
+			}
+			splitRouteConfig(own, theirs, l.Spec.TrafficSplit.Weight)
+		}
+	}
+}
+
+// listenerHostnames returns the hostnames of l's route configs.
+func listenerHostnames(l *flowcv1alpha1.Listener) []string {
+	if len(l.Spec.Hostnames) == 0 {
+		return []string{"*"}
+	}
+	return l.Spec.Hostnames
+}
+
+// splitRouteConfig sends weight percent of the requests of every route of
+// own that theirs has a route with the same match for to that route's
+// clusters.
+func splitRouteConfig(own, theirs *routev3.RouteConfiguration, weight uint32) {
+	for _, vh := range own.VirtualHosts {
+		for _, route := range vh.Routes {
+			action := route.GetRoute()
+			if action == nil {
+				continue
+			}
+			if other := matchingRoute(theirs, route.GetMatch()); other != nil {
+				splitAction(action, other, weight)
+			}
+		}
+	}
+}
+
+// matchingRoute returns the action of the first route of rc that matches
+// exactly as match does and forwards to clusters.
+func matchingRoute(rc *routev3.RouteConfiguration, match *routev3.RouteMatch) *routev3.RouteAction {
+	for _, vh := range rc.VirtualHosts {
+		for _, route := range vh.Routes {
+			if action := route.GetRoute(); action != nil && proto.Equal(route.GetMatch(), match) {
+				return action
+			}
+		}
+	}
+	return nil
+}
+
+// splitAction points action at weighted clusters: its own keep 100-weight
+// percent of the requests and other's get weight percent, each side's
+// clusters in the proportions they already had.
+func splitAction(action, other *routev3.RouteAction, weight uint32) {
+	own, theirs := actionClusterWeights(action), actionClusterWeights(other)
+	if len(own) == 0 || len(theirs) == 0 {
+		return
+	}
+	ownTotal, theirTotal := totalWeight(own), totalWeight(theirs)
+
+	var clusters []*routev3.WeightedCluster_ClusterWeight
+	add := func(name string, w uint32) {
+		for _, c := range clusters {
+			if c.Name == name {
+				c.Weight = wrapperspb.UInt32(c.Weight.GetValue() + w)
+				return
+			}
+		}
+		clusters = append(clusters, &routev3.WeightedCluster_ClusterWeight{Name: name, Weight: wrapperspb.UInt32(w)})
+	}
+	for _, c := range own {
+		add(c.Name, c.Weight.GetValue()*(100-weight)*theirTotal)
+	}
+	for _, c := range theirs {
+		add(c.Name, c.Weight.GetValue()*weight*ownTotal)
+	}
+	action.ClusterSpecifier = &routev3.RouteAction_WeightedClusters{
+		WeightedClusters: &routev3.WeightedCluster{Clusters: clusters},
+	}
+}
+
+// actionClusterWeights returns the clusters action forwards to with their
+// weights; a single cluster has weight 1.
+func actionClusterWeights(action *routev3.RouteAction) []*routev3.WeightedCluster_ClusterWeight {
+	if name := action.GetCluster(); name != "" {
+		return []*routev3.WeightedCluster_ClusterWeight{{Name: name, Weight: wrapperspb.UInt32(1)}}
+	}
+	var out []*routev3.WeightedCluster_ClusterWeight
+	for _, c := range action.GetWeightedClusters().GetClusters() {
+		if c.GetWeight().GetValue() > 0 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func totalWeight(clusters []*routev3.WeightedCluster_ClusterWeight) uint32 {
+	var total uint32
+	for _, c := range clusters {
+		total += c.GetWeight().GetValue()
+	}
+	return total
+}
@@ -0,0 +1,97 @@
+package dispatch
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestTrafficSplitWeightsHostRoutes(t *testing.T) {
+	s := store.NewMemoryStore()
+	putSpec(t, s, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+	putSpec(t, s, "Listener", "blue", flowcv1alpha1.ListenerSpec{
+		GatewayRef:   "edge",
+		Port:         8080,
+		Hostnames:    []string{"api.example.com"},
+		TrafficSplit: &flowcv1alpha1.TrafficSplit{Listener: "green", Weight: 30},
+	})
+	putSpec(t, s, "Listener", "green", flowcv1alpha1.ListenerSpec{
+		GatewayRef: "edge",
+		Port:       8081,
+		Hostnames:  []string{"api.example.com"},
+	})
+	// The same API on both stacks, plus one only the blue stack serves.
+	for _, d := range []struct{ name, context, listener string }{
+		{"pets-blue", "/pets", "blue"},
+		{"pets-green", "/pets", "green"},
+		{"owners-blue", "/owners", "blue"},
+	} {
+		putSpec(t, s, "API", d.name, flowcv1alpha1.APISpec{
+			Version:  "v1",
+			Context:  d.context,
+			Upstream: flowcv1alpha1.UpstreamConfig{Host: d.name + ".local", Port: 8080},
+		})
+		putSpec(t, s, "Deployment", d.name, flowcv1alpha1.DeploymentSpec{
+			APIRef:  d.name,
+			Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge", Listener: d.listener},
+		})
+	}
+
+	idx := index.New(nil)
+	if err := idx.Bootstrap(context.Background(), s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), DefaultListener{}, nil)
+	if err := gt.Translate(context.Background(), index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	snap, err := cm.GetSnapshot("edge-node")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	routes := snap.GetResources(resourcev3.RouteType)
+	routeTo := func(rc, prefix string) *routev3.RouteAction {
+		t.Helper()
+		for _, vh := range routes[rc].(*routev3.RouteConfiguration).VirtualHosts {
+			for _, r := range vh.Routes {
+				if r.GetMatch().GetPrefix() == prefix || r.GetMatch().GetPathSeparatedPrefix() == prefix {
+					return r.GetRoute()
+				}
+			}
+		}
+		t.Fatalf("%s has no route for %s", rc, prefix)
+		return nil
+	}
+
+	green := routeTo("route_green_api.example.com", "/pets")
+	if green.GetCluster() == "" {
+		t.Fatalf("green route = %v, want a single cluster", green.GetClusterSpecifier())
+	}
+	blue := routeTo("route_blue_api.example.com", "/pets")
+	weighted := blue.GetWeightedClusters().GetClusters()
+	if len(weighted) != 2 {
+		t.Fatalf("blue route clusters = %v, want a 70/30 split", blue.GetClusterSpecifier())
+	}
+	if weighted[0].GetName() == green.GetCluster() || weighted[0].GetWeight().GetValue() != 70 {
+		t.Errorf("blue share = %s:%d, want its own cluster at 70", weighted[0].GetName(), weighted[0].GetWeight().GetValue())
+	}
+	if weighted[1].GetName() != green.GetCluster() || weighted[1].GetWeight().GetValue() != 30 {
+		t.Errorf("green share = %s:%d, want %s at 30", weighted[1].GetName(), weighted[1].GetWeight().GetValue(), green.GetCluster())
+	}
+
+	if owners := routeTo("route_blue_api.example.com", "/owners"); owners.GetCluster() == "" {
+		t.Errorf("owners route = %v, want it unsplit", owners.GetClusterSpecifier())
+	}
+}
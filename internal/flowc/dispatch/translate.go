@@ -2,9 +2,13 @@ package dispatch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
 	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
 	"github.com/flowc-labs/flowc/internal/flowc/index"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
@@ -15,6 +19,10 @@ import (
 	"github.com/flowc-labs/flowc/pkg/types"
 )
 
+// defaultCanaryVersion names the canary cluster when a canary strategy
+// does not set one.
+const defaultCanaryVersion = "canary"
+
 // translateOne resolves a single Deployment's dependencies from the
 // indexer (API, Gateway, Listener) and runs the strategy-based composite
 // translator to produce xDS resources. Used by both DeploymentTranslator
@@ -30,6 +38,7 @@ func translateOne(
 	idx *index.Indexer,
 	parsers *ir.ParserRegistry,
 	options *translator.TranslatorOptions,
+	defaults DefaultListener,
 	log *logger.EnvoyLogger,
 ) (*translator.XDSResources, error) {
 	api, ok := idx.GetAPI(dep.Spec.APIRef)
@@ -40,29 +49,13 @@ func translateOne(
 	if !ok {
 		return nil, fmt.Errorf("gateway %q not in indexer", dep.Spec.Gateway.Name)
 	}
+	if (dep.Spec.ExtraRouteConfig != nil || dep.Spec.ExtraClusterConfig != nil) && (options == nil || !options.AllowEnvoyOverrides) {
+		return nil, fmt.Errorf("extraRouteConfig and extraClusterConfig require the envoy_overrides feature")
+	}
 
-	// Resolve listener: explicit name takes precedence; otherwise
-	// auto-resolve when the gateway has exactly one listener.
-	var listener *flowcv1alpha1.Listener
-	if explicit := dep.Spec.Gateway.Listener; explicit != "" {
-		l, ok := idx.GetListener(explicit)
-		if !ok {
-			return nil, fmt.Errorf("listener %q not in indexer", explicit)
-		}
-		if l.Spec.GatewayRef != gw.Name {
-			return nil, fmt.Errorf("listener %q targets gateway %q, not %q", explicit, l.Spec.GatewayRef, gw.Name)
-		}
-		listener = l
-	} else {
-		listeners := idx.ListenersForGateway(gw.Name)
-		switch len(listeners) {
-		case 0:
-			return nil, fmt.Errorf("gateway %q has no listeners", gw.Name)
-		case 1:
-			listener = listeners[0]
-		default:
-			return nil, fmt.Errorf("gateway %q has %d listeners; spec.gateway.listener is required", gw.Name, len(listeners))
-		}
+	listener, err := resolveListener(dep, idx, gw, defaults)
+	if err != nil {
+		return nil, err
 	}
 
 	hostname := "*"
@@ -88,8 +81,36 @@ func translateOne(
 
 	// Build the legacy model objects the strategy framework expects.
 	modelDep := toModelDeployment(dep.Name, api.Name, &api.Spec)
+	modelDep.Labels = dep.Labels
+	modelDep.ResourcePrefix = resourcePrefix(dep.Name)
+	modelDep.Metadata.Labels = api.Labels
+	modelDep.Metadata.Filters = v1FiltersToTypes(dep.Spec.Filters)
+	if m := dep.Spec.Mirror; m != nil {
+		modelDep.Metadata.Mirror = &types.MirrorConfig{Host: m.Host, Port: m.Port, Scheme: m.Scheme, Percentage: m.Percentage}
+	}
+	extraRoute, err := rawOverride(dep.Spec.ExtraRouteConfig)
+	if err != nil {
+		return nil, fmt.Errorf("extraRouteConfig: %w", err)
+	}
+	extraCluster, err := rawOverride(dep.Spec.ExtraClusterConfig)
+	if err != nil {
+		return nil, fmt.Errorf("extraClusterConfig: %w", err)
+	}
+	modelDep.Metadata.ExtraRouteConfig = extraRoute
+	modelDep.Metadata.ExtraClusterConfig = extraCluster
+	if api.Spec.Routing != nil {
+		modelDep.Metadata.Gateway.VirtualHost.GroupByTag = api.Spec.Routing.GroupByTag
+		modelDep.Metadata.Gateway.VirtualHost.QueryParams = api.Spec.Routing.QueryParams
+		modelDep.Metadata.Gateway.VirtualHost.UseExisting = api.Spec.Routing.VirtualHost
+	}
+	// Upstream precedence: API spec < gateway default < deployment override.
+	if def, ok := gw.Spec.Upstreams[api.Name]; ok {
+		applyUpstreamOverride(&modelDep.Metadata.Upstream, &def)
+	}
+	applyUpstreamOverride(&modelDep.Metadata.Upstream, dep.Spec.Upstream)
 	modelGw := toModelGateway(gw.Name, &gw.Spec, gw.Labels)
 	modelListener := toModelListener(listener.Name, &listener.Spec)
+	modelListener.HTTPSRedirectPort = httpsRedirectPort(idx, gw, listener, defaults)
 	modelVHost := &models.GatewayVirtualHost{
 		ID:         hostname,
 		ListenerID: listener.Name,
@@ -97,9 +118,7 @@ func translateOne(
 		Hostname:   hostname,
 	}
 
-	// 3-level strategy precedence: builtin < gateway defaults < per-API.
-	resolver := translator.NewConfigResolver(nil, v1StrategyToTypes(gw.Spec.Defaults), log)
-	resolvedConfig := resolver.Resolve(v1StrategyToTypes(dep.Spec.Strategy))
+	resolvedConfig := resolveStrategy(dep, api, gw, log)
 
 	factory := translator.NewStrategyFactory(options, log)
 	strategies, err := factory.CreateStrategySet(resolvedConfig, modelDep)
@@ -120,9 +139,71 @@ func translateOne(
 	return composite.Translate(ctx, modelDep, irAPI, gw.Spec.NodeID)
 }
 
+// resolveListener returns the listener deployment dep is served by. An
+// explicit name takes precedence; otherwise it auto-resolves when the
+// gateway has exactly one listener, falling back to the default
+// environment when it has none. Listeners sharing another's routes
+// (routesFrom) resolve to that listener, whose route configs they
+// reference.
+func resolveListener(dep *flowcv1alpha1.Deployment, idx *index.Indexer, gw *flowcv1alpha1.Gateway, defaults DefaultListener) (*flowcv1alpha1.Listener, error) {
+	if explicit := dep.Spec.Gateway.Listener; explicit != "" {
+		l, ok := idx.GetListener(explicit)
+		if !ok {
+			return nil, fmt.Errorf("listener %q not in indexer", explicit)
+		}
+		if l.Spec.GatewayRef != gw.Name {
+			return nil, fmt.Errorf("listener %q targets gateway %q, not %q", explicit, l.Spec.GatewayRef, gw.Name)
+		}
+		if isStatsListener(l) {
+			return nil, fmt.Errorf("listener %q is an %s listener and cannot serve deployments", explicit, flowcv1alpha1.ListenerKindAdminStats)
+		}
+		return routesListener(idx, l), nil
+	}
+
+	var listeners []*flowcv1alpha1.Listener
+	for _, l := range listenersForGateway(idx, gw.Name, defaults) {
+		if routesListener(idx, l) == l {
+			listeners = append(listeners, l)
+		}
+	}
+	if len(listeners) != 1 {
+		return nil, fmt.Errorf("gateway %q has %d listeners; spec.gateway.listener is required", gw.Name, len(listeners))
+	}
+	return listeners[0], nil
+}
+
+// resolveStrategy returns the strategy config deployment dep of api is
+// translated with on gateway gw, with the 3-level precedence builtin <
+// gateway defaults < per-deployment.
+func resolveStrategy(dep *flowcv1alpha1.Deployment, api *flowcv1alpha1.API, gw *flowcv1alpha1.Gateway, log *logger.EnvoyLogger) *types.StrategyConfig {
+	resolver := translator.NewConfigResolver(nil, v1StrategyToTypes(gw.Spec.Defaults), log)
+	resolvedConfig := resolver.Resolve(v1StrategyToTypes(dep.Spec.Strategy))
+
+	if d := resolvedConfig.Deployment; d != nil && d.Canary != nil {
+		canary := *d.Canary
+		if canary.BaselineVersion == "" {
+			canary.BaselineVersion = api.Spec.Version
+		}
+		if canary.CanaryVersion == "" {
+			canary.CanaryVersion = defaultCanaryVersion
+		}
+		d.Canary = &canary
+	}
+	return resolvedConfig
+}
+
+// resourcePrefix is the prefix of the cluster names deployment depName
+// publishes. Clusters are named after the API, so without it two
+// deployments of one API to the same gateway would publish, and on
+// delete remove, each other's clusters. Route configs keep their
+// per-environment names, which the listeners reference.
+func resourcePrefix(depName string) string {
+	return depName + "_"
+}
+
 // resourceNamesFromXDS extracts the names from a translation result so
 // they can be recorded in the indexer's ownership map and later passed to
-// cache.UnDeployAPI on delete.
+// cache.BulkUpdate on delete.
 func resourceNamesFromXDS(xds *translator.XDSResources) cache.ResourceNames {
 	out := cache.ResourceNames{
 		Clusters:  make([]string, 0, len(xds.Clusters)),
@@ -138,6 +219,9 @@ func resourceNamesFromXDS(xds *translator.XDSResources) cache.ResourceNames {
 	for _, r := range xds.Routes {
 		out.Routes = append(out.Routes, r.Name)
 	}
+	for _, f := range xds.HTTPFilters {
+		out.HTTPFilters = append(out.HTTPFilters, f.Name)
+	}
 	return out
 }
 
@@ -161,10 +245,15 @@ func toModelDeployment(depName, apiName string, apiSpec *flowcv1alpha1.APISpec)
 			Context: apiSpec.Context,
 			APIType: apiSpec.APIType,
 			Upstream: types.UpstreamConfig{
-				Host:    apiSpec.Upstream.Host,
-				Port:    apiSpec.Upstream.Port,
-				Scheme:  apiSpec.Upstream.Scheme,
-				Timeout: apiSpec.Upstream.Timeout,
+				Host:       apiSpec.Upstream.Host,
+				Port:       apiSpec.Upstream.Port,
+				Scheme:     apiSpec.Upstream.Scheme,
+				Timeout:    apiSpec.Upstream.Timeout,
+				Hosts:      upstreamHosts(apiSpec.Upstream.Hosts),
+				TLS:        upstreamTLS(apiSpec.Upstream.TLS),
+				Subsets:    upstreamSubsets(apiSpec.Upstream.Subsets),
+				Fallback:   upstreamFallback(apiSpec.Upstream.Fallback),
+				Connection: upstreamConnection(apiSpec.Upstream.Connection),
 			},
 			Gateway: types.GatewayConfig{
 				NodeID: "", // filled via translation context
@@ -187,11 +276,12 @@ func toModelGateway(name string, spec *flowcv1alpha1.GatewaySpec, labels map[str
 
 func toModelListener(name string, spec *flowcv1alpha1.ListenerSpec) *models.Listener {
 	ml := &models.Listener{
-		ID:        name,
-		GatewayID: spec.GatewayRef,
-		Port:      spec.Port,
-		Address:   spec.Address,
-		HTTP2:     spec.HTTP2,
+		ID:             name,
+		GatewayID:      spec.GatewayRef,
+		Port:           spec.Port,
+		Address:        spec.Address,
+		HTTP2:          spec.HTTP2,
+		AllowedMethods: spec.AllowedMethods,
 	}
 	if ml.Address == "" {
 		ml.Address = "0.0.0.0"
@@ -216,6 +306,23 @@ func v1StrategyToTypes(cfg *flowcv1alpha1.StrategyConfig) *types.StrategyConfig
 	out := &types.StrategyConfig{}
 	if cfg.Deployment != nil {
 		out.Deployment = &types.DeploymentStrategyConfig{Type: cfg.Deployment.Type}
+		if c := cfg.Deployment.Canary; c != nil {
+			out.Deployment.Canary = &types.CanaryConfig{
+				BaselineVersion: c.BaselineVersion,
+				CanaryVersion:   c.CanaryVersion,
+				CanaryWeight:    c.CanaryWeight,
+				Upstream:        canaryUpstream(c.Upstream),
+				Sticky:          c.Sticky,
+				StickyCookie:    c.StickyCookie,
+				StickyTTL:       c.StickyTTL,
+			}
+		}
+		if dfp := cfg.Deployment.DynamicForwardProxy; dfp != nil {
+			out.Deployment.DynamicForwardProxy = &types.DynamicForwardProxyConfig{
+				AllowedHosts: dfp.AllowedHosts,
+				HostHeader:   dfp.HostHeader,
+			}
+		}
 	}
 	if cfg.RouteMatching != nil {
 		out.RouteMatching = &types.RouteMatchStrategyConfig{
@@ -225,12 +332,27 @@ func v1StrategyToTypes(cfg *flowcv1alpha1.StrategyConfig) *types.StrategyConfig
 	}
 	if cfg.LoadBalancing != nil {
 		out.LoadBalancing = &types.LoadBalancingStrategyConfig{Type: cfg.LoadBalancing.Type}
+		if t := cfg.LoadBalancing.HealthyPanicThreshold; t != nil {
+			threshold := float64(*t)
+			out.LoadBalancing.HealthyPanicThreshold = &threshold
+		}
+		if ss := cfg.LoadBalancing.SlowStart; ss != nil {
+			// The CRD pattern guarantees aggression parses when set.
+			aggression, _ := strconv.ParseFloat(ss.Aggression, 64)
+			out.LoadBalancing.SlowStart = &types.SlowStartConfig{
+				Window:           ss.Window,
+				MinWeightPercent: float64(ss.MinWeightPercent),
+				Aggression:       aggression,
+			}
+		}
 	}
 	if cfg.Retry != nil {
 		out.Retry = &types.RetryStrategyConfig{
-			Type:       cfg.Retry.Type,
-			MaxRetries: cfg.Retry.MaxRetries,
-			RetryOn:    cfg.Retry.RetryOn,
+			Type:                     cfg.Retry.Type,
+			MaxRetries:               cfg.Retry.MaxRetries,
+			RetryOn:                  cfg.Retry.RetryOn,
+			HostPredicates:           cfg.Retry.HostPredicates,
+			HostSelectionMaxAttempts: cfg.Retry.HostSelectionMaxAttempts,
 		}
 	}
 	if cfg.RateLimit != nil {
@@ -243,6 +365,144 @@ func v1StrategyToTypes(cfg *flowcv1alpha1.StrategyConfig) *types.StrategyConfig
 	return out
 }
 
+func v1FiltersToTypes(cfg *flowcv1alpha1.DeploymentFilters) *types.HTTPFiltersConfig {
+	if cfg == nil {
+		return nil
+	}
+	out := &types.HTTPFiltersConfig{
+		CORSFromSpec:            cfg.CORSFromSpec,
+		CORSMaxAge:              int(cfg.CORSMaxAge),
+		RequiredHeadersFromSpec: cfg.RequiredHeadersFromSpec,
+		Buffer:                  cfg.Buffer,
+		BufferMaxRequestBytes:   uint32(cfg.BufferMaxRequestBytes),
+	}
+	if t := cfg.GRPCJSONTranscoder; t != nil {
+		out.GRPCJSONTranscoder = &types.GRPCJSONTranscoderConfig{
+			ProtoDescriptorBin:           t.ProtoDescriptorBin,
+			Services:                     t.Services,
+			AutoMapping:                  t.AutoMapping,
+			IgnoreUnknownQueryParameters: t.IgnoreUnknownQueryParameters,
+		}
+	}
+	if a := cfg.AdmissionControl; a != nil {
+		out.AdmissionControl = &types.AdmissionControlConfig{
+			SuccessRateThreshold: float64(a.SuccessRateThreshold),
+			SamplingWindow:       a.SamplingWindow,
+		}
+	}
+	if c := cfg.Cache; c != nil {
+		out.Cache = &types.CacheConfig{
+			TTL:         c.TTL,
+			Methods:     c.Methods,
+			VaryHeaders: c.VaryHeaders,
+		}
+	}
+	if d := cfg.Decompressor; d != nil {
+		out.Decompressor = &types.DecompressorConfig{Algorithms: d.Algorithms}
+	}
+	return out
+}
+
+// upstreamHosts converts the CRD weighted hosts into their types form.
+func upstreamHosts(in []flowcv1alpha1.UpstreamHost) []types.UpstreamHost {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]types.UpstreamHost, len(in))
+	for i, h := range in {
+		out[i] = types.UpstreamHost{Host: h.Host, Port: h.Port, Weight: h.Weight, Metadata: h.Metadata}
+	}
+	return out
+}
+
+func upstreamSubsets(in *flowcv1alpha1.UpstreamSubsets) *types.UpstreamSubsetConfig {
+	if in == nil {
+		return nil
+	}
+	return &types.UpstreamSubsetConfig{Selectors: in.Selectors, Match: in.Match}
+}
+
+func upstreamTLS(in *flowcv1alpha1.UpstreamTLS) *types.UpstreamTLSConfig {
+	if in == nil {
+		return nil
+	}
+	return &types.UpstreamTLSConfig{
+		CAPath:    in.CAPath,
+		CertPath:  in.CertPath,
+		KeyPath:   in.KeyPath,
+		SPIFFEIDs: in.SPIFFEIDs,
+		DNSNames:  in.DNSNames,
+	}
+}
+
+func upstreamFallback(in *flowcv1alpha1.UpstreamFallback) *types.UpstreamFallbackConfig {
+	if in == nil {
+		return nil
+	}
+	return &types.UpstreamFallbackConfig{
+		Host:   in.Host,
+		Port:   in.Port,
+		Scheme: in.Scheme,
+		TLS:    upstreamTLS(in.TLS),
+	}
+}
+
+func upstreamConnection(in *flowcv1alpha1.UpstreamConnection) *types.UpstreamConnectionConfig {
+	if in == nil {
+		return nil
+	}
+	return &types.UpstreamConnectionConfig{
+		MaxConnectionsPerHost:    in.MaxConnectionsPerHost,
+		MaxRequestsPerConnection: in.MaxRequestsPerConnection,
+	}
+}
+
+// rawOverride decodes a deployment's raw Envoy override, or returns nil
+// when it has none.
+func rawOverride(raw *apiextensionsv1.JSON) (map[string]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw.Raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// canaryUpstream carries the fields set in a canary's upstream; the
+// canary strategy fills the rest from the deployment's upstream.
+func canaryUpstream(in *flowcv1alpha1.UpstreamOverride) *types.UpstreamConfig {
+	if in == nil {
+		return nil
+	}
+	up := &types.UpstreamConfig{}
+	applyUpstreamOverride(up, in)
+	return up
+}
+
+// applyUpstreamOverride replaces the fields of up that are set in o.
+func applyUpstreamOverride(up *types.UpstreamConfig, o *flowcv1alpha1.UpstreamOverride) {
+	if o == nil {
+		return
+	}
+	if o.Host != "" {
+		up.Host = o.Host
+	}
+	if o.Port != 0 {
+		up.Port = o.Port
+	}
+	if o.Scheme != "" {
+		up.Scheme = o.Scheme
+	}
+	if o.Timeout != "" {
+		up.Timeout = o.Timeout
+	}
+	if o.TLS != nil {
+		up.TLS = upstreamTLS(o.TLS)
+	}
+}
+
 func normalizeBasePath(path string) string {
 	if path == "" || path == "/" {
 		return ""
@@ -0,0 +1,166 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
+)
+
+func applySpec(t *testing.T, idx *index.Indexer, kind, name string, spec any) {
+	t.Helper()
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal %s/%s: %v", kind, name, err)
+	}
+	idx.Apply(store.WatchEvent{
+		Type: store.WatchEventPut,
+		Resource: &store.StoredResource{
+			Meta:     store.StoreMeta{Kind: kind, Name: name},
+			SpecJSON: data,
+		},
+	})
+}
+
+func clusterHost(c *clusterv3.Cluster) string {
+	return c.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()[0].
+		GetEndpoint().GetAddress().GetSocketAddress().GetAddress()
+}
+
+func TestTranslateOneUpstreamOverrides(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "API", "petstore", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/petstore",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "petstore.local", Port: 8080, Scheme: "http"},
+	})
+	for _, env := range []string{"staging", "prod"} {
+		applySpec(t, idx, "Gateway", env, flowcv1alpha1.GatewaySpec{
+			NodeID: env + "-node",
+			Upstreams: map[string]flowcv1alpha1.UpstreamOverride{
+				"petstore": {Host: "petstore." + env + ".svc"},
+			},
+		})
+		applySpec(t, idx, "Listener", env+"-http", flowcv1alpha1.ListenerSpec{GatewayRef: env, Port: 10000})
+	}
+
+	staging := &flowcv1alpha1.Deployment{Spec: flowcv1alpha1.DeploymentSpec{
+		APIRef:  "petstore",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "staging"},
+	}}
+	staging.Name = "petstore-staging"
+	prod := &flowcv1alpha1.Deployment{Spec: flowcv1alpha1.DeploymentSpec{
+		APIRef:   "petstore",
+		Gateway:  flowcv1alpha1.DeploymentGatewayRef{Name: "prod"},
+		Upstream: &flowcv1alpha1.UpstreamOverride{Host: "petstore.prod.internal", Port: 9090},
+	}}
+	prod.Name = "petstore-prod"
+
+	tests := []struct {
+		dep      *flowcv1alpha1.Deployment
+		wantHost string
+		wantPort uint32
+	}{
+		// environment default beats the bundle
+		{staging, "petstore.staging.svc", 8080},
+		// deploy-time override beats the environment default
+		{prod, "petstore.prod.internal", 9090},
+	}
+
+	hosts := map[string]bool{}
+	for _, tt := range tests {
+		xds, err := translateOne(context.Background(), tt.dep, idx, ir.DefaultParserRegistry(), nil, DefaultListener{}, nil)
+		if err != nil {
+			t.Fatalf("%s: translateOne: %v", tt.dep.Name, err)
+		}
+		if len(xds.Clusters) != 1 {
+			t.Fatalf("%s: got %d clusters, want 1", tt.dep.Name, len(xds.Clusters))
+		}
+		c := xds.Clusters[0]
+		if got := clusterHost(c); got != tt.wantHost {
+			t.Errorf("%s: cluster host = %q, want %q", tt.dep.Name, got, tt.wantHost)
+		}
+		port := c.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()[0].
+			GetEndpoint().GetAddress().GetSocketAddress().GetPortValue()
+		if port != tt.wantPort {
+			t.Errorf("%s: cluster port = %d, want %d", tt.dep.Name, port, tt.wantPort)
+		}
+		hosts[clusterHost(c)] = true
+	}
+	if len(hosts) != 2 {
+		t.Errorf("expected two distinct upstream clusters, got hosts %v", hosts)
+	}
+}
+
+func TestTranslateOneDefaultEnvironment(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "API", "petstore", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/petstore",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "petstore.local", Port: 8080},
+	})
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+
+	dep := &flowcv1alpha1.Deployment{Spec: flowcv1alpha1.DeploymentSpec{
+		APIRef:  "petstore",
+		Gateway: flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+	}}
+	dep.Name = "petstore-edge"
+
+	defaults := DefaultListener{Name: "sandbox", Hostname: "api.example.com"}
+	xds, err := translateOne(context.Background(), dep, idx, ir.DefaultParserRegistry(), nil, defaults, nil)
+	if err != nil {
+		t.Fatalf("translateOne: %v", err)
+	}
+	if len(xds.Routes) != 1 {
+		t.Fatalf("got %d route configs, want 1", len(xds.Routes))
+	}
+	if got, want := xds.Routes[0].Name, "route_sandbox_api.example.com"; got != want {
+		t.Errorf("route config = %q, want %q", got, want)
+	}
+
+	listeners := listenersForGateway(idx, "edge", DefaultListener{})
+	if len(listeners) != 1 || listeners[0].Name != DefaultEnvironmentName ||
+		listeners[0].Spec.Port != DefaultListenerPort || listeners[0].Spec.Hostnames[0] != DefaultEnvironmentHostname {
+		t.Errorf("unconfigured default listener = %+v, want the built-in fallbacks", listeners)
+	}
+}
+
+func TestTranslateOneEnvoyOverridesNeedFeature(t *testing.T) {
+	idx := index.New(nil)
+	applySpec(t, idx, "API", "petstore", flowcv1alpha1.APISpec{
+		Version:  "v1",
+		Context:  "/petstore",
+		Upstream: flowcv1alpha1.UpstreamConfig{Host: "petstore.local", Port: 8080},
+	})
+	applySpec(t, idx, "Gateway", "edge", flowcv1alpha1.GatewaySpec{NodeID: "edge-node"})
+
+	dep := &flowcv1alpha1.Deployment{Spec: flowcv1alpha1.DeploymentSpec{
+		APIRef:             "petstore",
+		Gateway:            flowcv1alpha1.DeploymentGatewayRef{Name: "edge"},
+		ExtraClusterConfig: &apiextensionsv1.JSON{Raw: []byte(`{"connectTimeout":"7s"}`)},
+	}}
+	dep.Name = "petstore-edge"
+
+	if _, err := translateOne(context.Background(), dep, idx, ir.DefaultParserRegistry(), translator.DefaultTranslatorOptions(), DefaultListener{}, nil); err == nil {
+		t.Fatal("translateOne merged an override with envoy_overrides off")
+	}
+
+	options := translator.DefaultTranslatorOptions()
+	options.AllowEnvoyOverrides = true
+	xds, err := translateOne(context.Background(), dep, idx, ir.DefaultParserRegistry(), options, DefaultListener{}, nil)
+	if err != nil {
+		t.Fatalf("translateOne: %v", err)
+	}
+	if got := xds.Clusters[0].GetConnectTimeout().AsDuration().Seconds(); got != 7 {
+		t.Errorf("connect timeout = %vs, want 7s", got)
+	}
+}
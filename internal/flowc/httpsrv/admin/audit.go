@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+)
+
+// AuditHandler serves the audit trail of resource changes.
+type AuditHandler struct {
+	sink audit.Sink
+}
+
+// NewAuditHandler returns an AuditHandler. sink may be nil, in which case
+// auditing is reported as unavailable.
+func NewAuditHandler(sink audit.Sink) *AuditHandler {
+	return &AuditHandler{sink: sink}
+}
+
+// Handle handles GET /api/v1/audit. The query parameters kind, name,
+// actor and action filter by those fields, since and until (RFC 3339)
+// bound the entry time, and limit keeps only the most recent entries.
+// Entries are returned oldest first.
+func (h *AuditHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if h.sink == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "audit log is not available")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Kind:   q.Get("kind"),
+		Name:   q.Get("name"),
+		Actor:  q.Get("actor"),
+		Action: audit.Action(q.Get("action")),
+	}
+	for param, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		v := q.Get(param)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, param+" must be an RFC 3339 time")
+			return
+		}
+		*t = parsed
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			httputil.WriteError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.sink.Query(filter)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
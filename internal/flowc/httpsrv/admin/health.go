@@ -1,5 +1,5 @@
-// Package admin contains operational HTTP handlers (health, root doc).
-// These do not interact with the Store.
+// Package admin contains operational HTTP handlers (health, root doc, fleet
+// status, node xDS streams). Only the fleet status summary reads the Store.
 package admin
 
 import (
@@ -27,7 +27,14 @@ func (h *RootHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		"description": "Declarative Envoy xDS control plane with reconciliation-based architecture",
 		"api_style":   "Flat K8s-style: PUT to create/update, GET/DELETE, POST /apply for bulk",
 		"endpoints": map[string]any{
-			"health": "GET /health",
+			"health":  "GET /health",
+			"status":  "GET /api/v1/status",
+			"summary": "GET /api/v1/metrics/summary",
+			"audit":   "GET /api/v1/audit",
+			"streams": map[string]string{
+				"list":  "GET /api/v1/nodes/{nodeID}/streams",
+				"close": "DELETE /api/v1/nodes/{nodeID}/streams/{id}",
+			},
 			"resources": map[string]string{
 				"gateways":        "/api/v1/gateways/{name}",
 				"listeners":       "/api/v1/listeners/{name}",
@@ -37,8 +44,22 @@ func (h *RootHandler) Handle(w http.ResponseWriter, r *http.Request) {
 				"apipolicies":     "/api/v1/apipolicies/{name}",
 				"backendpolicies": "/api/v1/backendpolicies/{name}",
 			},
-			"bulk_apply": "POST /api/v1/apply",
-			"upload":     "POST /api/v1/upload",
+			"bulk_apply":      "POST /api/v1/apply",
+			"add_listeners":   "POST /api/v1/gateways/{name}/listeners",
+			"listener_port":   "PUT /api/v1/listeners/{name}/port",
+			"listener_xds":    "GET /api/v1/listeners/{name}/xds",
+			"routes":          "GET /api/v1/environments/{id}/routes",
+			"canary_weight":   "PUT /api/v1/deployments/{name}/canary",
+			"mirror":          "PUT /api/v1/deployments/{name}/mirror",
+			"bundle":          "GET /api/v1/deployments/{name}/bundle",
+			"rollback":        "POST /api/v1/deployments/{name}/rollback",
+			"drift":           "GET /api/v1/deployments/{name}/drift",
+			"endpoints":       "GET /api/v1/deployments/{name}/endpoints",
+			"describe":        "GET /api/v1/deployments/{name}/describe",
+			"openapi":         "GET /api/v1/deployments/{name}/openapi",
+			"upload":          "POST /api/v1/upload",
+			"validate_bundle": "POST /api/v1/bundles:validate",
+			"revalidate":      "POST /api/v1/deployments:revalidate",
 		},
 		"notes": []string{
 			"All resources use PUT for idempotent create-or-update",
@@ -46,6 +67,7 @@ func (h *RootHandler) Handle(w http.ResponseWriter, r *http.Request) {
 			"Reconciler watches the store and generates xDS snapshots automatically",
 			"Use If-Match header for optimistic concurrency control",
 			"Use X-Managed-By header for ownership tracking",
+			"Use X-Actor header to name who makes a change in the audit log",
 		},
 	})
 }
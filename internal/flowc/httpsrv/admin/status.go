@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// phaseUnknown buckets deployments whose status has no phase yet.
+const phaseUnknown = "Unknown"
+
+// NodeTracker reports which xDS nodes have snapshots installed and which
+// of them currently hold an open stream. Implemented by cache.ConfigManager.
+type NodeTracker interface {
+	ListNodes() []string
+	IsConnected(nodeID string) bool
+}
+
+// GatewayCounts summarizes gateway connectivity.
+type GatewayCounts struct {
+	Total        int `json:"total"`
+	Connected    int `json:"connected"`
+	Disconnected int `json:"disconnected"`
+}
+
+// FleetStatus is the response for GET /api/v1/status.
+type FleetStatus struct {
+	Gateways GatewayCounts `json:"gateways"`
+	// Deployments counts deployments by status phase.
+	Deployments map[string]int `json:"deployments"`
+	// OrphanNodes are node IDs with an installed snapshot but no Gateway.
+	OrphanNodes []string `json:"orphanNodes"`
+}
+
+// ResourceSummary is the response for GET /api/v1/metrics/summary.
+type ResourceSummary struct {
+	Gateways  int `json:"gateways"`
+	Listeners int `json:"listeners"`
+	// Environments counts the "data" listeners with routes of their own;
+	// listeners sharing another's routes (routesFrom) serve its
+	// environment, and "admin/stats" listeners serve none.
+	Environments int `json:"environments"`
+	APIs         int `json:"apis"`
+	Deployments  int `json:"deployments"`
+	// Nodes counts the xDS nodes with an installed snapshot, and
+	// ConnectedNodes those of them holding an open stream.
+	Nodes          int `json:"nodes"`
+	ConnectedNodes int `json:"connectedNodes"`
+}
+
+// StatusHandler aggregates fleet health from the Store and the xDS cache.
+type StatusHandler struct {
+	store store.Store
+	nodes NodeTracker
+}
+
+// NewStatusHandler returns a StatusHandler. nodes may be nil, in which case
+// every gateway is reported as disconnected and no orphan nodes are listed.
+func NewStatusHandler(s store.Store, nodes NodeTracker) *StatusHandler {
+	return &StatusHandler{store: s, nodes: nodes}
+}
+
+// Handle handles GET /api/v1/status.
+func (h *StatusHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	status := FleetStatus{
+		Deployments: map[string]int{},
+		OrphanNodes: []string{},
+	}
+
+	gateways, err := h.store.List(r.Context(), store.ListFilter{Kind: "Gateway"})
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	gatewayNodes := make(map[string]bool, len(gateways))
+	for _, gw := range gateways {
+		var spec struct {
+			NodeID string `json:"nodeId"`
+		}
+		_ = json.Unmarshal(gw.SpecJSON, &spec)
+		gatewayNodes[spec.NodeID] = true
+
+		status.Gateways.Total++
+		if h.nodes != nil && spec.NodeID != "" && h.nodes.IsConnected(spec.NodeID) {
+			status.Gateways.Connected++
+		} else {
+			status.Gateways.Disconnected++
+		}
+	}
+
+	deployments, err := h.store.List(r.Context(), store.ListFilter{Kind: "Deployment"})
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, dep := range deployments {
+		var st struct {
+			Phase string `json:"phase"`
+		}
+		if len(dep.StatusJSON) > 0 {
+			_ = json.Unmarshal(dep.StatusJSON, &st)
+		}
+		if st.Phase == "" {
+			st.Phase = phaseUnknown
+		}
+		status.Deployments[st.Phase]++
+	}
+
+	if h.nodes != nil {
+		for _, node := range h.nodes.ListNodes() {
+			if !gatewayNodes[node] {
+				status.OrphanNodes = append(status.OrphanNodes, node)
+			}
+		}
+		slices.Sort(status.OrphanNodes)
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, status)
+}
+
+// HandleSummary handles GET /api/v1/metrics/summary, counting the
+// resources of the whole control plane.
+func (h *StatusHandler) HandleSummary(w http.ResponseWriter, r *http.Request) {
+	var summary ResourceSummary
+	for kind, count := range map[string]*int{
+		"Gateway":    &summary.Gateways,
+		"API":        &summary.APIs,
+		"Deployment": &summary.Deployments,
+	} {
+		resources, err := h.store.List(r.Context(), store.ListFilter{Kind: kind})
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		*count = len(resources)
+	}
+
+	listeners, err := h.store.List(r.Context(), store.ListFilter{Kind: "Listener"})
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	summary.Listeners = len(listeners)
+	for _, l := range listeners {
+		var spec struct {
+			Kind       string `json:"kind"`
+			RoutesFrom string `json:"routesFrom"`
+		}
+		_ = json.Unmarshal(l.SpecJSON, &spec)
+		if spec.Kind != "admin/stats" && (spec.RoutesFrom == "" || spec.RoutesFrom == l.Meta.Name) {
+			summary.Environments++
+		}
+	}
+
+	if h.nodes != nil {
+		for _, node := range h.nodes.ListNodes() {
+			summary.Nodes++
+			if h.nodes.IsConnected(node) {
+				summary.ConnectedNodes++
+			}
+		}
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+type fakeNodes struct {
+	snapshots []string
+	connected map[string]bool
+}
+
+func (f *fakeNodes) ListNodes() []string            { return f.snapshots }
+func (f *fakeNodes) IsConnected(nodeID string) bool { return f.connected[nodeID] }
+
+func put(t *testing.T, s store.Store, kind, name, spec, status string) {
+	t.Helper()
+	res := &store.StoredResource{
+		Meta:     store.StoreMeta{Kind: kind, Name: name},
+		SpecJSON: json.RawMessage(spec),
+	}
+	if status != "" {
+		res.StatusJSON = json.RawMessage(status)
+	}
+	if _, err := s.Put(context.Background(), res, store.PutOptions{}); err != nil {
+		t.Fatalf("put %s/%s: %v", kind, name, err)
+	}
+}
+
+func TestStatusAggregates(t *testing.T) {
+	s := store.NewMemoryStore()
+	put(t, s, "Gateway", "edge", `{"nodeId":"edge-node"}`, "")
+	put(t, s, "Gateway", "internal", `{"nodeId":"internal-node"}`, "")
+	put(t, s, "Gateway", "staging", `{"nodeId":"staging-node"}`, "")
+	put(t, s, "Deployment", "a", `{"apiRef":"a","gateway":{"name":"edge"}}`, `{"phase":"Deployed"}`)
+	put(t, s, "Deployment", "b", `{"apiRef":"b","gateway":{"name":"edge"}}`, `{"phase":"Deployed"}`)
+	put(t, s, "Deployment", "c", `{"apiRef":"c","gateway":{"name":"internal"}}`, `{"phase":"Failed"}`)
+	put(t, s, "Deployment", "d", `{"apiRef":"d","gateway":{"name":"staging"}}`, "")
+
+	nodes := &fakeNodes{
+		snapshots: []string{"edge-node", "internal-node", "stale-node", "old-node"},
+		connected: map[string]bool{"edge-node": true, "stale-node": true},
+	}
+
+	rec := httptest.NewRecorder()
+	NewStatusHandler(s, nodes).Handle(rec, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var got FleetStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if want := (GatewayCounts{Total: 3, Connected: 1, Disconnected: 2}); got.Gateways != want {
+		t.Errorf("gateways = %+v, want %+v", got.Gateways, want)
+	}
+	wantDeps := map[string]int{"Deployed": 2, "Failed": 1, phaseUnknown: 1}
+	if len(got.Deployments) != len(wantDeps) {
+		t.Errorf("deployments = %v, want %v", got.Deployments, wantDeps)
+	}
+	for phase, n := range wantDeps {
+		if got.Deployments[phase] != n {
+			t.Errorf("deployments[%s] = %d, want %d", phase, got.Deployments[phase], n)
+		}
+	}
+	if want := []string{"old-node", "stale-node"}; !slices.Equal(got.OrphanNodes, want) {
+		t.Errorf("orphan nodes = %v, want %v", got.OrphanNodes, want)
+	}
+}
+
+func TestSummaryCounts(t *testing.T) {
+	s := store.NewMemoryStore()
+	put(t, s, "Gateway", "edge", `{"nodeId":"edge-node"}`, "")
+	put(t, s, "Gateway", "internal", `{"nodeId":"internal-node"}`, "")
+	put(t, s, "Listener", "blue", `{"gatewayRef":"edge","port":8080}`, "")
+	put(t, s, "Listener", "green", `{"gatewayRef":"edge","port":8081,"routesFrom":"blue"}`, "")
+	put(t, s, "Listener", "stats", `{"gatewayRef":"edge","port":9901,"kind":"admin/stats"}`, "")
+	put(t, s, "Listener", "internal", `{"gatewayRef":"internal","port":8080,"kind":"data"}`, "")
+	put(t, s, "API", "pets", `{"version":"v1","context":"/pets"}`, "")
+	put(t, s, "Deployment", "pets-edge", `{"apiRef":"pets","gateway":{"name":"edge"}}`, "")
+	put(t, s, "Deployment", "pets-internal", `{"apiRef":"pets","gateway":{"name":"internal"}}`, "")
+	put(t, s, "Deployment", "pets-stale", `{"apiRef":"pets","gateway":{"name":"gone"}}`, "")
+
+	nodes := &fakeNodes{
+		snapshots: []string{"edge-node", "internal-node", "stale-node"},
+		connected: map[string]bool{"edge-node": true},
+	}
+	rec := httptest.NewRecorder()
+	NewStatusHandler(s, nodes).HandleSummary(rec, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var got ResourceSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := ResourceSummary{
+		Gateways:       2,
+		Listeners:      4,
+		Environments:   2,
+		APIs:           1,
+		Deployments:    3,
+		Nodes:          3,
+		ConnectedNodes: 1,
+	}
+	if got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	xdsserver "github.com/flowc-labs/flowc/internal/flowc/xds/server"
+)
+
+// StreamRegistry lists and force-closes the open xDS streams of a node.
+// Implemented by server.StreamTracker.
+type StreamRegistry interface {
+	ListStreams(nodeID string) []xdsserver.StreamInfo
+	CloseStream(nodeID string, id int64) error
+}
+
+// StreamsHandler serves the open xDS streams of each node.
+type StreamsHandler struct {
+	streams StreamRegistry
+}
+
+// NewStreamsHandler returns a StreamsHandler. streams may be nil, in which
+// case stream tracking is reported as unavailable.
+func NewStreamsHandler(streams StreamRegistry) *StreamsHandler {
+	return &StreamsHandler{streams: streams}
+}
+
+// HandleList handles GET /api/v1/nodes/{nodeID}/streams.
+func (h *StreamsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if h.streams == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "stream tracking is not available")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"streams": h.streams.ListStreams(r.PathValue("nodeID")),
+	})
+}
+
+// HandleClose handles DELETE /api/v1/nodes/{nodeID}/streams/{id}. The
+// proxy is disconnected and is expected to reconnect on a new stream.
+func (h *StreamsHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	if h.streams == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "stream tracking is not available")
+		return
+	}
+	nodeID := r.PathValue("nodeID")
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "stream id must be an integer")
+		return
+	}
+
+	err = h.streams.CloseStream(nodeID, id)
+	if errors.Is(err, xdsserver.ErrStreamNotFound) {
+		httputil.WriteError(w, http.StatusNotFound, fmt.Sprintf("stream %d of node %q not found", id, nodeID))
+		return
+	}
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"message": fmt.Sprintf("stream %d of node %q closed", id, nodeID),
+	})
+}
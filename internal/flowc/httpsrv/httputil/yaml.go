@@ -0,0 +1,82 @@
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// YAMLContentType is the media type of YAML request and response bodies.
+const YAMLContentType = "application/yaml"
+
+// isYAML reports whether the media type mediaType (parameters allowed)
+// is a YAML one.
+func isYAML(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// ReadBody reads the request body as JSON. A body sent with a YAML
+// Content-Type is converted to JSON first, so handlers decode both the
+// same way (by json tags). The error is suitable for a 400 response.
+func ReadBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+	if !isYAML(r.Header.Get("Content-Type")) {
+		return body, nil
+	}
+	body, err = yaml.YAMLToJSON(body)
+	if err != nil {
+		return nil, errors.New("invalid YAML: " + err.Error())
+	}
+	return body, nil
+}
+
+// WantsYAML reports whether the request's Accept header prefers YAML to
+// JSON, that is names a YAML media type before any JSON one.
+func WantsYAML(r *http.Request) bool {
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		accept = strings.TrimSpace(accept)
+		if isYAML(accept) {
+			return true
+		}
+		if mt, _, _ := mime.ParseMediaType(accept); mt == "application/json" {
+			return false
+		}
+	}
+	return false
+}
+
+// Write serializes v as YAML when the request asks for it (see WantsYAML)
+// and as JSON otherwise. YAML keeps the JSON field names.
+func Write(w http.ResponseWriter, r *http.Request, code int, v any) {
+	if !WantsYAML(r) {
+		WriteJSON(w, code, v)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		data, err = yaml.JSONToYAML(data)
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "encode YAML: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", YAMLContentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,41 @@
+package httpsrv
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator generates the IDs of requests that arrive without an
+// X-Request-ID header.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator generates random UUIDs. It is the server's default.
+type UUIDGenerator struct{}
+
+// NewID returns a new random UUID.
+func (UUIDGenerator) NewID() string {
+	return uuid.NewString()
+}
+
+// SequentialIDGenerator generates the readable IDs prefix-1, prefix-2, and
+// so on. The sequence restarts with the process, so IDs are only unique
+// within one run of the control plane.
+type SequentialIDGenerator struct {
+	prefix string
+	next   atomic.Uint64
+}
+
+// NewSequentialIDGenerator returns a SequentialIDGenerator whose IDs start
+// with prefix.
+func NewSequentialIDGenerator(prefix string) *SequentialIDGenerator {
+	return &SequentialIDGenerator{prefix: prefix}
+}
+
+// NewID returns the next ID of the sequence.
+func (g *SequentialIDGenerator) NewID() string {
+	return g.prefix + "-" + strconv.FormatUint(g.next.Add(1), 10)
+}
@@ -1,7 +1,7 @@
 // Package httpsrv hosts the flowc HTTP server. It owns the mux, server
 // lifecycle, and middleware, and mounts handlers from three sibling packages:
 //
-//   - admin/      operational endpoints (health, root)
+//   - admin/      operational endpoints (health, root, fleet status, node streams)
 //   - dataplane/  Envoy-facing artifacts (bootstrap, deploy instructions)
 //   - providers/rest/  resource CRUD that writes to the Store
 //
@@ -15,9 +15,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
 	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/admin"
 	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/dataplane"
 	"github.com/flowc-labs/flowc/internal/flowc/providers/rest"
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest/loader"
 	"github.com/flowc-labs/flowc/internal/flowc/store"
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
@@ -31,27 +33,101 @@ type Server struct {
 	mux          *http.ServeMux
 	server       *http.Server
 	store        store.Store
+	bundles      store.BundleStore
+	nodes        admin.NodeTracker
+	streams      admin.StreamRegistry
+	drift        rest.DriftDetector
+	routes       rest.RouteInspector
+	endpoints    rest.EndpointInspector
+	describer    rest.DeploymentDescriber
+	limiter      *rest.DeployRateLimiter
+	parses       *loader.ParseLimiter
+	audit        audit.Sink
+	ids          IDGenerator
 	logger       *logger.EnvoyLogger
 	port         int
 	xdsPort      int
+	reserved     []int
 	readTimeout  time.Duration
 	writeTimeout time.Duration
 	idleTimeout  time.Duration
 	startTime    time.Time
 }
 
-// NewServer constructs the HTTP server. xdsPort is baked into Envoy bootstrap
-// configs the dataplane handlers serve.
-func NewServer(port, xdsPort int, readTimeout, writeTimeout, idleTimeout time.Duration, resourceStore store.Store, log *logger.EnvoyLogger) *Server {
+// Options configures a Server. Any dependency may be left nil: the
+// endpoints backed by a missing one report it unavailable.
+type Options struct {
+	// Port the API server listens on.
+	Port int
+
+	// XDSPort is baked into the Envoy bootstrap configs the dataplane
+	// handlers serve.
+	XDSPort int
+
+	// ReservedPorts are kept free of listeners, along with Port and
+	// XDSPort.
+	ReservedPorts []int
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// Bundles keeps uploaded ZIP bundles for download and rollback.
+	Bundles store.BundleStore
+
+	// Nodes backs the fleet status endpoint.
+	Nodes admin.NodeTracker
+
+	// Streams backs the node stream endpoints.
+	Streams admin.StreamRegistry
+
+	// Drift backs the deployment drift endpoint.
+	Drift rest.DriftDetector
+
+	// Routes backs the environment route table endpoint.
+	Routes rest.RouteInspector
+
+	// Endpoints backs the deployment endpoints endpoint.
+	Endpoints rest.EndpointInspector
+
+	// Describer backs the deployment describe endpoint.
+	Describer rest.DeploymentDescriber
+
+	// DeployLimiter throttles deploy operations per gateway; nil disables
+	// throttling.
+	DeployLimiter *rest.DeployRateLimiter
+
+	// Parses bounds the bundles parsed at once by uploads and validation;
+	// nil places no limit.
+	Parses *loader.ParseLimiter
+
+	// Audit backs the audit endpoint.
+	Audit audit.Sink
+}
+
+// NewServer constructs the HTTP server over resourceStore.
+func NewServer(resourceStore store.Store, opts Options, log *logger.EnvoyLogger) *Server {
 	s := &Server{
 		mux:          http.NewServeMux(),
 		store:        resourceStore,
+		bundles:      opts.Bundles,
+		nodes:        opts.Nodes,
+		streams:      opts.Streams,
+		drift:        opts.Drift,
+		routes:       opts.Routes,
+		endpoints:    opts.Endpoints,
+		describer:    opts.Describer,
+		limiter:      opts.DeployLimiter,
+		parses:       opts.Parses,
+		audit:        opts.Audit,
+		ids:          UUIDGenerator{},
 		logger:       log,
-		port:         port,
-		xdsPort:      xdsPort,
-		readTimeout:  readTimeout,
-		writeTimeout: writeTimeout,
-		idleTimeout:  idleTimeout,
+		port:         opts.Port,
+		xdsPort:      opts.XDSPort,
+		reserved:     opts.ReservedPorts,
+		readTimeout:  opts.ReadTimeout,
+		writeTimeout: opts.WriteTimeout,
+		idleTimeout:  opts.IdleTimeout,
 		startTime:    time.Now(),
 	}
 
@@ -59,23 +135,65 @@ func NewServer(port, xdsPort int, readTimeout, writeTimeout, idleTimeout time.Du
 	return s
 }
 
+// SetIDGenerator replaces the generator of request IDs, UUIDs by default.
+func (s *Server) SetIDGenerator(g IDGenerator) {
+	s.ids = g
+}
+
+// Handler returns the server's routes wrapped in its middleware.
+func (s *Server) Handler() http.Handler {
+	return s.corsMiddleware(s.requestContextMiddleware(s.mux))
+}
+
+// reservedPorts maps the ports listeners may not use to what uses them.
+func (s *Server) reservedPorts() map[uint32]string {
+	ports := make(map[uint32]string, len(s.reserved)+2)
+	for _, port := range s.reserved {
+		ports[uint32(port)] = "the control plane (server.reserved_ports)"
+	}
+	ports[uint32(s.xdsPort)] = "the flowc xDS server"
+	ports[uint32(s.port)] = "the flowc API server"
+	return ports
+}
+
 // setupRoutes configures all HTTP routes using Go 1.22+ method-based routing.
 func (s *Server) setupRoutes() {
 	// Provider — resource CRUD that writes to the Store.
 	rh := rest.NewResourceHandler(s.store, s.logger)
-	uh := rest.NewUploadHandler(s.store, s.logger)
+	rh.SetDeployRateLimiter(s.limiter)
+	rh.SetReservedPorts(s.reservedPorts())
+	uh := rest.NewUploadHandler(s.store, s.bundles, s.logger)
+	uh.SetDeployRateLimiter(s.limiter)
+	uh.SetParseLimiter(s.parses)
+	bdh := rest.NewBundleHandler(s.bundles)
+	drh := rest.NewDriftHandler(s.drift)
+	rth := rest.NewRoutesHandler(s.routes)
+	eph := rest.NewEndpointsHandler(s.endpoints)
+	dsh := rest.NewDescribeHandler(s.describer, s.audit)
+	vh := rest.NewValidateHandler(s.logger)
+	vh.SetParseLimiter(s.parses)
+	revh := rest.NewRevalidateHandler(s.store, s.logger)
+	revh.SetParseLimiter(s.parses)
 
 	// Dataplane — Envoy-facing artifacts (read-only against the Store).
 	bh := dataplane.NewBootstrapHandler(s.store, "host.docker.internal", s.xdsPort, s.logger)
 	dh := dataplane.NewDeployHandler(s.store, "host.docker.internal", s.xdsPort, s.port, s.logger)
 
-	// Admin — health, root doc.
+	// Admin — health, root doc, fleet status.
 	hh := admin.NewHealthHandler(s.startTime, version)
 	rooth := admin.NewRootHandler()
+	sh := admin.NewStatusHandler(s.store, s.nodes)
+	sth := admin.NewStreamsHandler(s.streams)
+	ah := admin.NewAuditHandler(s.audit)
 
 	// Admin
 	s.mux.HandleFunc("GET /health", hh.Handle)
 	s.mux.HandleFunc("GET /", rooth.Handle)
+	s.mux.HandleFunc("GET /api/v1/status", sh.Handle)
+	s.mux.HandleFunc("GET /api/v1/metrics/summary", sh.HandleSummary)
+	s.mux.HandleFunc("GET /api/v1/nodes/{nodeID}/streams", sth.HandleList)
+	s.mux.HandleFunc("DELETE /api/v1/nodes/{nodeID}/streams/{id}", sth.HandleClose)
+	s.mux.HandleFunc("GET /api/v1/audit", ah.Handle)
 
 	// --- Flat K8s-style resource endpoints (provider/rest) ---
 
@@ -84,12 +202,18 @@ func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("GET /api/v1/gateways/{name}", rh.HandleGet("Gateway"))
 	s.mux.HandleFunc("GET /api/v1/gateways", rh.HandleList("Gateway"))
 	s.mux.HandleFunc("DELETE /api/v1/gateways/{name}", rh.HandleDelete("Gateway"))
+	s.mux.HandleFunc("POST /api/v1/gateways/{name}/listeners", rh.HandleAddListeners)
 
 	// Listeners
 	s.mux.HandleFunc("PUT /api/v1/listeners/{name}", rh.HandlePut("Listener"))
 	s.mux.HandleFunc("GET /api/v1/listeners/{name}", rh.HandleGet("Listener"))
 	s.mux.HandleFunc("GET /api/v1/listeners", rh.HandleList("Listener"))
 	s.mux.HandleFunc("DELETE /api/v1/listeners/{name}", rh.HandleDelete("Listener"))
+	s.mux.HandleFunc("PUT /api/v1/listeners/{name}/port", rh.HandleChangeListenerPort)
+	s.mux.HandleFunc("GET /api/v1/listeners/{name}/xds", rth.HandleGetListenerXDS)
+
+	// Environments — a listener's live route table.
+	s.mux.HandleFunc("GET /api/v1/environments/{id}/routes", rth.HandleGetRoutes)
 
 	// APIs
 	s.mux.HandleFunc("PUT /api/v1/apis/{name}", rh.HandlePut("API"))
@@ -102,6 +226,14 @@ func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("GET /api/v1/deployments/{name}", rh.HandleGet("Deployment"))
 	s.mux.HandleFunc("GET /api/v1/deployments", rh.HandleList("Deployment"))
 	s.mux.HandleFunc("DELETE /api/v1/deployments/{name}", rh.HandleDelete("Deployment"))
+	s.mux.HandleFunc("PUT /api/v1/deployments/{name}/canary", rh.HandleSetCanaryWeight)
+	s.mux.HandleFunc("PUT /api/v1/deployments/{name}/mirror", rh.HandleSetMirrorPercentage)
+	s.mux.HandleFunc("GET /api/v1/deployments/{name}/bundle", bdh.HandleGetBundle)
+	s.mux.HandleFunc("POST /api/v1/deployments/{name}/rollback", uh.HandleRollback)
+	s.mux.HandleFunc("GET /api/v1/deployments/{name}/drift", drh.HandleGetDrift)
+	s.mux.HandleFunc("GET /api/v1/deployments/{name}/endpoints", eph.HandleGetEndpoints)
+	s.mux.HandleFunc("GET /api/v1/deployments/{name}/describe", dsh.HandleDescribe)
+	s.mux.HandleFunc("GET /api/v1/deployments/{name}/openapi", rh.HandleGetOpenAPI)
 
 	// GatewayPolicies
 	s.mux.HandleFunc("PUT /api/v1/gatewaypolicies/{name}", rh.HandlePut("GatewayPolicy"))
@@ -127,6 +259,12 @@ func (s *Server) setupRoutes() {
 	// ZIP upload convenience (provider/rest)
 	s.mux.HandleFunc("POST /api/v1/upload", uh.HandleUpload)
 
+	// Bundle lint / dry run; never touches the Store (provider/rest)
+	s.mux.HandleFunc("POST /api/v1/bundles:validate", vh.HandleValidate)
+
+	// Spec re-validation of deployed APIs; reads the Store only (provider/rest)
+	s.mux.HandleFunc("POST /api/v1/deployments:revalidate", revh.HandleRevalidate)
+
 	// --- Dataplane endpoints (Envoy-facing) ---
 	s.mux.HandleFunc("GET /api/v1/gateways/{name}/bootstrap", bh.HandleBootstrap)
 	s.mux.HandleFunc("GET /api/v1/gateways/{name}/deploy", dh.HandleDeploy)
@@ -137,7 +275,7 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Managed-By, If-Match")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Managed-By, X-Actor, If-Match")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		if r.Method == "OPTIONS" {
@@ -149,11 +287,40 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requestContextMiddleware attaches the server logger and the request's
+// ID to its context, so every log drawn from it (logger.FromContext,
+// EnvoyLogger.WithContext) carries request_id. The ID comes from the
+// X-Request-ID header, or is generated by the server's IDGenerator, and
+// is echoed in the response. The request's actor, recorded in the audit
+// log, is named by the X-Actor header, falling back to X-Managed-By.
+func (s *Server) requestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = s.ids.NewID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		actor := r.Header.Get("X-Actor")
+		if actor == "" {
+			actor = r.Header.Get("X-Managed-By")
+		}
+		if actor == "" {
+			actor = "anonymous"
+		}
+
+		ctx := logger.NewContext(r.Context(), s.logger)
+		ctx = logger.WithContext(ctx, map[string]any{"request_id": id})
+		ctx = audit.WithActor(ctx, actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // Start starts the HTTP server.
 func (s *Server) Start() error {
 	s.server = &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.port),
-		Handler:      s.corsMiddleware(s.mux),
+		Handler:      s.Handler(),
 		ReadTimeout:  s.readTimeout,
 		WriteTimeout: s.writeTimeout,
 		IdleTimeout:  s.idleTimeout,
@@ -0,0 +1,48 @@
+package httpsrv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func TestGatewayCreationRequestIDs(t *testing.T) {
+	s := NewServer(store.NewMemoryStore(), Options{
+		Port:         8080,
+		XDSPort:      18000,
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+		IdleTimeout:  time.Second,
+	}, nil)
+	s.SetIDGenerator(NewSequentialIDGenerator("req"))
+	handler := s.Handler()
+
+	put := func(name, requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/gateways/"+name, strings.NewReader(`{"spec":{"nodeId":"`+name+`-node"}}`))
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create gateway %s: status = %d, body %s", name, rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	for i, tt := range []struct{ gateway, header, want string }{
+		{"edge", "", "req-1"},
+		{"internal", "", "req-2"},
+		// A caller's ID is kept and does not advance the sequence.
+		{"partner", "caller-id", "caller-id"},
+		{"batch", "", "req-3"},
+	} {
+		if got := put(tt.gateway, tt.header).Header().Get("X-Request-ID"); got != tt.want {
+			t.Errorf("request %d: X-Request-ID = %q, want %q", i, got, tt.want)
+		}
+	}
+}
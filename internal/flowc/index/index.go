@@ -412,7 +412,7 @@ func (i *Indexer) APIPoliciesForAPI(api string) []*flowcv1alpha1.APIPolicy {
 
 // RecordOwnership stores the xDS resource names produced by a successful
 // translation of a single deployment, so a future delete can call
-// cache.UnDeployAPI with exactly the names that were pushed.
+// cache.BulkUpdate with exactly the names that were pushed.
 func (i *Indexer) RecordOwnership(nodeID, depName string, names cache.ResourceNames) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -451,7 +451,7 @@ func (i *Indexer) OwnershipForDeployment(depName string) (nodeID string, names c
 }
 
 // ClearOwnership removes the deployment's entry; called after a successful
-// UnDeployAPI on the cache.
+// BulkUpdate on the cache.
 func (i *Indexer) ClearOwnership(nodeID, depName string) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
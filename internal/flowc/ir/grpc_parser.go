@@ -52,6 +52,9 @@ func (p *GRPCParser) Parse(ctx context.Context, data []byte) (*API, error) {
 	//    - Bidirectional streaming -> EndpointTypeGRPCBidirectional
 	// 4. Converting Protobuf messages to DataModels
 	// 5. Handling nested types, enums, and options
+	// 6. Caching compiled descriptor sets by content hash (bounded, safe for
+	//    concurrent deploys) and reusing them for transcoding and route
+	//    generation, so redeploying an unchanged .proto skips compilation
 
 	return nil, fmt.Errorf("gRPC parser not yet implemented")
 }
@@ -0,0 +1,344 @@
+package ir
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ToOpenAPI serializes api back to an OpenAPI 3.0 document (JSON) as the
+// gateway exposes it: every path is prefixed with basePath (the
+// deployment's context, "" or "/" for none). Endpoints that are not HTTP
+// operations (gRPC methods, GraphQL fields, event channels) are left out,
+// and so are servers: clients reach the API through the gateway, not the
+// upstreams the spec's servers name.
+//
+// The IR does not keep which security requirements were alternatives, so
+// each endpoint requirement is emitted as an alternative of its own.
+func ToOpenAPI(api *API, basePath string) ([]byte, error) {
+	if api == nil {
+		return nil, fmt.Errorf("no API to export")
+	}
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    exportInfo(api.Metadata),
+		Paths:   openapi3.NewPaths(),
+	}
+	for _, tag := range api.Metadata.Tags {
+		doc.Tags = append(doc.Tags, &openapi3.Tag{Name: tag})
+	}
+
+	for i := range api.Endpoints {
+		endpoint := &api.Endpoints[i]
+		if endpoint.Type != EndpointTypeHTTP && endpoint.Type != EndpointTypeSSE {
+			continue
+		}
+		path := basePath + endpoint.Path.Pattern
+		if path == "" {
+			path = "/"
+		}
+		item := doc.Paths.Value(path)
+		if item == nil {
+			item = &openapi3.PathItem{}
+			doc.Paths.Set(path, item)
+		}
+		if item.GetOperation(endpoint.Method) != nil {
+			return nil, fmt.Errorf("endpoint %s: duplicate operation %s %s", endpoint.ID, endpoint.Method, path)
+		}
+		item.SetOperation(endpoint.Method, exportOperation(endpoint))
+	}
+
+	if len(api.DataModels) > 0 || len(api.Security) > 0 {
+		doc.Components = &openapi3.Components{}
+	}
+	for i := range api.DataModels {
+		model := &api.DataModels[i]
+		if doc.Components.Schemas == nil {
+			doc.Components.Schemas = openapi3.Schemas{}
+		}
+		doc.Components.Schemas[model.Name] = openapi3.NewSchemaRef("", exportDataModel(model))
+	}
+	for i := range api.Security {
+		scheme := &api.Security[i]
+		if doc.Components.SecuritySchemes == nil {
+			doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+		}
+		doc.Components.SecuritySchemes[scheme.Name] = &openapi3.SecuritySchemeRef{Value: exportSecurityScheme(scheme)}
+	}
+
+	return doc.MarshalJSON()
+}
+
+// exportInfo converts the API metadata into the document's info. Title
+// and version are required, so they fall back to the name and "0.0.0".
+func exportInfo(meta APIMetadata) *openapi3.Info {
+	info := &openapi3.Info{
+		Title:          meta.Title,
+		Description:    meta.Description,
+		Version:        meta.Version,
+		TermsOfService: meta.TermsOfService,
+	}
+	if info.Title == "" {
+		info.Title = meta.Name
+	}
+	if info.Version == "" {
+		info.Version = "0.0.0"
+	}
+	if c := meta.Contact; c != nil {
+		info.Contact = &openapi3.Contact{Name: c.Name, URL: c.URL, Email: c.Email}
+	}
+	if l := meta.License; l != nil {
+		info.License = &openapi3.License{Name: l.Name, URL: l.URL}
+	}
+	return info
+}
+
+// exportOperation converts an endpoint into an OpenAPI operation.
+func exportOperation(endpoint *Endpoint) *openapi3.Operation {
+	op := &openapi3.Operation{
+		OperationID: endpoint.ID,
+		Summary:     endpoint.Name,
+		Description: endpoint.Description,
+		Tags:        endpoint.Tags,
+		Deprecated:  endpoint.Deprecated,
+		Responses:   openapi3.NewResponses(),
+	}
+
+	params := endpoint.Path.Parameters
+	if req := endpoint.Request; req != nil {
+		params = append(params[:len(params):len(params)], req.QueryParameters...)
+		params = append(params, req.HeaderParameters...)
+		params = append(params, req.CookieParameters...)
+		if req.Body != nil {
+			contentType := req.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+				WithContent(openapi3.NewContentWithSchema(exportDataModel(req.Body), []string{contentType}))}
+		}
+	}
+	for i := range params {
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: exportParameter(&params[i])})
+	}
+
+	if len(endpoint.Responses) > 0 {
+		op.Responses = openapi3.NewResponsesWithCapacity(len(endpoint.Responses))
+	}
+	for i := range endpoint.Responses {
+		resp := &endpoint.Responses[i]
+		code := "default"
+		if resp.StatusCode > 0 {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		op.Responses.Set(code, &openapi3.ResponseRef{Value: exportResponse(resp)})
+	}
+
+	if len(endpoint.Security) > 0 {
+		security := openapi3.NewSecurityRequirements()
+		for _, req := range endpoint.Security {
+			entry := openapi3.SecurityRequirement{}
+			for _, scheme := range req.Schemes {
+				scopes := scheme.Scopes
+				if scopes == nil {
+					scopes = []string{}
+				}
+				entry[scheme.Name] = scopes
+			}
+			security.With(entry)
+		}
+		op.Security = security
+	}
+	return op
+}
+
+// exportParameter converts a parameter into an OpenAPI parameter.
+func exportParameter(param *Parameter) *openapi3.Parameter {
+	p := &openapi3.Parameter{
+		Name:        param.Name,
+		In:          string(param.In),
+		Description: param.Description,
+		Required:    param.Required || param.In == ParameterLocationPath,
+		Deprecated:  param.Deprecated,
+		Example:     param.Example,
+	}
+	if param.Routing {
+		var match any = true
+		if param.RoutingValue != "" {
+			match = param.RoutingValue
+		}
+		p.Extensions = map[string]any{RouteMatchExtension: match}
+	}
+	schema := exportDataType(param.Schema)
+	if schema == nil {
+		schema = openapi3.NewSchema()
+	}
+	schema.Default = param.Default
+	p.Schema = openapi3.NewSchemaRef("", schema)
+	return p
+}
+
+// exportResponse converts a response into an OpenAPI response. A
+// description is required, so it falls back to the status text.
+func exportResponse(resp *ResponseSpec) *openapi3.Response {
+	description := resp.Description
+	if description == "" {
+		description = http.StatusText(resp.StatusCode)
+	}
+	r := openapi3.NewResponse().WithDescription(description)
+	if resp.Body != nil || resp.ContentType != "" {
+		contentType := resp.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		var schema *openapi3.Schema
+		if resp.Body != nil {
+			schema = exportDataModel(resp.Body)
+		}
+		r.Content = openapi3.NewContentWithSchema(schema, []string{contentType})
+	}
+	for i := range resp.Headers {
+		h := &resp.Headers[i]
+		if r.Headers == nil {
+			r.Headers = openapi3.Headers{}
+		}
+		header := exportParameter(h)
+		header.Name, header.In = "", ""
+		r.Headers[h.Name] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: *header}}
+	}
+	return r
+}
+
+// exportDataModel converts a data model into a schema.
+func exportDataModel(model *DataModel) *openapi3.Schema {
+	if model.Ref != "" {
+		return &openapi3.Schema{AllOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("#/components/schemas/"+model.Ref, nil)}}
+	}
+	schema := exportDataType(model.Type)
+	if schema == nil {
+		schema = openapi3.NewSchema()
+	}
+	schema.Description = model.Description
+	schema.Required = model.Required
+	schema.Example = model.Example
+	if model.Items != nil && schema.Items == nil {
+		schema.Items = openapi3.NewSchemaRef("", exportDataType(model.Items))
+	}
+	if model.AdditionalProperties {
+		has := true
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Has: &has}
+	}
+	for i := range model.Properties {
+		prop := &model.Properties[i]
+		if schema.Properties == nil {
+			schema.Properties = openapi3.Schemas{}
+		}
+		s := exportDataType(prop.Type)
+		if s == nil {
+			s = openapi3.NewSchema()
+		}
+		s.Description = prop.Description
+		s.Default = prop.Default
+		s.Example = prop.Example
+		exportValidation(s, prop.Validation)
+		schema.Properties[prop.Name] = openapi3.NewSchemaRef("", s)
+	}
+	return schema
+}
+
+// exportDataType converts type information into a schema, referencing
+// the component schema of a model reference.
+func exportDataType(dt *DataType) *openapi3.Schema {
+	if dt == nil {
+		return nil
+	}
+	if dt.ModelRef != "" {
+		return &openapi3.Schema{AllOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("#/components/schemas/"+dt.ModelRef, nil)}}
+	}
+	schema := &openapi3.Schema{
+		Format:   dt.Format,
+		Nullable: dt.Nullable,
+		Enum:     dt.Enum,
+	}
+	if dt.BaseType != "" && dt.BaseType != "any" {
+		schema.Type = &openapi3.Types{dt.BaseType}
+	}
+	if dt.Items != nil {
+		schema.Items = openapi3.NewSchemaRef("", exportDataType(dt.Items))
+	}
+	return schema
+}
+
+// exportValidation copies validation rules onto schema.
+func exportValidation(schema *openapi3.Schema, v *Validation) {
+	if v == nil {
+		return
+	}
+	uint64Ptr := func(n *int) *uint64 {
+		if n == nil {
+			return nil
+		}
+		u := uint64(*n)
+		return &u
+	}
+	if v.MinLength != nil {
+		schema.MinLength = uint64(*v.MinLength)
+	}
+	schema.MaxLength = uint64Ptr(v.MaxLength)
+	schema.Pattern = v.Pattern
+	schema.Min = v.Minimum
+	schema.Max = v.Maximum
+	schema.ExclusiveMin = v.ExclusiveMinimum
+	schema.ExclusiveMax = v.ExclusiveMaximum
+	schema.MultipleOf = v.MultipleOf
+	if v.MinItems != nil {
+		schema.MinItems = uint64(*v.MinItems)
+	}
+	schema.MaxItems = uint64Ptr(v.MaxItems)
+	schema.UniqueItems = v.UniqueItems
+	if v.MinProperties != nil {
+		schema.MinProps = uint64(*v.MinProperties)
+	}
+	schema.MaxProps = uint64Ptr(v.MaxProperties)
+}
+
+// exportSecurityScheme converts a security scheme into an OpenAPI one.
+func exportSecurityScheme(scheme *SecurityScheme) *openapi3.SecurityScheme {
+	s := &openapi3.SecurityScheme{
+		Type:             scheme.Type,
+		Description:      scheme.Description,
+		In:               scheme.In,
+		Scheme:           scheme.Scheme,
+		BearerFormat:     scheme.BearerFormat,
+		OpenIdConnectUrl: scheme.OpenIDConnectURL,
+		Name:             scheme.KeyName,
+	}
+	if f := scheme.Flows; f != nil {
+		flow := func(of *OAuthFlow) *openapi3.OAuthFlow {
+			if of == nil {
+				return nil
+			}
+			return &openapi3.OAuthFlow{
+				AuthorizationURL: of.AuthorizationURL,
+				TokenURL:         of.TokenURL,
+				RefreshURL:       of.RefreshURL,
+				Scopes:           of.Scopes,
+			}
+		}
+		s.Flows = &openapi3.OAuthFlows{
+			Implicit:          flow(f.Implicit),
+			Password:          flow(f.Password),
+			ClientCredentials: flow(f.ClientCredentials),
+			AuthorizationCode: flow(f.AuthorizationCode),
+		}
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package ir
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const petstoreSpec = `openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.2.0
+servers:
+  - url: https://pets.internal:8443
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      summary: Get a pet
+      security:
+        - apiKey: []
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: fields
+          in: query
+          schema:
+            type: string
+      responses:
+        "200":
+          description: the pet
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string
+                    minLength: 1
+        "404":
+          description: no such pet
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+`
+
+func TestToOpenAPIRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	api, err := NewOpenAPIParser().Parse(ctx, []byte(petstoreSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	data, err := ToOpenAPI(api, "/petstore/")
+	if err != nil {
+		t.Fatalf("ToOpenAPI: %v", err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("re-emitted document does not load: %v\n%s", err, data)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		t.Fatalf("re-emitted document is invalid: %v\n%s", err, data)
+	}
+	if doc.Info.Title != "Petstore" || doc.Info.Version != "1.2.0" {
+		t.Errorf("info = %+v", doc.Info)
+	}
+	if len(doc.Servers) != 0 {
+		t.Errorf("upstream servers re-emitted: %v", doc.Servers)
+	}
+	if doc.Paths.Len() != 1 {
+		t.Errorf("paths = %v, want only /petstore/pets/{id}", doc.Paths.InMatchingOrder())
+	}
+	op := doc.Paths.Value("/petstore/pets/{id}").GetOperation("GET")
+	if op == nil {
+		t.Fatalf("GET /petstore/pets/{id} missing\n%s", data)
+	}
+	if op.OperationID != "getPet" || op.Summary != "Get a pet" {
+		t.Errorf("operation = %q %q", op.OperationID, op.Summary)
+	}
+	if p := op.Parameters.GetByInAndName("path", "id"); p == nil || !p.Required {
+		t.Errorf("path parameter id = %+v", p)
+	}
+	if op.Parameters.GetByInAndName("query", "fields") == nil {
+		t.Error("query parameter fields missing")
+	}
+	if op.Responses.Status(404) == nil {
+		t.Error("404 response missing")
+	}
+	ok := op.Responses.Status(200)
+	if ok == nil || ok.Value.Content.Get("application/json") == nil {
+		t.Fatalf("200 response = %+v", ok)
+	}
+	schema := ok.Value.Content.Get("application/json").Schema.Value
+	if name := schema.Properties["name"]; name == nil || name.Value.MinLength != 1 || len(schema.Required) != 1 {
+		t.Errorf("response schema = %+v", schema)
+	}
+
+	if op.Security == nil || len(*op.Security) != 1 {
+		t.Fatalf("security = %v, want apiKey", op.Security)
+	}
+	if _, ok := (*op.Security)[0]["apiKey"]; !ok {
+		t.Errorf("security = %v, want apiKey", *op.Security)
+	}
+	scheme := doc.Components.SecuritySchemes["apiKey"]
+	if scheme == nil || scheme.Value.Type != "apiKey" || scheme.Value.In != "header" || scheme.Value.Name != "X-API-Key" {
+		t.Errorf("security scheme = %+v", scheme)
+	}
+
+	// Parsing the re-emitted document gives back the same endpoints.
+	again, err := NewOpenAPIParser().Parse(ctx, data)
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	if len(again.Endpoints) != 1 || again.Endpoints[0].ID != "getPet" || again.Endpoints[0].Path.Pattern != "/petstore/pets/{id}" {
+		t.Errorf("re-parsed endpoints = %+v", again.Endpoints)
+	}
+}
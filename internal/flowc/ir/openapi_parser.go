@@ -1,11 +1,14 @@
 package ir
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -38,20 +41,23 @@ func (p *OpenAPIParser) SupportedFormats() []string {
 	return []string{"openapi-3.0", "openapi-3.1", "swagger-2.0"}
 }
 
-// Validate validates the OpenAPI specification
+// Validate validates the OpenAPI specification. Problems are returned as
+// ValidationIssues, one per offending section of the document.
 func (p *OpenAPIParser) Validate(ctx context.Context, data []byte) error {
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
 
 	spec, err := loader.LoadFromData(data)
 	if err != nil {
-		return fmt.Errorf("failed to load OpenAPI spec: %w", err)
+		return ValidationIssues{{
+			Message:  fmt.Sprintf("failed to load OpenAPI spec: %v", err),
+			Severity: ValidationSeverityError,
+		}}
 	}
 
-	if err := spec.Validate(ctx); err != nil {
-		return fmt.Errorf("OpenAPI spec validation failed: %w", err)
+	if issues := p.validateSpec(ctx, spec); len(issues) > 0 {
+		return issues
 	}
-
 	return nil
 }
 
@@ -68,21 +74,26 @@ func (p *OpenAPIParser) Parse(ctx context.Context, data []byte) (*API, error) {
 
 	// Validate if requested
 	if p.options.Validate {
-		if err := spec.Validate(ctx); err != nil {
+		if issues := p.validateSpec(ctx, spec); len(issues) > 0 {
 			if p.options.Strict {
-				return nil, fmt.Errorf("OpenAPI spec validation failed: %w", err)
+				return nil, fmt.Errorf("OpenAPI spec validation failed: %w", issues)
 			}
 			// Log warning but continue in non-strict mode
 		}
 	}
 
+	endpoints, err := p.parseEndpoints(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to IR
 	api := &API{
 		Metadata:   p.parseMetadata(spec),
-		Endpoints:  p.parseEndpoints(spec),
+		Endpoints:  endpoints,
 		DataModels: p.parseDataModels(spec),
 		Security:   p.parseSecuritySchemes(spec),
-		Servers:    p.parseServers(spec),
+		Servers:    p.parseServers(spec.Servers),
 	}
 
 	// Include extensions if requested
@@ -93,6 +104,112 @@ func (p *OpenAPIParser) Parse(ctx context.Context, data []byte) (*API, error) {
 	return api, nil
 }
 
+// validateSpec runs the kin-openapi validators section by section so that
+// every problem is reported, not only the first. spec.Validate stops at the
+// first error, so each component, path, and server is validated on its own
+// and tagged with its JSON pointer.
+func (p *OpenAPIParser) validateSpec(ctx context.Context, spec *openapi3.T) ValidationIssues {
+	var issues ValidationIssues
+	report := func(pointer string, err error) {
+		if err != nil {
+			issues = append(issues, ValidationIssue{
+				Pointer:  pointer,
+				Message:  err.Error(),
+				Severity: ValidationSeverityError,
+			})
+		}
+	}
+
+	if spec.OpenAPI == "" {
+		report("/openapi", fmt.Errorf("value of openapi must be a non-empty string"))
+	}
+
+	if c := spec.Components; c != nil {
+		rest := *c
+		rest.Schemas = nil
+		report("/components", rest.Validate(ctx))
+		for _, name := range slices.Sorted(maps.Keys(c.Schemas)) {
+			one := openapi3.Components{Schemas: openapi3.Schemas{name: c.Schemas[name]}}
+			report("/components/schemas/"+escapeJSONPointer(name), one.Validate(ctx))
+		}
+	}
+
+	if spec.Info == nil {
+		report("/info", fmt.Errorf("must be an object"))
+	} else {
+		report("/info", spec.Info.Validate(ctx))
+	}
+
+	if spec.Paths == nil {
+		report("/paths", fmt.Errorf("must be an object"))
+	} else {
+		before := len(issues)
+		for _, path := range slices.Sorted(maps.Keys(spec.Paths.Map())) {
+			item := spec.Paths.Value(path)
+			one := openapi3.NewPaths(openapi3.WithPath(path, item))
+			report("/paths/"+escapeJSONPointer(path), one.Validate(ctx))
+			if item == nil {
+				continue
+			}
+			ops := item.Operations()
+			for _, method := range slices.Sorted(maps.Keys(ops)) {
+				_, err := p.parseRateLimit(ops[method].Extensions)
+				report("/paths/"+escapeJSONPointer(path)+"/"+strings.ToLower(method)+"/"+RateLimitExtension, err)
+			}
+		}
+		// Cross-path checks (e.g. conflicting templates) only make sense
+		// once every path is individually valid.
+		if len(issues) == before {
+			report("/paths", spec.Paths.Validate(ctx))
+		}
+		if countOperations(spec.Paths) == 0 {
+			// Nothing to route: a deployment of it would serve no requests.
+			severity := ValidationSeverityWarning
+			if p.options.Strict {
+				severity = ValidationSeverityError
+			}
+			issues = append(issues, ValidationIssue{
+				Pointer:  "/paths",
+				Message:  "specification defines no operations",
+				Severity: severity,
+			})
+		}
+	}
+
+	if spec.Security != nil {
+		report("/security", spec.Security.Validate(ctx))
+	}
+	for i, server := range spec.Servers {
+		if server != nil {
+			report(fmt.Sprintf("/servers/%d", i), server.Validate(ctx))
+		}
+	}
+	if spec.Tags != nil {
+		report("/tags", spec.Tags.Validate(ctx))
+	}
+	if spec.ExternalDocs != nil {
+		report("/externalDocs", spec.ExternalDocs.Validate(ctx))
+	}
+
+	return issues
+}
+
+// countOperations returns the number of operations across all paths.
+func countOperations(paths *openapi3.Paths) int {
+	n := 0
+	for _, item := range paths.Map() {
+		if item != nil {
+			n += len(item.Operations())
+		}
+	}
+	return n
+}
+
+// escapeJSONPointer escapes a single JSON pointer reference token.
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
 // parseMetadata extracts metadata from OpenAPI spec
 func (p *OpenAPIParser) parseMetadata(spec *openapi3.T) APIMetadata {
 	metadata := APIMetadata{
@@ -133,11 +250,11 @@ func (p *OpenAPIParser) parseMetadata(spec *openapi3.T) APIMetadata {
 }
 
 // parseEndpoints extracts all endpoints/operations from the OpenAPI spec
-func (p *OpenAPIParser) parseEndpoints(spec *openapi3.T) []Endpoint {
+func (p *OpenAPIParser) parseEndpoints(spec *openapi3.T) ([]Endpoint, error) {
 	endpoints := make([]Endpoint, 0)
 
 	if spec.Paths == nil {
-		return endpoints
+		return endpoints, nil
 	}
 
 	for path, pathItem := range spec.Paths.Map() {
@@ -162,16 +279,22 @@ func (p *OpenAPIParser) parseEndpoints(spec *openapi3.T) []Endpoint {
 				continue
 			}
 
-			endpoint := p.parseOperation(path, method, operation, pathItem.Parameters)
+			endpoint, err := p.parseOperation(path, method, operation, pathItem.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if len(endpoint.Servers) == 0 {
+				endpoint.Servers = p.parseServers(pathItem.Servers)
+			}
 			endpoints = append(endpoints, endpoint)
 		}
 	}
 
-	return endpoints
+	return endpoints, nil
 }
 
 // parseOperation converts an OpenAPI operation to an IR endpoint
-func (p *OpenAPIParser) parseOperation(path, method string, operation *openapi3.Operation, pathParams openapi3.Parameters) Endpoint {
+func (p *OpenAPIParser) parseOperation(path, method string, operation *openapi3.Operation, pathParams openapi3.Parameters) (Endpoint, error) {
 	// Generate endpoint ID
 	endpointID := operation.OperationID
 	if endpointID == "" {
@@ -217,6 +340,10 @@ func (p *OpenAPIParser) parseOperation(path, method string, operation *openapi3.
 	// Parse responses
 	if operation.Responses != nil {
 		endpoint.Responses = p.parseResponses(operation.Responses)
+		if events := p.parseSSEEvents(operation.Responses); len(events) > 0 {
+			endpoint.Type = EndpointTypeSSE
+			endpoint.Events = events
+		}
 	}
 
 	// Parse security requirements
@@ -230,7 +357,72 @@ func (p *OpenAPIParser) parseOperation(path, method string, operation *openapi3.
 		maps.Copy(endpoint.Extensions, operation.Extensions)
 	}
 
-	return endpoint
+	rateLimit, err := p.parseRateLimit(operation.Extensions)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	endpoint.RateLimit = rateLimit
+
+	if operation.Servers != nil {
+		endpoint.Servers = p.parseServers(*operation.Servers)
+	}
+
+	return endpoint, nil
+}
+
+// RateLimitExtension is the operation-level OpenAPI extension carrying an
+// endpoint rate limit, e.g. `x-flowc-rate-limit: {requests: 10, window: 1m}`.
+const RateLimitExtension = "x-flowc-rate-limit"
+
+// parseRateLimit decodes the rate limit extension, returning nil when it is
+// absent. A malformed or out-of-range limit is an error rather than
+// silently unenforced.
+func (p *OpenAPIParser) parseRateLimit(extensions map[string]any) (*RateLimit, error) {
+	raw, ok := extensions[RateLimitExtension]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RateLimitExtension, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var rl RateLimit
+	if err := dec.Decode(&rl); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RateLimitExtension, err)
+	}
+	if rl.Requests <= 0 {
+		return nil, fmt.Errorf("invalid %s: requests must be positive", RateLimitExtension)
+	}
+	if rl.Burst < 0 {
+		return nil, fmt.Errorf("invalid %s: burst must not be negative", RateLimitExtension)
+	}
+	if window, err := time.ParseDuration(rl.Window); err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid %s: window %q is not a positive duration", RateLimitExtension, rl.Window)
+	}
+	return &rl, nil
+}
+
+// RouteMatchExtension is the query parameter OpenAPI extension making
+// the endpoint's route match on the parameter: `x-flowc-route-match: "2"`
+// matches ?<name>=2 only, `x-flowc-route-match: true` any value.
+const RouteMatchExtension = "x-flowc-route-match"
+
+// parseRouteMatch applies the route match extension of a query parameter
+// to parameter. Values other than true and scalars are ignored.
+func (p *OpenAPIParser) parseRouteMatch(param *openapi3.Parameter, parameter *Parameter) {
+	if param.In != "query" {
+		return
+	}
+	switch v := param.Extensions[RouteMatchExtension].(type) {
+	case bool:
+		parameter.Routing = v
+	case string:
+		parameter.Routing, parameter.RoutingValue = true, v
+	case float64, int, int64:
+		parameter.Routing, parameter.RoutingValue = true, fmt.Sprint(v)
+	}
 }
 
 // parsePathParameters extracts path parameters
@@ -314,6 +506,7 @@ func (p *OpenAPIParser) convertParameter(param *openapi3.Parameter) Parameter {
 		Required:    param.Required,
 		Deprecated:  param.Deprecated,
 	}
+	p.parseRouteMatch(param, &parameter)
 
 	if param.Schema != nil && param.Schema.Value != nil {
 		parameter.Schema = p.convertSchemaToDataType(param.Schema.Value)
@@ -343,6 +536,52 @@ func (p *OpenAPIParser) parseRequestBody(requestBody *openapi3.RequestBodyRef) *
 	return nil
 }
 
+// parseSSEEvents reads the event types of the first successful
+// text/event-stream response, in status code order. A oneOf schema
+// declares one event per alternative, named by the alternative's title or
+// component name; any other schema is a single unnamed "message" event.
+func (p *OpenAPIParser) parseSSEEvents(responses *openapi3.Responses) []EventSpec {
+	byCode := responses.Map()
+	for _, code := range slices.Sorted(maps.Keys(byCode)) {
+		if !strings.HasPrefix(code, "2") || byCode[code] == nil || byCode[code].Value == nil {
+			continue
+		}
+		mediaType, ok := byCode[code].Value.Content["text/event-stream"]
+		if !ok || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+			continue
+		}
+
+		schema := mediaType.Schema.Value
+		if len(schema.OneOf) == 0 {
+			return []EventSpec{p.sseEvent(mediaType.Schema)}
+		}
+		events := make([]EventSpec, 0, len(schema.OneOf))
+		for _, alt := range schema.OneOf {
+			if alt != nil && alt.Value != nil {
+				events = append(events, p.sseEvent(alt))
+			}
+		}
+		return events
+	}
+	return nil
+}
+
+// sseEvent converts one event schema into an EventSpec.
+func (p *OpenAPIParser) sseEvent(ref *openapi3.SchemaRef) EventSpec {
+	name := ref.Value.Title
+	if name == "" && ref.Ref != "" {
+		name = ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]
+	}
+	if name == "" {
+		name = "message"
+	}
+	return EventSpec{
+		Name:        name,
+		Description: ref.Value.Description,
+		Data:        p.convertSchemaToDataModel(ref.Value, name),
+	}
+}
+
 // parseResponses converts OpenAPI responses to IR format
 func (p *OpenAPIParser) parseResponses(responses *openapi3.Responses) []ResponseSpec {
 	responseSpecs := make([]ResponseSpec, 0)
@@ -372,6 +611,11 @@ func (p *OpenAPIParser) parseResponses(responses *openapi3.Responses) []Response
 			IsError:     code >= 400,
 		}
 
+		// Server-sent events stream for as long as the connection lives
+		if _, ok := response.Content["text/event-stream"]; ok {
+			responseSpec.Streaming = true
+		}
+
 		// Parse response body
 		if response.Content != nil {
 			for contentType, mediaType := range response.Content {
@@ -401,6 +645,11 @@ func (p *OpenAPIParser) parseResponses(responses *openapi3.Responses) []Response
 
 				if header.Schema != nil && header.Schema.Value != nil {
 					param.Schema = p.convertSchemaToDataType(header.Schema.Value)
+					param.Default = header.Schema.Value.Default
+
+					if p.options.IncludeExamples && header.Example != nil {
+						param.Example = header.Example
+					}
 				}
 
 				responseSpec.Headers = append(responseSpec.Headers, param)
@@ -635,6 +884,10 @@ func (p *OpenAPIParser) parseSecuritySchemes(spec *openapi3.T) []SecurityScheme
 			Scheme:      scheme.Scheme,
 		}
 
+		if scheme.Type == "apiKey" {
+			securityScheme.KeyName = scheme.Name
+		}
+
 		if scheme.Type == "http" && scheme.Scheme == "bearer" {
 			securityScheme.BearerFormat = scheme.BearerFormat
 		}
@@ -697,27 +950,35 @@ func (p *OpenAPIParser) parseOAuthFlows(flows *openapi3.OAuthFlows) *OAuthFlows
 	return oauthFlows
 }
 
-// parseSecurityRequirements converts OpenAPI security requirements
+// parseSecurityRequirements converts OpenAPI security requirements. Each
+// entry of the array stays a requirement of its own (OR); the schemes of
+// an entry are sorted by name (AND)
 func (p *OpenAPIParser) parseSecurityRequirements(security openapi3.SecurityRequirements) []SecurityRequirement {
-	requirements := make([]SecurityRequirement, 0)
+	requirements := make([]SecurityRequirement, 0, len(security))
 
 	for _, req := range security {
-		for name, scopes := range req {
-			requirements = append(requirements, SecurityRequirement{
+		requirement := SecurityRequirement{}
+		for _, name := range slices.Sorted(maps.Keys(req)) {
+			requirement.Schemes = append(requirement.Schemes, SecuritySchemeRequirement{
 				Name:   name,
-				Scopes: scopes,
+				Scopes: req[name],
 			})
 		}
+		requirements = append(requirements, requirement)
 	}
 
 	return requirements
 }
 
-// parseServers extracts server information
-func (p *OpenAPIParser) parseServers(spec *openapi3.T) []Server {
-	servers := make([]Server, 0, len(spec.Servers))
+// parseServers extracts server information from the root, a path, or an
+// operation
+func (p *OpenAPIParser) parseServers(specServers openapi3.Servers) []Server {
+	if len(specServers) == 0 {
+		return nil
+	}
+	servers := make([]Server, 0, len(specServers))
 
-	for _, server := range spec.Servers {
+	for _, server := range specServers {
 		if server == nil {
 			continue
 		}
@@ -0,0 +1,377 @@
+package ir
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// Two independent problems: info.version is missing, and GET /pets/{id}
+// does not declare its path parameter.
+const invalidOpenAPISpec = `openapi: 3.0.0
+info:
+  title: Petstore
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+  /pets/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+
+func TestOpenAPIValidateReportsAllIssues(t *testing.T) {
+	err := NewOpenAPIParser().Validate(context.Background(), []byte(invalidOpenAPISpec))
+
+	var issues ValidationIssues
+	if !errors.As(err, &issues) {
+		t.Fatalf("expected ValidationIssues, got %T: %v", err, err)
+	}
+
+	want := map[string]bool{"/info": false, "/paths/~1pets~1{id}": false}
+	for _, issue := range issues {
+		if _, ok := want[issue.Pointer]; ok {
+			want[issue.Pointer] = true
+		}
+		if issue.Severity != ValidationSeverityError {
+			t.Errorf("issue %+v: severity = %q, want %q", issue, issue.Severity, ValidationSeverityError)
+		}
+		if issue.Message == "" {
+			t.Errorf("issue %+v has no message", issue)
+		}
+	}
+	for pointer, found := range want {
+		if !found {
+			t.Errorf("missing issue at %s; got %+v", pointer, issues)
+		}
+	}
+}
+
+func TestOpenAPIParseStrictReturnsIssues(t *testing.T) {
+	parser := NewOpenAPIParser().WithOptions(&ParseOptions{Strict: true, Validate: true})
+	_, err := parser.Parse(context.Background(), []byte(invalidOpenAPISpec))
+
+	var issues ValidationIssues
+	if !errors.As(err, &issues) {
+		t.Fatalf("expected ValidationIssues, got %T: %v", err, err)
+	}
+	if len(issues) < 2 {
+		t.Errorf("expected at least 2 issues, got %+v", issues)
+	}
+}
+
+func TestOpenAPIValidateNoOperations(t *testing.T) {
+	const emptyPaths = `openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+paths: {}
+`
+	want := ValidationIssue{Pointer: "/paths", Message: "specification defines no operations", Severity: ValidationSeverityWarning}
+	var issues ValidationIssues
+	if err := NewOpenAPIParser().Validate(context.Background(), []byte(emptyPaths)); !errors.As(err, &issues) {
+		t.Fatalf("expected ValidationIssues, got %T: %v", err, err)
+	}
+	if len(issues) != 1 || issues[0] != want {
+		t.Errorf("issues = %+v, want only %+v", issues, want)
+	}
+
+	// Strict mode turns the warning into an error that fails Parse.
+	strict := NewOpenAPIParser().WithOptions(&ParseOptions{Strict: true, Validate: true})
+	_, err := strict.Parse(context.Background(), []byte(emptyPaths))
+	want.Severity = ValidationSeverityError
+	if !errors.As(err, &issues) || len(issues) != 1 || issues[0] != want {
+		t.Errorf("strict Parse: err = %v, want only %+v", err, want)
+	}
+}
+
+func TestOpenAPIParseEndpointRateLimit(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Search
+  version: 1.0.0
+paths:
+  /search:
+    get:
+      x-flowc-rate-limit:
+        requests: 10
+        window: 1m
+        burst: 5
+      responses:
+        "200":
+          description: ok
+  /items:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+	api, err := NewOpenAPIParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for _, ep := range api.Endpoints {
+		switch ep.Path.Pattern {
+		case "/search":
+			if ep.RateLimit == nil || *ep.RateLimit != (RateLimit{Requests: 10, Window: "1m", Burst: 5}) {
+				t.Errorf("/search rate limit = %+v, want 10 per 1m with burst 5", ep.RateLimit)
+			}
+		case "/items":
+			if ep.RateLimit != nil {
+				t.Errorf("/items rate limit = %+v, want nil", ep.RateLimit)
+			}
+		}
+	}
+}
+
+func TestOpenAPIParseRejectsMalformedRateLimit(t *testing.T) {
+	for name, limit := range map[string]string{
+		"not an object":   `"10/m"`,
+		"string requests": `{requests: ten, window: 1m}`,
+		"zero requests":   `{requests: 0, window: 1m}`,
+		"negative burst":  `{requests: 10, window: 1m, burst: -1}`,
+		"bad window":      `{requests: 10, window: soon}`,
+		"unknown field":   `{request: 10, window: 1m}`,
+	} {
+		spec := `openapi: 3.0.0
+info:
+  title: Search
+  version: 1.0.0
+paths:
+  /search:
+    get:
+      x-flowc-rate-limit: ` + limit + `
+      responses:
+        "200":
+          description: ok
+`
+		if _, err := NewOpenAPIParser().Parse(context.Background(), []byte(spec)); err == nil {
+			t.Errorf("%s: Parse succeeded, want error", name)
+		}
+		var issues ValidationIssues
+		err := NewOpenAPIParser().Validate(context.Background(), []byte(spec))
+		if !errors.As(err, &issues) || len(issues) != 1 || issues[0].Pointer != "/paths/~1search/get/x-flowc-rate-limit" {
+			t.Errorf("%s: Validate = %v, want one x-flowc-rate-limit issue", name, err)
+		}
+	}
+}
+
+func TestOpenAPIParseEndpointServers(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Shop
+  version: 1.0.0
+servers:
+  - url: https://shop.example.com
+paths:
+  /orders:
+    servers:
+      - url: https://orders.internal
+    get:
+      responses:
+        "200":
+          description: ok
+    post:
+      servers:
+        - url: https://{region}.writes.internal
+          variables:
+            region:
+              default: eu
+      responses:
+        "200":
+          description: ok
+  /items:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+	api, err := NewOpenAPIParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(api.Servers) != 1 || api.Servers[0].URL != "https://shop.example.com" {
+		t.Errorf("API servers = %+v, want the root server", api.Servers)
+	}
+	want := map[string]string{
+		"GET /orders":  "https://orders.internal",
+		"POST /orders": "https://{region}.writes.internal",
+		"GET /items":   "",
+	}
+	for _, ep := range api.Endpoints {
+		key := ep.Method + " " + ep.Path.Pattern
+		got := ""
+		if len(ep.Servers) > 0 {
+			got = ep.Servers[0].URL
+		}
+		if got != want[key] {
+			t.Errorf("%s server = %q, want %q", key, got, want[key])
+		}
+	}
+}
+
+func TestOpenAPIParseEventStreamResponse(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Events
+  version: 1.0.0
+paths:
+  /events:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            text/event-stream:
+              schema:
+                type: string
+  /items:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+`
+	api, err := NewOpenAPIParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for _, ep := range api.Endpoints {
+		if got, want := ep.Responses[0].Streaming, ep.Path.Pattern == "/events"; got != want {
+			t.Errorf("%s streaming = %v, want %v", ep.Path.Pattern, got, want)
+		}
+	}
+}
+
+func TestOpenAPIParseSSEEvents(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Events
+  version: 1.0.0
+paths:
+  /pets/events:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            text/event-stream:
+              schema:
+                oneOf:
+                  - $ref: "#/components/schemas/PetAdded"
+                  - title: pet-removed
+                    type: object
+                    properties:
+                      id:
+                        type: string
+  /ticks:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            text/event-stream:
+              schema:
+                type: string
+components:
+  schemas:
+    PetAdded:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	api, err := NewOpenAPIParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	events := map[string][]string{}
+	for _, ep := range api.Endpoints {
+		if ep.Type != EndpointTypeSSE {
+			t.Errorf("%s type = %q, want %q", ep.Path.Pattern, ep.Type, EndpointTypeSSE)
+		}
+		for _, ev := range ep.Events {
+			events[ep.Path.Pattern] = append(events[ep.Path.Pattern], ev.Name)
+			if ev.Data == nil {
+				t.Errorf("%s event %s has no data model", ep.Path.Pattern, ev.Name)
+			}
+		}
+	}
+	if got := events["/pets/events"]; !slices.Equal(got, []string{"PetAdded", "pet-removed"}) {
+		t.Errorf("/pets/events events = %v, want [PetAdded pet-removed]", got)
+	}
+	if got := events["/ticks"]; !slices.Equal(got, []string{"message"}) {
+		t.Errorf("/ticks events = %v, want [message]", got)
+	}
+}
+
+func TestOpenAPIParseSecurityAlternatives(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Reports
+  version: 1.0.0
+paths:
+  /reports:
+    get:
+      security:
+        - oauth: [reports:read]
+          bearer: []
+        - apiKey: []
+      responses:
+        "200":
+          description: ok
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    bearer:
+      type: http
+      scheme: bearer
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes:
+            reports:read: read reports
+`
+	// (oauth AND bearer) OR apiKey, with the schemes of an entry by name.
+	want := []SecurityRequirement{
+		{Schemes: []SecuritySchemeRequirement{{Name: "bearer", Scopes: []string{}}, {Name: "oauth", Scopes: []string{"reports:read"}}}},
+		{Schemes: []SecuritySchemeRequirement{{Name: "apiKey", Scopes: []string{}}}},
+	}
+
+	ctx := context.Background()
+	api, err := NewOpenAPIParser().Parse(ctx, []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := api.Endpoints[0].Security; !reflect.DeepEqual(got, want) {
+		t.Errorf("security = %+v, want %+v", got, want)
+	}
+
+	// The groups survive a round trip through the exported document.
+	data, err := ToOpenAPI(api, "/")
+	if err != nil {
+		t.Fatalf("ToOpenAPI: %v", err)
+	}
+	again, err := NewOpenAPIParser().Parse(ctx, data)
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	if got := again.Endpoints[0].Security; !reflect.DeepEqual(got, want) {
+		t.Errorf("re-parsed security = %+v, want %+v", got, want)
+	}
+}
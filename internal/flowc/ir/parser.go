@@ -3,6 +3,7 @@ package ir
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 // Parser is the interface that all API spec parsers must implement
@@ -121,3 +122,37 @@ func DefaultParseOptions() *ParseOptions {
 		Context:           make(map[string]any),
 	}
 }
+
+// ValidationSeverity classifies a ValidationIssue.
+type ValidationSeverity string
+
+const (
+	ValidationSeverityError   ValidationSeverity = "error"
+	ValidationSeverityWarning ValidationSeverity = "warning"
+)
+
+// ValidationIssue is a single problem found while validating a spec.
+// Pointer is a JSON pointer (RFC 6901) to the offending node; empty when
+// the problem concerns the document as a whole.
+type ValidationIssue struct {
+	Pointer  string             `json:"pointer"`
+	Message  string             `json:"message"`
+	Severity ValidationSeverity `json:"severity"`
+}
+
+// ValidationIssues is the error returned by parsers that report every
+// validation problem rather than the first. Use errors.As to recover it.
+type ValidationIssues []ValidationIssue
+
+// Error implements the error interface.
+func (v ValidationIssues) Error() string {
+	msgs := make([]string, len(v))
+	for i, issue := range v {
+		if issue.Pointer == "" {
+			msgs[i] = issue.Message
+			continue
+		}
+		msgs[i] = issue.Pointer + ": " + issue.Message
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -142,7 +142,12 @@ type Endpoint struct {
 	// Response specification(s)
 	Responses []ResponseSpec `json:"responses,omitempty" yaml:"responses,omitempty"`
 
-	// Security requirements for this endpoint
+	// Server-sent events emitted on the stream (SSE endpoints only)
+	Events []EventSpec `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Security requirements for this endpoint. Any one requirement
+	// authorizes a request (OR); within a requirement every scheme must be
+	// satisfied (AND)
 	Security []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
 
 	// Tags for organization
@@ -157,6 +162,10 @@ type Endpoint struct {
 	// Rate limit configuration specific to this endpoint
 	RateLimit *RateLimit `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
 
+	// Servers overriding the API's servers for this endpoint (OpenAPI
+	// path- or operation-level servers)
+	Servers []Server `json:"servers,omitempty" yaml:"servers,omitempty"`
+
 	// Extensions for endpoint-specific features
 	Extensions map[string]any `json:"extensions,omitempty" yaml:"extensions,omitempty"`
 }
@@ -245,6 +254,18 @@ type ResponseSpec struct {
 	IsError bool `json:"is_error,omitempty" yaml:"is_error,omitempty"`
 }
 
+// EventSpec describes one event type on a server-sent events stream
+type EventSpec struct {
+	// Event name, as sent in the SSE "event:" field ("message" when unnamed)
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the event
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Schema of the event's "data:" payload
+	Data *DataModel `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
 // Parameter represents a parameter (path, query, header, etc.)
 type Parameter struct {
 	// Name of the parameter
@@ -270,6 +291,11 @@ type Parameter struct {
 
 	// Deprecated flag
 	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+
+	// Whether the endpoint's route matches on this query parameter: only
+	// requests carrying it, with RoutingValue when that is set, match
+	Routing      bool   `json:"routing,omitempty" yaml:"routing,omitempty"`
+	RoutingValue string `json:"routing_value,omitempty" yaml:"routing_value,omitempty"`
 }
 
 // ParameterLocation defines where a parameter is located
@@ -395,6 +421,9 @@ type SecurityScheme struct {
 	// For apiKey: location (query, header, cookie)
 	In string `json:"in,omitempty" yaml:"in,omitempty"`
 
+	// For apiKey: name of the header, query parameter or cookie
+	KeyName string `json:"key_name,omitempty" yaml:"key_name,omitempty"`
+
 	// For http: scheme (basic, bearer, etc.)
 	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
 
@@ -424,8 +453,17 @@ type OAuthFlow struct {
 	Scopes           map[string]string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
 }
 
-// SecurityRequirement specifies security requirements for an endpoint
+// SecurityRequirement is one alternative way of satisfying an endpoint's
+// security: every scheme it lists must be satisfied. A requirement with no
+// schemes allows anonymous access
 type SecurityRequirement struct {
+	// Schemes that must all be satisfied
+	Schemes []SecuritySchemeRequirement `json:"schemes,omitempty" yaml:"schemes,omitempty"`
+}
+
+// SecuritySchemeRequirement names a security scheme and the scopes it
+// requires
+type SecuritySchemeRequirement struct {
 	// Name of the security scheme
 	Name string `json:"name" yaml:"name"`
 
@@ -0,0 +1,140 @@
+// Package janitor garbage-collects ephemeral gateways: those with a ttl
+// (GatewaySpec.TTL) that has elapsed while no Envoy is connected.
+//
+// The janitor only deletes from the store. The reconciler sees the
+// deletions like any other and drops the gateway's xDS snapshot.
+package janitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	xdsserver "github.com/flowc-labs/flowc/internal/flowc/xds/server"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// StreamLister lists the open xDS streams of a node. Implemented by
+// xds/server.StreamTracker.
+type StreamLister interface {
+	ListStreams(nodeID string) []xdsserver.StreamInfo
+}
+
+// Janitor deletes gateways whose ttl has elapsed, with their listeners,
+// deployments and gateway policies, unless an Envoy is still connected to them.
+type Janitor struct {
+	store   store.Store
+	streams StreamLister
+	log     *logger.EnvoyLogger
+	now     func() time.Time
+}
+
+// New returns a janitor over s. streams tells live gateways apart; log
+// may be nil.
+func New(s store.Store, streams StreamLister, log *logger.EnvoyLogger) *Janitor {
+	return &Janitor{store: s, streams: streams, log: log, now: time.Now}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Sweep(ctx); err != nil && j.log != nil {
+				j.log.WithError(err).Error("Gateway garbage collection failed")
+			}
+		}
+	}
+}
+
+// Sweep deletes the gateways that have expired (created longer than their
+// ttl ago) and have no open xDS stream, and returns their names. A
+// gateway whose deletion fails is retried on the next sweep.
+func (j *Janitor) Sweep(ctx context.Context) ([]string, error) {
+	gateways, err := j.store.List(ctx, store.ListFilter{Kind: "Gateway"})
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	var errs []error
+	for _, gw := range gateways {
+		var spec flowcv1alpha1.GatewaySpec
+		if err := json.Unmarshal(gw.SpecJSON, &spec); err != nil || spec.TTL == "" {
+			continue
+		}
+		ttl, err := time.ParseDuration(spec.TTL)
+		if err != nil || ttl <= 0 {
+			continue
+		}
+		if j.now().Before(gw.Meta.CreatedAt.Add(ttl)) || len(j.streams.ListStreams(spec.NodeID)) > 0 {
+			continue
+		}
+		if err := j.deleteGateway(ctx, gw.Meta.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, gw.Meta.Name)
+		if j.log != nil {
+			j.log.WithFields(map[string]any{
+				"gateway": gw.Meta.Name,
+				"node_id": spec.NodeID,
+				"ttl":     spec.TTL,
+			}).Info("Removed expired gateway")
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// deleteGateway deletes gateway name after its deployments, listeners
+// and gateway policies, so nothing is left referencing it.
+func (j *Janitor) deleteGateway(ctx context.Context, name string) error {
+	if err := deleteWhere(ctx, j, "Deployment", func(spec *flowcv1alpha1.DeploymentSpec) bool {
+		return spec.Gateway.Name == name
+	}); err != nil {
+		return err
+	}
+	if err := deleteWhere(ctx, j, "Listener", func(spec *flowcv1alpha1.ListenerSpec) bool {
+		return spec.GatewayRef == name
+	}); err != nil {
+		return err
+	}
+	if err := deleteWhere(ctx, j, "GatewayPolicy", func(spec *flowcv1alpha1.GatewayPolicySpec) bool {
+		return spec.TargetRef.Kind == "Gateway" && spec.TargetRef.Name == name
+	}); err != nil {
+		return err
+	}
+	return j.delete(ctx, store.ResourceKey{Kind: "Gateway", Name: name})
+}
+
+// deleteWhere deletes the resources of kind whose spec, decoded as S,
+// matches.
+func deleteWhere[S any](ctx context.Context, j *Janitor, kind string, match func(*S) bool) error {
+	resources, err := j.store.List(ctx, store.ListFilter{Kind: kind})
+	if err != nil {
+		return err
+	}
+	for _, res := range resources {
+		var spec S
+		if json.Unmarshal(res.SpecJSON, &spec) == nil && match(&spec) {
+			if err := j.delete(ctx, res.Key()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// delete deletes key, already gone counting as done.
+func (j *Janitor) delete(ctx context.Context, key store.ResourceKey) error {
+	if err := j.store.Delete(ctx, key, store.DeleteOptions{}); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
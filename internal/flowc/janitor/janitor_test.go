@@ -0,0 +1,89 @@
+package janitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	xdsserver "github.com/flowc-labs/flowc/internal/flowc/xds/server"
+)
+
+// fakeStreams reports one open stream for each connected node.
+type fakeStreams map[string]bool
+
+func (f fakeStreams) ListStreams(nodeID string) []xdsserver.StreamInfo {
+	if f[nodeID] {
+		return []xdsserver.StreamInfo{{ID: 1, NodeID: nodeID}}
+	}
+	return nil
+}
+
+func put(t *testing.T, s store.Store, kind, name, spec string) {
+	t.Helper()
+	_, err := s.Put(context.Background(), &store.StoredResource{
+		Meta:     store.StoreMeta{Kind: kind, Name: name},
+		SpecJSON: json.RawMessage(spec),
+	}, store.PutOptions{})
+	if err != nil {
+		t.Fatalf("put %s/%s: %v", kind, name, err)
+	}
+}
+
+func exists(t *testing.T, s store.Store, kind, name string) bool {
+	t.Helper()
+	_, err := s.Get(context.Background(), store.ResourceKey{Kind: kind, Name: name})
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("get %s/%s: %v", kind, name, err)
+	}
+	return err == nil
+}
+
+func TestSweepRemovesExpiredDisconnectedGateways(t *testing.T) {
+	s := store.NewMemoryStore()
+	put(t, s, "Gateway", "preview", `{"nodeId":"preview-node","ttl":"1h"}`)
+	put(t, s, "Listener", "preview-http", `{"gatewayRef":"preview","port":8080}`)
+	put(t, s, "Deployment", "preview-pets", `{"apiRef":"pets","gateway":{"name":"preview"}}`)
+	put(t, s, "GatewayPolicy", "preview-limits", `{"targetRef":{"kind":"Gateway","name":"preview"}}`)
+	put(t, s, "Gateway", "live", `{"nodeId":"live-node","ttl":"1h"}`)
+	put(t, s, "Gateway", "fresh", `{"nodeId":"fresh-node","ttl":"48h"}`)
+	put(t, s, "Gateway", "permanent", `{"nodeId":"permanent-node"}`)
+	put(t, s, "Listener", "permanent-http", `{"gatewayRef":"permanent","port":8080}`)
+	put(t, s, "GatewayPolicy", "permanent-limits", `{"targetRef":{"kind":"Gateway","name":"permanent"}}`)
+	put(t, s, "GatewayPolicy", "preview-listener", `{"targetRef":{"kind":"Listener","name":"preview"}}`)
+
+	j := New(s, fakeStreams{"live-node": true}, nil)
+	j.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	removed, err := j.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "preview" {
+		t.Errorf("removed = %v, want [preview]", removed)
+	}
+
+	for _, gone := range []store.ResourceKey{
+		{Kind: "Gateway", Name: "preview"},
+		{Kind: "Listener", Name: "preview-http"},
+		{Kind: "Deployment", Name: "preview-pets"},
+		{Kind: "GatewayPolicy", Name: "preview-limits"},
+	} {
+		if exists(t, s, gone.Kind, gone.Name) {
+			t.Errorf("%s still in the store", gone)
+		}
+	}
+	for _, kept := range []store.ResourceKey{
+		{Kind: "Gateway", Name: "live"},
+		{Kind: "Gateway", Name: "fresh"},
+		{Kind: "Gateway", Name: "permanent"},
+		{Kind: "Listener", Name: "permanent-http"},
+		{Kind: "GatewayPolicy", Name: "permanent-limits"},
+		{Kind: "GatewayPolicy", Name: "preview-listener"},
+	} {
+		if !exists(t, s, kept.Kind, kept.Name) {
+			t.Errorf("%s was removed", kept)
+		}
+	}
+}
@@ -79,6 +79,14 @@ type Listener struct {
 	// AccessLog is the path for access logs (stdout, stderr, or file path)
 	AccessLog string `json:"access_log,omitempty"`
 
+	// AllowedMethods restricts the HTTP methods served on the listener;
+	// others are answered with 405. Empty allows every method.
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+
+	// HTTPSRedirectPort, when set, redirects requests made over plain HTTP
+	// to https on this port with a 301.
+	HTTPSRedirectPort uint32 `json:"https_redirect_port,omitempty"`
+
 	// CreatedAt is the timestamp when the listener was created
 	CreatedAt time.Time `json:"created_at"`
 
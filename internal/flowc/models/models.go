@@ -9,14 +9,18 @@ import (
 // APIDeployment represents a complete API deployment
 // This is the persisted model - IR is NOT stored here (it's transient for translation only)
 type APIDeployment struct {
-	ID        string              `json:"id"`
-	Name      string              `json:"name"`
-	Version   string              `json:"version"`
-	Context   string              `json:"context"`
-	Status    string              `json:"status"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
-	Metadata  types.FlowCMetadata `json:"metadata"`
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Context string            `json:"context"`
+	Status  string            `json:"status"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// ResourcePrefix starts the names of the deployment's clusters so they
+	// stay apart from other deployments of the same API
+	ResourcePrefix string              `json:"resource_prefix,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	Metadata       types.FlowCMetadata `json:"metadata"`
 }
 
 // DeploymentStatus represents the status of an API deployment
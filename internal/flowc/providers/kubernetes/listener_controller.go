@@ -16,6 +16,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	listenerbuilder "github.com/flowc-labs/flowc/internal/flowc/xds/resources/listener"
 )
 
 const (
@@ -156,6 +157,11 @@ func validateListenerSpec(spec *flowcv1alpha1.ListenerSpec) error {
 	if spec.Port == 0 || spec.Port > 65535 {
 		return fmt.Errorf("spec.port must be in [1, 65535] (got %d)", spec.Port)
 	}
+	for i, hostname := range spec.Hostnames {
+		if err := listenerbuilder.ValidateHostname(hostname); err != nil {
+			return fmt.Errorf("spec.hostnames[%d] %q is invalid: %v", i, hostname, err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// BundleHandler serves the ZIP bundles kept by a BundleStore.
+type BundleHandler struct {
+	bundles store.BundleStore
+}
+
+// NewBundleHandler creates a new bundle handler.
+func NewBundleHandler(bundles store.BundleStore) *BundleHandler {
+	return &BundleHandler{bundles: bundles}
+}
+
+// HandleGetBundle handles GET /api/v1/deployments/{name}/bundle
+// Returns the ZIP bundle the deployment was uploaded from.
+func (h *BundleHandler) HandleGetBundle(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if h.bundles == nil {
+		httputil.WriteError(w, http.StatusNotFound, "no bundle stored for deployment "+name)
+		return
+	}
+
+	data, err := h.bundles.Get(r.Context(), name)
+	if err != nil {
+		if isNotFound(err) {
+			httputil.WriteError(w, http.StatusNotFound, "no bundle stored for deployment "+name)
+			return
+		}
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
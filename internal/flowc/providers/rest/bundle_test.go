@@ -0,0 +1,286 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
+	"github.com/flowc-labs/flowc/internal/flowc/index"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+func TestUploadedBundleCanBeDownloaded(t *testing.T) {
+	bundles := store.NewMemoryBundleStore()
+	zipData := makeZip(t, testFlowCYAML+"gateway:\n  gateway_id: edge\n  port: 10000\n", testOpenAPIYAML)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "bundle.zip")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	if _, err := fw.Write(zipData); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	NewUploadHandler(store.NewMemoryStore(), bundles, nil).HandleUpload(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upload status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	h := NewBundleHandler(bundles)
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/deployments/petstore-deploy/bundle", nil)
+	req.SetPathValue("name", "petstore-deploy")
+	rec = httptest.NewRecorder()
+	h.HandleGetBundle(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("download status = %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), zipData) {
+		t.Error("downloaded bundle differs from the uploaded one")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/deployments/missing/bundle", nil)
+	req.SetPathValue("name", "missing")
+	rec = httptest.NewRecorder()
+	h.HandleGetBundle(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing bundle status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRollbackRedeploysPreviousBundle(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	bundles := store.NewMemoryBundleStore()
+	h := NewUploadHandler(s, bundles, nil)
+	gateway := "gateway:\n  gateway_id: edge\n  port: 10000\n"
+	v1 := makeZip(t, testFlowCYAML+gateway, testOpenAPIYAML)
+	v2 := makeZip(t, strings.Replace(testFlowCYAML, "petstore.local", "petstore-v2.local", 1)+gateway, testOpenAPIYAML)
+
+	rollback := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/deployments/petstore-deploy/rollback", nil)
+		req.SetPathValue("name", "petstore-deploy")
+		rec := httptest.NewRecorder()
+		h.HandleRollback(rec, req)
+		return rec
+	}
+	upstreamHost := func() string {
+		res, err := s.Get(ctx, store.ResourceKey{Kind: "API", Name: "petstore"})
+		if err != nil {
+			t.Fatalf("Get API: %v", err)
+		}
+		var spec flowcv1alpha1.APISpec
+		if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+			t.Fatalf("decode API spec: %v", err)
+		}
+		return spec.Upstream.Host
+	}
+
+	if _, err := h.Upload(ctx, v1, "upload"); err != nil {
+		t.Fatalf("upload v1: %v", err)
+	}
+	if rec := rollback(); rec.Code != http.StatusConflict {
+		t.Fatalf("rollback without previous bundle: status = %d, want 409 (body %s)", rec.Code, rec.Body)
+	}
+
+	if _, err := h.Upload(ctx, v2, "upload"); err != nil {
+		t.Fatalf("upload v2: %v", err)
+	}
+	if got := upstreamHost(); got != "petstore-v2.local" {
+		t.Fatalf("upstream host after v2 = %q, want petstore-v2.local", got)
+	}
+
+	if rec := rollback(); rec.Code != http.StatusOK {
+		t.Fatalf("rollback: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got := upstreamHost(); got != "petstore.local" {
+		t.Errorf("upstream host after rollback = %q, want petstore.local", got)
+	}
+	if current, err := bundles.Get(ctx, "petstore-deploy"); err != nil || !bytes.Equal(current, v1) {
+		t.Errorf("current bundle after rollback is not v1 (err %v)", err)
+	}
+	if previous, _, err := bundles.Previous(ctx, "petstore-deploy"); err != nil || !bytes.Equal(previous, v2) {
+		t.Errorf("previous bundle after rollback is not v2 (err %v)", err)
+	}
+}
+
+func TestRollbackResolvesTemplatedBundle(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	bundles := store.NewMemoryBundleStore()
+	h := NewUploadHandler(s, bundles, nil)
+	gateway := "gateway:\n  gateway_id: edge\n  port: 10000\n"
+	// UPSTREAM_HOST has no default, so the bundle cannot be applied
+	// without the variables it was uploaded with.
+	v1 := makeZip(t, strings.Replace(testFlowCYAML, "petstore.local", "${UPSTREAM_HOST}", 1)+gateway, testOpenAPIYAML)
+	v2 := makeZip(t, strings.Replace(testFlowCYAML, "petstore.local", "petstore-v2.local", 1)+gateway, testOpenAPIYAML)
+
+	if _, err := h.UploadWithVariables(ctx, v1, map[string]string{"UPSTREAM_HOST": "petstore.staging.internal"}, "upload"); err != nil {
+		t.Fatalf("upload v1: %v", err)
+	}
+	if _, err := h.Upload(ctx, v2, "upload"); err != nil {
+		t.Fatalf("upload v2: %v", err)
+	}
+
+	if _, err := h.Rollback(ctx, "petstore-deploy", "upload"); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	res, err := s.Get(ctx, store.ResourceKey{Kind: "API", Name: "petstore"})
+	if err != nil {
+		t.Fatalf("Get API: %v", err)
+	}
+	var spec flowcv1alpha1.APISpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode API spec: %v", err)
+	}
+	if spec.Upstream.Host != "petstore.staging.internal" {
+		t.Errorf("upstream host after rollback = %q, want petstore.staging.internal", spec.Upstream.Host)
+	}
+
+	// Rolling forward again applies v2, which has no variables.
+	if _, err := h.Rollback(ctx, "petstore-deploy", "upload"); err != nil {
+		t.Fatalf("second Rollback: %v", err)
+	}
+}
+
+func TestUploadToPortWithoutListener(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge"}`, "rest")
+	h := NewUploadHandler(s, nil, nil)
+	bundleOn := func(port string) []byte {
+		return makeZip(t, testFlowCYAML+"gateway:\n  gateway_id: edge\n  port: "+port+"\n", testOpenAPIYAML)
+	}
+
+	_, err := h.Upload(ctx, bundleOn("9000"), "upload")
+	if !errors.Is(err, ErrNoListener) || !strings.Contains(err.Error(), `gateway "edge" has no listeners; none on 9000`) {
+		t.Errorf("upload to a gateway without listeners: err = %v", err)
+	}
+
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "https"}, `{"gatewayRef":"edge","port":8443}`, "rest")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8080}`, "rest")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "other"}, `{"gatewayRef":"internal","port":9000}`, "rest")
+	_, err = h.Upload(ctx, bundleOn("9000"), "upload")
+	if !errors.Is(err, ErrNoListener) || !strings.Contains(err.Error(), "has listeners on ports [8080,8443]; none on 9000") {
+		t.Errorf("upload to port 9000: err = %v", err)
+	}
+	if _, err := s.Get(ctx, store.ResourceKey{Kind: "API", Name: "petstore"}); !isNotFound(err) {
+		t.Errorf("API stored by a rejected upload (err %v)", err)
+	}
+
+	// A port with a listener deploys to it, whatever its name.
+	if _, err := h.Upload(ctx, bundleOn("8443"), "upload"); err != nil {
+		t.Fatalf("upload to port 8443: %v", err)
+	}
+	res, err := s.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: "petstore-deploy"})
+	if err != nil {
+		t.Fatalf("Get deployment: %v", err)
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode deployment spec: %v", err)
+	}
+	if spec.Gateway.Listener != "https" {
+		t.Errorf("deployment listener = %q, want https", spec.Gateway.Listener)
+	}
+}
+
+func TestUploadResolvesVariables(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge"}`, "rest")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8080}`, "rest")
+	h := NewUploadHandler(s, nil, nil)
+	flowcYAML := strings.Replace(testFlowCYAML, "petstore.local", "${UPSTREAM_HOST}", 1) +
+		"gateway:\n  gateway_id: edge\n  port: ${PORT:-8080}\n"
+	zipData := makeZip(t, flowcYAML, testOpenAPIYAML)
+
+	_, err := h.Upload(ctx, zipData, "upload")
+	if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), "unresolved variables in flowc.yaml: UPSTREAM_HOST") {
+		t.Fatalf("upload without variables: err = %v", err)
+	}
+
+	// A value that would add keys to flowc.yaml instead of filling in the
+	// host is rejected rather than spliced in.
+	injected := map[string]string{"UPSTREAM_HOST": "a\nupstream:\n  host: evil"}
+	_, err = h.UploadWithVariables(ctx, zipData, injected, "upload")
+	if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), "not plain YAML scalars: UPSTREAM_HOST") {
+		t.Fatalf("upload with a multi-line variable: err = %v", err)
+	}
+	if _, err := s.Get(ctx, store.ResourceKey{Kind: "API", Name: "petstore"}); !isNotFound(err) {
+		t.Errorf("API stored by a rejected upload (err %v)", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "bundle.zip")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	if _, err := fw.Write(zipData); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := mw.WriteField("variables", `{"UPSTREAM_HOST":"petstore.staging.internal"}`); err != nil {
+		t.Fatalf("write variables: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleUpload(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upload status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	idx := index.New(nil)
+	if err := idx.Bootstrap(ctx, s); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	cm := cache.NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	gt := dispatch.NewGatewayTranslator(idx, cm, ir.DefaultParserRegistry(), dispatch.DefaultListener{}, nil)
+	if err := gt.Translate(ctx, index.AffectedTask{Kind: "Gateway", Name: "edge"}); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	snap, err := cm.GetSnapshot("edge")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	var addrs []string
+	for _, res := range snap.GetResources(resourcev3.ClusterType) {
+		for _, eps := range res.(*clusterv3.Cluster).GetLoadAssignment().GetEndpoints() {
+			for _, lb := range eps.LbEndpoints {
+				addrs = append(addrs, lb.GetEndpoint().GetAddress().GetSocketAddress().GetAddress())
+			}
+		}
+	}
+	if len(addrs) != 1 || addrs[0] != "petstore.staging.internal" {
+		t.Errorf("cluster addresses = %v, want [petstore.staging.internal]", addrs)
+	}
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// CanaryHistoryAnnotation records each canary weight step on the
+// Deployment as a JSON list of CanaryStep.
+const CanaryHistoryAnnotation = "flowc.io/canary-history"
+
+// ErrNotCanary is returned when a weight is set on a Deployment whose
+// strategy is not canary.
+var ErrNotCanary = errors.New("deployment strategy is not canary")
+
+// CanaryStep is one entry in a Deployment's canary history.
+type CanaryStep struct {
+	Weight int       `json:"weight"`
+	Time   time.Time `json:"time"`
+}
+
+// SetCanaryWeight moves the canary of Deployment name to weight percent of
+// traffic. The reconciler picks up the stored change and re-translates
+// the weighted routes.
+func (h *ResourceHandler) SetCanaryWeight(ctx context.Context, name string, weight int) (*store.StoredResource, error) {
+	if weight < 0 || weight > 100 {
+		return nil, fmt.Errorf("canary weight must be between 0 and 100, got %d", weight)
+	}
+
+	res, err := h.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		return nil, fmt.Errorf("decode deployment spec: %w", err)
+	}
+	if spec.Strategy == nil || spec.Strategy.Deployment == nil || spec.Strategy.Deployment.Type != "canary" {
+		return nil, ErrNotCanary
+	}
+	if spec.Strategy.Deployment.Canary == nil {
+		spec.Strategy.Deployment.Canary = &flowcv1alpha1.CanaryConfig{}
+	}
+	if err := h.limiter.Allow(spec.Gateway.Name); err != nil {
+		return nil, err
+	}
+	spec.Strategy.Deployment.Canary.CanaryWeight = weight
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encode deployment spec: %w", err)
+	}
+
+	var history []CanaryStep
+	if raw := res.Meta.Annotations[CanaryHistoryAnnotation]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &history)
+	}
+	history = append(history, CanaryStep{Weight: weight, Time: time.Now().UTC()})
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return nil, fmt.Errorf("encode canary history: %w", err)
+	}
+
+	updated := res.Clone()
+	updated.SpecJSON = specJSON
+	if updated.Meta.Annotations == nil {
+		updated.Meta.Annotations = make(map[string]string)
+	}
+	updated.Meta.Annotations[CanaryHistoryAnnotation] = string(historyJSON)
+
+	return h.store.Put(ctx, updated, store.PutOptions{ExpectedRevision: res.Meta.Revision})
+}
+
+// HandleSetCanaryWeight handles PUT /api/v1/deployments/{name}/canary
+// with a body of {"weight": N}.
+func (h *ResourceHandler) HandleSetCanaryWeight(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	body, err := httputil.ReadBody(r)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req struct {
+		Weight *int `json:"weight"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Weight == nil {
+		httputil.WriteError(w, http.StatusBadRequest, "weight is required")
+		return
+	}
+	if *req.Weight < 0 || *req.Weight > 100 {
+		httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("canary weight must be between 0 and 100, got %d", *req.Weight))
+		return
+	}
+
+	out, err := h.SetCanaryWeight(r.Context(), name, *req.Weight)
+	if errors.Is(err, ErrNotCanary) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	writeResourceResponse(w, r, http.StatusOK, "Deployment", out)
+}
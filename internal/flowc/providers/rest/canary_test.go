@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// canaryDeployment is the spec of a canary deployment of API petstore
+// at weight 0.
+const canaryDeployment = `{"apiRef":"petstore","gateway":{"name":"gw"},"strategy":{"deployment":{"type":"canary","canary":{"canaryWeight":0}}}}`
+
+func setWeight(h *ResourceHandler, name, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/deployments/"+name+"/canary", strings.NewReader(body))
+	req.SetPathValue("name", name)
+	rec := httptest.NewRecorder()
+	h.HandleSetCanaryWeight(rec, req)
+	return rec
+}
+
+func TestSetCanaryWeightRamp(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "petstore"}, canaryDeployment, "")
+	h := NewResourceHandler(s, nil)
+
+	for _, weight := range []string{"10", "50"} {
+		if rec := setWeight(h, "petstore", `{"weight":`+weight+`}`); rec.Code != http.StatusOK {
+			t.Fatalf("weight %s: status = %d, body %s", weight, rec.Code, rec.Body)
+		}
+	}
+
+	res, err := s.Get(context.Background(), store.ResourceKey{Kind: "Deployment", Name: "petstore"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if got := spec.Strategy.Deployment.Canary.CanaryWeight; got != 50 {
+		t.Errorf("canaryWeight = %d, want 50", got)
+	}
+
+	var history []CanaryStep
+	if err := json.Unmarshal([]byte(res.Meta.Annotations[CanaryHistoryAnnotation]), &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(history) != 2 || history[0].Weight != 10 || history[1].Weight != 50 {
+		t.Errorf("history = %+v, want steps 10 then 50", history)
+	}
+}
+
+func TestSetCanaryWeightRejects(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "petstore"}, canaryDeployment, "")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "basic"}, petstoreDeployment, "")
+	h := NewResourceHandler(s, nil)
+
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"petstore", `{"weight":101}`, http.StatusBadRequest},
+		{"petstore", `{"weight":-1}`, http.StatusBadRequest},
+		{"petstore", `{}`, http.StatusBadRequest},
+		{"basic", `{"weight":10}`, http.StatusBadRequest},
+		{"missing", `{"weight":10}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := setWeight(h, tt.name, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.name, tt.body, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// ErrUnknownDeploymentStatus is returned when deployments are filtered by a
+// status that is not a Deployment phase.
+var ErrUnknownDeploymentStatus = errors.New("unknown deployment status")
+
+// ListDeploymentsByStatus returns the Deployments whose status.phase is
+// status, matched case-insensitively ("failed" selects Failed).
+func (h *ResourceHandler) ListDeploymentsByStatus(ctx context.Context, status string) ([]*store.StoredResource, error) {
+	phase, err := deploymentPhase(status)
+	if err != nil {
+		return nil, err
+	}
+	items, err := h.store.List(ctx, store.ListFilter{Kind: "Deployment"})
+	if err != nil {
+		return nil, err
+	}
+	return filterByDeploymentPhase(items, phase), nil
+}
+
+// deploymentPhase resolves status to one of the Deployment phases.
+func deploymentPhase(status string) (string, error) {
+	for _, phase := range flowcv1alpha1.DeploymentPhases {
+		if strings.EqualFold(status, phase) {
+			return phase, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q (must be one of: %s)", ErrUnknownDeploymentStatus,
+		status, strings.Join(flowcv1alpha1.DeploymentPhases, ", "))
+}
+
+// filterByDeploymentPhase keeps the deployments whose status.phase is phase.
+func filterByDeploymentPhase(items []*store.StoredResource, phase string) []*store.StoredResource {
+	var result []*store.StoredResource
+	for _, item := range items {
+		var status flowcv1alpha1.DeploymentStatus
+		if len(item.StatusJSON) > 0 && json.Unmarshal(item.StatusJSON, &status) != nil {
+			continue
+		}
+		if status.Phase == phase {
+			result = append(result, item)
+		}
+	}
+	return result
+}
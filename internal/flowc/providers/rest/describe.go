@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+)
+
+// DeploymentDescriber describes a deployment from the store and a fresh
+// translation. Implemented by dispatch.DeploymentDescriber.
+type DeploymentDescriber interface {
+	DescribeDeployment(ctx context.Context, deployment string) (*dispatch.DeploymentDescription, error)
+}
+
+// DeploymentDescribeResponse is a deployment's description along with
+// its change history from the audit log, oldest first.
+type DeploymentDescribeResponse struct {
+	*dispatch.DeploymentDescription
+	History []audit.Entry `json:"history"`
+}
+
+// DescribeHandler serves deployment descriptions.
+type DescribeHandler struct {
+	describer DeploymentDescriber
+	audit     audit.Sink
+}
+
+// NewDescribeHandler creates a new describe handler. describer may be
+// nil, in which case descriptions are reported as unavailable; sink may
+// be nil, in which case the history is left empty.
+func NewDescribeHandler(describer DeploymentDescriber, sink audit.Sink) *DescribeHandler {
+	return &DescribeHandler{describer: describer, audit: sink}
+}
+
+// HandleDescribe handles GET /api/v1/deployments/{name}/describe
+// Returns the deployment's record, target gateway and environment,
+// resolved strategy config, generated resource names and change history
+// in one response.
+func (h *DescribeHandler) HandleDescribe(w http.ResponseWriter, r *http.Request) {
+	if h.describer == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "deployment description is not available")
+		return
+	}
+
+	name := r.PathValue("name")
+	desc, err := h.describer.DescribeDeployment(r.Context(), name)
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	out := DeploymentDescribeResponse{DeploymentDescription: desc, History: []audit.Entry{}}
+	if h.audit != nil {
+		entries, err := h.audit.Query(audit.Filter{Kind: "Deployment", Name: name})
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "read audit log: "+err.Error())
+			return
+		}
+		out.History = append(out.History, entries...)
+	}
+	httputil.WriteJSON(w, http.StatusOK, out)
+}
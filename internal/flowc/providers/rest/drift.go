@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+)
+
+// DriftDetector re-translates a deployment and compares the result with
+// the live xDS snapshot. Implemented by dispatch.DriftDetector.
+type DriftDetector interface {
+	DetectDrift(ctx context.Context, deployment string) (*dispatch.DriftReport, error)
+}
+
+// DriftHandler serves drift reports for deployments.
+type DriftHandler struct {
+	detector DriftDetector
+}
+
+// NewDriftHandler creates a new drift handler. detector may be nil, in
+// which case drift detection is reported as unavailable.
+func NewDriftHandler(detector DriftDetector) *DriftHandler {
+	return &DriftHandler{detector: detector}
+}
+
+// HandleGetDrift handles GET /api/v1/deployments/{name}/drift
+// Returns the deployment's resources that a fresh translation would change.
+func (h *DriftHandler) HandleGetDrift(w http.ResponseWriter, r *http.Request) {
+	if h.detector == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "drift detection is not available")
+		return
+	}
+
+	report, err := h.detector.DetectDrift(r.Context(), r.PathValue("name"))
+	if errors.Is(err, dispatch.ErrNotTranslatable) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, report)
+}
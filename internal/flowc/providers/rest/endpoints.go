@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+)
+
+// EndpointInspector reads the endpoints a deployment's clusters are
+// advertised with from the live xDS snapshot. Implemented by
+// dispatch.EndpointInspector.
+type EndpointInspector interface {
+	DeploymentEndpoints(ctx context.Context, deployment string) (*dispatch.DeploymentEndpoints, error)
+}
+
+// EndpointsHandler serves deployments' advertised endpoints.
+type EndpointsHandler struct {
+	inspector EndpointInspector
+}
+
+// NewEndpointsHandler creates a new endpoints handler. inspector may be
+// nil, in which case endpoints are reported as unavailable.
+func NewEndpointsHandler(inspector EndpointInspector) *EndpointsHandler {
+	return &EndpointsHandler{inspector: inspector}
+}
+
+// HandleGetEndpoints handles GET /api/v1/deployments/{name}/endpoints
+// Returns the address, port, weight and locality of every endpoint the
+// deployment's clusters are advertised with.
+func (h *EndpointsHandler) HandleGetEndpoints(w http.ResponseWriter, r *http.Request) {
+	if h.inspector == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "endpoint inspection is not available")
+		return
+	}
+
+	endpoints, err := h.inspector.DeploymentEndpoints(r.Context(), r.PathValue("name"))
+	if errors.Is(err, dispatch.ErrNotTranslatable) || errors.Is(err, dispatch.ErrNotServed) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, endpoints)
+}
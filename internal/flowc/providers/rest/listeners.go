@@ -0,0 +1,307 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// ListenerItem is one listener in an AddListeners request.
+type ListenerItem struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	} `json:"metadata"`
+	Spec json.RawMessage `json:"spec"`
+}
+
+// AddListeners creates or updates the listeners of gateway. Every
+// listener is validated before anything is written: ports must be unique
+// within the gateway, hostnames valid SNI names and gatewayRef, if set,
+// must name it.
+//
+// The store has no multi-resource transactions, so the listeners are
+// written one at a time and watchers such as the reconciler may see part
+// of the batch before the rest. Each write expects the revision read just
+// before it, so a concurrent change fails the call rather than being
+// overwritten. If a write fails, the listeners already written are
+// restored to their previous revision (or deleted, if they were new),
+// unless another writer has changed them since, and the error is
+// returned.
+func (h *ResourceHandler) AddListeners(ctx context.Context, gateway string, items []ListenerItem, managedBy string) ([]ApplyResultItem, error) {
+	if _, err := h.store.Get(ctx, store.ResourceKey{Kind: "Gateway", Name: gateway}); err != nil {
+		return nil, err
+	}
+	toPut, err := h.validateListeners(ctx, gateway, items)
+	if err != nil {
+		return nil, err
+	}
+
+	var written, previous []*store.StoredResource // nil previous entries were created
+	var results []ApplyResultItem
+	for _, res := range toPut {
+		prev, err := h.store.Get(ctx, res.Key())
+		if err != nil && !isNotFound(err) {
+			h.rollbackListeners(ctx, written, previous)
+			return nil, err
+		}
+		opts := store.PutOptions{ManagedBy: managedBy}
+		if prev != nil {
+			opts.ExpectedRevision = prev.Meta.Revision
+		}
+		out, err := h.store.Put(ctx, res, opts)
+		if err != nil {
+			h.rollbackListeners(ctx, written, previous)
+			return nil, err
+		}
+		written = append(written, out)
+		previous = append(previous, prev)
+		results = append(results, ApplyResultItem{
+			Kind:   "Listener",
+			Name:   out.Meta.Name,
+			Action: actionFromRevision(out.Meta.Revision),
+		})
+	}
+	return results, nil
+}
+
+// validateListeners decodes items into listener resources bound to
+// gateway, checking names and ports against each other and the gateway's
+// existing listeners.
+func (h *ResourceHandler) validateListeners(ctx context.Context, gateway string, items []ListenerItem) ([]*store.StoredResource, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: at least one listener is required", store.ErrInvalidResource)
+	}
+
+	existing, err := h.store.List(ctx, store.ListFilter{Kind: "Listener"})
+	if err != nil {
+		return nil, err
+	}
+	batch := map[string]bool{}
+	for _, item := range items {
+		batch[item.Metadata.Name] = true
+	}
+	ports := map[uint32]string{}
+	for _, res := range existing {
+		var spec flowcv1alpha1.ListenerSpec
+		if batch[res.Meta.Name] || json.Unmarshal(res.SpecJSON, &spec) != nil || spec.GatewayRef != gateway {
+			continue
+		}
+		ports[spec.Port] = res.Meta.Name
+	}
+
+	seen := map[string]bool{}
+	out := make([]*store.StoredResource, 0, len(items))
+	for _, item := range items {
+		name := item.Metadata.Name
+		if name == "" {
+			return nil, fmt.Errorf("%w: listener name is required", store.ErrInvalidResource)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: listener %q is listed twice", store.ErrInvalidResource, name)
+		}
+		seen[name] = true
+
+		var spec flowcv1alpha1.ListenerSpec
+		if err := json.Unmarshal(item.Spec, &spec); err != nil {
+			return nil, fmt.Errorf("%w: listener %q: %v", store.ErrInvalidResource, name, err)
+		}
+		if spec.GatewayRef != "" && spec.GatewayRef != gateway {
+			return nil, fmt.Errorf("%w: listener %q references gateway %q, not %q",
+				store.ErrInvalidResource, name, spec.GatewayRef, gateway)
+		}
+		spec.GatewayRef = gateway
+		if spec.Port == 0 || spec.Port > 65535 {
+			return nil, fmt.Errorf("%w: listener %q port must be between 1 and 65535", store.ErrInvalidResource, name)
+		}
+		if err := h.reservedPortError(spec.Port); err != nil {
+			return nil, fmt.Errorf("%w: listener %q: %v", store.ErrInvalidResource, name, err)
+		}
+		if err := validateHostnames(spec.Hostnames); err != nil {
+			return nil, fmt.Errorf("%w: listener %q: %v", store.ErrInvalidResource, name, err)
+		}
+		if other, ok := ports[spec.Port]; ok {
+			return nil, fmt.Errorf("%w: listener %q port %d is already used by listener %q",
+				store.ErrInvalidResource, name, spec.Port, other)
+		}
+		ports[spec.Port] = name
+
+		specJSON, err := json.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("encode listener spec: %w", err)
+		}
+		out = append(out, &store.StoredResource{
+			Meta:     store.StoreMeta{Kind: "Listener", Name: name, Labels: item.Metadata.Labels},
+			SpecJSON: specJSON,
+		})
+	}
+	return out, nil
+}
+
+// reservedPortError reports why port cannot be used by a listener when it
+// is reserved, and returns nil otherwise.
+func (h *ResourceHandler) reservedPortError(port uint32) error {
+	if owner, ok := h.reservedPorts[port]; ok {
+		return fmt.Errorf("port %d is reserved for %s", port, owner)
+	}
+	return nil
+}
+
+// allowListenerPort rejects a Listener spec on a reserved port. Other
+// kinds, and specs that do not decode, are left to the other checks.
+func (h *ResourceHandler) allowListenerPort(kind string, specJSON json.RawMessage) error {
+	if kind != "Listener" || len(h.reservedPorts) == 0 {
+		return nil
+	}
+	var spec flowcv1alpha1.ListenerSpec
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil
+	}
+	return h.reservedPortError(spec.Port)
+}
+
+// rollbackListeners undoes the writes of written, newest first: listeners
+// with a previous revision get it back, new ones are deleted. Each undo
+// expects the revision that was written, so a listener changed since by
+// another writer keeps that change. Failures are logged; the caller
+// reports the original error.
+func (h *ResourceHandler) rollbackListeners(ctx context.Context, written, previous []*store.StoredResource) {
+	for i := len(written) - 1; i >= 0; i-- {
+		revision := written[i].Meta.Revision
+		var err error
+		if previous[i] == nil {
+			err = h.store.Delete(ctx, written[i].Key(), store.DeleteOptions{ExpectedRevision: revision})
+		} else {
+			// No ManagedBy: restore the previous owner along with the spec.
+			_, err = h.store.Put(ctx, previous[i], store.PutOptions{ExpectedRevision: revision})
+		}
+		if err != nil && h.logger != nil {
+			h.logger.WithContext(ctx).WithFields(map[string]any{
+				"listener": written[i].Meta.Name,
+				"error":    err.Error(),
+			}).Error("Failed to roll back listener")
+		}
+	}
+}
+
+// ChangeListenerPort moves listener name to port, which must not be
+// reserved or used by another listener of the same gateway. Only the port changes: the
+// listener keeps its name, so deployments targeting it follow it to the
+// new port when the gateway is re-translated, and the Envoy listener on
+// the old port is dropped from the snapshot.
+func (h *ResourceHandler) ChangeListenerPort(ctx context.Context, name string, port uint32) (*store.StoredResource, error) {
+	if port == 0 || port > 65535 {
+		return nil, fmt.Errorf("%w: listener port must be between 1 and 65535", store.ErrInvalidResource)
+	}
+	if err := h.reservedPortError(port); err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrInvalidResource, err)
+	}
+
+	res, err := h.store.Get(ctx, store.ResourceKey{Kind: "Listener", Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var spec flowcv1alpha1.ListenerSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		return nil, fmt.Errorf("decode listener spec: %w", err)
+	}
+	if spec.Port == port {
+		return res, nil
+	}
+
+	listeners, err := h.store.List(ctx, store.ListFilter{Kind: "Listener"})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range listeners {
+		var otherSpec flowcv1alpha1.ListenerSpec
+		if other.Meta.Name == name || json.Unmarshal(other.SpecJSON, &otherSpec) != nil {
+			continue
+		}
+		if otherSpec.GatewayRef == spec.GatewayRef && otherSpec.Port == port {
+			return nil, fmt.Errorf("%w: port %d is already used by listener %q",
+				store.ErrInvalidResource, port, other.Meta.Name)
+		}
+	}
+
+	spec.Port = port
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encode listener spec: %w", err)
+	}
+	updated := res.Clone()
+	updated.SpecJSON = specJSON
+	return h.store.Put(ctx, updated, store.PutOptions{ExpectedRevision: res.Meta.Revision})
+}
+
+// HandleChangeListenerPort handles PUT /api/v1/listeners/{name}/port
+// with a body of {"port": N}.
+func (h *ResourceHandler) HandleChangeListenerPort(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	body, err := httputil.ReadBody(r)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req struct {
+		Port *uint32 `json:"port"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Port == nil {
+		httputil.WriteError(w, http.StatusBadRequest, "port is required")
+		return
+	}
+
+	out, err := h.ChangeListenerPort(r.Context(), name, *req.Port)
+	if errors.Is(err, store.ErrInvalidResource) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	writeResourceResponse(w, r, http.StatusOK, "Listener", out)
+}
+
+// HandleAddListeners handles POST /api/v1/gateways/{name}/listeners
+// with a body of {"listeners": [{"metadata": {...}, "spec": {...}}]}.
+func (h *ResourceHandler) HandleAddListeners(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	body, err := httputil.ReadBody(r)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req struct {
+		Listeners []ListenerItem `json:"listeners"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	results, err := h.AddListeners(r.Context(), name, req.Listeners, r.Header.Get("X-Managed-By"))
+	if errors.Is(err, store.ErrInvalidResource) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	httputil.Write(w, r, http.StatusOK, ApplyResult{Results: results})
+}
@@ -0,0 +1,287 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func addListeners(h *ResourceHandler, gateway, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gateways/"+gateway+"/listeners", strings.NewReader(body))
+	req.SetPathValue("name", gateway)
+	req.Header.Set("X-Managed-By", "rest")
+	rec := httptest.NewRecorder()
+	h.HandleAddListeners(rec, req)
+	return rec
+}
+
+func listenerPort(t *testing.T, s store.Store, name string) uint32 {
+	t.Helper()
+	res, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: name})
+	if err != nil {
+		t.Fatalf("get listener %s: %v", name, err)
+	}
+	var spec flowcv1alpha1.ListenerSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode listener %s: %v", name, err)
+	}
+	return spec.Port
+}
+
+const twoListeners = `{"listeners": [
+	{"metadata": {"name": "http"}, "spec": {"port": 8080, "hostnames": ["staging.example.com"]}},
+	{"metadata": {"name": "https"}, "spec": {"port": 8443, "hostnames": ["prod.example.com"]}}
+]}`
+
+func TestAddListenersToGateway(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	h := NewResourceHandler(s, nil)
+
+	rec := addListeners(h, "edge", twoListeners)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var result ApplyResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(result.Results) != 2 || result.Results[0].Action != "created" || result.Results[1].Action != "created" {
+		t.Errorf("results = %+v, want two created listeners", result.Results)
+	}
+
+	res, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: "https"})
+	if err != nil {
+		t.Fatalf("get https: %v", err)
+	}
+	var spec flowcv1alpha1.ListenerSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode https: %v", err)
+	}
+	if spec.GatewayRef != "edge" || spec.Port != 8443 {
+		t.Errorf("https spec = %+v, want gatewayRef edge and port 8443", spec)
+	}
+}
+
+func TestAddListenersRollsBackOnFailure(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	// "https" already exists and is owned by another writer, so writing
+	// the second listener fails after the first has been created.
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "https"}, `{"gatewayRef":"other","port":9443}`, "kubernetes")
+	h := NewResourceHandler(s, nil)
+
+	if rec := addListeners(h, "edge", twoListeners); rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+	if _, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: "http"}); err != store.ErrNotFound {
+		t.Errorf("listener http after rollback: err = %v, want ErrNotFound", err)
+	}
+	if got := listenerPort(t, s, "https"); got != 9443 {
+		t.Errorf("https port = %d, want the untouched 9443", got)
+	}
+}
+
+func TestAddListenersRestoresUpdatedListeners(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8000}`, "rest")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "https"}, `{"gatewayRef":"other","port":9443}`, "kubernetes")
+	h := NewResourceHandler(s, nil)
+
+	if rec := addListeners(h, "edge", twoListeners); rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+	if got := listenerPort(t, s, "http"); got != 8000 {
+		t.Errorf("http port = %d, want the restored 8000", got)
+	}
+}
+
+// racingStore fails the Put of listener "https" after another writer has
+// moved listener "http" to port 7000.
+type racingStore struct {
+	store.Store
+	t *testing.T
+}
+
+func (s racingStore) Put(ctx context.Context, res *store.StoredResource, opts store.PutOptions) (*store.StoredResource, error) {
+	if res.Meta.Kind == "Listener" && res.Meta.Name == "https" {
+		putResource(s.t, s.Store, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":7000}`, "")
+		return nil, errors.New("store unavailable")
+	}
+	return s.Store.Put(ctx, res, opts)
+}
+
+func TestAddListenersRollbackKeepsConcurrentChanges(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8000}`, "rest")
+	h := NewResourceHandler(racingStore{Store: s, t: t}, nil)
+
+	if _, err := h.AddListeners(context.Background(), "edge", decodeListeners(t, twoListeners), "rest"); err == nil {
+		t.Fatal("AddListeners succeeded, want the store error")
+	}
+	if got := listenerPort(t, s, "http"); got != 7000 {
+		t.Errorf("http port = %d, want the concurrent writer's 7000", got)
+	}
+}
+
+func decodeListeners(t *testing.T, body string) []ListenerItem {
+	t.Helper()
+	var req struct {
+		Listeners []ListenerItem `json:"listeners"`
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("decode listeners: %v", err)
+	}
+	return req.Listeners
+}
+
+func TestAddListenersRejectsBeforeWriting(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "admin"}, `{"gatewayRef":"edge","port":8443}`, "")
+	h := NewResourceHandler(s, nil)
+
+	tests := []struct {
+		name, gateway, body string
+		want                int
+	}{
+		{"port taken", "edge", twoListeners, http.StatusBadRequest},
+		{"duplicate port in batch", "edge", `{"listeners": [
+			{"metadata": {"name": "a"}, "spec": {"port": 9000}},
+			{"metadata": {"name": "b"}, "spec": {"port": 9000}}]}`, http.StatusBadRequest},
+		{"other gateway", "edge", `{"listeners": [
+			{"metadata": {"name": "a"}, "spec": {"gatewayRef": "other", "port": 9000}}]}`, http.StatusBadRequest},
+		{"invalid hostname", "edge", `{"listeners": [
+			{"metadata": {"name": "a"}, "spec": {"port": 9000, "hostnames": ["https://api.example.com"]}}]}`, http.StatusBadRequest},
+		{"empty", "edge", `{"listeners": []}`, http.StatusBadRequest},
+		{"missing gateway", "missing", twoListeners, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := addListeners(h, tt.gateway, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d; body %s", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+	if items, _ := s.List(context.Background(), store.ListFilter{Kind: "Listener"}); len(items) != 1 {
+		t.Errorf("got %d listeners, want only the existing one", len(items))
+	}
+}
+
+func changePort(h *ResourceHandler, listener, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/listeners/"+listener+"/port", strings.NewReader(body))
+	req.SetPathValue("name", listener)
+	rec := httptest.NewRecorder()
+	h.HandleChangeListenerPort(rec, req)
+	return rec
+}
+
+func TestChangeListenerPort(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8080,"hostnames":["staging.example.com","prod.example.com"]}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "https"}, `{"gatewayRef":"edge","port":8443}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "other"}, `{"gatewayRef":"internal","port":9090}`, "")
+	h := NewResourceHandler(s, nil)
+
+	if rec := changePort(h, "http", `{"port": 8443}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("move onto a used port: status = %d, want 400", rec.Code)
+	}
+	if got := listenerPort(t, s, "http"); got != 8080 {
+		t.Errorf("port after rejected move = %d, want 8080", got)
+	}
+
+	// A port used on another gateway is free on this one.
+	if rec := changePort(h, "http", `{"port": 9090}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	res, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: "http"})
+	if err != nil {
+		t.Fatalf("get http: %v", err)
+	}
+	var spec flowcv1alpha1.ListenerSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode http: %v", err)
+	}
+	if spec.Port != 9090 || spec.GatewayRef != "edge" || len(spec.Hostnames) != 2 {
+		t.Errorf("http spec = %+v, want port 9090 with gateway and hostnames kept", spec)
+	}
+
+	if rec := changePort(h, "missing", `{"port": 8081}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown listener: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestPutListenerValidatesHostnames(t *testing.T) {
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	tests := []struct {
+		name, hostname string
+		want           int
+	}{
+		{"exact", "api.example.com", http.StatusCreated},
+		{"any", "*", http.StatusCreated},
+		{"wildcard", "*.example.com", http.StatusCreated},
+		{"single label", "localhost", http.StatusCreated},
+		{"space", "api example.com", http.StatusBadRequest},
+		{"scheme", "https://api.example.com", http.StatusBadRequest},
+		{"path", "api.example.com/v1", http.StatusBadRequest},
+		{"port", "api.example.com:443", http.StatusBadRequest},
+		{"inner wildcard", "api.*.example.com", http.StatusBadRequest},
+		{"partial wildcard", "*api.example.com", http.StatusBadRequest},
+		{"uppercase", "API.example.com", http.StatusBadRequest},
+		{"empty label", "api..example.com", http.StatusBadRequest},
+		{"trailing hyphen", "api-.example.com", http.StatusBadRequest},
+		{"empty", "", http.StatusBadRequest},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := fmt.Sprintf("env-%d", i)
+			body := fmt.Sprintf(`{"spec":{"gatewayRef":"edge","port":%d,"hostnames":[%q]}}`, 9000+i, tt.hostname)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/listeners/"+name, strings.NewReader(body))
+			req.SetPathValue("name", name)
+			rec := httptest.NewRecorder()
+			h.HandlePut("Listener")(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("hostname %q: status = %d, want %d; body %s", tt.hostname, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestListenerRejectedOnReservedPort(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"edge-node"}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Listener", Name: "http"}, `{"gatewayRef":"edge","port":8081}`, "")
+	h := NewResourceHandler(s, nil)
+	h.SetReservedPorts(map[uint32]string{8080: "the flowc API server", 18000: "the flowc xDS server"})
+
+	body := `{"spec":{"gatewayRef":"edge","port":8080}}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/listeners/api", strings.NewReader(body))
+	req.SetPathValue("name", "api")
+	rec := httptest.NewRecorder()
+	h.HandlePut("Listener")(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "port 8080 is reserved for the flowc API server") {
+		t.Errorf("put on the API port: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if _, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: "api"}); err != store.ErrNotFound {
+		t.Errorf("listener on the API port was stored: %v", err)
+	}
+
+	if rec := addListeners(h, "edge", `{"listeners": [{"metadata": {"name": "xds"}, "spec": {"port": 18000}}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("add on the xDS port: status = %d, want 400", rec.Code)
+	}
+	if rec := changePort(h, "http", `{"port": 8080}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("move onto the API port: status = %d, want 400", rec.Code)
+	}
+	if got := listenerPort(t, s, "http"); got != 8081 {
+		t.Errorf("port after rejected move = %d, want 8081", got)
+	}
+}
@@ -0,0 +1,101 @@
+package loader
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+// DefaultIRCacheSize is the number of parsed specs NewBundleLoader keeps.
+const DefaultIRCacheSize = 64
+
+// irCacheKey identifies a spec by API type and content hash.
+type irCacheKey struct {
+	apiType ir.APIType
+	sum     [sha256.Size]byte
+}
+
+type irCacheEntry struct {
+	key irCacheKey
+	api *ir.API
+}
+
+// IRCache is a content-hash-keyed LRU of parsed specs, safe for concurrent
+// use. Identical spec bytes of the same API type skip re-parsing.
+type IRCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front = most recently used
+	entries map[irCacheKey]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+// NewIRCache returns a cache holding up to size parsed specs. A size of
+// zero or less disables caching.
+func NewIRCache(size int) *IRCache {
+	return &IRCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[irCacheKey]*list.Element),
+	}
+}
+
+// Get returns a copy of the cached IR for data, if present. The copy's
+// Metadata may be modified freely; its slices and maps are shared with
+// the cache and must be treated as read-only.
+func (c *IRCache) Get(apiType ir.APIType, data []byte) (*ir.API, bool) {
+	key := irCacheKey{apiType: apiType, sum: sha256.Sum256(data)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	api := *el.Value.(*irCacheEntry).api
+	return &api, true
+}
+
+// Put stores a copy of api as the parse result for data, evicting the
+// least recently used entry when full.
+func (c *IRCache) Put(apiType ir.APIType, data []byte, api *ir.API) {
+	if c.size <= 0 || api == nil {
+		return
+	}
+	key := irCacheKey{apiType: apiType, sum: sha256.Sum256(data)}
+	stored := *api
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*irCacheEntry).api = &stored
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&irCacheEntry{key: key, api: &stored})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*irCacheEntry).key)
+	}
+}
+
+// Len returns the number of cached specs.
+func (c *IRCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the hit and miss counts since the cache was created.
+func (c *IRCache) Stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
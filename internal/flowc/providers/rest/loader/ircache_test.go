@@ -0,0 +1,115 @@
+package loader
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/pkg/bundle"
+)
+
+const testFlowCYAML = `name: petstore
+version: v1
+context: /petstore
+upstream:
+  host: petstore.local
+  port: 8080
+`
+
+const testOpenAPIYAML = `openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+
+func makeZip(t testing.TB) []byte {
+	t.Helper()
+	zipData, err := bundle.CreateZip([]byte(testFlowCYAML), []byte(testOpenAPIYAML), "openapi.yaml")
+	if err != nil {
+		t.Fatalf("CreateZip: %v", err)
+	}
+	return zipData
+}
+
+func TestLoadBundleCachesParsedSpec(t *testing.T) {
+	l := NewBundleLoader()
+	zipData := makeZip(t)
+
+	first, err := l.LoadBundle(zipData)
+	if err != nil {
+		t.Fatalf("LoadBundle: %v", err)
+	}
+	second, err := l.LoadBundle(zipData)
+	if err != nil {
+		t.Fatalf("LoadBundle: %v", err)
+	}
+
+	hits, misses := l.IRCache().Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 1/1", hits, misses)
+	}
+	if first.IR == second.IR {
+		t.Error("cache handed out the same *ir.API twice")
+	}
+	if len(second.IR.Endpoints) != 1 || second.IR.Metadata.BasePath != "/petstore" {
+		t.Errorf("cached IR = %+v", second.IR)
+	}
+}
+
+func TestIRCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewIRCache(2)
+	for _, spec := range []string{"a", "b"} {
+		c.Put(ir.APITypeREST, []byte(spec), &ir.API{})
+	}
+	c.Get(ir.APITypeREST, []byte("a"))
+	c.Put(ir.APITypeREST, []byte("c"), &ir.API{})
+
+	if _, ok := c.Get(ir.APITypeREST, []byte("b")); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, spec := range []string{"a", "c"} {
+		if _, ok := c.Get(ir.APITypeREST, []byte(spec)); !ok {
+			t.Errorf("expected %s to be cached", spec)
+		}
+	}
+}
+
+func TestIRCacheConcurrentUse(t *testing.T) {
+	c := NewIRCache(8)
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spec := []byte(fmt.Sprintf("spec-%d", i%10))
+			if _, ok := c.Get(ir.APITypeREST, spec); !ok {
+				c.Put(ir.APITypeREST, spec, &ir.API{})
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() > 8 {
+		t.Errorf("Len = %d, want <= 8", c.Len())
+	}
+}
+
+func BenchmarkLoadBundle(b *testing.B) {
+	zipData := makeZip(b)
+	for _, size := range []int{0, DefaultIRCacheSize} {
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			l := NewBundleLoaderWithCacheSize(size)
+			for b.Loop() {
+				if _, err := l.LoadBundle(zipData); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package loader
+
+import "context"
+
+// ParseLimiter bounds how many specs are parsed at once. Parses over the
+// limit wait for a running one to finish, so a burst of deploys is queued
+// rather than parsed all in parallel. A nil *ParseLimiter places no limit.
+type ParseLimiter struct {
+	slots chan struct{}
+}
+
+// NewParseLimiter returns a limiter running at most n parses at once, or
+// nil (no limit) when n <= 0.
+func NewParseLimiter(n int) *ParseLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &ParseLimiter{slots: make(chan struct{}, n)}
+}
+
+// Acquire waits for a parse slot. It fails only when ctx is done first.
+// Every successful Acquire must be paired with a Release.
+func (p *ParseLimiter) Acquire(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot taken by Acquire.
+func (p *ParseLimiter) Release() {
+	if p == nil {
+		return
+	}
+	<-p.slots
+}
@@ -0,0 +1,80 @@
+package loader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+// blockingParser counts the parses running at once, each blocking until
+// release is closed.
+type blockingParser struct {
+	running, peak atomic.Int32
+	release       chan struct{}
+}
+
+func (p *blockingParser) Parse(ctx context.Context, data []byte) (*ir.API, error) {
+	n := p.running.Add(1)
+	defer p.running.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	<-p.release
+	return &ir.API{}, nil
+}
+
+func (p *blockingParser) SupportedType() ir.APIType                       { return ir.APITypeREST }
+func (p *blockingParser) SupportedFormats() []string                      { return nil }
+func (p *blockingParser) Validate(ctx context.Context, data []byte) error { return nil }
+
+func TestParseLimiterBoundsBurst(t *testing.T) {
+	const limit, burst = 2, 10
+	parser := &blockingParser{release: make(chan struct{})}
+	registry := ir.NewParserRegistry()
+	if err := registry.Register(ir.APITypeREST, parser); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	l := NewBundleLoaderWithCacheSize(0)
+	l.parserRegistry = registry
+	l.SetParseLimiter(NewParseLimiter(limit))
+	zipData := makeZip(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, burst)
+	for range burst {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.LoadBundle(zipData); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for parser.running.Load() < limit && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give queued parses the chance to (wrongly) start.
+	time.Sleep(20 * time.Millisecond)
+	if got := parser.running.Load(); got != limit {
+		t.Errorf("%d parses running during the burst, want %d", got, limit)
+	}
+
+	close(parser.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("LoadBundle: %v", err)
+	}
+	if got := parser.peak.Load(); got > limit {
+		t.Errorf("peak of %d concurrent parses, want at most %d", got, limit)
+	}
+}
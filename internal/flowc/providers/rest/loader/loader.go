@@ -4,9 +4,14 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/pkg/types"
@@ -17,15 +22,36 @@ import (
 // Supports multiple API types through the IR (Intermediate Representation) layer
 type BundleLoader struct {
 	parserRegistry *ir.ParserRegistry
+	irCache        *IRCache
+	limiter        *ParseLimiter
 }
 
-// NewBundleLoader creates a new bundle loader instance
+// NewBundleLoader creates a new bundle loader instance that caches up to
+// DefaultIRCacheSize parsed specs
 func NewBundleLoader() *BundleLoader {
+	return NewBundleLoaderWithCacheSize(DefaultIRCacheSize)
+}
+
+// NewBundleLoaderWithCacheSize creates a bundle loader caching up to size
+// parsed specs; size <= 0 disables the cache
+func NewBundleLoaderWithCacheSize(size int) *BundleLoader {
 	return &BundleLoader{
 		parserRegistry: ir.DefaultParserRegistry(),
+		irCache:        NewIRCache(size),
 	}
 }
 
+// IRCache returns the loader's parsed-spec cache
+func (l *BundleLoader) IRCache() *IRCache {
+	return l.irCache
+}
+
+// SetParseLimiter bounds the specs parsed at once; cache hits are not
+// limited. A nil limiter (the default) places no limit.
+func (l *BundleLoader) SetParseLimiter(p *ParseLimiter) {
+	l.limiter = p
+}
+
 // DeploymentBundle contains the parsed results from a bundle
 type DeploymentBundle struct {
 	FlowCMetadata *types.FlowCMetadata // FlowC metadata from flowc.yaml
@@ -36,6 +62,12 @@ type DeploymentBundle struct {
 // LoadBundle loads a bundle from a zip file
 // This method automatically detects the API type and uses the appropriate parser
 func (l *BundleLoader) LoadBundle(zipData []byte) (*DeploymentBundle, error) {
+	return l.LoadBundleWithVariables(zipData, nil)
+}
+
+// LoadBundleWithVariables loads a bundle like LoadBundle, first expanding
+// the ${NAME} references of its flowc.yaml from vars (see ExpandVariables)
+func (l *BundleLoader) LoadBundleWithVariables(zipData []byte, vars map[string]string) (*DeploymentBundle, error) {
 	ctx := context.Background()
 
 	// Create a reader from the zip data
@@ -92,6 +124,11 @@ func (l *BundleLoader) LoadBundle(zipData []byte) (*DeploymentBundle, error) {
 		return nil, fmt.Errorf("flowc.yaml not found in zip file")
 	}
 
+	flowcData, err = ExpandVariables(flowcData, vars)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load FlowC metadata
 	flowcMetadata, err := l.loadFlowCMetadata(flowcData)
 	if err != nil {
@@ -112,7 +149,7 @@ func (l *BundleLoader) LoadBundle(zipData []byte) (*DeploymentBundle, error) {
 
 	// Set the gateway basepath from FlowCMetadata.Context
 	// This is a unified concept that works across all API types
-	irAPI.Metadata.BasePath = l.normalizeBasePath(flowcMetadata.Context)
+	irAPI.Metadata.BasePath = NormalizeBasePath(flowcMetadata.Context)
 
 	return &DeploymentBundle{
 		FlowCMetadata: flowcMetadata,
@@ -190,18 +227,28 @@ func (l *BundleLoader) getSpecDataForType(apiType ir.APIType, specFiles map[stri
 	}
 }
 
-// parseSpecification parses the API specification using the IR layer
+// parseSpecification parses the API specification using the IR layer.
+// Identical spec bytes are served from the IR cache.
 func (l *BundleLoader) parseSpecification(ctx context.Context, apiType ir.APIType, specData []byte) (*ir.API, error) {
+	if cached, ok := l.irCache.Get(apiType, specData); ok {
+		return cached, nil
+	}
+
 	parser, err := l.parserRegistry.GetParser(apiType)
 	if err != nil {
 		return nil, fmt.Errorf("no parser available for API type %s: %w", apiType, err)
 	}
 
+	if err := l.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.limiter.Release()
 	irAPI, err := parser.Parse(ctx, specData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse %s specification: %w", apiType, err)
 	}
 
+	l.irCache.Put(apiType, specData, irAPI)
 	return irAPI, nil
 }
 
@@ -229,20 +276,8 @@ func (l *BundleLoader) loadFlowCMetadata(data []byte) (*types.FlowCMetadata, err
 	}
 
 	// Validate required fields
-	if metadata.Name == "" {
-		return nil, fmt.Errorf("name is required in flowc.yaml")
-	}
-	if metadata.Version == "" {
-		return nil, fmt.Errorf("version is required in flowc.yaml")
-	}
-	if metadata.Context == "" {
-		return nil, fmt.Errorf("context is required in flowc.yaml")
-	}
-	if metadata.Upstream.Host == "" {
-		return nil, fmt.Errorf("upstream.host is required in flowc.yaml")
-	}
-	if metadata.Upstream.Port == 0 {
-		return nil, fmt.Errorf("upstream.port is required in flowc.yaml")
+	if err := ValidateMetadata(&metadata); err != nil {
+		return nil, err
 	}
 
 	// Gateway configuration is optional in flowc.yaml
@@ -263,15 +298,141 @@ func (l *BundleLoader) loadFlowCMetadata(data []byte) (*types.FlowCMetadata, err
 	return &metadata, nil
 }
 
-// normalizeBasePath normalizes a base path to ensure it starts with a slash
-// and doesn't end with a slash (unless it's the root path)
-func (l *BundleLoader) normalizeBasePath(path string) string {
-	if path == "" {
-		return "/"
+// ValidateMetadata checks the required fields of a flowc.yaml. Every
+// problem found is reported; the returned error joins them with errors.Join.
+// upstream.host is normalized first (see NormalizeUpstream).
+func ValidateMetadata(metadata *types.FlowCMetadata) error {
+	var errs []error
+	if err := NormalizeUpstream(&metadata.Upstream); err != nil {
+		errs = append(errs, err)
+	}
+	if metadata.Name == "" {
+		errs = append(errs, fmt.Errorf("name is required in flowc.yaml"))
+	}
+	if metadata.Version == "" {
+		errs = append(errs, fmt.Errorf("version is required in flowc.yaml"))
+	}
+	if metadata.Context == "" {
+		errs = append(errs, fmt.Errorf("context is required in flowc.yaml"))
+	}
+	// A dynamic forward proxy resolves its upstream per request.
+	if len(metadata.Upstream.Hosts) == 0 && !IsDynamicForwardProxy(metadata) {
+		if metadata.Upstream.Host == "" {
+			errs = append(errs, fmt.Errorf("upstream.host is required in flowc.yaml"))
+		}
+		if metadata.Upstream.Port == 0 {
+			errs = append(errs, fmt.Errorf("upstream.port is required in flowc.yaml"))
+		}
+	}
+	for i, h := range metadata.Upstream.Hosts {
+		if h.Host == "" {
+			errs = append(errs, fmt.Errorf("upstream.hosts[%d].host is required in flowc.yaml", i))
+		}
+		if h.Port == 0 && metadata.Upstream.Port == 0 {
+			errs = append(errs, fmt.Errorf("upstream.hosts[%d].port or upstream.port is required in flowc.yaml", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NormalizeUpstream reduces upstream.host to a bare host. A URL scheme
+// moves into Scheme and a ":port" suffix into Port; when the scheme came
+// from the host and no port is set, the scheme's default port is used.
+// Paths, queries and credentials are rejected, as is a scheme or port
+// that contradicts the one set explicitly.
+func NormalizeUpstream(upstream *types.UpstreamConfig) error {
+	host := strings.TrimSpace(upstream.Host)
+	if host == "" {
+		return nil
+	}
+
+	schemeFromHost := ""
+	if i := strings.Index(host, "://"); i >= 0 {
+		u, err := url.Parse(host)
+		if err != nil {
+			return fmt.Errorf("upstream.host %q is not a valid host or URL: %w", upstream.Host, err)
+		}
+		scheme := strings.ToLower(u.Scheme)
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("upstream.host %q has unsupported scheme %q (want http or https)", upstream.Host, u.Scheme)
+		}
+		if upstream.Scheme != "" && !strings.EqualFold(upstream.Scheme, scheme) {
+			return fmt.Errorf("upstream.host %q conflicts with upstream.scheme %q", upstream.Host, upstream.Scheme)
+		}
+		if u.User != nil {
+			return fmt.Errorf("upstream.host %q must not contain credentials", upstream.Host)
+		}
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("upstream.host %q must not contain a path; route prefixes belong in context", upstream.Host)
+		}
+		schemeFromHost = scheme
+		host = u.Host
+	} else if strings.ContainsAny(host, "/?#") {
+		return fmt.Errorf("upstream.host %q must not contain a path; route prefixes belong in context", upstream.Host)
+	}
+
+	// A single colon (or a bracketed IPv6 literal) carries a port; a bare
+	// IPv6 address has several colons and no port.
+	port := uint32(0)
+	if strings.HasPrefix(host, "[") || strings.Count(host, ":") == 1 {
+		h, p, err := net.SplitHostPort(host)
+		if err != nil {
+			if !strings.HasPrefix(host, "[") || !strings.HasSuffix(host, "]") {
+				return fmt.Errorf("upstream.host %q is not a valid host[:port]: %w", upstream.Host, err)
+			}
+			h, p = strings.Trim(host, "[]"), ""
+		}
+		if p != "" {
+			n, err := strconv.ParseUint(p, 10, 16)
+			if err != nil || n == 0 {
+				return fmt.Errorf("upstream.host %q has invalid port %q", upstream.Host, p)
+			}
+			port = uint32(n)
+		}
+		host = h
+	}
+	if host == "" {
+		return fmt.Errorf("upstream.host %q has no host name", upstream.Host)
+	}
+	if port != 0 && upstream.Port != 0 && upstream.Port != port {
+		return fmt.Errorf("upstream.host %q conflicts with upstream.port %d", upstream.Host, upstream.Port)
+	}
+
+	upstream.Host = host
+	if schemeFromHost != "" {
+		upstream.Scheme = schemeFromHost
+	}
+	if port != 0 {
+		upstream.Port = port
+	}
+	if upstream.Port == 0 {
+		switch schemeFromHost {
+		case "https":
+			upstream.Port = 443
+		case "http":
+			upstream.Port = 80
+		}
+	}
+	return nil
+}
+
+// IsDynamicForwardProxy reports whether the deployment proxies to hosts
+// named by each request rather than a configured upstream.
+func IsDynamicForwardProxy(metadata *types.FlowCMetadata) bool {
+	return metadata.Strategy != nil && metadata.Strategy.Deployment != nil &&
+		metadata.Strategy.Deployment.Type == "dynamic-forward-proxy"
+}
+
+// NormalizeBasePath turns a flowc.yaml context into an IR base path: ""
+// for the root, otherwise a leading slash and no trailing slash. Bundle
+// validation uses it too, so validated and loaded bundles route alike.
+func NormalizeBasePath(path string) string {
+	if path == "" || path == "/" {
+		return ""
 	}
 
-	// Remove trailing slash (unless it's the root)
-	if len(path) > 1 && path[len(path)-1] == '/' {
+	// Remove trailing slash
+	if path[len(path)-1] == '/' {
 		path = path[:len(path)-1]
 	}
 
@@ -0,0 +1,111 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestNormalizeUpstream(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      types.UpstreamConfig
+		want    types.UpstreamConfig
+		wantErr string
+	}{
+		{
+			name: "bare host",
+			in:   types.UpstreamConfig{Host: "api.example.com", Port: 8080},
+			want: types.UpstreamConfig{Host: "api.example.com", Port: 8080},
+		},
+		{
+			name: "scheme moves into scheme with default port",
+			in:   types.UpstreamConfig{Host: "https://x"},
+			want: types.UpstreamConfig{Host: "x", Port: 443, Scheme: "https"},
+		},
+		{
+			name: "host:port splits",
+			in:   types.UpstreamConfig{Host: "x:443"},
+			want: types.UpstreamConfig{Host: "x", Port: 443},
+		},
+		{
+			name: "url with port and trailing slash",
+			in:   types.UpstreamConfig{Host: "http://x:8080/", Scheme: "http"},
+			want: types.UpstreamConfig{Host: "x", Port: 8080, Scheme: "http"},
+		},
+		{
+			name: "bracketed ipv6 with port",
+			in:   types.UpstreamConfig{Host: "[::1]:9000"},
+			want: types.UpstreamConfig{Host: "::1", Port: 9000},
+		},
+		{
+			name: "bare ipv6",
+			in:   types.UpstreamConfig{Host: "::1", Port: 80},
+			want: types.UpstreamConfig{Host: "::1", Port: 80},
+		},
+		{
+			name:    "path rejected",
+			in:      types.UpstreamConfig{Host: "https://x/api"},
+			wantErr: "must not contain a path",
+		},
+		{
+			name:    "bare path rejected",
+			in:      types.UpstreamConfig{Host: "x/api"},
+			wantErr: "must not contain a path",
+		},
+		{
+			name:    "conflicting scheme",
+			in:      types.UpstreamConfig{Host: "https://x", Scheme: "http"},
+			wantErr: "conflicts with upstream.scheme",
+		},
+		{
+			name:    "conflicting port",
+			in:      types.UpstreamConfig{Host: "x:443", Port: 8443},
+			wantErr: "conflicts with upstream.port",
+		},
+		{
+			name:    "unsupported scheme",
+			in:      types.UpstreamConfig{Host: "ftp://x"},
+			wantErr: "unsupported scheme",
+		},
+		{
+			name:    "invalid port",
+			in:      types.UpstreamConfig{Host: "x:http"},
+			wantErr: "invalid port",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.in
+			err := NormalizeUpstream(&got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeUpstream: %v", err)
+			}
+			if got.Host != tt.want.Host || got.Port != tt.want.Port || got.Scheme != tt.want.Scheme {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":           "",
+		"/":          "",
+		"petstore":   "/petstore",
+		"/petstore/": "/petstore",
+		"/v1/pets":   "/v1/pets",
+	} {
+		if got := NormalizeBasePath(in); got != want {
+			t.Errorf("NormalizeBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// variableRef matches an escaped "$${", or a ${NAME} reference with an
+// optional ":-default"
+var variableRef = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// unsafeValue matches variable values that would not stay a single scalar
+// once spliced into YAML: line breaks, quotes and flow indicators anywhere,
+// mapping separators and comments, and a leading block or node indicator.
+var unsafeValue = regexp.MustCompile(`[\r\n"',\[\]{}]|:(\s|$)|\s#|^[-?](\s|$)|^[#&*!|>%@` + "`]")
+
+// ExpandVariables substitutes the ${NAME} references of a flowc.yaml with
+// vars[NAME] before it is parsed, so one bundle can be deployed to several
+// environments. ${NAME:-default} falls back to default when NAME is not
+// supplied, and $${ stands for a literal ${. A reference without a value
+// or default fails, naming every such variable, and so does a value that
+// could add or override keys rather than fill in one scalar.
+func ExpandVariables(data []byte, vars map[string]string) ([]byte, error) {
+	var missing, unsafe []string
+	out := variableRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		if string(ref) == "$${" {
+			return []byte("${")
+		}
+		m := variableRef.FindSubmatch(ref)
+		name := string(m[1])
+		if v, ok := vars[name]; ok {
+			if unsafeValue.MatchString(v) && !slices.Contains(unsafe, name) {
+				unsafe = append(unsafe, name)
+			}
+			return []byte(v)
+		}
+		if m[2] != nil {
+			return m[3]
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return ref
+	})
+	if len(unsafe) > 0 {
+		return nil, fmt.Errorf("variables with values that are not plain YAML scalars: %s", strings.Join(unsafe, ", "))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unresolved variables in flowc.yaml: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
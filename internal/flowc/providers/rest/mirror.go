@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// ErrNoMirror is returned when a mirror percentage is set on a Deployment
+// without a mirror.
+var ErrNoMirror = errors.New("deployment has no mirror")
+
+// SetMirrorPercentage shadows percent of the requests of Deployment name
+// to its mirror. Like SetCanaryWeight, only the stored spec changes; the
+// reconciler re-translates the routes' mirror policies.
+func (h *ResourceHandler) SetMirrorPercentage(ctx context.Context, name string, percent int) (*store.StoredResource, error) {
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("mirror percentage must be between 0 and 100, got %d", percent)
+	}
+
+	res, err := h.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		return nil, fmt.Errorf("decode deployment spec: %w", err)
+	}
+	if spec.Mirror == nil {
+		return nil, ErrNoMirror
+	}
+	if err := h.limiter.Allow(spec.Gateway.Name); err != nil {
+		return nil, err
+	}
+	spec.Mirror.Percentage = percent
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encode deployment spec: %w", err)
+	}
+	updated := res.Clone()
+	updated.SpecJSON = specJSON
+	return h.store.Put(ctx, updated, store.PutOptions{ExpectedRevision: res.Meta.Revision})
+}
+
+// HandleSetMirrorPercentage handles PUT /api/v1/deployments/{name}/mirror
+// with a body of {"percentage": N}.
+func (h *ResourceHandler) HandleSetMirrorPercentage(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	body, err := httputil.ReadBody(r)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req struct {
+		Percentage *int `json:"percentage"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Percentage == nil {
+		httputil.WriteError(w, http.StatusBadRequest, "percentage is required")
+		return
+	}
+	if *req.Percentage < 0 || *req.Percentage > 100 {
+		httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf("mirror percentage must be between 0 and 100, got %d", *req.Percentage))
+		return
+	}
+
+	out, err := h.SetMirrorPercentage(r.Context(), name, *req.Percentage)
+	if errors.Is(err, ErrNoMirror) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	writeResourceResponse(w, r, http.StatusOK, "Deployment", out)
+}
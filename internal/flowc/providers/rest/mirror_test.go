@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func setMirror(h *ResourceHandler, name, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/deployments/"+name+"/mirror", strings.NewReader(body))
+	req.SetPathValue("name", name)
+	rec := httptest.NewRecorder()
+	h.HandleSetMirrorPercentage(rec, req)
+	return rec
+}
+
+func TestSetMirrorPercentageRamp(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "petstore"},
+		`{"apiRef":"petstore","gateway":{"name":"gw"},"mirror":{"host":"shadow.local","port":8080,"percentage":5}}`, "")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "basic"}, petstoreDeployment, "")
+	h := NewResourceHandler(s, nil)
+
+	if rec := setMirror(h, "petstore", `{"percentage":20}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	res, err := s.Get(context.Background(), store.ResourceKey{Kind: "Deployment", Name: "petstore"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if spec.Mirror == nil || spec.Mirror.Percentage != 20 || spec.Mirror.Host != "shadow.local" {
+		t.Errorf("mirror = %+v, want shadow.local at 20%%", spec.Mirror)
+	}
+
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"petstore", `{"percentage":101}`, http.StatusBadRequest},
+		{"petstore", `{"percentage":-1}`, http.StatusBadRequest},
+		{"petstore", `{}`, http.StatusBadRequest},
+		{"basic", `{"percentage":10}`, http.StatusBadRequest},
+		{"missing", `{"percentage":10}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := setMirror(h, tt.name, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d, want %d", tt.name, tt.body, rec.Code, tt.want)
+		}
+	}
+}
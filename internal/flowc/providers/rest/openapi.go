@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/yaml"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// ErrNoOpenAPI is returned when a deployment's API has no OpenAPI
+// document to export.
+var ErrNoOpenAPI = errors.New("no OpenAPI document")
+
+// DeploymentOpenAPI re-emits the OpenAPI document of deployment name's API
+// as the gateway exposes it, with paths under the API's base path (see
+// ir.ToOpenAPI).
+func (h *ResourceHandler) DeploymentOpenAPI(ctx context.Context, name string) ([]byte, error) {
+	depRes, err := h.store.Get(ctx, store.ResourceKey{Kind: "Deployment", Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var dep flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(depRes.SpecJSON, &dep); err != nil {
+		return nil, fmt.Errorf("decode deployment %s: %w", name, err)
+	}
+	apiRes, err := h.store.Get(ctx, store.ResourceKey{Kind: "API", Name: dep.APIRef})
+	if err != nil {
+		return nil, err
+	}
+	var api flowcv1alpha1.APISpec
+	if err := json.Unmarshal(apiRes.SpecJSON, &api); err != nil {
+		return nil, fmt.Errorf("decode API %s: %w", dep.APIRef, err)
+	}
+	if api.APIType != "" && ir.APIType(api.APIType) != ir.APITypeREST {
+		return nil, fmt.Errorf("%w: API %s is of type %s", ErrNoOpenAPI, dep.APIRef, api.APIType)
+	}
+	if api.SpecContent == "" {
+		return nil, fmt.Errorf("%w: API %s has no specification", ErrNoOpenAPI, dep.APIRef)
+	}
+
+	irAPI, err := ir.NewOpenAPIParser().Parse(ctx, []byte(api.SpecContent))
+	if err != nil {
+		return nil, err
+	}
+	// Same base path as the routes the translator generates.
+	basePath := irAPI.Metadata.BasePath
+	if basePath == "" {
+		basePath = api.Context
+	}
+	return ir.ToOpenAPI(irAPI, basePath)
+}
+
+// HandleGetOpenAPI handles GET /api/v1/deployments/{name}/openapi
+// Returns the deployment's OpenAPI document, in YAML when the request
+// asks for it.
+func (h *ResourceHandler) HandleGetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := h.DeploymentOpenAPI(r.Context(), r.PathValue("name"))
+	if errors.Is(err, ErrNoOpenAPI) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	contentType := "application/json"
+	if httputil.WantsYAML(r) {
+		if doc, err = yaml.JSONToYAML(doc); err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "encode YAML: "+err.Error())
+			return
+		}
+		contentType = httputil.YAMLContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(doc)
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+)
+
+// ErrDeployRateLimited is returned when a deploy operation targets a
+// gateway that has used up its deploy budget.
+var ErrDeployRateLimited = errors.New("deploy rate limit exceeded")
+
+// DeployRateLimiter throttles deploy operations per target gateway with a
+// token bucket: each gateway may deploy burst times in quick succession,
+// then rate times per second. Gateways never share a bucket, so one busy
+// pipeline cannot starve deploys to another. Buckets are keyed by the
+// gateway name a client sends, before it is validated, so buckets left
+// idle long enough to refill are dropped rather than kept forever. A nil
+// limiter allows everything.
+type DeployRateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewDeployRateLimiter returns a limiter refilling rate deploys per second
+// up to burst (at least 1). It returns nil, which allows every deploy,
+// when rate is not positive.
+func NewDeployRateLimiter(rate float64, burst int) *DeployRateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &DeployRateLimiter{
+		rate:    rate,
+		burst:   math.Max(float64(burst), 1),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes one token from gateway's bucket, returning
+// ErrDeployRateLimited when the bucket is empty.
+func (l *DeployRateLimiter) Allow(gateway string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[gateway]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[gateway] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return fmt.Errorf("%w: gateway %q allows %g deploys per second", ErrDeployRateLimited, gateway, l.rate)
+	}
+	b.tokens--
+	return nil
+}
+
+// sweep drops the buckets that have been idle long enough to refill,
+// which are no different from a new bucket. It runs at most once per
+// refill time, so the map holds only the gateways seen in the last two.
+func (l *DeployRateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < refill {
+		return
+	}
+	for gateway, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, gateway)
+		}
+	}
+	l.swept = now
+}
+
+// allowDeployment applies the limiter to a write of a resource of kind.
+// Only Deployments are limited, by the gateway their spec targets; specs
+// that do not decode are left for validation to reject.
+func (l *DeployRateLimiter) allowDeployment(kind string, specJSON json.RawMessage) error {
+	if l == nil || kind != "Deployment" {
+		return nil
+	}
+	var spec flowcv1alpha1.DeploymentSpec
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil
+	}
+	return l.Allow(spec.Gateway.Name)
+}
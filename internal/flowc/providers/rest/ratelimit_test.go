@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func deploy(h *ResourceHandler, name, gateway string) *httptest.ResponseRecorder {
+	body := `{"spec":{"apiRef":"petstore","gateway":{"name":"` + gateway + `"}}}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/deployments/"+name, strings.NewReader(body))
+	req.SetPathValue("name", name)
+	rec := httptest.NewRecorder()
+	h.HandlePut("Deployment")(rec, req)
+	return rec
+}
+
+func TestDeployRateLimitPerGateway(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewDeployRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	h.SetDeployRateLimiter(limiter)
+
+	for i, want := range []int{http.StatusCreated, http.StatusOK, http.StatusTooManyRequests} {
+		if rec := deploy(h, "busy", "gw-a"); rec.Code != want {
+			t.Fatalf("deploy %d to gw-a: status = %d, want %d (body %s)", i+1, rec.Code, want, rec.Body)
+		}
+	}
+
+	// gw-a's exhausted bucket does not throttle another gateway.
+	if rec := deploy(h, "quiet", "gw-b"); rec.Code != http.StatusCreated {
+		t.Errorf("deploy to gw-b: status = %d, want 201 (body %s)", rec.Code, rec.Body)
+	}
+
+	// Non-deployment writes are never throttled.
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/apis/petstore", strings.NewReader(`{"spec":{"version":"v1","context":"/pets"}}`))
+	req.SetPathValue("name", "petstore")
+	rec := httptest.NewRecorder()
+	h.HandlePut("API")(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("put API: status = %d, want 201 (body %s)", rec.Code, rec.Body)
+	}
+
+	now = now.Add(time.Second)
+	if rec := deploy(h, "busy", "gw-a"); rec.Code != http.StatusOK {
+		t.Errorf("deploy to gw-a after refill: status = %d, want 200 (body %s)", rec.Code, rec.Body)
+	}
+}
+
+func TestDeployRateLimitDropsIdleBuckets(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewDeployRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 100 {
+		if err := limiter.Allow(fmt.Sprintf("made-up-%d", i)); err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+	}
+	if err := limiter.Allow("gw-a"); err != nil {
+		t.Fatalf("Allow gw-a: %v", err)
+	}
+
+	// Two seconds refill every bucket, so they are all dropped and gw-a
+	// starts over from a full one.
+	now = now.Add(2 * time.Second)
+	if err := limiter.Allow("gw-a"); err != nil {
+		t.Fatalf("Allow gw-a after refill: %v", err)
+	}
+	if n := len(limiter.buckets); n != 1 {
+		t.Errorf("got %d buckets, want only gw-a's", n)
+	}
+	if err := limiter.Allow("gw-a"); err != nil {
+		t.Errorf("second Allow gw-a after refill: %v", err)
+	}
+	if err := limiter.Allow("gw-a"); err == nil {
+		t.Error("third Allow gw-a after refill succeeded, want rate limited")
+	}
+}
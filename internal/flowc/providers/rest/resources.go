@@ -1,22 +1,33 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
 	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
 	"github.com/flowc-labs/flowc/internal/flowc/store"
+	listenerbuilder "github.com/flowc-labs/flowc/internal/flowc/xds/resources/listener"
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
 // ResourceHandler is the unified HTTP handler for all declarative resource operations.
 type ResourceHandler struct {
-	store  store.Store
-	logger *logger.EnvoyLogger
+	store   store.Store
+	logger  *logger.EnvoyLogger
+	limiter *DeployRateLimiter
+
+	// reservedPorts maps ports listeners may not use to what uses them.
+	reservedPorts map[uint32]string
 }
 
 // NewResourceHandler creates a new resource handler.
@@ -24,6 +35,20 @@ func NewResourceHandler(s store.Store, log *logger.EnvoyLogger) *ResourceHandler
 	return &ResourceHandler{store: s, logger: log}
 }
 
+// SetDeployRateLimiter throttles Deployment writes per target gateway.
+// A nil limiter (the default) disables throttling.
+func (h *ResourceHandler) SetDeployRateLimiter(l *DeployRateLimiter) {
+	h.limiter = l
+}
+
+// SetReservedPorts keeps listeners off ports, each mapped to a
+// description of what uses it, such as "the xDS server". Envoy would fail
+// to bind a listener on a port the control plane already listens on when
+// both share a host.
+func (h *ResourceHandler) SetReservedPorts(ports map[uint32]string) {
+	h.reservedPorts = ports
+}
+
 // ApplyRequest is the bulk-apply request body.
 type ApplyRequest struct {
 	Resources []json.RawMessage `json:"resources"`
@@ -48,9 +73,9 @@ func (h *ResourceHandler) HandlePut(kind string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		name := r.PathValue("name")
 
-		body, err := io.ReadAll(r.Body)
+		body, err := httputil.ReadBody(r)
 		if err != nil {
-			httputil.WriteError(w, http.StatusBadRequest, "failed to read request body")
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
@@ -69,7 +94,11 @@ func (h *ResourceHandler) HandlePut(kind string) http.HandlerFunc {
 		}
 
 		// Validate the typed resource
-		if err := validateResource(name, envelope.Spec); err != nil {
+		err = validateResource(kind, name, envelope.Spec)
+		if err == nil {
+			err = h.allowListenerPort(kind, envelope.Spec)
+		}
+		if err != nil {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -110,6 +139,26 @@ func (h *ResourceHandler) HandlePut(kind string) http.HandlerFunc {
 			}
 		}
 
+		if err := h.limiter.allowDeployment(kind, stored.SpecJSON); err != nil {
+			handleStoreError(w, err)
+			return
+		}
+
+		// Gateway updates report the fields they changed. The write
+		// expects the revision the changes are computed against, so they
+		// are the ones the audit trail records for it.
+		var previous *store.StoredResource
+		if kind == "Gateway" {
+			previous, err = h.store.Get(r.Context(), stored.Key())
+			if err != nil && !isNotFound(err) {
+				handleStoreError(w, err)
+				return
+			}
+			if previous != nil && opts.ExpectedRevision == 0 {
+				opts.ExpectedRevision = previous.Meta.Revision
+			}
+		}
+
 		out, err := h.store.Put(r.Context(), stored, opts)
 		if err != nil {
 			handleStoreError(w, err)
@@ -121,7 +170,13 @@ func (h *ResourceHandler) HandlePut(kind string) http.HandlerFunc {
 			status = http.StatusCreated
 		}
 
-		writeResourceResponse(w, status, kind, out)
+		if previous == nil {
+			writeResourceResponse(w, r, status, kind, out)
+			return
+		}
+		resp := resourceResponse(kind, out)
+		resp["changes"] = audit.Diff(previous, out)
+		httputil.Write(w, r, status, resp)
 	}
 }
 
@@ -137,12 +192,14 @@ func (h *ResourceHandler) HandleGet(kind string) http.HandlerFunc {
 			return
 		}
 
-		writeResourceResponse(w, http.StatusOK, kind, res)
+		writeResourceResponse(w, r, http.StatusOK, kind, res)
 	}
 }
 
 // HandleList handles GET /api/v1/{kind-plural}
-// Supports query params: labels (metadata labels), gatewayRef, listenerRef (spec fields).
+// Supports query params: labels (metadata label equality), labelSelector
+// (Kubernetes selector syntax), gatewayRef, listenerRef (spec fields) and,
+// for deployments, status (status.phase).
 func (h *ResourceHandler) HandleList(kind string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		filter := store.ListFilter{
@@ -150,12 +207,32 @@ func (h *ResourceHandler) HandleList(kind string) http.HandlerFunc {
 			Labels: parseLabelsQuery(r),
 		}
 
+		selector, err := parseLabelSelector(r)
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "invalid labelSelector: "+err.Error())
+			return
+		}
+
+		var phase string
+		if status := r.URL.Query().Get("status"); status != "" && kind == "Deployment" {
+			if phase, err = deploymentPhase(status); err != nil {
+				httputil.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
 		items, err := h.store.List(r.Context(), filter)
 		if err != nil {
 			httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 
+		// Set-based selectors (tier in (canary,beta), !paused) are
+		// evaluated here; the store only does label equality.
+		if selector != nil {
+			items = filterByLabelSelector(items, selector)
+		}
+
 		// Apply spec-field filters (gatewayRef, listenerRef, etc.).
 		// These are post-filters applied after the store list since the store
 		// only supports kind+label filtering.
@@ -163,6 +240,9 @@ func (h *ResourceHandler) HandleList(kind string) http.HandlerFunc {
 		if len(specFilters) > 0 {
 			items = filterBySpec(items, specFilters)
 		}
+		if phase != "" {
+			items = filterByDeploymentPhase(items, phase)
+		}
 
 		crdItems := make([]map[string]any, 0, len(items))
 		for _, item := range items {
@@ -175,7 +255,7 @@ func (h *ResourceHandler) HandleList(kind string) http.HandlerFunc {
 			})
 		}
 
-		httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		httputil.Write(w, r, http.StatusOK, map[string]any{
 			"apiVersion": "flowc.io/v1alpha1",
 			"kind":       kind + "List",
 			"items":      crdItems,
@@ -211,9 +291,9 @@ func (h *ResourceHandler) HandleDelete(kind string) http.HandlerFunc {
 
 // HandleApply handles POST /api/v1/apply -- bulk create-or-update.
 func (h *ResourceHandler) HandleApply(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+	body, err := httputil.ReadBody(r)
 	if err != nil {
-		httputil.WriteError(w, http.StatusBadRequest, "failed to read request body")
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -223,10 +303,17 @@ func (h *ResourceHandler) HandleApply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	managedBy := r.Header.Get("X-Managed-By")
+	results := h.Apply(r.Context(), req.Resources, r.Header.Get("X-Managed-By"))
+	httputil.Write(w, r, http.StatusOK, ApplyResult{Results: results})
+}
+
+// Apply creates or updates each resource (a CRD-shaped object with kind,
+// metadata and spec) in order. A resource that fails is reported in its
+// result item and does not stop the others.
+func (h *ResourceHandler) Apply(ctx context.Context, resources []json.RawMessage, managedBy string) []ApplyResultItem {
 	var results []ApplyResultItem
 
-	for _, raw := range req.Resources {
+	for _, raw := range resources {
 		var envelope struct {
 			Kind     string `json:"kind"`
 			Metadata struct {
@@ -258,7 +345,17 @@ func (h *ResourceHandler) HandleApply(w http.ResponseWriter, r *http.Request) {
 			StatusJSON: envelope.Status,
 		}
 
-		out, err := h.store.Put(r.Context(), stored, store.PutOptions{ManagedBy: managedBy})
+		err := validateSpec(envelope.Kind, stored.SpecJSON)
+		if err == nil {
+			err = h.allowListenerPort(envelope.Kind, stored.SpecJSON)
+		}
+		if err == nil {
+			err = h.limiter.allowDeployment(envelope.Kind, stored.SpecJSON)
+		}
+		var out *store.StoredResource
+		if err == nil {
+			out, err = h.store.Put(ctx, stored, store.PutOptions{ManagedBy: managedBy})
+		}
 		if err != nil {
 			results = append(results, ApplyResultItem{
 				Kind:   envelope.Kind,
@@ -280,17 +377,56 @@ func (h *ResourceHandler) HandleApply(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, ApplyResult{Results: results})
+	return results
 }
 
 // --- Helpers ---
 
-func validateResource(name string, specJSON json.RawMessage) error {
+func validateResource(kind, name string, specJSON json.RawMessage) error {
 	if name == "" {
 		return fmt.Errorf("name is required")
 	}
 	var raw map[string]any
-	return json.Unmarshal(specJSON, &raw)
+	if err := json.Unmarshal(specJSON, &raw); err != nil {
+		return err
+	}
+	return validateSpec(kind, specJSON)
+}
+
+// validateSpec runs the checks specific to kind. Listener hostnames end up
+// in filter chain SNI matches, where an invalid one breaks the listener.
+func validateSpec(kind string, specJSON json.RawMessage) error {
+	switch kind {
+	case "Listener":
+		var spec flowcv1alpha1.ListenerSpec
+		if err := json.Unmarshal(specJSON, &spec); err != nil {
+			return err
+		}
+		return validateHostnames(spec.Hostnames)
+	case "Gateway":
+		var spec flowcv1alpha1.GatewaySpec
+		if err := json.Unmarshal(specJSON, &spec); err != nil {
+			return err
+		}
+		if spec.TTL == "" {
+			return nil
+		}
+		if ttl, err := time.ParseDuration(spec.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid ttl %q: must be a positive duration", spec.TTL)
+		}
+	}
+	return nil
+}
+
+// validateHostnames checks each listener hostname is a valid SNI name or
+// wildcard.
+func validateHostnames(hostnames []string) error {
+	for _, hostname := range hostnames {
+		if err := listenerbuilder.ValidateHostname(hostname); err != nil {
+			return fmt.Errorf("invalid hostname %q: %w", hostname, err)
+		}
+	}
+	return nil
 }
 
 func extractLabels(body []byte) map[string]string {
@@ -318,6 +454,28 @@ func parseLabelsQuery(r *http.Request) map[string]string {
 	return labels
 }
 
+// parseLabelSelector parses the labelSelector query param using Kubernetes
+// label selector syntax (e.g. "tier=canary,team in (payments,search)").
+// Returns nil when the param is absent.
+func parseLabelSelector(r *http.Request) (labels.Selector, error) {
+	raw := r.URL.Query().Get("labelSelector")
+	if raw == "" {
+		return nil, nil
+	}
+	return labels.Parse(raw)
+}
+
+// filterByLabelSelector post-filters stored resources by their labels.
+func filterByLabelSelector(items []*store.StoredResource, selector labels.Selector) []*store.StoredResource {
+	var result []*store.StoredResource
+	for _, item := range items {
+		if selector.Matches(labels.Set(item.Meta.Labels)) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // parseSpecFilters extracts spec-field query params (gatewayRef, listenerRef, etc.).
 func parseSpecFilters(r *http.Request) map[string]string {
 	filters := make(map[string]string)
@@ -390,14 +548,18 @@ func resolveNestedField(m map[string]any, key string) any {
 	return current
 }
 
-func writeResourceResponse(w http.ResponseWriter, status int, kind string, res *store.StoredResource) {
-	httputil.WriteJSON(w, status, map[string]any{
+func writeResourceResponse(w http.ResponseWriter, r *http.Request, status int, kind string, res *store.StoredResource) {
+	httputil.Write(w, r, status, resourceResponse(kind, res))
+}
+
+func resourceResponse(kind string, res *store.StoredResource) map[string]any {
+	return map[string]any{
 		"apiVersion": "flowc.io/v1alpha1",
 		"kind":       kind,
 		"metadata":   store.StoreMetaToObjectMeta(res.Meta),
 		"spec":       res.SpecJSON,
 		"status":     res.StatusJSON,
-	})
+	}
 }
 
 func handleStoreError(w http.ResponseWriter, err error) {
@@ -408,6 +570,8 @@ func handleStoreError(w http.ResponseWriter, err error) {
 		httputil.WriteError(w, http.StatusConflict, err.Error())
 	case isOwnershipConflict(err):
 		httputil.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrDeployRateLimited):
+		httputil.WriteError(w, http.StatusTooManyRequests, err.Error())
 	default:
 		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
 	}
@@ -0,0 +1,243 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/flowc-labs/flowc/internal/flowc/audit"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+// petstoreDeployment is the spec of a deployment of API petstore to
+// gateway gw.
+const petstoreDeployment = `{"apiRef":"petstore","gateway":{"name":"gw"}}`
+
+// putResource writes a resource to s as managedBy, failing the test if
+// the store rejects it.
+func putResource(t *testing.T, s store.Store, meta store.StoreMeta, spec, managedBy string) {
+	t.Helper()
+	_, err := s.Put(context.Background(), &store.StoredResource{
+		Meta:     meta,
+		SpecJSON: json.RawMessage(spec),
+	}, store.PutOptions{ManagedBy: managedBy})
+	if err != nil {
+		t.Fatalf("put %s %s: %v", meta.Kind, meta.Name, err)
+	}
+}
+
+func listNames(t *testing.T, h *ResourceHandler, query string) (int, []string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/deployments"+query, nil)
+	rec := httptest.NewRecorder()
+	h.HandleList("Deployment")(rec, req)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+
+	var body struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	names := make([]string, 0, len(body.Items))
+	for _, item := range body.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	slices.Sort(names)
+	return rec.Code, names
+}
+
+func TestListDeploymentsByLabelSelector(t *testing.T) {
+	s := store.NewMemoryStore()
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "search-canary", Labels: map[string]string{"team": "search", "tier": "canary"}}, petstoreDeployment, "")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "search-stable", Labels: map[string]string{"team": "search", "tier": "stable"}}, petstoreDeployment, "")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "payments-canary", Labels: map[string]string{"team": "payments", "tier": "canary"}}, petstoreDeployment, "")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "unlabeled"}, petstoreDeployment, "")
+	h := NewResourceHandler(s, nil)
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"tier=canary", []string{"payments-canary", "search-canary"}},
+		{"team=search,tier!=canary", []string{"search-stable"}},
+		{"tier in (canary,stable),team notin (payments)", []string{"search-canary", "search-stable"}},
+		{"!tier", []string{"unlabeled"}},
+	}
+	for _, tt := range tests {
+		code, got := listNames(t, h, "?labelSelector="+url.QueryEscape(tt.selector))
+		if code != http.StatusOK {
+			t.Errorf("%q: status = %d, want 200", tt.selector, code)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestListDeploymentsInvalidLabelSelector(t *testing.T) {
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	if code, _ := listNames(t, h, "?labelSelector="+url.QueryEscape("tier in (")); code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestListDeploymentsByStatus(t *testing.T) {
+	s := store.NewMemoryStore()
+	for name, phase := range map[string]string{
+		"pets":   "Deployed",
+		"orders": "Failed",
+		"users":  "Failed",
+		"search": "Pending",
+		"new":    "",
+	} {
+		res := &store.StoredResource{
+			Meta:     store.StoreMeta{Kind: "Deployment", Name: name},
+			SpecJSON: json.RawMessage(`{"apiRef":"petstore","gateway":{"name":"gw"}}`),
+		}
+		if phase != "" {
+			res.StatusJSON = json.RawMessage(`{"phase":"` + phase + `"}`)
+		}
+		if _, err := s.Put(context.Background(), res, store.PutOptions{}); err != nil {
+			t.Fatalf("put %s: %v", name, err)
+		}
+	}
+	h := NewResourceHandler(s, nil)
+
+	code, got := listNames(t, h, "?status=failed")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if want := []string{"orders", "users"}; !slices.Equal(got, want) {
+		t.Errorf("failed deployments = %v, want %v", got, want)
+	}
+
+	items, err := h.ListDeploymentsByStatus(context.Background(), "Deployed")
+	if err != nil {
+		t.Fatalf("ListDeploymentsByStatus: %v", err)
+	}
+	if len(items) != 1 || items[0].Meta.Name != "pets" {
+		t.Errorf("deployed deployments = %v, want [pets]", items)
+	}
+
+	if code, _ := listNames(t, h, "?status=broken"); code != http.StatusBadRequest {
+		t.Errorf("unknown status: code = %d, want 400", code)
+	}
+}
+
+func putGateway(t *testing.T, h *ResourceHandler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/gateways/edge", strings.NewReader(body))
+	req.SetPathValue("name", "edge")
+	rec := httptest.NewRecorder()
+	h.HandlePut("Gateway")(rec, req)
+	return rec
+}
+
+func TestPutGatewayReportsChangedFields(t *testing.T) {
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	const labels = `"metadata":{"labels":{"team":"edge"}}`
+	if rec := putGateway(t, h, `{`+labels+`,"spec":{"nodeId":"edge-node","description":"old"}}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec := putGateway(t, h, `{`+labels+`,"spec":{"nodeId":"edge-node","description":"new"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status = %d, body %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Changes []audit.FieldChange `json:"changes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := []audit.FieldChange{{Field: "spec.description", Old: "old", New: "new"}}
+	if !reflect.DeepEqual(body.Changes, want) {
+		t.Errorf("changes = %+v, want %+v", body.Changes, want)
+	}
+}
+
+func TestPutGatewayYAML(t *testing.T) {
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	const manifest = `metadata:
+  labels:
+    team: edge
+spec:
+  nodeId: edge-node
+  description: edge gateway
+`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/gateways/edge", strings.NewReader(manifest))
+	req.SetPathValue("name", "edge")
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("Accept", "application/yaml")
+	rec := httptest.NewRecorder()
+	h.HandlePut("Gateway")(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("Content-Type = %q, want application/yaml", ct)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/gateways/edge", nil)
+	req.SetPathValue("name", "edge")
+	req.Header.Set("Accept", "application/yaml")
+	rec = httptest.NewRecorder()
+	h.HandleGet("Gateway")(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get: status = %d, body %s", rec.Code, rec.Body)
+	}
+	var got struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			NodeID      string `json:"nodeId"`
+			Description string `json:"description"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode YAML response: %v\n%s", err, rec.Body)
+	}
+	if got.Kind != "Gateway" || got.Metadata.Labels["team"] != "edge" ||
+		got.Spec.NodeID != "edge-node" || got.Spec.Description != "edge gateway" {
+		t.Errorf("read back %+v from\n%s", got, rec.Body)
+	}
+
+	// Without a YAML Accept header the response stays JSON.
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/gateways/edge", nil)
+	req.SetPathValue("name", "edge")
+	rec = httptest.NewRecorder()
+	h.HandleGet("Gateway")(rec, req)
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Errorf("default response is not JSON: %s", rec.Body)
+	}
+}
+
+func TestPutInvalidYAML(t *testing.T) {
+	h := NewResourceHandler(store.NewMemoryStore(), nil)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/gateways/edge", strings.NewReader("spec: [unclosed"))
+	req.SetPathValue("name", "edge")
+	req.Header.Set("Content-Type", "application/x-yaml")
+	rec := httptest.NewRecorder()
+	h.HandlePut("Gateway")(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid YAML") {
+		t.Errorf("status = %d, body %s; want 400 invalid YAML", rec.Code, rec.Body)
+	}
+}
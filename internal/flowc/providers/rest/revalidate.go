@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest/loader"
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// DeploymentValidation lists what the current validators find in the
+// spec of a deployed API. Valid is false when any diagnostic has error
+// severity.
+type DeploymentValidation struct {
+	Deployment  string             `json:"deployment"`
+	API         string             `json:"api"`
+	Valid       bool               `json:"valid"`
+	Diagnostics []BundleDiagnostic `json:"diagnostics"`
+}
+
+// RevalidationResult is the response for a revalidation request. Checked
+// counts the deployments whose spec was validated; Deployments lists
+// those with diagnostics, sorted by name.
+type RevalidationResult struct {
+	Checked     int                    `json:"checked"`
+	Deployments []DeploymentValidation `json:"deployments"`
+}
+
+// RevalidateHandler re-validates the specs of deployed APIs, so specs
+// accepted by an older validator that the current one flags are found.
+// It only reads the store; live config is left as it is.
+type RevalidateHandler struct {
+	store     store.Store
+	validator *ValidateHandler
+}
+
+// NewRevalidateHandler creates a new revalidation handler.
+func NewRevalidateHandler(s store.Store, log *logger.EnvoyLogger) *RevalidateHandler {
+	return &RevalidateHandler{store: s, validator: NewValidateHandler(log)}
+}
+
+// SetParseLimiter bounds the specs validated at once. A nil limiter (the
+// default) places no limit.
+func (h *RevalidateHandler) SetParseLimiter(p *loader.ParseLimiter) {
+	h.validator.SetParseLimiter(p)
+}
+
+// HandleRevalidate handles POST /api/v1/deployments:revalidate
+// Validates the spec of every deployment's API with the current parsers
+// and reports the deployments with diagnostics.
+func (h *RevalidateHandler) HandleRevalidate(w http.ResponseWriter, r *http.Request) {
+	result, err := h.Revalidate(r.Context())
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, result)
+}
+
+// Revalidate validates the spec of the API of every stored Deployment.
+// Deployments of APIs without a spec are not counted; a deployment whose
+// API is missing or unreadable is reported with an error diagnostic.
+func (h *RevalidateHandler) Revalidate(ctx context.Context) (*RevalidationResult, error) {
+	deployments, err := h.store.List(ctx, store.ListFilter{Kind: "Deployment"})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(deployments, func(a, b *store.StoredResource) int {
+		return strings.Compare(a.Meta.Name, b.Meta.Name)
+	})
+
+	result := &RevalidationResult{Deployments: []DeploymentValidation{}}
+	for _, dep := range deployments {
+		var depSpec flowcv1alpha1.DeploymentSpec
+		if err := json.Unmarshal(dep.SpecJSON, &depSpec); err != nil {
+			continue
+		}
+		v := DeploymentValidation{Deployment: dep.Meta.Name, API: depSpec.APIRef, Diagnostics: []BundleDiagnostic{}}
+		add := func(d BundleDiagnostic) {
+			v.Diagnostics = append(v.Diagnostics, d)
+		}
+
+		apiSpec, err := h.apiSpec(ctx, depSpec.APIRef)
+		if err != nil {
+			add(BundleDiagnostic{Stage: StageSpec, Severity: SeverityError, Message: err.Error()})
+		} else if apiSpec.SpecContent == "" {
+			continue
+		} else if err := h.validate(ctx, apiSpec, add); err != nil {
+			return nil, err
+		}
+
+		result.Checked++
+		if len(v.Diagnostics) == 0 {
+			continue
+		}
+		v.Valid = !slices.ContainsFunc(v.Diagnostics, func(d BundleDiagnostic) bool {
+			return d.Severity == SeverityError
+		})
+		result.Deployments = append(result.Deployments, v)
+	}
+	return result, nil
+}
+
+// apiSpec reads the spec of the named API.
+func (h *RevalidateHandler) apiSpec(ctx context.Context, name string) (*flowcv1alpha1.APISpec, error) {
+	res, err := h.store.Get(ctx, store.ResourceKey{Kind: "API", Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("API %q: %w", name, err)
+	}
+	var spec flowcv1alpha1.APISpec
+	if err := json.Unmarshal(res.SpecJSON, &spec); err != nil {
+		return nil, fmt.Errorf("decode API %q spec: %w", name, err)
+	}
+	return &spec, nil
+}
+
+// validate runs the spec stage of bundle validation on spec in a parse
+// slot. It fails only when no slot can be had.
+func (h *RevalidateHandler) validate(ctx context.Context, spec *flowcv1alpha1.APISpec, add func(BundleDiagnostic)) error {
+	if err := h.validator.limiter.Acquire(ctx); err != nil {
+		return err
+	}
+	defer h.validator.limiter.Release()
+	h.validator.validateSpec(ctx, specAPIType(spec.APIType), []byte(spec.SpecContent), add)
+	return nil
+}
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+func TestRevalidateReportsSpecsFlaggedByCurrentValidator(t *testing.T) {
+	s := store.NewMemoryStore()
+	apiSpec := func(openapi string) string {
+		spec, _ := json.Marshal(map[string]any{
+			"version":     "v1",
+			"context":     "/petstore",
+			"specContent": openapi,
+			"upstream":    map[string]any{"host": "petstore.local", "port": 8080},
+		})
+		return string(spec)
+	}
+	// Accepted when it was deployed, but info.version is required.
+	stale := "openapi: 3.0.0\ninfo:\n  title: Petstore\npaths:\n  /pets:\n    get:\n      responses:\n        \"200\":\n          description: ok\n"
+	putResource(t, s, store.StoreMeta{Kind: "API", Name: "good"}, apiSpec(testOpenAPIYAML), "upload")
+	putResource(t, s, store.StoreMeta{Kind: "API", Name: "stale"}, apiSpec(stale), "upload")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "good-deploy"}, `{"apiRef":"good","gateway":{"name":"edge"}}`, "upload")
+	putResource(t, s, store.StoreMeta{Kind: "Deployment", Name: "stale-deploy"}, `{"apiRef":"stale","gateway":{"name":"edge"}}`, "upload")
+	before, _ := s.Get(t.Context(), store.ResourceKey{Kind: "API", Name: "stale"})
+
+	rec := httptest.NewRecorder()
+	NewRevalidateHandler(s, nil).HandleRevalidate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/deployments:revalidate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var result RevalidationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+
+	if result.Checked != 2 {
+		t.Errorf("checked = %d, want 2", result.Checked)
+	}
+	if len(result.Deployments) != 1 {
+		t.Fatalf("deployments = %+v, want only stale-deploy", result.Deployments)
+	}
+	got := result.Deployments[0]
+	if got.Deployment != "stale-deploy" || got.API != "stale" || got.Valid {
+		t.Errorf("reported %+v, want invalid stale-deploy of API stale", got)
+	}
+	if len(got.Diagnostics) == 0 || got.Diagnostics[0].Pointer != "/info" || got.Diagnostics[0].Stage != StageSpec {
+		t.Errorf("diagnostics = %+v, want a spec diagnostic at /info", got.Diagnostics)
+	}
+
+	// Nothing was rewritten.
+	after, _ := s.Get(t.Context(), store.ResourceKey{Kind: "API", Name: "stale"})
+	if after.Meta.Revision != before.Meta.Revision {
+		t.Errorf("API revision changed from %d to %d", before.Meta.Revision, after.Meta.Revision)
+	}
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/flowc-labs/flowc/internal/flowc/dispatch"
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+)
+
+// RouteInspector reads the route table and listener an environment is
+// serving from the live xDS snapshot. Implemented by
+// dispatch.RouteInspector.
+type RouteInspector interface {
+	EnvironmentRoutes(ctx context.Context, environment string) (*dispatch.EnvironmentRoutes, error)
+	ListenerResource(ctx context.Context, environment string) (*listenerv3.Listener, error)
+}
+
+// RoutesHandler serves environments' route tables and xDS listeners.
+type RoutesHandler struct {
+	inspector RouteInspector
+}
+
+// NewRoutesHandler creates a new routes handler. inspector may be nil, in
+// which case route tables and listeners are reported as unavailable.
+func NewRoutesHandler(inspector RouteInspector) *RoutesHandler {
+	return &RoutesHandler{inspector: inspector}
+}
+
+// HandleGetRoutes handles GET /api/v1/environments/{id}/routes
+// Returns the environment's routes across all its deployments, in the
+// order Envoy matches them, so shadowed routes can be spotted.
+func (h *RoutesHandler) HandleGetRoutes(w http.ResponseWriter, r *http.Request) {
+	if h.inspector == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "route inspection is not available")
+		return
+	}
+
+	routes, err := h.inspector.EnvironmentRoutes(r.Context(), r.PathValue("id"))
+	if errors.Is(err, dispatch.ErrNotServed) || errors.Is(err, dispatch.ErrAmbiguousEnvironment) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, routes)
+}
+
+// HandleGetListenerXDS handles GET /api/v1/listeners/{name}/xds
+// Returns the xDS listener generated for the listener as protojson, or
+// 404 while its gateway's snapshot does not hold it yet.
+func (h *RoutesHandler) HandleGetListenerXDS(w http.ResponseWriter, r *http.Request) {
+	if h.inspector == nil {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "route inspection is not available")
+		return
+	}
+
+	l, err := h.inspector.ListenerResource(r.Context(), r.PathValue("name"))
+	if errors.Is(err, dispatch.ErrNotServed) {
+		httputil.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, dispatch.ErrAmbiguousEnvironment) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleStoreError(w, err)
+		return
+	}
+
+	data, err := protojson.Marshal(l)
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, "encode listener: "+err.Error())
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, json.RawMessage(data))
+}
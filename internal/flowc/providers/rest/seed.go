@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// SeedManagedBy is the owner recorded on resources created from a seed file.
+const SeedManagedBy = "seed"
+
+// SeedManifest is the YAML document read by Seed. Resources are applied in
+// order, in the same kind/metadata/spec shape accepted by POST /api/v1/apply,
+// so gateways should come before the listeners that reference them. Bundle
+// paths are relative to the manifest's directory.
+type SeedManifest struct {
+	Resources []map[string]any `yaml:"resources"`
+	Bundles   []SeedBundle     `yaml:"bundles"`
+}
+
+// SeedBundle names a ZIP bundle to deploy as if it had been uploaded.
+type SeedBundle struct {
+	Path string `yaml:"path"`
+}
+
+// Seed loads the manifest at path and writes its resources and bundles to
+// the store through resources and uploads (uploads may be nil when the
+// manifest lists no bundles). A resource or bundle that fails is logged
+// and skipped; with strict, Seed stops at the first failure and returns it.
+func Seed(ctx context.Context, resources *ResourceHandler, uploads *UploadHandler, path string, strict bool, log *logger.EnvoyLogger) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read seed file: %w", err)
+	}
+	var manifest SeedManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("parse seed file %s: %w", path, err)
+	}
+
+	// fail reports a failed item: fatal when strict, logged otherwise.
+	fail := func(err error) error {
+		if strict {
+			return err
+		}
+		if log != nil {
+			log.WithError(err).Warn("Skipping seed item")
+		}
+		return nil
+	}
+
+	for i, res := range manifest.Resources {
+		raw, err := json.Marshal(res)
+		if err != nil {
+			if err := fail(fmt.Errorf("seed resource %d: %w", i, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := seedResults(resources.Apply(ctx, []json.RawMessage{raw}, SeedManagedBy), log, fail); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range manifest.Bundles {
+		bundlePath := b.Path
+		if !filepath.IsAbs(bundlePath) {
+			bundlePath = filepath.Join(filepath.Dir(path), bundlePath)
+		}
+		results, err := seedBundle(ctx, uploads, bundlePath)
+		if err != nil {
+			if err := fail(fmt.Errorf("seed bundle %s: %w", b.Path, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := seedResults(results, log, fail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedBundle deploys the ZIP bundle at path.
+func seedBundle(ctx context.Context, uploads *UploadHandler, path string) ([]ApplyResultItem, error) {
+	if uploads == nil {
+		return nil, errors.New("bundle uploads are not available")
+	}
+	zipData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return uploads.Upload(ctx, zipData, SeedManagedBy)
+}
+
+// seedResults logs each applied item and passes failed ones to fail.
+func seedResults(results []ApplyResultItem, log *logger.EnvoyLogger, fail func(error) error) error {
+	for _, item := range results {
+		if item.Error != "" {
+			if err := fail(fmt.Errorf("seed %s %q: %s", item.Kind, item.Name, item.Error)); err != nil {
+				return err
+			}
+			continue
+		}
+		if log != nil {
+			log.WithFields(map[string]any{
+				"kind":   item.Kind,
+				"name":   item.Name,
+				"action": item.Action,
+			}).Info("Seeded resource")
+		}
+	}
+	return nil
+}
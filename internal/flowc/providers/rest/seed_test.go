@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/store"
+)
+
+const seedManifest = `
+resources:
+  - kind: Gateway
+    metadata:
+      name: edge
+    spec:
+      nodeId: edge-node
+  - kind: Listener
+    metadata:
+      name: http
+    spec:
+      gatewayRef: edge
+      port: 8080
+      hostnames: ["api.example.com"]
+`
+
+func writeSeed(t *testing.T, manifest string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write seed file: %v", err)
+	}
+	return path
+}
+
+func TestSeedGatewayFromManifest(t *testing.T) {
+	s := store.NewMemoryStore()
+	err := Seed(context.Background(), NewResourceHandler(s, nil), nil, writeSeed(t, seedManifest), true, nil)
+	if err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+
+	gw, err := s.Get(context.Background(), store.ResourceKey{Kind: "Gateway", Name: "edge"})
+	if err != nil {
+		t.Fatalf("get gateway: %v", err)
+	}
+	if gw.Meta.ManagedBy != SeedManagedBy {
+		t.Errorf("managedBy = %q, want %q", gw.Meta.ManagedBy, SeedManagedBy)
+	}
+	if got := listenerPort(t, s, "http"); got != 8080 {
+		t.Errorf("listener port = %d, want 8080", got)
+	}
+}
+
+func TestSeedFailures(t *testing.T) {
+	// The gateway is owned by another manager, so seeding it conflicts.
+	newStore := func() store.Store {
+		s := store.NewMemoryStore()
+		putResource(t, s, store.StoreMeta{Kind: "Gateway", Name: "edge"}, `{"nodeId":"other"}`, "kubernetes")
+		return s
+	}
+	path := writeSeed(t, seedManifest)
+
+	s := newStore()
+	if err := Seed(context.Background(), NewResourceHandler(s, nil), nil, path, false, nil); err != nil {
+		t.Fatalf("non-strict Seed: %v", err)
+	}
+	if got := listenerPort(t, s, "http"); got != 8080 {
+		t.Errorf("listener after skipped gateway: port = %d, want 8080", got)
+	}
+
+	s = newStore()
+	if err := Seed(context.Background(), NewResourceHandler(s, nil), nil, path, true, nil); err == nil {
+		t.Fatal("strict Seed succeeded despite a conflicting gateway")
+	}
+	if _, err := s.Get(context.Background(), store.ResourceKey{Kind: "Listener", Name: "http"}); !isNotFound(err) {
+		t.Errorf("strict Seed kept going after the failure: get listener err = %v", err)
+	}
+}
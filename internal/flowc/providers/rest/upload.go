@@ -1,11 +1,17 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
+	flowcv1alpha1 "github.com/flowc-labs/flowc/api/v1alpha1"
 	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
 	"github.com/flowc-labs/flowc/internal/flowc/providers/rest/loader"
 	"github.com/flowc-labs/flowc/internal/flowc/store"
@@ -13,24 +19,54 @@ import (
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
+// ErrInvalidBundle is returned when an uploaded bundle is not a valid ZIP
+// or cannot be loaded.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// ErrNoListener is returned when a bundle deploys to a port its gateway
+// has no listener on.
+var ErrNoListener = errors.New("no listener on port")
+
+// ErrNoPreviousVersion is returned when rolling back a deployment that has
+// no previous bundle to return to.
+var ErrNoPreviousVersion = errors.New("no previous version to roll back to")
+
 // UploadHandler handles ZIP bundle uploads and converts them to API + Deployment resources.
 type UploadHandler struct {
 	store        store.Store
+	bundles      store.BundleStore
 	bundleLoader *loader.BundleLoader
 	logger       *logger.EnvoyLogger
+	limiter      *DeployRateLimiter
 }
 
-// NewUploadHandler creates a new upload handler.
-func NewUploadHandler(s store.Store, log *logger.EnvoyLogger) *UploadHandler {
+// NewUploadHandler creates a new upload handler. When bundles is non-nil,
+// the uploaded ZIP is kept under the name of the Deployment it creates.
+func NewUploadHandler(s store.Store, bundles store.BundleStore, log *logger.EnvoyLogger) *UploadHandler {
 	return &UploadHandler{
 		store:        s,
+		bundles:      bundles,
 		bundleLoader: loader.NewBundleLoader(),
 		logger:       log,
 	}
 }
 
+// SetDeployRateLimiter throttles bundle uploads that deploy, per target
+// gateway. A nil limiter (the default) disables throttling.
+func (h *UploadHandler) SetDeployRateLimiter(l *DeployRateLimiter) {
+	h.limiter = l
+}
+
+// SetParseLimiter bounds the bundles parsed at once across uploads. A nil
+// limiter (the default) places no limit.
+func (h *UploadHandler) SetParseLimiter(p *loader.ParseLimiter) {
+	h.bundleLoader.SetParseLimiter(p)
+}
+
 // HandleUpload handles POST /api/v1/upload
 // Accepts a multipart ZIP file, creates an API resource and optionally a Deployment resource.
+// An optional variables field holds a JSON object of the values of the
+// ${NAME} references in the bundle's flowc.yaml.
 func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
@@ -51,20 +87,170 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var vars map[string]string
+	if v := r.FormValue("variables"); v != "" {
+		if err := json.Unmarshal([]byte(v), &vars); err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "variables must be a JSON object of strings: "+err.Error())
+			return
+		}
+	}
+
+	managedBy := r.Header.Get("X-Managed-By")
+	if managedBy == "" {
+		managedBy = "upload"
+	}
+
+	result, err := h.UploadWithVariables(r.Context(), zipData, vars, managedBy)
+	if errors.Is(err, ErrInvalidBundle) || errors.Is(err, ErrNoListener) {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, ErrDeployRateLimited) {
+		httputil.WriteError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, ApplyResult{Results: result})
+}
+
+// Upload creates the API resource described by a ZIP bundle and, when
+// the bundle names a gateway, a Deployment of it. Bundles that cannot be
+// read are reported as ErrInvalidBundle. A deploy to a port the gateway
+// has no listener on fails with ErrNoListener, and one over the gateway's
+// rate limit with ErrDeployRateLimited, before anything is stored.
+func (h *UploadHandler) Upload(ctx context.Context, zipData []byte, managedBy string) ([]ApplyResultItem, error) {
+	return h.UploadWithVariables(ctx, zipData, nil, managedBy)
+}
+
+// UploadWithVariables uploads a bundle like Upload, resolving the ${NAME}
+// references of its flowc.yaml from vars first. A reference with neither
+// a value nor a default makes the bundle invalid. The bundle is kept
+// unresolved, as uploaded, with vars alongside so it can be rolled back
+// to.
+func (h *UploadHandler) UploadWithVariables(ctx context.Context, zipData []byte, vars map[string]string, managedBy string) ([]ApplyResultItem, error) {
+	result, err := h.apply(ctx, zipData, vars, managedBy)
+	if err != nil {
+		return nil, err
+	}
+	if dep := deployedItem(result); dep != nil && h.bundles != nil {
+		if err := h.bundles.Put(ctx, dep.Name, zipData, vars); err != nil {
+			dep.Error = "failed to store bundle: " + err.Error()
+		}
+	}
+	return result, nil
+}
+
+// Rollback redeploys the bundle deployment was uploaded from before its
+// current one: its API and Deployment resources are rewritten from that
+// bundle, resolved with the variables it was uploaded with, and the
+// reconciler re-translates them. The two bundles then swap places, so
+// rolling back again returns to the version rolled back from. It fails
+// with ErrNoPreviousVersion when no previous bundle is kept.
+func (h *UploadHandler) Rollback(ctx context.Context, deployment, managedBy string) ([]ApplyResultItem, error) {
+	if h.bundles == nil {
+		return nil, fmt.Errorf("%w: bundles are not kept", ErrNoPreviousVersion)
+	}
+	previous, vars, err := h.bundles.Previous(ctx, deployment)
+	if isNotFound(err) {
+		return nil, fmt.Errorf("%w: deployment %q", ErrNoPreviousVersion, deployment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.apply(ctx, previous, vars, managedBy)
+	if err != nil {
+		return nil, err
+	}
+	dep := deployedItem(result)
+	if dep == nil || dep.Name != deployment {
+		return nil, fmt.Errorf("previous bundle of %q does not deploy it", deployment)
+	}
+	if err := h.bundles.Rollback(ctx, deployment); err != nil {
+		dep.Error = "failed to swap bundles: " + err.Error()
+	}
+	return result, nil
+}
+
+// listenerOnPort names the listener of gateway on port. A gateway that is
+// not stored yet gets the conventional port-<port> name, so the deployment
+// resolves once the gateway and its listener are created. A stored
+// gateway without a listener on port fails with ErrNoListener, naming the
+// ports it does listen on.
+func (h *UploadHandler) listenerOnPort(ctx context.Context, gateway string, port uint32) (string, error) {
+	if _, err := h.store.Get(ctx, store.ResourceKey{Kind: "Gateway", Name: gateway}); isNotFound(err) {
+		return fmt.Sprintf("port-%d", port), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	listeners, err := h.store.List(ctx, store.ListFilter{Kind: "Listener"})
+	if err != nil {
+		return "", err
+	}
+	var ports []uint32
+	for _, res := range listeners {
+		var spec flowcv1alpha1.ListenerSpec
+		if json.Unmarshal(res.SpecJSON, &spec) != nil || spec.GatewayRef != gateway {
+			continue
+		}
+		if spec.Port == port {
+			return res.Meta.Name, nil
+		}
+		ports = append(ports, spec.Port)
+	}
+	if len(ports) == 0 {
+		return "", fmt.Errorf("%w: gateway %q has no listeners; none on %d", ErrNoListener, gateway, port)
+	}
+	slices.Sort(ports)
+	list := make([]string, len(ports))
+	for i, p := range ports {
+		list[i] = strconv.FormatUint(uint64(p), 10)
+	}
+	return "", fmt.Errorf("%w: gateway %q has listeners on ports [%s]; none on %d", ErrNoListener, gateway, strings.Join(list, ","), port)
+}
+
+// deployedItem returns the result of the Deployment apply wrote, or nil
+// when it wrote none.
+func deployedItem(result []ApplyResultItem) *ApplyResultItem {
+	for i := range result {
+		if result[i].Kind == "Deployment" && result[i].Action != "failed" {
+			return &result[i]
+		}
+	}
+	return nil
+}
+
+// apply writes the API and Deployment resources described by a ZIP
+// bundle, as documented on UploadWithVariables, without storing the bundle.
+func (h *UploadHandler) apply(ctx context.Context, zipData []byte, vars map[string]string, managedBy string) ([]ApplyResultItem, error) {
 	// Validate ZIP
 	if err := bundle.ValidateZip(zipData); err != nil {
-		httputil.WriteError(w, http.StatusBadRequest, "invalid zip: "+err.Error())
-		return
+		return nil, fmt.Errorf("%w: invalid zip: %v", ErrInvalidBundle, err)
 	}
 
 	// Load bundle
-	deploymentBundle, err := h.bundleLoader.LoadBundle(zipData)
+	deploymentBundle, err := h.bundleLoader.LoadBundleWithVariables(zipData, vars)
 	if err != nil {
-		httputil.WriteError(w, http.StatusBadRequest, "failed to parse bundle: "+err.Error())
-		return
+		return nil, fmt.Errorf("%w: failed to parse bundle: %v", ErrInvalidBundle, err)
 	}
 
 	meta := deploymentBundle.FlowCMetadata
+	deploys := meta.Gateway.GatewayID != "" || meta.Gateway.NodeID != ""
+	var listener string
+	if deploys {
+		gateway := coalesce(meta.Gateway.GatewayID, meta.Gateway.NodeID)
+		if listener, err = h.listenerOnPort(ctx, gateway, meta.Gateway.Port); err != nil {
+			return nil, err
+		}
+		if err := h.limiter.Allow(gateway); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create API resource spec
 	apiSpec := map[string]any{
@@ -78,28 +264,38 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 			"port":    meta.Upstream.Port,
 			"scheme":  meta.Upstream.Scheme,
 			"timeout": meta.Upstream.Timeout,
+			"hosts":   meta.Upstream.Hosts,
 		},
 	}
 
+	routing := map[string]any{}
+	if meta.Gateway.VirtualHost.GroupByTag {
+		routing["groupByTag"] = true
+	}
+	if len(meta.Gateway.VirtualHost.QueryParams) > 0 {
+		routing["queryParams"] = meta.Gateway.VirtualHost.QueryParams
+	}
+	if meta.Gateway.VirtualHost.UseExisting != "" {
+		routing["virtualHost"] = meta.Gateway.VirtualHost.UseExisting
+	}
+	if len(routing) > 0 {
+		apiSpec["routing"] = routing
+	}
+
 	apiName := meta.Name
 	apiSpecJSON, _ := json.Marshal(apiSpec)
 	apiStored := &store.StoredResource{
 		Meta: store.StoreMeta{
-			Kind: "API",
-			Name: apiName,
+			Kind:   "API",
+			Name:   apiName,
+			Labels: meta.Labels,
 		},
 		SpecJSON: apiSpecJSON,
 	}
 
-	managedBy := r.Header.Get("X-Managed-By")
-	if managedBy == "" {
-		managedBy = "upload"
-	}
-
-	apiOut, err := h.store.Put(r.Context(), apiStored, store.PutOptions{ManagedBy: managedBy})
+	apiOut, err := h.store.Put(ctx, apiStored, store.PutOptions{ManagedBy: managedBy})
 	if err != nil {
-		httputil.WriteError(w, http.StatusInternalServerError, "failed to store API: "+err.Error())
-		return
+		return nil, fmt.Errorf("failed to store API: %w", err)
 	}
 
 	result := []ApplyResultItem{
@@ -111,29 +307,36 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// If gateway config is present, create a Deployment resource too
-	if meta.Gateway.GatewayID != "" || meta.Gateway.NodeID != "" {
+	if deploys {
 		depName := fmt.Sprintf("%s-deploy", apiName)
 		depSpec := map[string]any{
 			"apiRef": apiName,
 			"gateway": map[string]any{
 				"name":     coalesce(meta.Gateway.GatewayID, meta.Gateway.NodeID),
-				"listener": fmt.Sprintf("port-%d", meta.Gateway.Port),
+				"listener": listener,
 			},
 		}
 		if meta.Strategy != nil {
 			depSpec["strategy"] = meta.Strategy
 		}
+		if meta.ExtraRouteConfig != nil {
+			depSpec["extraRouteConfig"] = meta.ExtraRouteConfig
+		}
+		if meta.ExtraClusterConfig != nil {
+			depSpec["extraClusterConfig"] = meta.ExtraClusterConfig
+		}
 
 		depSpecJSON, _ := json.Marshal(depSpec)
 		depStored := &store.StoredResource{
 			Meta: store.StoreMeta{
-				Kind: "Deployment",
-				Name: depName,
+				Kind:   "Deployment",
+				Name:   depName,
+				Labels: meta.Labels,
 			},
 			SpecJSON: depSpecJSON,
 		}
 
-		depOut, err := h.store.Put(r.Context(), depStored, store.PutOptions{ManagedBy: managedBy})
+		depOut, err := h.store.Put(ctx, depStored, store.PutOptions{ManagedBy: managedBy})
 		if err != nil {
 			// API was created but deployment failed
 			result = append(result, ApplyResultItem{
@@ -151,6 +354,32 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	return result, nil
+}
+
+// HandleRollback handles POST /api/v1/deployments/{name}/rollback
+// Redeploys the bundle the deployment was uploaded from before its
+// current one.
+func (h *UploadHandler) HandleRollback(w http.ResponseWriter, r *http.Request) {
+	managedBy := r.Header.Get("X-Managed-By")
+	if managedBy == "" {
+		managedBy = "upload"
+	}
+
+	result, err := h.Rollback(r.Context(), r.PathValue("name"), managedBy)
+	if errors.Is(err, ErrNoPreviousVersion) || errors.Is(err, ErrNoListener) {
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, ErrDeployRateLimited) {
+		httputil.WriteError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		httputil.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	httputil.WriteJSON(w, http.StatusOK, ApplyResult{Results: result})
 }
 
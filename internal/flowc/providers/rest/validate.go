@@ -0,0 +1,265 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/flowc-labs/flowc/internal/flowc/httpsrv/httputil"
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/providers/rest/loader"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
+	"github.com/flowc-labs/flowc/pkg/bundle"
+	"github.com/flowc-labs/flowc/pkg/logger"
+	"github.com/flowc-labs/flowc/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic stages, in the order they run.
+const (
+	StageBundle      = "bundle"
+	StageMetadata    = "metadata"
+	StageSpec        = "spec"
+	StageTranslation = "translation"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// BundleDiagnostic is a single problem found while validating a bundle.
+// Pointer is set for spec diagnostics that carry a location in the spec.
+type BundleDiagnostic struct {
+	Stage    string `json:"stage"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Pointer  string `json:"pointer,omitempty"`
+}
+
+// BundleValidationResult is the response for a bundle validation request.
+// Valid is false when any diagnostic has error severity.
+type BundleValidationResult struct {
+	Valid       bool               `json:"valid"`
+	Diagnostics []BundleDiagnostic `json:"diagnostics"`
+}
+
+// ValidateHandler lints ZIP bundles without touching the store. Nothing
+// about the target gateway, listener, or environment needs to exist.
+type ValidateHandler struct {
+	parsers *ir.ParserRegistry
+	limiter *loader.ParseLimiter
+	logger  *logger.EnvoyLogger
+}
+
+// NewValidateHandler creates a new bundle validation handler.
+func NewValidateHandler(log *logger.EnvoyLogger) *ValidateHandler {
+	return &ValidateHandler{
+		parsers: ir.DefaultParserRegistry(),
+		logger:  log,
+	}
+}
+
+// SetParseLimiter bounds the bundles parsed and dry-run translated at
+// once. A nil limiter (the default) places no limit.
+func (h *ValidateHandler) SetParseLimiter(p *loader.ParseLimiter) {
+	h.limiter = p
+}
+
+// HandleValidate handles POST /api/v1/bundles:validate
+// Accepts a multipart ZIP file and returns every diagnostic found. Responds
+// 200 when the bundle is valid and 422 otherwise.
+func (h *ValidateHandler) HandleValidate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "failed to parse multipart form: "+err.Error())
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "file field is required")
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	zipData, err := io.ReadAll(file)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+
+	result := h.ValidateBundle(r.Context(), zipData)
+
+	status := http.StatusOK
+	if !result.Valid {
+		status = http.StatusUnprocessableEntity
+	}
+	httputil.WriteJSON(w, status, result)
+}
+
+// ValidateBundle runs the bundle checks in order: ZIP structure, flowc.yaml,
+// spec validation and parsing, and a dry-run translation against a
+// synthetic gateway/listener. Later stages are skipped only when an
+// earlier stage leaves them nothing to work with.
+func (h *ValidateHandler) ValidateBundle(ctx context.Context, zipData []byte) *BundleValidationResult {
+	result := &BundleValidationResult{Diagnostics: []BundleDiagnostic{}}
+	add := func(d BundleDiagnostic) {
+		result.Diagnostics = append(result.Diagnostics, d)
+	}
+	report := func(stage, severity string, err error) {
+		add(BundleDiagnostic{Stage: stage, Severity: severity, Message: err.Error()})
+	}
+	defer func() {
+		result.Valid = true
+		for _, d := range result.Diagnostics {
+			if d.Severity == SeverityError {
+				result.Valid = false
+				break
+			}
+		}
+	}()
+
+	// Bundle structure
+	if err := bundle.ValidateZip(zipData); err != nil {
+		report(StageBundle, SeverityError, err)
+		return result
+	}
+	flowcYAML, specInfo, err := bundle.ExtractFiles(zipData, "")
+	if err != nil {
+		report(StageBundle, SeverityError, err)
+		return result
+	}
+
+	// flowc.yaml
+	var meta types.FlowCMetadata
+	if err := yaml.Unmarshal(flowcYAML, &meta); err != nil {
+		report(StageMetadata, SeverityError, err)
+		return result
+	}
+	for _, err := range unjoin(loader.ValidateMetadata(&meta)) {
+		report(StageMetadata, SeverityError, err)
+	}
+	if meta.APIType == "" {
+		meta.APIType = string(specAPIType(specInfo.APIType))
+	}
+
+	// Spec parsing and translation are the costly stages; run them in a
+	// parse slot.
+	if err := h.limiter.Acquire(ctx); err != nil {
+		report(StageSpec, SeverityError, err)
+		return result
+	}
+	defer h.limiter.Release()
+
+	// Spec
+	irAPI := h.validateSpec(ctx, ir.APIType(meta.APIType), specInfo.Data, add)
+
+	// Dry-run translation. Needs a routable upstream; missing fields were
+	// already reported against flowc.yaml.
+	if len(meta.Upstream.Hosts) == 0 && !loader.IsDynamicForwardProxy(&meta) &&
+		(meta.Upstream.Host == "" || meta.Upstream.Port == 0) {
+		return result
+	}
+	if irAPI != nil {
+		irAPI.Metadata.BasePath = loader.NormalizeBasePath(meta.Context)
+	}
+	if err := h.dryRunTranslate(ctx, &meta, irAPI); err != nil {
+		report(StageTranslation, SeverityError, err)
+	}
+
+	return result
+}
+
+// validateSpec validates and parses the spec, returning the IR when parsing
+// succeeds. API types without a registered parser yield a warning. Parsers
+// that return ir.ValidationIssues get one diagnostic per issue.
+func (h *ValidateHandler) validateSpec(ctx context.Context, apiType ir.APIType, data []byte, add func(BundleDiagnostic)) *ir.API {
+	report := func(severity string, err error) {
+		add(BundleDiagnostic{Stage: StageSpec, Severity: severity, Message: err.Error()})
+	}
+
+	parser, err := h.parsers.GetParser(apiType)
+	if err != nil {
+		report(SeverityWarning, err)
+		return nil
+	}
+	if err := parser.Validate(ctx, data); err != nil {
+		var issues ir.ValidationIssues
+		if errors.As(err, &issues) {
+			for _, issue := range issues {
+				add(BundleDiagnostic{
+					Stage:    StageSpec,
+					Severity: string(issue.Severity),
+					Message:  issue.Message,
+					Pointer:  issue.Pointer,
+				})
+			}
+		} else {
+			report(SeverityError, err)
+		}
+	}
+	irAPI, err := parser.Parse(ctx, data)
+	if err != nil {
+		report(SeverityError, err)
+		return nil
+	}
+	return irAPI
+}
+
+// dryRunTranslate runs the composite translator for the bundle against a
+// synthetic single-listener gateway. Results are discarded.
+func (h *ValidateHandler) dryRunTranslate(ctx context.Context, meta *types.FlowCMetadata, irAPI *ir.API) error {
+	const target = "bundle-validate"
+
+	dep := &models.APIDeployment{
+		ID:       target,
+		Name:     meta.Name,
+		Version:  meta.Version,
+		Context:  meta.Context,
+		Metadata: *meta,
+	}
+
+	resolved := translator.NewConfigResolver(nil, nil, h.logger).Resolve(meta.Strategy)
+	strategies, err := translator.NewStrategyFactory(nil, h.logger).CreateStrategySet(resolved, dep)
+	if err != nil {
+		return err
+	}
+	composite, err := translator.NewCompositeTranslator(strategies, nil, h.logger)
+	if err != nil {
+		return err
+	}
+	composite.SetTranslationContext(&translator.TranslationContext{
+		Gateway:     &models.Gateway{ID: target, NodeID: target, Name: target},
+		Listener:    &models.Listener{ID: target, GatewayID: target, Port: meta.Gateway.Port, Address: "0.0.0.0"},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: target, Name: "*", Hostname: "*"},
+	})
+
+	_, err = composite.Translate(ctx, dep, irAPI, target)
+	return err
+}
+
+// specAPIType maps a detected spec file type onto an IR API type.
+func specAPIType(detected string) ir.APIType {
+	switch detected {
+	case "asyncapi":
+		return ir.APITypeWebSocket
+	case "":
+		return ir.APITypeREST
+	default:
+		return ir.APIType(detected)
+	}
+}
+
+// unjoin splits an errors.Join result back into its parts.
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
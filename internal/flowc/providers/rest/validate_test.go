@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flowc-labs/flowc/pkg/bundle"
+)
+
+const testFlowCYAML = `name: petstore
+version: v1
+context: /petstore
+upstream:
+  host: petstore.local
+  port: 8080
+`
+
+const testOpenAPIYAML = `openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+`
+
+func postBundle(t *testing.T, h *ValidateHandler, zipData []byte) (*httptest.ResponseRecorder, BundleValidationResult) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "bundle.zip")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	if _, err := fw.Write(zipData); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bundles:validate", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleValidate(rec, req)
+
+	var result BundleValidationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec, result
+}
+
+func makeZip(t *testing.T, flowcYAML, spec string) []byte {
+	t.Helper()
+	zipData, err := bundle.CreateZip([]byte(flowcYAML), []byte(spec), "openapi.yaml")
+	if err != nil {
+		t.Fatalf("CreateZip: %v", err)
+	}
+	return zipData
+}
+
+func TestValidateBundleValid(t *testing.T) {
+	rec, result := postBundle(t, NewValidateHandler(nil), makeZip(t, testFlowCYAML, testOpenAPIYAML))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !result.Valid || len(result.Diagnostics) != 0 {
+		t.Errorf("expected valid bundle with no diagnostics, got %+v", result)
+	}
+}
+
+func TestValidateBundleBrokenOpenAPI(t *testing.T) {
+	broken := "openapi: 3.0.0\ninfo: [title\npaths:\n  /pets: {\n"
+	rec, result := postBundle(t, NewValidateHandler(nil), makeZip(t, testFlowCYAML, broken))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if result.Valid {
+		t.Error("expected bundle to be invalid")
+	}
+	specErrors := 0
+	for _, d := range result.Diagnostics {
+		if d.Stage == StageSpec && d.Severity == SeverityError {
+			specErrors++
+		}
+	}
+	if specErrors == 0 {
+		t.Errorf("expected spec parse errors, got %+v", result.Diagnostics)
+	}
+}
+
+func TestValidateBundleReportsAllMetadataErrors(t *testing.T) {
+	_, result := postBundle(t, NewValidateHandler(nil), makeZip(t, "name: petstore\n", testOpenAPIYAML))
+
+	metadataErrors := 0
+	for _, d := range result.Diagnostics {
+		if d.Stage == StageMetadata {
+			metadataErrors++
+		}
+	}
+	// version, context, upstream.host, upstream.port
+	if metadataErrors != 4 {
+		t.Errorf("expected 4 metadata errors, got %d: %+v", metadataErrors, result.Diagnostics)
+	}
+}
@@ -20,38 +20,54 @@ import (
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/internal/flowc/store"
 	"github.com/flowc-labs/flowc/internal/flowc/xds/cache"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/translator"
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
 // Reconciler watches the resource store for changes and drives xDS
 // translation through the dispatch package.
 type Reconciler struct {
-	store      store.Store
-	indexer    *index.Indexer
-	dispatcher *dispatch.Dispatcher
-	log        *logger.EnvoyLogger
+	store       store.Store
+	indexer     *index.Indexer
+	dispatcher  *dispatch.Dispatcher
+	gateways    *dispatch.GatewayTranslator
+	deployments *dispatch.DeploymentTranslator
+	log         *logger.EnvoyLogger
 }
 
 // NewReconciler wires the indexer, dispatcher, and per-kind translators.
-// The returned reconciler is ready to Start; nothing has run yet.
+// defaults configures the listener of gateways without Listener
+// resources. The returned reconciler is ready to Start; nothing has run yet.
 func NewReconciler(
 	s store.Store,
 	cm *cache.ConfigManager,
 	parsers *ir.ParserRegistry,
+	defaults dispatch.DefaultListener,
 	log *logger.EnvoyLogger,
 ) *Reconciler {
 	idx := index.New(log)
 	disp := dispatch.New(dispatch.DefaultDebounce, log)
-	disp.Register(dispatch.NewGatewayTranslator(idx, cm, parsers, log))
-	disp.Register(dispatch.NewDeploymentTranslator(idx, cm, parsers, log))
+	gateways := dispatch.NewGatewayTranslator(idx, cm, parsers, defaults, log)
+	deployments := dispatch.NewDeploymentTranslator(idx, cm, parsers, defaults, log)
+	disp.Register(gateways)
+	disp.Register(deployments)
 	return &Reconciler{
-		store:      s,
-		indexer:    idx,
-		dispatcher: disp,
-		log:        log,
+		store:       s,
+		indexer:     idx,
+		dispatcher:  disp,
+		gateways:    gateways,
+		deployments: deployments,
+		log:         log,
 	}
 }
 
+// SetTranslatorOptions replaces the options the gateway and deployment
+// translators run with. Call it before Start.
+func (r *Reconciler) SetTranslatorOptions(options *translator.TranslatorOptions) {
+	r.gateways.SetOptions(options)
+	r.deployments.SetOptions(options)
+}
+
 // Start runs the reconciler loop: bootstrap the indexer from the store,
 // do a full rebuild for every known gateway, then enter the watch loop.
 // Blocks until ctx is cancelled or the watch channel closes.
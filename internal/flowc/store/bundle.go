@@ -0,0 +1,396 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BundleStore persists the uploaded ZIP bundle a deployment was created
+// from, keyed by deployment name, so it can be downloaded or re-applied
+// later. The bundles it replaced are kept too, up to the store's history
+// depth, so the deployment can be rolled back. Bundles are kept as
+// uploaded, with the variables their ${NAME} references were resolved
+// with alongside.
+type BundleStore interface {
+	// Put stores zipData, resolved with vars, as the deployment's current
+	// bundle. The bundle it replaces becomes the previous one.
+	Put(ctx context.Context, deployment string, zipData []byte, vars map[string]string) error
+
+	// Get returns the current bundle for the deployment, or ErrNotFound.
+	Get(ctx context.Context, deployment string) ([]byte, error)
+
+	// Previous returns the bundle the current one replaced and the
+	// variables it was resolved with, or ErrNotFound.
+	Previous(ctx context.Context, deployment string) ([]byte, map[string]string, error)
+
+	// Rollback swaps the deployment's current and previous bundles, or
+	// returns ErrNotFound when there is no previous bundle.
+	Rollback(ctx context.Context, deployment string) error
+
+	// Delete removes every bundle for the deployment, or returns
+	// ErrNotFound.
+	Delete(ctx context.Context, deployment string) error
+}
+
+// DefaultBundleHistory is the number of bundles kept per deployment, the
+// current one included: enough to roll back once.
+const DefaultBundleHistory = 2
+
+// MemoryBundleStore is an in-memory implementation of BundleStore.
+type MemoryBundleStore struct {
+	mu      sync.RWMutex
+	depth   int
+	bundles map[string][]memoryBundle // newest first
+}
+
+// memoryBundle is a bundle kept by MemoryBundleStore.
+type memoryBundle struct {
+	zipData []byte
+	vars    map[string]string
+}
+
+// NewMemoryBundleStore creates a new in-memory bundle store keeping
+// DefaultBundleHistory bundles per deployment.
+func NewMemoryBundleStore() *MemoryBundleStore {
+	return &MemoryBundleStore{depth: DefaultBundleHistory, bundles: make(map[string][]memoryBundle)}
+}
+
+// SetHistory sets the number of bundles kept per deployment, the current
+// one included. Values below 1 keep only the current bundle.
+func (s *MemoryBundleStore) SetHistory(depth int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depth = max(depth, 1)
+}
+
+func (s *MemoryBundleStore) Put(ctx context.Context, deployment string, zipData []byte, vars map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := memoryBundle{zipData: append([]byte(nil), zipData...), vars: maps.Clone(vars)}
+	versions := append([]memoryBundle{b}, s.bundles[deployment]...)
+	s.bundles[deployment] = versions[:min(len(versions), s.depth)]
+	return nil
+}
+
+func (s *MemoryBundleStore) Get(ctx context.Context, deployment string) ([]byte, error) {
+	zipData, _, err := s.version(ctx, deployment, 0)
+	return zipData, err
+}
+
+func (s *MemoryBundleStore) Previous(ctx context.Context, deployment string) ([]byte, map[string]string, error) {
+	return s.version(ctx, deployment, 1)
+}
+
+// version returns the deployment's i-th newest bundle and its variables.
+func (s *MemoryBundleStore) version(ctx context.Context, deployment string, i int) ([]byte, map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.bundles[deployment]
+	if i >= len(versions) {
+		return nil, nil, ErrNotFound
+	}
+	return append([]byte(nil), versions[i].zipData...), maps.Clone(versions[i].vars), nil
+}
+
+func (s *MemoryBundleStore) Rollback(ctx context.Context, deployment string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.bundles[deployment]
+	if len(versions) < 2 {
+		return ErrNotFound
+	}
+	versions[0], versions[1] = versions[1], versions[0]
+	return nil
+}
+
+func (s *MemoryBundleStore) Delete(ctx context.Context, deployment string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.bundles[deployment]; !ok {
+		return ErrNotFound
+	}
+	delete(s.bundles, deployment)
+	return nil
+}
+
+// FileBundleStore stores each deployment's current bundle as
+// <dir>/<deployment>.zip and the ones it replaced as
+// <dir>/.history/<deployment>/<n>.zip, n = 1 being the previous one. The
+// variables of a bundle, when it has any, are kept next to it in a
+// .vars.json file readable only by the owner, as they may hold secrets.
+type FileBundleStore struct {
+	dir   string
+	depth int
+
+	// mu serializes writers: a Put or Rollback moves several files.
+	mu sync.Mutex
+}
+
+// historyDir is the subdirectory of the bundle directory previous bundles
+// are kept in. Current bundles are files ending in .zip, so it cannot
+// clash with one.
+const historyDir = ".history"
+
+// NewFileBundleStore creates a bundle store rooted at dir, creating the
+// directory if it does not exist. It keeps DefaultBundleHistory bundles
+// per deployment.
+func NewFileBundleStore(dir string) (*FileBundleStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create bundle directory: %w", err)
+	}
+	return &FileBundleStore{dir: dir, depth: DefaultBundleHistory}, nil
+}
+
+// SetHistory sets the number of bundles kept per deployment, the current
+// one included. Values below 1 keep only the current bundle.
+func (s *FileBundleStore) SetHistory(depth int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depth = max(depth, 1)
+}
+
+func (s *FileBundleStore) Put(ctx context.Context, deployment string, zipData []byte, vars map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := s.path(deployment)
+	if err != nil {
+		return err
+	}
+
+	// Write to temporary files and rename so readers never see a
+	// partially written bundle.
+	tmp, err := s.writeTemp(zipData)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+	var tmpVars string
+	if len(vars) > 0 {
+		data, err := json.Marshal(vars)
+		if err != nil {
+			return fmt.Errorf("encode bundle variables: %w", err)
+		}
+		if tmpVars, err = s.writeTemp(data); err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(tmpVars) }()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.shiftHistory(deployment, path); err != nil {
+		return fmt.Errorf("keep previous bundle: %w", err)
+	}
+	if tmpVars != "" {
+		if err := os.Rename(tmpVars, varsPath(path)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(varsPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeTemp writes data to a new temporary file, readable only by the
+// owner, in the bundle directory and returns its name.
+func (s *FileBundleStore) writeTemp(data []byte) (string, error) {
+	tmp, err := os.CreateTemp(s.dir, ".bundle-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// shiftHistory moves each of the deployment's bundles one place back in
+// its history, the current one becoming the previous one. The oldest is
+// overwritten once the history is full.
+func (s *FileBundleStore) shiftHistory(deployment, path string) error {
+	if s.depth < 2 {
+		return nil
+	}
+	if err := os.MkdirAll(s.historyPath(deployment, 0), 0o755); err != nil {
+		return err
+	}
+	for n := s.depth - 1; n > 1; n-- {
+		if err := moveBundle(s.historyPath(deployment, n-1), s.historyPath(deployment, n)); err != nil {
+			return err
+		}
+	}
+	return moveBundle(path, s.historyPath(deployment, 1))
+}
+
+// moveBundle renames the bundle at from, and its variables, to to. The
+// variables of a bundle at to are dropped when the one at from has none.
+// A missing bundle at from is not an error.
+func moveBundle(from, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	err := os.Rename(varsPath(from), varsPath(to))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = os.Remove(varsPath(to))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// varsPath is the file holding the variables of the bundle at path.
+func varsPath(path string) string {
+	return strings.TrimSuffix(path, ".zip") + ".vars.json"
+}
+
+func (s *FileBundleStore) Get(ctx context.Context, deployment string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := s.path(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	return readBundle(path)
+}
+
+func (s *FileBundleStore) Previous(ctx context.Context, deployment string) ([]byte, map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.path(deployment); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.historyPath(deployment, 1)
+	zipData, err := readBundle(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(varsPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return zipData, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var vars map[string]string
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, nil, fmt.Errorf("decode bundle variables: %w", err)
+	}
+	return zipData, vars, nil
+}
+
+func (s *FileBundleStore) Rollback(ctx context.Context, deployment string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := s.path(deployment)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.historyPath(deployment, 1)
+	if _, err := os.Stat(previous); errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	swap := filepath.Join(s.historyPath(deployment, 0), "swap.zip")
+	if err := moveBundle(path, swap); err != nil {
+		return err
+	}
+	if err := moveBundle(previous, path); err != nil {
+		return err
+	}
+	return moveBundle(swap, previous)
+}
+
+func readBundle(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *FileBundleStore) Delete(ctx context.Context, deployment string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := s.path(deployment)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(varsPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.RemoveAll(s.historyPath(deployment, 0))
+}
+
+// path maps a deployment name to its file, rejecting names that would
+// escape the bundle directory.
+func (s *FileBundleStore) path(deployment string) (string, error) {
+	if deployment == "" || deployment == "." || deployment == ".." || strings.ContainsAny(deployment, `/\`) {
+		return "", fmt.Errorf("%w: invalid deployment name %q", ErrInvalidResource, deployment)
+	}
+	return filepath.Join(s.dir, deployment+".zip"), nil
+}
+
+// historyPath is the file of the deployment's n-th previous bundle, or
+// with n = 0 the directory holding them. deployment must have passed path.
+func (s *FileBundleStore) historyPath(deployment string, n int) string {
+	dir := filepath.Join(s.dir, historyDir, deployment)
+	if n == 0 {
+		return dir
+	}
+	return filepath.Join(dir, fmt.Sprintf("%d.zip", n))
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBundleStorePutGet(t *testing.T) {
+	fileStore, err := NewFileBundleStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBundleStore: %v", err)
+	}
+	stores := map[string]BundleStore{
+		"memory": NewMemoryBundleStore(),
+		"file":   fileStore,
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, err := s.Get(ctx, "petstore-deploy"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get before Put: err = %v, want ErrNotFound", err)
+			}
+
+			zipData := []byte("PK\x03\x04bundle")
+			if err := s.Put(ctx, "petstore-deploy", zipData, nil); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			got, err := s.Get(ctx, "petstore-deploy")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if !bytes.Equal(got, zipData) {
+				t.Errorf("Get = %q, want %q", got, zipData)
+			}
+
+			if err := s.Delete(ctx, "petstore-deploy"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := s.Get(ctx, "petstore-deploy"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestFileBundleStoreRejectsPathNames(t *testing.T) {
+	s, err := NewFileBundleStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBundleStore: %v", err)
+	}
+	for _, name := range []string{"", "..", "../escape", "a/b"} {
+		if err := s.Put(context.Background(), name, []byte("x"), nil); !errors.Is(err, ErrInvalidResource) {
+			t.Errorf("Put(%q): err = %v, want ErrInvalidResource", name, err)
+		}
+	}
+}
+
+func TestBundleStoreHistory(t *testing.T) {
+	fileStore, err := NewFileBundleStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBundleStore: %v", err)
+	}
+	fileStore.SetHistory(3)
+	memoryStore := NewMemoryBundleStore()
+	memoryStore.SetHistory(3)
+	stores := map[string]BundleStore{
+		"memory": memoryStore,
+		"file":   fileStore,
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			expect := func(get func(context.Context, string) ([]byte, error), what, want string) {
+				t.Helper()
+				got, err := get(ctx, "petstore-deploy")
+				if err != nil {
+					t.Fatalf("%s: %v", what, err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", what, got, want)
+				}
+			}
+			// previous checks the previous bundle and that it keeps the
+			// variables it was put with: each version's own name.
+			previous := func(what, want string) {
+				t.Helper()
+				got, vars, err := s.Previous(ctx, "petstore-deploy")
+				if err != nil {
+					t.Fatalf("%s: %v", what, err)
+				}
+				if string(got) != want || vars["VERSION"] != want {
+					t.Errorf("%s = %q with variables %v, want %q", what, got, vars, want)
+				}
+			}
+
+			if err := s.Put(ctx, "petstore-deploy", []byte("v1"), map[string]string{"VERSION": "v1"}); err != nil {
+				t.Fatalf("Put v1: %v", err)
+			}
+			if _, _, err := s.Previous(ctx, "petstore-deploy"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Previous of the first bundle: err = %v, want ErrNotFound", err)
+			}
+			if err := s.Rollback(ctx, "petstore-deploy"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Rollback of the first bundle: err = %v, want ErrNotFound", err)
+			}
+
+			for _, v := range []string{"v2", "v3", "v4"} {
+				if err := s.Put(ctx, "petstore-deploy", []byte(v), map[string]string{"VERSION": v}); err != nil {
+					t.Fatalf("Put %s: %v", v, err)
+				}
+			}
+			expect(s.Get, "Get", "v4")
+			previous("Previous", "v3")
+
+			// Rolling back swaps the two newest, so doing it twice
+			// returns to where it started.
+			if err := s.Rollback(ctx, "petstore-deploy"); err != nil {
+				t.Fatalf("Rollback: %v", err)
+			}
+			expect(s.Get, "Get after rollback", "v3")
+			previous("Previous after rollback", "v4")
+			if err := s.Rollback(ctx, "petstore-deploy"); err != nil {
+				t.Fatalf("second Rollback: %v", err)
+			}
+			expect(s.Get, "Get after second rollback", "v4")
+
+			if err := s.Delete(ctx, "petstore-deploy"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, _, err := s.Previous(ctx, "petstore-deploy"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Previous after Delete: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
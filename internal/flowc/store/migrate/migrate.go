@@ -0,0 +1,149 @@
+// Package migrate applies ordered, idempotent schema migrations for the
+// SQL-backed store backends. Each backend passes its own migrations to
+// Apply from its constructor; applied versions are recorded in the
+// schema_migrations table so that Apply can run on every startup.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// TableName is the bookkeeping table that records applied migration versions.
+const TableName = "schema_migrations"
+
+// Migration is a single schema change identified by a monotonically
+// increasing Version. Up is executed inside a transaction together with the
+// bookkeeping insert, so a migration is either fully applied or not at all.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+}
+
+// migrationNamePattern restricts names to identifiers that can be embedded
+// in SQL literals without escaping.
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Apply applies the pending subset of migrations to db in version order.
+// Versions already recorded in schema_migrations are skipped, including ones
+// a concurrently starting instance records first. On databases without
+// transactional DDL both instances may run Up, so statements should be
+// idempotent (CREATE TABLE IF NOT EXISTS and the like). Once
+// released, a migration must never be edited; schema changes are new
+// migrations with a higher Version.
+func Apply(ctx context.Context, db *sql.DB, migrations []Migration) error {
+	ordered, err := validate(migrations)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("failed to create %s table: %w", TableName, err)
+	}
+
+	applied, err := AppliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ordered {
+		if applied[m.Version] {
+			continue
+		}
+		if err := applyOne(ctx, db, m); err != nil {
+			// Another instance starting at the same time may have applied m
+			// since applied was read; its record then makes ours fail on the
+			// primary key, and the migration is done either way.
+			if recorded, rerr := AppliedVersions(ctx, db); rerr == nil && recorded[m.Version] {
+				continue
+			}
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// AppliedVersions returns the set of migration versions recorded in
+// schema_migrations.
+func AppliedVersions(ctx context.Context, db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version FROM "+TableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TableName, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", TableName, err)
+		}
+		applied[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TableName, err)
+	}
+	return applied, nil
+}
+
+const createTableSQL = `CREATE TABLE IF NOT EXISTS ` + TableName + ` (
+	version    BIGINT       NOT NULL PRIMARY KEY,
+	name       VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// applyOne runs a single migration and records it in one transaction.
+func applyOne(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// Values are inlined rather than bound so the statement is portable across
+	// placeholder styles ($1 vs ?). Name is validated against
+	// migrationNamePattern, so no escaping is needed.
+	record := fmt.Sprintf("INSERT INTO %s (version, name) VALUES (%d, '%s')", TableName, m.Version, m.Name)
+	if _, err := tx.ExecContext(ctx, record); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to record version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// validate checks that versions are positive and unique and that names are
+// safe, returning the migrations sorted by version.
+func validate(migrations []Migration) ([]Migration, error) {
+	ordered := make([]Migration, len(migrations))
+	copy(ordered, migrations)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	for i, m := range ordered {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has non-positive version %d", m.Name, m.Version)
+		}
+		if i > 0 && ordered[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		if !migrationNamePattern.MatchString(m.Name) {
+			return nil, fmt.Errorf("migration %d has invalid name %q", m.Version, m.Name)
+		}
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no statements", m.Version, m.Name)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,229 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDB is a minimal in-memory database/sql driver that understands just
+// enough SQL to exercise the migration bookkeeping.
+type fakeDB struct {
+	mu      sync.Mutex
+	applied []int64
+	execs   []string
+
+	// barrier, when set, holds the first readers reads of schema_migrations
+	// until all of them have taken their snapshot, so concurrent Apply calls
+	// see the same pending versions.
+	barrier *sync.WaitGroup
+	reads   int
+	readers int
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+type fakeConn struct {
+	db      *fakeDB
+	pending []int64
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{conn: c}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.execs = append(c.db.execs, query)
+
+	var version int64
+	if _, err := fmt.Sscanf(query, "INSERT INTO "+TableName+" (version, name) VALUES (%d,", &version); err == nil {
+		c.pending = append(c.pending, version)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, "SELECT version FROM "+TableName) {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	c.db.mu.Lock()
+	rows := &fakeRows{versions: append([]int64(nil), c.db.applied...)}
+	wait := c.db.barrier != nil && c.db.reads < c.db.readers
+	c.db.reads++
+	c.db.mu.Unlock()
+	if wait {
+		c.db.barrier.Done()
+		c.db.barrier.Wait()
+	}
+	return rows, nil
+}
+
+type fakeTx struct{ conn *fakeConn }
+
+func (t *fakeTx) Commit() error {
+	t.conn.db.mu.Lock()
+	defer t.conn.db.mu.Unlock()
+	for _, v := range t.conn.pending {
+		for _, applied := range t.conn.db.applied {
+			if v == applied {
+				t.conn.pending = nil
+				return fmt.Errorf("duplicate key value violates primary key of %s: version %d", TableName, v)
+			}
+		}
+	}
+	t.conn.db.applied = append(t.conn.db.applied, t.conn.pending...)
+	t.conn.pending = nil
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.pending = nil
+	return nil
+}
+
+type fakeRows struct {
+	versions []int64
+	pos      int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.versions) {
+		return io.EOF
+	}
+	dest[0] = r.versions[r.pos]
+	r.pos++
+	return nil
+}
+
+// fakeDrivers keeps registered driver names unique across -count reruns.
+var fakeDrivers atomic.Int64
+
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	name := fmt.Sprintf("migrate-fake-%s-%d", t.Name(), fakeDrivers.Add(1))
+	sql.Register(name, fake)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, fake
+}
+
+func countExecs(execs []string, stmt string) int {
+	n := 0
+	for _, e := range execs {
+		if e == stmt {
+			n++
+		}
+	}
+	return n
+}
+
+// testMigrations stand in for a SQL backend's schema.
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_items", Up: "CREATE TABLE items (id INTEGER)"},
+	{Version: 2, Name: "create_tags", Up: "CREATE TABLE tags (id INTEGER)"},
+}
+
+func TestApplyIdempotent(t *testing.T) {
+	db, fake := openFake(t)
+	ctx := context.Background()
+
+	if err := Apply(ctx, db, testMigrations); err != nil {
+		t.Fatalf("first Apply: %v", err)
+	}
+	if got := len(fake.applied); got != len(testMigrations) {
+		t.Fatalf("expected %d applied versions, got %d", len(testMigrations), got)
+	}
+
+	if err := Apply(ctx, db, testMigrations); err != nil {
+		t.Fatalf("second Apply: %v", err)
+	}
+	if got := len(fake.applied); got != len(testMigrations) {
+		t.Errorf("second run changed applied versions: got %d, want %d", got, len(testMigrations))
+	}
+	for _, m := range testMigrations {
+		if got := countExecs(fake.execs, m.Up); got != 1 {
+			t.Errorf("migration %d executed %d times, want 1", m.Version, got)
+		}
+	}
+}
+
+func TestApplyConcurrent(t *testing.T) {
+	const instances = 4
+	db, fake := openFake(t)
+	db.SetMaxOpenConns(instances)
+	fake.barrier = &sync.WaitGroup{}
+	fake.barrier.Add(instances)
+	fake.readers = instances
+
+	errs := make(chan error, instances)
+	for i := 0; i < instances; i++ {
+		go func() { errs <- Apply(context.Background(), db, testMigrations) }()
+	}
+	for i := 0; i < instances; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("concurrent Apply: %v", err)
+		}
+	}
+
+	counts := make(map[int64]int)
+	for _, v := range fake.applied {
+		counts[v]++
+	}
+	for _, m := range testMigrations {
+		if counts[m.Version] != 1 {
+			t.Errorf("version %d recorded %d times, want 1", m.Version, counts[m.Version])
+		}
+	}
+}
+
+func TestApplyOrdersByVersion(t *testing.T) {
+	db, fake := openFake(t)
+
+	migrations := []Migration{
+		{Version: 2, Name: "second", Up: "CREATE TABLE b (id INTEGER)"},
+		{Version: 1, Name: "first", Up: "CREATE TABLE a (id INTEGER)"},
+	}
+	if err := Apply(context.Background(), db, migrations); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(fake.applied) != 2 || fake.applied[0] != 1 || fake.applied[1] != 2 {
+		t.Errorf("expected versions applied in order [1 2], got %v", fake.applied)
+	}
+}
+
+func TestApplyRejectsInvalidMigrations(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{"duplicate version", []Migration{{Version: 1, Name: "a", Up: "x"}, {Version: 1, Name: "b", Up: "y"}}},
+		{"zero version", []Migration{{Version: 0, Name: "a", Up: "x"}}},
+		{"unsafe name", []Migration{{Version: 1, Name: "a'; DROP", Up: "x"}}},
+		{"empty up", []Migration{{Version: 1, Name: "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := openFake(t)
+			if err := Apply(context.Background(), db, tt.migrations); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
@@ -3,9 +3,9 @@
 //
 // Two distinct write paths exist:
 //
-//   - DeployAPI / UnDeployAPI: per-deployment merge + remove. Operates on
-//     clusters / endpoints / routes, never touches listeners. Used by the
-//     dispatch package's DeploymentTranslator.
+//   - DeployAPI / UpdateAPI / UnDeployAPI / BulkUpdate: per-deployment
+//     merge + remove. Operates on clusters / endpoints / routes, never touches
+//     listeners. Used by the dispatch package's DeploymentTranslator.
 //
 //   - ReplaceSnapshot: full-snapshot replace including listeners. Used by
 //     the dispatch package's GatewayTranslator for full gateway rebuilds
@@ -17,7 +17,9 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
@@ -27,36 +29,330 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/protobuf/proto"
+
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
 // ConfigManager manages xDS configuration snapshots per Envoy node.
 type ConfigManager struct {
 	cache  cachev3.SnapshotCache
+	retry  RetryOptions
+	limits ResourceLimits
 	logger *logger.EnvoyLogger
+
+	consistency     ConsistencyMode
+	nodeConsistency map[string]ConsistencyMode
+
+	locksMu   sync.Mutex
+	nodeLocks map[string]*sync.Mutex
+}
+
+// RetryOptions bounds how UpdateSnapshot retries a snapshot that fails its
+// consistency check or cannot be set on the cache.
+type RetryOptions struct {
+	// Attempts is the total number of tries, including the first. Values
+	// below 1 are treated as 1 (no retry).
+	Attempts int
+
+	// Backoff is the delay before the second attempt. It doubles after
+	// every further failure, capped at MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryOptions returns the retry bounds used by NewConfigManager.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		Attempts:   3,
+		Backoff:    50 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+}
+
+// ConsistencyMode selects what UpdateSnapshot does with a snapshot whose
+// resources reference resources it does not hold: a listener's route
+// config, a route's cluster or an EDS cluster's endpoints.
+type ConsistencyMode string
+
+const (
+	// ConsistencyStrict rejects inconsistent snapshots. It is the
+	// default.
+	ConsistencyStrict ConsistencyMode = "strict"
+
+	// ConsistencyBestEffort installs inconsistent snapshots and logs a
+	// warning, so multi-step updates may publish partial states (a route
+	// before its cluster). Envoy serves them as they are: requests routed
+	// to a missing cluster fail with 503, and listeners and clusters
+	// waiting for a missing route config or endpoints stay warming, so
+	// they serve nothing until a later snapshot fills the gap.
+	ConsistencyBestEffort ConsistencyMode = "best-effort"
+)
+
+// ParseConsistencyMode parses a consistency mode name; "" is
+// ConsistencyStrict.
+func ParseConsistencyMode(s string) (ConsistencyMode, error) {
+	switch mode := ConsistencyMode(s); mode {
+	case "":
+		return ConsistencyStrict, nil
+	case ConsistencyStrict, ConsistencyBestEffort:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown consistency mode %q (must be %s or %s)", s, ConsistencyStrict, ConsistencyBestEffort)
+	}
+}
+
+// ErrNodeResourceLimitExceeded is returned (wrapped in a
+// *ResourceLimitError) when a new snapshot would hold more resources than
+// the manager's ResourceLimits allow.
+var ErrNodeResourceLimitExceeded = errors.New("node resource limit exceeded")
+
+// ResourceLimits caps the number of xDS resources one node's snapshot may
+// hold, per type and in total. Zero means unlimited.
+type ResourceLimits struct {
+	Clusters  int
+	Endpoints int
+	Listeners int
+	Routes    int
+	Total     int
+}
+
+// ResourceLimitError reports which limit a rejected snapshot exceeded.
+// Type is the xDS type URL, or "total" for the overall limit.
+type ResourceLimitError struct {
+	NodeID string
+	Type   string
+	Count  int
+	Limit  int
+}
+
+func (e *ResourceLimitError) Error() string {
+	return fmt.Sprintf("node %s: %d %s resources exceed the limit of %d", e.NodeID, e.Count, e.Type, e.Limit)
+}
+
+func (e *ResourceLimitError) Unwrap() error { return ErrNodeResourceLimitExceeded }
+
+// ErrDuplicateResourceName is returned (wrapped in a
+// *DuplicateResourceError) when a deployment or snapshot passed to
+// DeployAPI or ReplaceSnapshot holds two resources of the same type and
+// name. The snapshot would otherwise keep only one of them.
+var ErrDuplicateResourceName = errors.New("duplicate resource name")
+
+// DuplicateResourceError reports the first resource a rejected
+// deployment or snapshot names twice. Type is the xDS type URL.
+type DuplicateResourceError struct {
+	NodeID string
+	Type   string
+	Name   string
 }
 
+func (e *DuplicateResourceError) Error() string {
+	return fmt.Sprintf("node %s: %s resource %q is defined more than once", e.NodeID, e.Type, e.Name)
+}
+
+func (e *DuplicateResourceError) Unwrap() error { return ErrDuplicateResourceName }
+
 // NewConfigManager creates a new configuration manager.
 func NewConfigManager(cache cachev3.SnapshotCache, log *logger.EnvoyLogger) *ConfigManager {
 	return &ConfigManager{
-		cache:  cache,
-		logger: log,
+		cache:       cache,
+		retry:       DefaultRetryOptions(),
+		logger:      log,
+		consistency: ConsistencyStrict,
+		nodeLocks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// LockNode blocks until the caller holds nodeID's deploy lock and returns
+// the function releasing it. DeployAPI, UnDeployAPI and ReplaceSnapshot
+// read-modify-write the node's snapshot without locking; translators hold
+// this lock across translate-and-publish so deploys to the same node
+// serialize while different nodes proceed in parallel.
+func (cm *ConfigManager) LockNode(nodeID string) (unlock func()) {
+	cm.locksMu.Lock()
+	mu, ok := cm.nodeLocks[nodeID]
+	if !ok {
+		mu = &sync.Mutex{}
+		cm.nodeLocks[nodeID] = mu
 	}
+	cm.locksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// SetRetryOptions replaces the snapshot install retry bounds. Not safe to
+// call concurrently with snapshot updates; set it before serving.
+func (cm *ConfigManager) SetRetryOptions(opts RetryOptions) {
+	cm.retry = opts
+}
+
+// SetResourceLimits replaces the per-node resource limits enforced by
+// DeployAPI and ReplaceSnapshot. Not safe to call concurrently with
+// snapshot updates; set it before serving.
+func (cm *ConfigManager) SetResourceLimits(limits ResourceLimits) {
+	cm.limits = limits
+}
+
+// SetConsistencyMode sets the consistency mode of nodes without one of
+// their own. Not safe to call concurrently with snapshot updates; set it
+// before serving.
+func (cm *ConfigManager) SetConsistencyMode(mode ConsistencyMode) {
+	cm.consistency = mode
+}
+
+// SetNodeConsistencyMode sets nodeID's consistency mode, overriding the
+// manager's. Not safe to call concurrently with snapshot updates; set it
+// before serving.
+func (cm *ConfigManager) SetNodeConsistencyMode(nodeID string, mode ConsistencyMode) {
+	if cm.nodeConsistency == nil {
+		cm.nodeConsistency = make(map[string]ConsistencyMode)
+	}
+	cm.nodeConsistency[nodeID] = mode
+}
+
+// consistencyMode returns the consistency mode of nodeID.
+func (cm *ConfigManager) consistencyMode(nodeID string) ConsistencyMode {
+	if mode, ok := cm.nodeConsistency[nodeID]; ok {
+		return mode
+	}
+	return cm.consistency
+}
+
+// checkLimits rejects a node's new resource set if it exceeds a limit.
+func (cm *ConfigManager) checkLimits(nodeID string, resources map[resourcev3.Type][]types.Resource) error {
+	perType := []struct {
+		typ   resourcev3.Type
+		limit int
+	}{
+		{resourcev3.ClusterType, cm.limits.Clusters},
+		{resourcev3.EndpointType, cm.limits.Endpoints},
+		{resourcev3.ListenerType, cm.limits.Listeners},
+		{resourcev3.RouteType, cm.limits.Routes},
+	}
+	total := 0
+	for _, t := range perType {
+		n := len(resources[t.typ])
+		total += n
+		if t.limit > 0 && n > t.limit {
+			return &ResourceLimitError{NodeID: nodeID, Type: t.typ, Count: n, Limit: t.limit}
+		}
+	}
+	if cm.limits.Total > 0 && total > cm.limits.Total {
+		return &ResourceLimitError{NodeID: nodeID, Type: "total", Count: total, Limit: cm.limits.Total}
+	}
+	return nil
+}
+
+// checkDuplicates rejects a resource set that names a resource of one
+// type more than once. Endpoints are keyed by cluster name.
+func checkDuplicates(nodeID string, resources map[resourcev3.Type][]types.Resource) error {
+	for _, typ := range []resourcev3.Type{
+		resourcev3.ClusterType,
+		resourcev3.EndpointType,
+		resourcev3.ListenerType,
+		resourcev3.RouteType,
+	} {
+		seen := make(map[string]struct{}, len(resources[typ]))
+		for _, res := range resources[typ] {
+			name := cachev3.GetResourceName(res)
+			if _, dup := seen[name]; dup {
+				return &DuplicateResourceError{NodeID: nodeID, Type: typ, Name: name}
+			}
+			seen[name] = struct{}{}
+		}
+	}
+	return nil
 }
 
 // UpdateSnapshot updates the configuration snapshot for a given node ID.
-// Validates internal consistency before installing.
+// Validates internal consistency before installing, rejecting an
+// inconsistent snapshot unless the node's ConsistencyMode is
+// ConsistencyBestEffort. Failures are retried
+// with exponential backoff within the manager's RetryOptions so a
+// transiently wedged cache doesn't fail the deploy; the last error is
+// returned once attempts run out.
 func (cm *ConfigManager) UpdateSnapshot(nodeID string, snapshot *cachev3.Snapshot) error {
-	if err := snapshot.Consistent(); err != nil {
-		return fmt.Errorf("snapshot inconsistent: %w", err)
+	attempts := max(cm.retry.Attempts, 1)
+	backoff := cm.retry.Backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = cm.setSnapshot(nodeID, snapshot); err == nil {
+			cm.logger.Infof("Updated snapshot for node %s", nodeID)
+			return nil
+		}
+		if attempt >= attempts {
+			return err
+		}
+		cm.logger.WithFields(map[string]any{
+			"node":    nodeID,
+			"attempt": attempt,
+			"error":   err.Error(),
+		}).Warn("Snapshot update failed; retrying")
+		time.Sleep(backoff)
+		backoff *= 2
+		if cm.retry.MaxBackoff > 0 && backoff > cm.retry.MaxBackoff {
+			backoff = cm.retry.MaxBackoff
+		}
+	}
+}
+
+// setSnapshot is a single consistency check + install attempt.
+func (cm *ConfigManager) setSnapshot(nodeID string, snapshot *cachev3.Snapshot) error {
+	if err := checkConsistency(snapshot); err != nil {
+		if cm.consistencyMode(nodeID) != ConsistencyBestEffort {
+			return fmt.Errorf("snapshot inconsistent: %w", err)
+		}
+		cm.logger.WithFields(map[string]any{
+			"node":  nodeID,
+			"error": err.Error(),
+		}).Warn("Installing inconsistent snapshot (best-effort consistency)")
 	}
 	if err := cm.cache.SetSnapshot(context.Background(), nodeID, snapshot); err != nil {
 		return fmt.Errorf("failed to set snapshot: %w", err)
 	}
-	cm.logger.Infof("Updated snapshot for node %s", nodeID)
 	return nil
 }
 
+// checkConsistency reports a snapshot that references resources it does
+// not hold. Snapshot.Consistent covers route configs and endpoints; the
+// clusters routes point at are checked here.
+func checkConsistency(snapshot *cachev3.Snapshot) error {
+	if err := snapshot.Consistent(); err != nil {
+		return err
+	}
+	clusters := snapshot.GetResources(resourcev3.ClusterType)
+	for _, res := range snapshot.GetResources(resourcev3.RouteType) {
+		rc, ok := res.(*routev3.RouteConfiguration)
+		if !ok {
+			continue
+		}
+		for _, vh := range rc.GetVirtualHosts() {
+			for _, r := range vh.GetRoutes() {
+				for _, name := range routeClusters(r.GetRoute()) {
+					if _, ok := clusters[name]; !ok {
+						return fmt.Errorf("route config %q routes to missing cluster %q", rc.Name, name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// routeClusters returns the clusters action routes to by name.
+func routeClusters(action *routev3.RouteAction) []string {
+	if name := action.GetCluster(); name != "" {
+		return []string{name}
+	}
+	var names []string
+	for _, cw := range action.GetWeightedClusters().GetClusters() {
+		names = append(names, cw.GetName())
+	}
+	return names
+}
+
 // GetSnapshot retrieves the current snapshot for a given node ID.
 func (cm *ConfigManager) GetSnapshot(nodeID string) (*cachev3.Snapshot, error) {
 	snapshot, err := cm.cache.GetSnapshot(nodeID)
@@ -97,6 +393,21 @@ type APIDeployment struct {
 	Routes    []*routev3.RouteConfiguration
 }
 
+// resources returns the deployment's resources by type.
+func (d *APIDeployment) resources() map[resourcev3.Type][]types.Resource {
+	out := make(map[resourcev3.Type][]types.Resource, 3)
+	for _, c := range d.Clusters {
+		out[resourcev3.ClusterType] = append(out[resourcev3.ClusterType], c)
+	}
+	for _, e := range d.Endpoints {
+		out[resourcev3.EndpointType] = append(out[resourcev3.EndpointType], e)
+	}
+	for _, r := range d.Routes {
+		out[resourcev3.RouteType] = append(out[resourcev3.RouteType], r)
+	}
+	return out
+}
+
 // Snapshot is the complete xDS resource set for one node, used by
 // ReplaceSnapshot for full gateway rebuilds. Includes listeners since
 // rebuilds reconstruct the entire snapshot including the listener layer.
@@ -110,78 +421,11 @@ type Snapshot struct {
 // DeployAPI merges a single deployment's clusters / endpoints / routes
 // into the node's existing snapshot. Dedup by name means re-deploying the
 // same deployment replaces (rather than duplicates) its xDS resources.
-// Listeners pass through unchanged from the previous snapshot.
+// Listeners pass through unchanged from the previous snapshot. A
+// deployment that names one of its own resources twice is rejected with a
+// *DuplicateResourceError before the snapshot is read.
 func (cm *ConfigManager) DeployAPI(nodeID string, deployment *APIDeployment) error {
-	snapshot, err := cm.GetSnapshot(nodeID)
-	if err != nil {
-		snapshot, err = cm.CreateEmptySnapshot(nodeID)
-		if err != nil {
-			return fmt.Errorf("failed to create snapshot: %w", err)
-		}
-	}
-
-	resources := make(map[resourcev3.Type][]types.Resource)
-
-	// Dedup clusters by name.
-	clusterMap := make(map[string]types.Resource)
-	for _, res := range snapshot.GetResources(resourcev3.ClusterType) {
-		if c, ok := res.(*clusterv3.Cluster); ok {
-			clusterMap[c.Name] = res
-		}
-	}
-	for _, c := range deployment.Clusters {
-		clusterMap[c.Name] = c
-	}
-	clusterResources := make([]types.Resource, 0, len(clusterMap))
-	for _, res := range clusterMap {
-		clusterResources = append(clusterResources, res)
-	}
-	resources[resourcev3.ClusterType] = clusterResources
-
-	// Dedup endpoints by ClusterName.
-	endpointMap := make(map[string]types.Resource)
-	for _, res := range snapshot.GetResources(resourcev3.EndpointType) {
-		if e, ok := res.(*endpointv3.ClusterLoadAssignment); ok {
-			endpointMap[e.ClusterName] = res
-		}
-	}
-	for _, e := range deployment.Endpoints {
-		endpointMap[e.ClusterName] = e
-	}
-	endpointResources := make([]types.Resource, 0, len(endpointMap))
-	for _, res := range endpointMap {
-		endpointResources = append(endpointResources, res)
-	}
-	resources[resourcev3.EndpointType] = endpointResources
-
-	// Dedup routes by name.
-	routeMap := make(map[string]types.Resource)
-	for _, res := range snapshot.GetResources(resourcev3.RouteType) {
-		if r, ok := res.(*routev3.RouteConfiguration); ok {
-			routeMap[r.Name] = res
-		}
-	}
-	for _, r := range deployment.Routes {
-		routeMap[r.Name] = r
-	}
-	routeResources := make([]types.Resource, 0, len(routeMap))
-	for _, res := range routeMap {
-		routeResources = append(routeResources, res)
-	}
-	resources[resourcev3.RouteType] = routeResources
-
-	// Listeners pass through untouched — they're owned by the gateway-
-	// scoped path (ReplaceSnapshot), never published per-deployment.
-	resources[resourcev3.ListenerType] = convertResourceMap(snapshot.GetResources(resourcev3.ListenerType))
-
-	// Monotonic timestamp version: count-based versions can go backwards
-	// on resource removal and cause Envoy to skip updates.
-	newVersion := fmt.Sprintf("%d", time.Now().UnixNano())
-	newSnapshot, err := cachev3.NewSnapshot(newVersion, resources)
-	if err != nil {
-		return fmt.Errorf("failed to create new snapshot: %w", err)
-	}
-	return cm.UpdateSnapshot(nodeID, newSnapshot)
+	return cm.BulkUpdate(nodeID, deployment, ResourceNames{})
 }
 
 // ResourceNames identifies the named xDS resources owned by a single API
@@ -193,6 +437,11 @@ type ResourceNames struct {
 	Clusters  []string
 	Endpoints []string // by ClusterName
 	Routes    []string
+
+	// HTTPFilters names the HCM filters the deployment contributed to its
+	// listener. They live inside listeners, so UnDeployAPI ignores them;
+	// callers use them to decide whether a listener rebuild is needed.
+	HTTPFilters []string
 }
 
 // UnDeployAPI removes named clusters, endpoints, and routes from a node's
@@ -203,72 +452,125 @@ type ResourceNames struct {
 // Removal is idempotent: missing names are silently skipped, missing
 // snapshots return nil.
 func (cm *ConfigManager) UnDeployAPI(nodeID string, names ResourceNames) error {
-	snapshot, err := cm.GetSnapshot(nodeID)
-	if err != nil {
+	if _, err := cm.GetSnapshot(nodeID); err != nil {
 		return nil
 	}
+	return cm.BulkUpdate(nodeID, nil, names)
+}
 
-	dropClusters := stringSet(names.Clusters)
-	dropEndpoints := stringSet(names.Endpoints)
-	dropRoutes := stringSet(names.Routes)
-
-	resources := make(map[resourcev3.Type][]types.Resource)
-
-	keepClusters := make([]types.Resource, 0)
-	for _, res := range snapshot.GetResources(resourcev3.ClusterType) {
-		c, ok := res.(*clusterv3.Cluster)
-		if !ok {
-			keepClusters = append(keepClusters, res)
-			continue
-		}
-		if _, drop := dropClusters[c.Name]; drop {
-			continue
-		}
-		keepClusters = append(keepClusters, res)
+// BulkUpdate removes the named clusters, endpoints and routes from the
+// node's snapshot and merges upsert's resources into it, publishing both
+// as one new snapshot version. A resource both removed and upserted keeps
+// the upserted version, so a route config still referenced by a listener
+// can be swapped for a placeholder while the clusters it routed to are
+// removed. upsert may be nil; listeners pass through unchanged. An upsert
+// naming one of its resources twice is rejected with a
+// *DuplicateResourceError before the snapshot is read. Resource types
+// the update leaves alone keep their version, so Envoy is not sent them
+// again.
+func (cm *ConfigManager) BulkUpdate(nodeID string, upsert *APIDeployment, remove ResourceNames) error {
+	if upsert == nil {
+		upsert = &APIDeployment{}
+	}
+	added := upsert.resources()
+	if err := checkDuplicates(nodeID, added); err != nil {
+		return err
 	}
-	resources[resourcev3.ClusterType] = keepClusters
 
-	keepEndpoints := make([]types.Resource, 0)
-	for _, res := range snapshot.GetResources(resourcev3.EndpointType) {
-		e, ok := res.(*endpointv3.ClusterLoadAssignment)
-		if !ok {
-			keepEndpoints = append(keepEndpoints, res)
-			continue
-		}
-		if _, drop := dropEndpoints[e.ClusterName]; drop {
-			continue
+	snapshot, err := cm.GetSnapshot(nodeID)
+	if err != nil {
+		snapshot, err = cm.CreateEmptySnapshot(nodeID)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
 		}
-		keepEndpoints = append(keepEndpoints, res)
 	}
-	resources[resourcev3.EndpointType] = keepEndpoints
 
-	keepRoutes := make([]types.Resource, 0)
-	for _, res := range snapshot.GetResources(resourcev3.RouteType) {
-		r, ok := res.(*routev3.RouteConfiguration)
-		if !ok {
-			keepRoutes = append(keepRoutes, res)
-			continue
+	removed := map[resourcev3.Type]map[string]struct{}{
+		resourcev3.ClusterType:  stringSet(remove.Clusters),
+		resourcev3.EndpointType: stringSet(remove.Endpoints),
+		resourcev3.RouteType:    stringSet(remove.Routes),
+	}
+	resources := make(map[resourcev3.Type][]types.Resource)
+	touched := make(map[resourcev3.Type]bool)
+	for typ, drop := range removed {
+		// Dedup by name (endpoints by ClusterName): upserted resources
+		// replace the snapshot's.
+		merged := make(map[string]types.Resource)
+		for name, res := range snapshot.GetResources(typ) {
+			if _, ok := drop[name]; !ok {
+				merged[name] = res
+			} else {
+				touched[typ] = true
+			}
 		}
-		if _, drop := dropRoutes[r.Name]; drop {
-			continue
+		for _, res := range added[typ] {
+			merged[cachev3.GetResourceName(res)] = res
+			touched[typ] = true
 		}
-		keepRoutes = append(keepRoutes, res)
+		resources[typ] = convertResourceMap(merged)
 	}
-	resources[resourcev3.RouteType] = keepRoutes
 
+	// Listeners pass through untouched — they're owned by the gateway-
+	// scoped path (ReplaceSnapshot), never published per-deployment.
 	resources[resourcev3.ListenerType] = convertResourceMap(snapshot.GetResources(resourcev3.ListenerType))
 
+	if err := cm.checkLimits(nodeID, resources); err != nil {
+		return err
+	}
+
+	// Monotonic timestamp version: count-based versions can go backwards
+	// on resource removal and cause Envoy to skip updates.
 	newVersion := fmt.Sprintf("%d", time.Now().UnixNano())
 	newSnapshot, err := cachev3.NewSnapshot(newVersion, resources)
 	if err != nil {
 		return fmt.Errorf("failed to create new snapshot: %w", err)
 	}
+	for typ := range resources {
+		if !touched[typ] {
+			newSnapshot.Resources[cachev3.GetResponseType(typ)].Version = snapshot.GetVersion(typ)
+		}
+	}
 	return cm.UpdateSnapshot(nodeID, newSnapshot)
 }
 
+// UpdateAPI republishes a deployment as a delta against the node's
+// snapshot: resources of next equal to the snapshot's are left as they
+// are, changed and new ones are upserted, and those named in prev but not
+// in next are removed, all in one BulkUpdate. Nothing is published when
+// nothing changed. It reports whether a new snapshot was installed.
+func (cm *ConfigManager) UpdateAPI(nodeID string, prev ResourceNames, next *APIDeployment) (bool, error) {
+	if next == nil {
+		next = &APIDeployment{}
+	}
+	if err := checkDuplicates(nodeID, next.resources()); err != nil {
+		return false, err
+	}
+	snapshot, err := cm.GetSnapshot(nodeID)
+	if err != nil {
+		return true, cm.BulkUpdate(nodeID, next, ResourceNames{})
+	}
+
+	upsert := &APIDeployment{
+		Clusters:  changedResources(snapshot.GetResources(resourcev3.ClusterType), next.Clusters),
+		Endpoints: changedResources(snapshot.GetResources(resourcev3.EndpointType), next.Endpoints),
+		Routes:    changedResources(snapshot.GetResources(resourcev3.RouteType), next.Routes),
+	}
+	remove := ResourceNames{
+		Clusters:  staleNames(snapshot.GetResources(resourcev3.ClusterType), prev.Clusters, next.Clusters),
+		Endpoints: staleNames(snapshot.GetResources(resourcev3.EndpointType), prev.Endpoints, next.Endpoints),
+		Routes:    staleNames(snapshot.GetResources(resourcev3.RouteType), prev.Routes, next.Routes),
+	}
+	if len(upsert.Clusters)+len(upsert.Endpoints)+len(upsert.Routes)+
+		len(remove.Clusters)+len(remove.Endpoints)+len(remove.Routes) == 0 {
+		return false, nil
+	}
+	return true, cm.BulkUpdate(nodeID, upsert, remove)
+}
+
 // ReplaceSnapshot sets the node's snapshot to exactly the provided
 // resources. Used for full gateway rebuilds where the dispatcher has
 // re-translated every deployment plus every listener for that gateway.
+// Resources named twice are rejected with a *DuplicateResourceError.
 func (cm *ConfigManager) ReplaceSnapshot(nodeID string, snap *Snapshot) error {
 	resources := make(map[resourcev3.Type][]types.Resource)
 
@@ -296,6 +598,13 @@ func (cm *ConfigManager) ReplaceSnapshot(nodeID string, snap *Snapshot) error {
 	}
 	resources[resourcev3.RouteType] = routes
 
+	if err := checkDuplicates(nodeID, resources); err != nil {
+		return err
+	}
+	if err := cm.checkLimits(nodeID, resources); err != nil {
+		return err
+	}
+
 	newVersion := fmt.Sprintf("%d", time.Now().UnixNano())
 	newSnapshot, err := cachev3.NewSnapshot(newVersion, resources)
 	if err != nil {
@@ -316,8 +625,46 @@ func (cm *ConfigManager) ListNodes() []string {
 	return cm.cache.GetStatusKeys()
 }
 
+// IsConnected reports whether the node currently has an open xDS stream,
+// i.e. at least one outstanding watch on its snapshot.
+func (cm *ConfigManager) IsConnected(nodeID string) bool {
+	info := cm.cache.GetStatusInfo(nodeID)
+	if info == nil {
+		return false
+	}
+	return info.GetNumWatches()+info.GetNumDeltaWatches() > 0
+}
+
 // --- helpers ---
 
+// changedResources returns the items that are not in live as they are.
+func changedResources[T types.Resource](live map[string]types.Resource, items []T) []T {
+	var out []T
+	for _, item := range items {
+		if cur, ok := live[cachev3.GetResourceName(item)]; !ok || !proto.Equal(cur, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// staleNames returns the names in prev that are in live but not among
+// items.
+func staleNames[T types.Resource](live map[string]types.Resource, prev []string, items []T) []string {
+	keep := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		keep[cachev3.GetResourceName(item)] = struct{}{}
+	}
+	var out []string
+	for _, name := range prev {
+		_, kept := keep[name]
+		if _, ok := live[name]; ok && !kept {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
 func stringSet(items []string) map[string]struct{} {
 	out := make(map[string]struct{}, len(items))
 	for _, i := range items {
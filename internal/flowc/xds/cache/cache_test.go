@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/listener"
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// flakyCache fails the first failures SetSnapshot calls, then delegates.
+type flakyCache struct {
+	cachev3.SnapshotCache
+	failures int
+	calls    int
+}
+
+func (c *flakyCache) SetSnapshot(ctx context.Context, node string, snapshot cachev3.ResourceSnapshot) error {
+	c.calls++
+	if c.calls <= c.failures {
+		return errors.New("cache wedged")
+	}
+	return c.SnapshotCache.SetSnapshot(ctx, node, snapshot)
+}
+
+func newFlakyManager(failures int) (*ConfigManager, *flakyCache) {
+	fc := &flakyCache{
+		SnapshotCache: cachev3.NewSnapshotCache(true, cachev3.IDHash{}, nil),
+		failures:      failures,
+	}
+	cm := NewConfigManager(fc, logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	cm.SetRetryOptions(RetryOptions{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	return cm, fc
+}
+
+func TestUpdateSnapshotRetriesTransientFailure(t *testing.T) {
+	cm, fc := newFlakyManager(1)
+	if err := cm.ReplaceSnapshot("node-1", &Snapshot{}); err != nil {
+		t.Fatalf("ReplaceSnapshot: %v", err)
+	}
+	if fc.calls != 2 {
+		t.Errorf("SetSnapshot calls = %d, want 2", fc.calls)
+	}
+	if _, err := cm.GetSnapshot("node-1"); err != nil {
+		t.Errorf("snapshot not installed: %v", err)
+	}
+}
+
+func TestUpdateSnapshotGivesUpAfterAttempts(t *testing.T) {
+	cm, fc := newFlakyManager(5)
+	if err := cm.ReplaceSnapshot("node-1", &Snapshot{}); err == nil {
+		t.Fatal("expected error once retries are exhausted")
+	}
+	if fc.calls != 3 {
+		t.Errorf("SetSnapshot calls = %d, want 3", fc.calls)
+	}
+}
+
+func TestLockNodeSerializesPerNode(t *testing.T) {
+	cm, _ := newFlakyManager(0)
+	unlockA := cm.LockNode("a")
+
+	// A different node is not blocked by a.
+	done := make(chan struct{})
+	go func() {
+		cm.LockNode("b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking node b blocked on node a")
+	}
+
+	// The same node waits for the holder to release.
+	acquired := make(chan struct{})
+	go func() {
+		cm.LockNode("a")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("node a locked twice concurrently")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockA()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("node a lock not handed over after unlock")
+	}
+}
+
+func TestResourceLimitsRejectOversizedSnapshots(t *testing.T) {
+	cm := NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	cm.SetResourceLimits(ResourceLimits{Clusters: 2, Total: 3})
+	cluster := func(name string) *clusterv3.Cluster { return &clusterv3.Cluster{Name: name} }
+
+	if err := cm.DeployAPI("node-1", &APIDeployment{Clusters: []*clusterv3.Cluster{cluster("a"), cluster("b")}}); err != nil {
+		t.Fatalf("DeployAPI within limits: %v", err)
+	}
+
+	err := cm.DeployAPI("node-1", &APIDeployment{Clusters: []*clusterv3.Cluster{cluster("c")}})
+	var limitErr *ResourceLimitError
+	if !errors.Is(err, ErrNodeResourceLimitExceeded) || !errors.As(err, &limitErr) {
+		t.Fatalf("DeployAPI over the cluster limit: err = %v, want ErrNodeResourceLimitExceeded", err)
+	}
+	if limitErr.Type != resourcev3.ClusterType || limitErr.Count != 3 || limitErr.Limit != 2 {
+		t.Errorf("limit error = %+v", limitErr)
+	}
+	snap, err := cm.GetSnapshot("node-1")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if n := len(snap.GetResources(resourcev3.ClusterType)); n != 2 {
+		t.Errorf("clusters after rejected deploy = %d, want the previous 2", n)
+	}
+
+	err = cm.ReplaceSnapshot("node-1", &Snapshot{
+		Clusters: []*clusterv3.Cluster{cluster("a"), cluster("b")},
+		Routes:   []*routev3.RouteConfiguration{{Name: "r1"}, {Name: "r2"}},
+	})
+	if !errors.As(err, &limitErr) || limitErr.Type != "total" || limitErr.Count != 4 {
+		t.Errorf("ReplaceSnapshot over the total limit: err = %v, want a total limit error", err)
+	}
+}
+
+func TestDuplicateResourceNamesRejected(t *testing.T) {
+	cm := NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+
+	err := cm.ReplaceSnapshot("node-1", &Snapshot{
+		Listeners: []*listenerv3.Listener{{Name: "listener_8080"}, {Name: "listener_8080"}},
+	})
+	var dupErr *DuplicateResourceError
+	if !errors.Is(err, ErrDuplicateResourceName) || !errors.As(err, &dupErr) {
+		t.Fatalf("ReplaceSnapshot with duplicate listeners: err = %v, want ErrDuplicateResourceName", err)
+	}
+	if dupErr.Type != resourcev3.ListenerType || dupErr.Name != "listener_8080" || dupErr.NodeID != "node-1" {
+		t.Errorf("duplicate error = %+v", dupErr)
+	}
+	if _, err := cm.GetSnapshot("node-1"); err == nil {
+		t.Error("rejected snapshot was installed")
+	}
+
+	if err := cm.DeployAPI("node-1", &APIDeployment{Clusters: []*clusterv3.Cluster{{Name: "a"}}}); err != nil {
+		t.Fatalf("DeployAPI: %v", err)
+	}
+	err = cm.DeployAPI("node-1", &APIDeployment{Clusters: []*clusterv3.Cluster{{Name: "b"}, {Name: "b"}}})
+	if !errors.As(err, &dupErr) || dupErr.Type != resourcev3.ClusterType || dupErr.Name != "b" {
+		t.Fatalf("DeployAPI with duplicate clusters: err = %v, want a duplicate cluster error", err)
+	}
+	snap, err := cm.GetSnapshot("node-1")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if _, ok := snap.GetResources(resourcev3.ClusterType)["b"]; ok {
+		t.Error("cluster from rejected deploy reached the snapshot")
+	}
+}
+
+func TestConsistencyModes(t *testing.T) {
+	cm := NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	cm.SetRetryOptions(RetryOptions{Attempts: 1})
+	cm.SetNodeConsistencyMode("lenient", ConsistencyBestEffort)
+
+	// A route published before the cluster it routes to.
+	routeOnly := &Snapshot{
+		Listeners: []*listenerv3.Listener{listener.CreateListener("listener_10000", "route_http_*", 10000)},
+		Routes: []*routev3.RouteConfiguration{{
+			Name: "route_http_*",
+			VirtualHosts: []*routev3.VirtualHost{{
+				Name:    "pets",
+				Domains: []string{"*"},
+				Routes: []*routev3.Route{{
+					Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/pets"}},
+					Action: &routev3.Route_Route{Route: &routev3.RouteAction{ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: "pets-cluster"}}},
+				}},
+			}},
+		}},
+	}
+
+	if err := cm.ReplaceSnapshot("strict", routeOnly); err == nil {
+		t.Error("strict node accepted a route to a missing cluster")
+	}
+	if _, err := cm.GetSnapshot("strict"); err == nil {
+		t.Error("strict node installed the inconsistent snapshot")
+	}
+
+	if err := cm.ReplaceSnapshot("lenient", routeOnly); err != nil {
+		t.Fatalf("best-effort node rejected a route to a missing cluster: %v", err)
+	}
+	if err := cm.DeployAPI("lenient", &APIDeployment{Clusters: []*clusterv3.Cluster{{Name: "pets-cluster"}}}); err != nil {
+		t.Fatalf("DeployAPI cluster: %v", err)
+	}
+	snap, err := cm.GetSnapshot("lenient")
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if err := checkConsistency(snap); err != nil {
+		t.Errorf("snapshot still inconsistent once the cluster is added: %v", err)
+	}
+}
+
+func TestUpdateAPIPublishesOnlyChanges(t *testing.T) {
+	cm := NewConfigManager(cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil), logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel))
+	cm.SetConsistencyMode(ConsistencyBestEffort)
+
+	route := func(name, prefix string) *routev3.RouteConfiguration {
+		return &routev3.RouteConfiguration{
+			Name: name,
+			VirtualHosts: []*routev3.VirtualHost{{
+				Name:    name,
+				Domains: []string{"*"},
+				Routes: []*routev3.Route{{
+					Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: prefix}},
+					Action: &routev3.Route_Route{Route: &routev3.RouteAction{ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: "pets"}}},
+				}},
+			}},
+		}
+	}
+	initial := &APIDeployment{
+		Clusters: []*clusterv3.Cluster{{Name: "pets"}, {Name: "pets-old"}},
+		Routes:   []*routev3.RouteConfiguration{route("route_a", "/pets"), route("route_b", "/owners")},
+	}
+	if err := cm.DeployAPI("node-1", initial); err != nil {
+		t.Fatalf("DeployAPI: %v", err)
+	}
+	before, _ := cm.GetSnapshot("node-1")
+	owned := ResourceNames{Clusters: []string{"pets", "pets-old"}, Routes: []string{"route_a", "route_b"}}
+
+	unchanged := &APIDeployment{
+		Clusters: []*clusterv3.Cluster{{Name: "pets"}, {Name: "pets-old"}},
+		Routes:   []*routev3.RouteConfiguration{route("route_a", "/pets"), route("route_b", "/owners")},
+	}
+	if changed, err := cm.UpdateAPI("node-1", owned, unchanged); err != nil || changed {
+		t.Fatalf("UpdateAPI without changes = %v, %v; want no publish", changed, err)
+	}
+	if snap, _ := cm.GetSnapshot("node-1"); snap != before {
+		t.Fatal("unchanged update installed a new snapshot")
+	}
+
+	// Change one route; the cluster the update drops goes with it.
+	updated := &APIDeployment{
+		Clusters: []*clusterv3.Cluster{{Name: "pets"}},
+		Routes:   []*routev3.RouteConfiguration{route("route_a", "/pets/v2"), route("route_b", "/owners")},
+	}
+	if changed, err := cm.UpdateAPI("node-1", owned, updated); err != nil || !changed {
+		t.Fatalf("UpdateAPI = %v, %v; want a publish", changed, err)
+	}
+	after, _ := cm.GetSnapshot("node-1")
+	if after.GetVersion(resourcev3.RouteType) == before.GetVersion(resourcev3.RouteType) {
+		t.Error("route version unchanged after a route change")
+	}
+	if after.GetVersion(resourcev3.EndpointType) != before.GetVersion(resourcev3.EndpointType) {
+		t.Error("endpoint version changed though no endpoint did")
+	}
+	routes := after.GetResources(resourcev3.RouteType)
+	if routes["route_a"] != updated.Routes[0] {
+		t.Error("changed route was not published")
+	}
+	if routes["route_b"] != initial.Routes[1] {
+		t.Error("unchanged route was republished")
+	}
+	clusters := after.GetResources(resourcev3.ClusterType)
+	if _, ok := clusters["pets-old"]; ok {
+		t.Error("cluster dropped by the update is still published")
+	}
+	if clusters["pets"] != initial.Clusters[0] {
+		t.Error("unchanged cluster was republished")
+	}
+}
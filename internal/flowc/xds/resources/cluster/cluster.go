@@ -6,9 +6,14 @@ import (
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	aggregatev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/aggregate/v3"
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	httpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 // CreateCluster creates a cluster configuration with optional TLS
@@ -18,61 +23,115 @@ func CreateCluster(clusterName, serviceName string, port uint32) *clusterv3.Clus
 
 // CreateClusterWithScheme creates a cluster configuration with specific scheme (http/https)
 func CreateClusterWithScheme(clusterName, serviceName string, port uint32, scheme string) *clusterv3.Cluster {
+	return CreateClusterWithEndpoints(clusterName, serviceName, []Endpoint{{Host: serviceName, Port: port}}, scheme)
+}
+
+// Endpoint is a single upstream host of a cluster. Weight is relative to
+// the other endpoints; zero leaves it unset (Envoy treats it as 1).
+// Metadata is published under the envoy.lb namespace for subset load
+// balancing.
+type Endpoint struct {
+	Host     string
+	Port     uint32
+	Weight   uint32
+	Metadata map[string]string
+}
+
+// LBMetadataNamespace is the metadata namespace the subset load balancer
+// matches endpoints and routes on.
+const LBMetadataNamespace = "envoy.lb"
+
+// DefaultCAPath is the system CA bundle upstream certificates are verified
+// against when no CA path is configured. Common path on Linux.
+const DefaultCAPath = "/etc/ssl/certs/ca-certificates.crt"
+
+// UpstreamTLS configures verification of upstream certificates and the
+// client certificate presented for mTLS. A certificate is accepted if it
+// chains to CAPath and, when any SAN matchers are set, carries one of the
+// listed SPIFFE IDs (URI SANs) or DNS names.
+type UpstreamTLS struct {
+	// CAPath is the CA bundle to trust (default: DefaultCAPath). The file
+	// may hold several concatenated CA certificates.
+	CAPath string
+
+	// CertPath and KeyPath are the client certificate and key presented to
+	// the upstream. Both must be set to enable mTLS.
+	CertPath string
+	KeyPath  string
+
+	// SPIFFEIDs are accepted URI SANs, e.g. spiffe://example.org/ns/prod/sa/api.
+	SPIFFEIDs []string
+
+	// DNSNames are accepted DNS SANs.
+	DNSNames []string
+}
+
+// CreateClusterWithEndpoints creates a cluster balancing across endpoints
+// with their load balancing weights. sni is sent on upstream TLS
+// connections when scheme is https.
+func CreateClusterWithEndpoints(clusterName, sni string, endpoints []Endpoint, scheme string) *clusterv3.Cluster {
+	return CreateClusterWithTLS(clusterName, sni, endpoints, scheme, nil)
+}
+
+// CreateClusterWithTLS is CreateClusterWithEndpoints with upstream TLS
+// settings, which apply when scheme is https. A nil tls trusts the system
+// CA bundle and accepts any SAN.
+func CreateClusterWithTLS(clusterName, sni string, endpoints []Endpoint, scheme string, tls *UpstreamTLS) *clusterv3.Cluster {
+	// LOGICAL_DNS only supports a single endpoint; STRICT_DNS resolves
+	// and balances across all of them.
+	discoveryType := clusterv3.Cluster_LOGICAL_DNS
+	if len(endpoints) > 1 {
+		discoveryType = clusterv3.Cluster_STRICT_DNS
+	}
+
+	lbEndpoints := make([]*endpointv3.LbEndpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		lbEndpoint := &endpointv3.LbEndpoint{
+			HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+				Endpoint: &endpointv3.Endpoint{
+					Address: &corev3.Address{
+						Address: &corev3.Address_SocketAddress{
+							SocketAddress: &corev3.SocketAddress{
+								Address: ep.Host,
+								PortSpecifier: &corev3.SocketAddress_PortValue{
+									PortValue: ep.Port,
+								},
+								Protocol: corev3.SocketAddress_TCP,
+							},
+						},
+					},
+				},
+			},
+		}
+		if ep.Weight > 0 {
+			lbEndpoint.LoadBalancingWeight = wrapperspb.UInt32(ep.Weight)
+		}
+		if len(ep.Metadata) > 0 {
+			lbEndpoint.Metadata = LBMetadata(ep.Metadata)
+		}
+		lbEndpoints = append(lbEndpoints, lbEndpoint)
+	}
+
 	cluster := &clusterv3.Cluster{
 		Name:           clusterName,
 		ConnectTimeout: durationpb.New(5 * time.Second),
-		// Use LOGICAL_DNS for hostname resolution
-		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_LOGICAL_DNS},
-		LbPolicy:             clusterv3.Cluster_ROUND_ROBIN,
-		DnsLookupFamily:      clusterv3.Cluster_V4_ONLY,
-		// Load assignment with hostname
+		// Use DNS for hostname resolution
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: discoveryType},
+		// All built-in policies (round robin, least request, random, ring
+		// hash) honor endpoint weights.
+		LbPolicy:        clusterv3.Cluster_ROUND_ROBIN,
+		DnsLookupFamily: clusterv3.Cluster_V4_ONLY,
 		LoadAssignment: &endpointv3.ClusterLoadAssignment{
 			ClusterName: clusterName,
 			Endpoints: []*endpointv3.LocalityLbEndpoints{
-				{
-					LbEndpoints: []*endpointv3.LbEndpoint{
-						{
-							HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
-								Endpoint: &endpointv3.Endpoint{
-									Address: &corev3.Address{
-										Address: &corev3.Address_SocketAddress{
-											SocketAddress: &corev3.SocketAddress{
-												Address: serviceName,
-												PortSpecifier: &corev3.SocketAddress_PortValue{
-													PortValue: port,
-												},
-												Protocol: corev3.SocketAddress_TCP,
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-				},
+				{LbEndpoints: lbEndpoints},
 			},
 		},
 	}
 
 	// Add TLS configuration for HTTPS
 	if scheme == "https" {
-		tlsContext := &tlsv3.UpstreamTlsContext{
-			Sni: serviceName, // Server Name Indication - required for TLS
-			CommonTlsContext: &tlsv3.CommonTlsContext{
-				ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
-					ValidationContext: &tlsv3.CertificateValidationContext{
-						// Trust system CA certificates
-						TrustedCa: &corev3.DataSource{
-							Specifier: &corev3.DataSource_Filename{
-								Filename: "/etc/ssl/certs/ca-certificates.crt", // Common path on Linux
-							},
-						},
-					},
-				},
-			},
-		}
-
-		tlsContextAny, err := anypb.New(tlsContext)
+		tlsContextAny, err := anypb.New(upstreamTLSContext(sni, tls))
 		if err == nil {
 			cluster.TransportSocket = &corev3.TransportSocket{
 				Name: "envoy.transport_sockets.tls",
@@ -85,3 +144,151 @@ func CreateClusterWithScheme(clusterName, serviceName string, port uint32, schem
 
 	return cluster
 }
+
+// AggregateClusterType is the custom cluster type of aggregate clusters.
+const AggregateClusterType = "envoy.clusters.aggregate"
+
+// CreateAggregateCluster creates a cluster that sends each request to the
+// first of clusters with healthy hosts. Retries whose policy uses the
+// previous_priorities retry priority move on to the next cluster.
+func CreateAggregateCluster(clusterName string, clusters []string) (*clusterv3.Cluster, error) {
+	cfg, err := anypb.New(&aggregatev3.ClusterConfig{Clusters: clusters})
+	if err != nil {
+		return nil, err
+	}
+	return &clusterv3.Cluster{
+		Name:           clusterName,
+		ConnectTimeout: durationpb.New(5 * time.Second),
+		// The aggregated clusters' own load balancers pick the host.
+		LbPolicy: clusterv3.Cluster_CLUSTER_PROVIDED,
+		ClusterDiscoveryType: &clusterv3.Cluster_ClusterType{
+			ClusterType: &clusterv3.Cluster_CustomClusterType{
+				Name:        AggregateClusterType,
+				TypedConfig: cfg,
+			},
+		},
+	}, nil
+}
+
+// SetSubsets enables subset load balancing on c with one subset per
+// distinct combination of values for each selector's metadata keys.
+// Requests whose route metadata matches no subset go to any endpoint.
+func SetSubsets(c *clusterv3.Cluster, selectors [][]string) {
+	subsets := &clusterv3.Cluster_LbSubsetConfig{
+		FallbackPolicy: clusterv3.Cluster_LbSubsetConfig_ANY_ENDPOINT,
+	}
+	for _, keys := range selectors {
+		subsets.SubsetSelectors = append(subsets.SubsetSelectors,
+			&clusterv3.Cluster_LbSubsetConfig_LbSubsetSelector{Keys: keys})
+	}
+	c.LbSubsetConfig = subsets
+	// Subsets are built from the resolved hosts, which LOGICAL_DNS does
+	// not keep.
+	c.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS}
+}
+
+// SetConnectionLimits caps the connections c opens to each host at
+// maxConnectionsPerHost and closes a connection after it has served
+// maxRequestsPerConnection requests. Zero leaves a limit unset.
+func SetConnectionLimits(c *clusterv3.Cluster, maxConnectionsPerHost, maxRequestsPerConnection uint32) {
+	if maxConnectionsPerHost > 0 {
+		c.CircuitBreakers = &clusterv3.CircuitBreakers{
+			PerHostThresholds: []*clusterv3.CircuitBreakers_Thresholds{
+				{MaxConnections: wrapperspb.UInt32(maxConnectionsPerHost)},
+			},
+		}
+	}
+	if maxRequestsPerConnection > 0 {
+		options, err := anypb.New(&httpv3.HttpProtocolOptions{
+			CommonHttpProtocolOptions: &corev3.HttpProtocolOptions{
+				MaxRequestsPerConnection: wrapperspb.UInt32(maxRequestsPerConnection),
+			},
+			UpstreamProtocolOptions: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_{
+				ExplicitHttpConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig{
+					ProtocolConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_HttpProtocolOptions{},
+				},
+			},
+		})
+		if err == nil {
+			if c.TypedExtensionProtocolOptions == nil {
+				c.TypedExtensionProtocolOptions = map[string]*anypb.Any{}
+			}
+			c.TypedExtensionProtocolOptions[HTTPProtocolOptionsName] = options
+		}
+	}
+}
+
+// HTTPProtocolOptionsName is the key of a cluster's upstream HTTP
+// protocol options in its typed extension protocol options.
+const HTTPProtocolOptionsName = "envoy.extensions.upstreams.http.v3.HttpProtocolOptions"
+
+// LBMetadata returns values as metadata in the envoy.lb namespace, as
+// matched by subset load balancing.
+func LBMetadata(values map[string]string) *corev3.Metadata {
+	fields := make(map[string]*structpb.Value, len(values))
+	for k, v := range values {
+		fields[k] = structpb.NewStringValue(v)
+	}
+	return &corev3.Metadata{
+		FilterMetadata: map[string]*structpb.Struct{
+			LBMetadataNamespace: {Fields: fields},
+		},
+	}
+}
+
+// upstreamTLSContext builds the TLS context for connections to the
+// upstream named by sni.
+func upstreamTLSContext(sni string, tls *UpstreamTLS) *tlsv3.UpstreamTlsContext {
+	if tls == nil {
+		tls = &UpstreamTLS{}
+	}
+	caPath := tls.CAPath
+	if caPath == "" {
+		caPath = DefaultCAPath
+	}
+
+	validation := &tlsv3.CertificateValidationContext{
+		TrustedCa: &corev3.DataSource{
+			Specifier: &corev3.DataSource_Filename{Filename: caPath},
+		},
+	}
+	for _, id := range tls.SPIFFEIDs {
+		validation.MatchTypedSubjectAltNames = append(validation.MatchTypedSubjectAltNames,
+			sanMatcher(tlsv3.SubjectAltNameMatcher_URI, id))
+	}
+	for _, name := range tls.DNSNames {
+		validation.MatchTypedSubjectAltNames = append(validation.MatchTypedSubjectAltNames,
+			sanMatcher(tlsv3.SubjectAltNameMatcher_DNS, name))
+	}
+
+	common := &tlsv3.CommonTlsContext{
+		ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
+			ValidationContext: validation,
+		},
+	}
+	if tls.CertPath != "" && tls.KeyPath != "" {
+		common.TlsCertificates = []*tlsv3.TlsCertificate{{
+			CertificateChain: &corev3.DataSource{
+				Specifier: &corev3.DataSource_Filename{Filename: tls.CertPath},
+			},
+			PrivateKey: &corev3.DataSource{
+				Specifier: &corev3.DataSource_Filename{Filename: tls.KeyPath},
+			},
+		}}
+	}
+
+	return &tlsv3.UpstreamTlsContext{
+		Sni:              sni, // Server Name Indication - required for TLS
+		CommonTlsContext: common,
+	}
+}
+
+// sanMatcher matches a subject alternative name of sanType exactly.
+func sanMatcher(sanType tlsv3.SubjectAltNameMatcher_SanType, value string) *tlsv3.SubjectAltNameMatcher {
+	return &tlsv3.SubjectAltNameMatcher{
+		SanType: sanType,
+		Matcher: &matcherv3.StringMatcher{
+			MatchPattern: &matcherv3.StringMatcher_Exact{Exact: value},
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package listener
+
+import (
+	"fmt"
+
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	filev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	streamv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/stream/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Access log formats and the paths logged to Envoy's own streams.
+const (
+	AccessLogFormatJSON = "json"
+	AccessLogFormatText = "text"
+
+	AccessLogPathStdout = "stdout"
+	AccessLogPathStderr = "stderr"
+)
+
+// DefaultJSONAccessLogFormat maps the keys of JSON access log entries to
+// the command operators filling them. Besides the request and response,
+// entries say where the request went (upstream cluster and host), why
+// Envoy answered as it did (response flags) and how long each side took.
+var DefaultJSONAccessLogFormat = map[string]string{
+	"start_time":            "%START_TIME%",
+	"method":                "%REQ(:METHOD)%",
+	"path":                  "%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%",
+	"protocol":              "%PROTOCOL%",
+	"authority":             "%REQ(:AUTHORITY)%",
+	"request_id":            "%REQ(X-REQUEST-ID)%",
+	"user_agent":            "%REQ(USER-AGENT)%",
+	"x_forwarded_for":       "%REQ(X-FORWARDED-FOR)%",
+	"response_code":         "%RESPONSE_CODE%",
+	"response_flags":        "%RESPONSE_FLAGS%",
+	"bytes_received":        "%BYTES_RECEIVED%",
+	"bytes_sent":            "%BYTES_SENT%",
+	"duration":              "%DURATION%",
+	"request_duration":      "%REQUEST_DURATION%",
+	"response_duration":     "%RESPONSE_DURATION%",
+	"upstream_service_time": "%RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)%",
+	"upstream_cluster":      "%UPSTREAM_CLUSTER%",
+	"upstream_host":         "%UPSTREAM_HOST%",
+}
+
+// DefaultTextAccessLogFormat is Envoy's default text format with the
+// upstream cluster and the request and response durations added.
+const DefaultTextAccessLogFormat = `[%START_TIME%] "%REQ(:METHOD)% %REQ(X-ENVOY-ORIGINAL-PATH?:PATH)% %PROTOCOL%" ` +
+	`%RESPONSE_CODE% %RESPONSE_FLAGS% %BYTES_RECEIVED% %BYTES_SENT% ` +
+	`%DURATION% %REQUEST_DURATION% %RESPONSE_DURATION% %RESP(X-ENVOY-UPSTREAM-SERVICE-TIME)% ` +
+	`"%REQ(X-FORWARDED-FOR)%" "%REQ(USER-AGENT)%" "%REQ(X-REQUEST-ID)%" "%REQ(:AUTHORITY)%" ` +
+	`"%UPSTREAM_HOST%" %UPSTREAM_CLUSTER%` + "\n"
+
+// AccessLogOptions configures the access log of every filter chain
+type AccessLogOptions struct {
+	// Format is "json" (the default) or "text"
+	Format string
+
+	// Path is "stdout" (the default), "stderr" or a file path
+	Path string
+}
+
+// buildAccessLog returns the HCM access log opts describes, or nil when
+// opts is nil.
+func buildAccessLog(opts *AccessLogOptions) (*accesslogv3.AccessLog, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	format := &corev3.SubstitutionFormatString{}
+	switch opts.Format {
+	case "", AccessLogFormatJSON:
+		fields := make(map[string]any, len(DefaultJSONAccessLogFormat))
+		for k, v := range DefaultJSONAccessLogFormat {
+			fields[k] = v
+		}
+		js, err := structpb.NewStruct(fields)
+		if err != nil {
+			return nil, fmt.Errorf("access log: %w", err)
+		}
+		format.Format = &corev3.SubstitutionFormatString_JsonFormat{JsonFormat: js}
+	case AccessLogFormatText:
+		format.Format = &corev3.SubstitutionFormatString_TextFormatSource{
+			TextFormatSource: &corev3.DataSource{
+				Specifier: &corev3.DataSource_InlineString{InlineString: DefaultTextAccessLogFormat},
+			},
+		}
+	default:
+		return nil, fmt.Errorf("access log: unknown format %q (must be %s or %s)", opts.Format, AccessLogFormatJSON, AccessLogFormatText)
+	}
+
+	var (
+		name   string
+		config proto.Message
+	)
+	switch opts.Path {
+	case "", AccessLogPathStdout:
+		name = "envoy.access_loggers.stdout"
+		config = &streamv3.StdoutAccessLog{
+			AccessLogFormat: &streamv3.StdoutAccessLog_LogFormat{LogFormat: format},
+		}
+	case AccessLogPathStderr:
+		name = "envoy.access_loggers.stderr"
+		config = &streamv3.StderrAccessLog{
+			AccessLogFormat: &streamv3.StderrAccessLog_LogFormat{LogFormat: format},
+		}
+	default:
+		name = "envoy.access_loggers.file"
+		config = &filev3.FileAccessLog{
+			Path:            opts.Path,
+			AccessLogFormat: &filev3.FileAccessLog_LogFormat{LogFormat: format},
+		}
+	}
+	typed, err := anypb.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("access log: %w", err)
+	}
+	return &accesslogv3.AccessLog{
+		Name:       name,
+		ConfigType: &accesslogv3.AccessLog_TypedConfig{TypedConfig: typed},
+	}, nil
+}
@@ -0,0 +1,111 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxHostnameLength is the longest DNS name, without the trailing dot.
+const maxHostnameLength = 253
+
+// ValidateHostname checks that hostname can be matched against a TLS SNI
+// value: a lowercase DNS name ("api.example.com"), a wildcard of one
+// ("*.example.com"), or "*" for any hostname.
+func ValidateHostname(hostname string) error {
+	if hostname == "*" {
+		return nil
+	}
+	if hostname == "" {
+		return errors.New("hostname is empty")
+	}
+	name := strings.TrimPrefix(hostname, "*.")
+	if len(name) > maxHostnameLength {
+		return fmt.Errorf("longer than %d characters", maxHostnameLength)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if err := validateLabel(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompareHostnames orders hostnames by match precedence, the order Envoy
+// applies to SNI server names and virtual host domains: exact names
+// first, then wildcards from the longest suffix to the shortest, then
+// "*" (or no hostname). Hostnames of equal precedence compare equal, so a stable sort
+// keeps their order.
+func CompareHostnames(a, b string) int {
+	return hostnameRank(b) - hostnameRank(a)
+}
+
+// hostnameRank scores hostname by precedence: exact names outrank every
+// wildcard, and a wildcard ranks by the length of its suffix.
+func hostnameRank(hostname string) int {
+	switch {
+	case hostname == "*", hostname == "":
+		return 0
+	case strings.HasPrefix(hostname, "*."):
+		return len(hostname)
+	default:
+		return maxHostnameLength + 3
+	}
+}
+
+// MatchHostname returns the hostname of hostnames that serves host, by
+// CompareHostnames precedence, or "" when none matches. A wildcard
+// "*.example.com" matches any name ending in ".example.com" with at least
+// one more label, as it does in Envoy.
+func MatchHostname(hostnames []string, host string) string {
+	host = strings.ToLower(host)
+	best := ""
+	found := false
+	for _, hostname := range hostnames {
+		if !hostnameMatches(hostname, host) {
+			continue
+		}
+		if !found || CompareHostnames(hostname, best) < 0 {
+			best, found = hostname, true
+		}
+	}
+	return best
+}
+
+// hostnameMatches reports whether hostname serves host.
+func hostnameMatches(hostname, host string) bool {
+	switch {
+	case hostname == "*":
+		return true
+	case strings.HasPrefix(hostname, "*."):
+		suffix := hostname[1:]
+		return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+	default:
+		return hostname == host
+	}
+}
+
+// validateLabel checks one DNS label: 1 to 63 lowercase letters, digits
+// and hyphens, not starting or ending with a hyphen.
+func validateLabel(label string) error {
+	switch {
+	case label == "":
+		return errors.New("empty DNS label")
+	case len(label) > 63:
+		return fmt.Errorf("DNS label %q is longer than 63 characters", label)
+	case label[0] == '-' || label[len(label)-1] == '-':
+		return fmt.Errorf("DNS label %q starts or ends with a hyphen", label)
+	}
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+		case r == '*':
+			return errors.New("wildcard is only allowed as the whole leftmost label (*.example.com)")
+		case r >= 'A' && r <= 'Z':
+			return fmt.Errorf("DNS label %q must be lowercase", label)
+		default:
+			return fmt.Errorf("DNS label %q contains invalid character %q", label, r)
+		}
+	}
+	return nil
+}
@@ -1,13 +1,31 @@
 package listener
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/config/common/matcher/v3"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	tapconfigv3 "github.com/envoyproxy/go-control-plane/envoy/config/tap/v3"
+	commontapv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/tap/v3"
 	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	tapv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/tap/v3"
+	httpinspectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/http_inspector/v3"
+	originaldstv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/original_dst/v3"
+	proxyprotocolv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/proxy_protocol/v3"
 	tlsinspectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/tls_inspector/v3"
 	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	typematcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/flowc-labs/flowc/pkg/types"
 )
@@ -19,6 +37,42 @@ const (
 	DefaultNodeID       = "test-envoy-node"
 )
 
+// Bounds Envoy accepts for HTTP/2 initial window sizes and concurrent
+// streams.
+const (
+	MinHTTP2WindowSize        = 65535
+	MaxHTTP2WindowSize        = 2147483647
+	MaxHTTP2ConcurrentStreams = 2147483647
+)
+
+// TapFilterName is the name of the tap HTTP filter.
+const TapFilterName = "envoy.filters.http.tap"
+
+// Tap outputs.
+const (
+	TapOutputAdmin    = "admin"
+	TapOutputStreamed = "streamed"
+)
+
+// Listener filters, by the short name configuring them. Their Envoy names
+// are prefixed with "envoy.filters.listener.".
+const (
+	ListenerFilterProxyProtocol = "proxy_protocol"
+	ListenerFilterOriginalDst   = "original_dst"
+	ListenerFilterTLSInspector  = "tls_inspector"
+	ListenerFilterHTTPInspector = "http_inspector"
+)
+
+// listenerFilterOrder is the order listener filters run in: a PROXY
+// protocol header comes first on the connection, and the inspectors read
+// the bytes after it.
+var listenerFilterOrder = []string{
+	ListenerFilterProxyProtocol,
+	ListenerFilterOriginalDst,
+	ListenerFilterTLSInspector,
+	ListenerFilterHTTPInspector,
+}
+
 // CreateListener creates a listener configuration
 func CreateListener(listenerName, routeName string, port uint32) *listenerv3.Listener {
 	routerConfig, _ := anypb.New(&routerv3.Router{})
@@ -90,9 +144,18 @@ type FilterChainConfig struct {
 	// HTTPFilters are environment-specific HTTP filters to apply
 	HTTPFilters []types.HTTPFilter
 
+	// Filters are pre-built HTTP filters (e.g. contributed by deployments)
+	// inserted ahead of the router filter, in order
+	Filters []*hcmv3.HttpFilter
+
 	// RouteConfigName is the name of the RDS route configuration
 	RouteConfigName string
 
+	// ApplicationProtocols restricts the chain to connections negotiating
+	// one of these protocols (e.g. "h2", "http/1.1"); ALPN on TLS
+	// connections, detected by the http_inspector otherwise
+	ApplicationProtocols []string
+
 	// TLS configuration for this filter chain
 	TLS *TLSConfig
 }
@@ -124,8 +187,75 @@ type ListenerConfig struct {
 	// HTTP2 enables HTTP/2 support
 	HTTP2 bool
 
-	// AccessLog path
-	AccessLog string
+	// HTTP2Options tunes HTTP/2 flow control and implies HTTP2
+	HTTP2Options *HTTP2Options
+
+	// AccessLog enables the access log of every filter chain
+	AccessLog *AccessLogOptions
+
+	// HCM holds HTTP connection manager settings shared by all filter chains
+	HCM *HCMOptions
+
+	// Tap adds a tap filter ahead of every filter chain's other HTTP filters
+	Tap *TapOptions
+
+	// ListenerFilters are added on top of the ones the filter chains need
+	// (tls_inspector for SNI, http_inspector for protocol matching)
+	ListenerFilters []string
+
+	// AuthFailureResponse replaces the body of the 401 and 403 replies
+	// auth filters send on every filter chain
+	AuthFailureResponse *AuthFailureResponse
+
+	// LocalReply rewrites the other replies Envoy generates itself (404
+	// for no route, 503 for no healthy upstream, ...), first match wins
+	LocalReply []LocalReplyMapping
+}
+
+// HTTP2Options contains HTTP/2 flow-control and stream settings; zero
+// values keep Envoy's defaults
+type HTTP2Options struct {
+	InitialStreamWindowSize     uint32
+	InitialConnectionWindowSize uint32
+	MaxConcurrentStreams        uint32
+}
+
+// TapOptions contains tap filter settings
+type TapOptions struct {
+	// ConfigID identifies the tap to Envoy's /tap admin endpoint and
+	// names the default file prefix of streamed taps
+	ConfigID string
+
+	// Output is TapOutputAdmin (the default) or TapOutputStreamed
+	Output string
+
+	// RequestHeaders restricts streamed taps to requests carrying all of
+	// these headers with exactly these values; empty taps every request
+	RequestHeaders map[string]string
+
+	// PathPrefix is the file prefix of streamed taps
+	// (default "/tmp/flowc_tap_<ConfigID>")
+	PathPrefix string
+}
+
+// HCMOptions contains HTTP connection manager settings
+type HCMOptions struct {
+	// UseRemoteAddress makes Envoy use the downstream connection's address,
+	// rather than X-Forwarded-For, as the client address
+	UseRemoteAddress bool
+
+	// XFFNumTrustedHops is the number of trusted proxies in front of Envoy;
+	// the client address is taken that many hops from the right of
+	// X-Forwarded-For
+	XFFNumTrustedHops uint32
+
+	// MaxConnectionDuration is how long a downstream connection may stay
+	// open before Envoy drains it (e.g. "10m"); empty means no limit
+	MaxConnectionDuration string
+
+	// MaxRequestsPerConnection is how many requests a downstream
+	// connection may serve before Envoy drains it; zero means no limit
+	MaxRequestsPerConnection uint32
 }
 
 // CreateListenerWithFilterChains creates a listener with multiple SNI-matched filter chains.
@@ -135,6 +265,22 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 		config.Address = "0.0.0.0"
 	}
 
+	http2, err := http2ProtocolOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	common, err := commonHTTPProtocolOptions(config.HCM)
+	if err != nil {
+		return nil, err
+	}
+
+	var tapFilter *hcmv3.HttpFilter
+	if config.Tap != nil {
+		if tapFilter, err = buildTapFilter(config.Tap); err != nil {
+			return nil, err
+		}
+	}
+
 	filterChains := make([]*listenerv3.FilterChain, 0, len(config.FilterChains))
 
 	// Track whether any filter chain needs TLS — only then do we add the
@@ -146,16 +292,47 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 			break
 		}
 	}
+	listenerFilters, err := buildListenerFilters(config, hasTLS)
+	if err != nil {
+		return nil, err
+	}
+	localReply, err := buildLocalReplyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	accessLog, err := buildAccessLog(config.AccessLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chains are listed in match order, exact hostnames before wildcards,
+	// so the listener reads the way Envoy picks among its SNI matches.
+	chains := slices.Clone(config.FilterChains)
+	slices.SortStableFunc(chains, func(a, b *FilterChainConfig) int {
+		return CompareHostnames(a.Hostname, b.Hostname)
+	})
+	for _, fcConfig := range chains {
+		if fcConfig.Hostname != "" {
+			if err := ValidateHostname(fcConfig.Hostname); err != nil {
+				return nil, fmt.Errorf("filter chain %q: hostname %q: %w", fcConfig.Name, fcConfig.Hostname, err)
+			}
+		}
 
-	for _, fcConfig := range config.FilterChains {
 		// Create HTTP Connection Manager for this filter chain
 		routerConfig, _ := anypb.New(&routerv3.Router{})
 
 		// TODO: Add environment-specific HTTP filters from fcConfig.HTTPFilters
-		httpFilters := []*hcmv3.HttpFilter{{
+		// The router must be the terminal filter, so pre-built filters go
+		// first. The tap leads so it records requests as they arrived.
+		httpFilters := make([]*hcmv3.HttpFilter, 0, len(fcConfig.Filters)+2)
+		if tapFilter != nil {
+			httpFilters = append(httpFilters, tapFilter)
+		}
+		httpFilters = append(httpFilters, fcConfig.Filters...)
+		httpFilters = append(httpFilters, &hcmv3.HttpFilter{
 			Name:       "http-router",
 			ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: routerConfig},
-		}}
+		})
 
 		manager := &hcmv3.HttpConnectionManager{
 			CodecType:  hcmv3.HttpConnectionManager_AUTO,
@@ -166,11 +343,20 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 					RouteConfigName: fcConfig.RouteConfigName,
 				},
 			},
-			HttpFilters: httpFilters,
+			HttpFilters:      httpFilters,
+			LocalReplyConfig: localReply,
 		}
 
-		if config.HTTP2 {
-			manager.Http2ProtocolOptions = &corev3.Http2ProtocolOptions{}
+		manager.Http2ProtocolOptions = http2
+		manager.CommonHttpProtocolOptions = common
+		if accessLog != nil {
+			manager.AccessLog = []*accesslogv3.AccessLog{accessLog}
+		}
+		if hcm := config.HCM; hcm != nil {
+			if hcm.UseRemoteAddress {
+				manager.UseRemoteAddress = wrapperspb.Bool(true)
+			}
+			manager.XffNumTrustedHops = hcm.XFFNumTrustedHops
 		}
 
 		pbst, err := anypb.New(manager)
@@ -179,6 +365,7 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 		}
 
 		filterChain := &listenerv3.FilterChain{
+			Name: fcConfig.Name,
 			Filters: []*listenerv3.Filter{
 				{
 					Name: "http_connection_manager",
@@ -198,12 +385,25 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 				ServerNames: []string{fcConfig.Hostname},
 			}
 		}
+		if len(fcConfig.ApplicationProtocols) > 0 {
+			if filterChain.FilterChainMatch == nil {
+				filterChain.FilterChainMatch = &listenerv3.FilterChainMatch{}
+			}
+			filterChain.FilterChainMatch.ApplicationProtocols = fcConfig.ApplicationProtocols
+		}
 
 		// TODO: Add TLS configuration if fcConfig.TLS is set
 
 		filterChains = append(filterChains, filterChain)
 	}
 
+	// Everything outside FilterChains must be a pure function of the
+	// listener's name, address, port and listener filters, which depend on
+	// the chains only through TLS and protocol matching. Envoy then
+	// applies a changed listener as a filter-chain-only update: chains whose
+	// config is unchanged keep their connections and only removed or
+	// modified chains are drained. Any other field change makes Envoy drain
+	// and rebind the whole listener.
 	l := &listenerv3.Listener{
 		Name: config.Name,
 		Address: &corev3.Address{
@@ -217,25 +417,186 @@ func CreateListenerWithFilterChains(config *ListenerConfig) (*listenerv3.Listene
 			},
 		},
 		FilterChains: filterChains,
+		// Pinned rather than left to the platform default so it never
+		// differs between updates; a change here forces a full rebind.
+		EnableReusePort: wrapperspb.Bool(true),
+		ListenerFilters: listenerFilters,
 	}
 
-	// Only add the tls_inspector when at least one filter chain uses TLS.
-	// Without TLS there is no ClientHello for the inspector to parse, and
-	// adding it to a plain HTTP listener causes Envoy to drop connections.
+	return l, nil
+}
+
+// buildListenerFilters returns the listener's configured listener filters
+// plus the ones its filter chain matches depend on, in
+// listenerFilterOrder. The tls_inspector is only added unasked when a
+// filter chain uses TLS: without TLS there is no ClientHello for it to
+// parse, and adding it to a plain HTTP listener causes Envoy to drop
+// connections. The http_inspector is added when a chain matches on
+// application protocols, which it detects on plaintext connections.
+func buildListenerFilters(config *ListenerConfig, hasTLS bool) ([]*listenerv3.ListenerFilter, error) {
+	want := map[string]bool{}
+	for _, name := range config.ListenerFilters {
+		if !slices.Contains(listenerFilterOrder, name) {
+			return nil, fmt.Errorf("unknown listener filter %q", name)
+		}
+		want[name] = true
+	}
 	if hasTLS {
-		tlsInspector, err := anypb.New(&tlsinspectorv3.TlsInspector{})
+		want[ListenerFilterTLSInspector] = true
+	}
+	if slices.ContainsFunc(config.FilterChains, func(fc *FilterChainConfig) bool { return len(fc.ApplicationProtocols) > 0 }) {
+		want[ListenerFilterHTTPInspector] = true
+	}
+
+	var filters []*listenerv3.ListenerFilter
+	for _, name := range listenerFilterOrder {
+		if !want[name] {
+			continue
+		}
+		var cfg proto.Message
+		switch name {
+		case ListenerFilterProxyProtocol:
+			cfg = &proxyprotocolv3.ProxyProtocol{}
+		case ListenerFilterOriginalDst:
+			cfg = &originaldstv3.OriginalDst{}
+		case ListenerFilterTLSInspector:
+			cfg = &tlsinspectorv3.TlsInspector{}
+		case ListenerFilterHTTPInspector:
+			cfg = &httpinspectorv3.HttpInspector{}
+		}
+		typed, err := anypb.New(cfg)
 		if err != nil {
 			return nil, err
 		}
-		l.ListenerFilters = []*listenerv3.ListenerFilter{
-			{
-				Name: "envoy.filters.listener.tls_inspector",
-				ConfigType: &listenerv3.ListenerFilter_TypedConfig{
-					TypedConfig: tlsInspector,
+		filters = append(filters, &listenerv3.ListenerFilter{
+			Name:       "envoy.filters.listener." + name,
+			ConfigType: &listenerv3.ListenerFilter_TypedConfig{TypedConfig: typed},
+		})
+	}
+	return filters, nil
+}
+
+// http2ProtocolOptions returns the HCM's HTTP/2 options, or nil when
+// HTTP/2 is off. Window sizes outside Envoy's range are rejected.
+func http2ProtocolOptions(config *ListenerConfig) (*corev3.Http2ProtocolOptions, error) {
+	if !config.HTTP2 && config.HTTP2Options == nil {
+		return nil, nil
+	}
+	opts := &corev3.Http2ProtocolOptions{}
+	if o := config.HTTP2Options; o != nil {
+		windows := []struct {
+			name  string
+			size  uint32
+			field **wrapperspb.UInt32Value
+		}{
+			{"initial_stream_window_size", o.InitialStreamWindowSize, &opts.InitialStreamWindowSize},
+			{"initial_connection_window_size", o.InitialConnectionWindowSize, &opts.InitialConnectionWindowSize},
+		}
+		for _, w := range windows {
+			if w.size == 0 {
+				continue
+			}
+			if w.size < MinHTTP2WindowSize || w.size > MaxHTTP2WindowSize {
+				return nil, fmt.Errorf("invalid %s: %d (must be between %d and %d)",
+					w.name, w.size, MinHTTP2WindowSize, MaxHTTP2WindowSize)
+			}
+			*w.field = wrapperspb.UInt32(w.size)
+		}
+		if o.MaxConcurrentStreams > MaxHTTP2ConcurrentStreams {
+			return nil, fmt.Errorf("invalid max_concurrent_streams: %d (must be at most %d)",
+				o.MaxConcurrentStreams, MaxHTTP2ConcurrentStreams)
+		}
+		if o.MaxConcurrentStreams > 0 {
+			opts.MaxConcurrentStreams = wrapperspb.UInt32(o.MaxConcurrentStreams)
+		}
+	}
+	return opts, nil
+}
+
+// commonHTTPProtocolOptions returns the HCM's downstream connection
+// limits, or nil when hcm sets none.
+func commonHTTPProtocolOptions(hcm *HCMOptions) (*corev3.HttpProtocolOptions, error) {
+	if hcm == nil || (hcm.MaxConnectionDuration == "" && hcm.MaxRequestsPerConnection == 0) {
+		return nil, nil
+	}
+	opts := &corev3.HttpProtocolOptions{}
+	if hcm.MaxConnectionDuration != "" {
+		d, err := time.ParseDuration(hcm.MaxConnectionDuration)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid max_connection_duration %q: must be a positive duration", hcm.MaxConnectionDuration)
+		}
+		opts.MaxConnectionDuration = durationpb.New(d)
+	}
+	if hcm.MaxRequestsPerConnection > 0 {
+		opts.MaxRequestsPerConnection = wrapperspb.UInt32(hcm.MaxRequestsPerConnection)
+	}
+	return opts, nil
+}
+
+// buildTapFilter builds the tap filter for opts. Admin taps are configured
+// at runtime through Envoy's /tap endpoint under opts.ConfigID; streamed
+// taps are static and write each matching request to its own file.
+func buildTapFilter(opts *TapOptions) (*hcmv3.HttpFilter, error) {
+	common := &commontapv3.CommonExtensionConfig{}
+	switch opts.Output {
+	case "", TapOutputAdmin:
+		if len(opts.RequestHeaders) > 0 {
+			return nil, fmt.Errorf("tap match is only supported with %q output; admin taps take it from the /tap request", TapOutputStreamed)
+		}
+		common.ConfigType = &commontapv3.CommonExtensionConfig_AdminConfig{
+			AdminConfig: &commontapv3.AdminConfig{ConfigId: opts.ConfigID},
+		}
+	case TapOutputStreamed:
+		pathPrefix := opts.PathPrefix
+		if pathPrefix == "" {
+			pathPrefix = "/tmp/flowc_tap_" + opts.ConfigID
+		}
+		common.ConfigType = &commontapv3.CommonExtensionConfig_StaticConfig{
+			StaticConfig: &tapconfigv3.TapConfig{
+				Match: tapMatch(opts.RequestHeaders),
+				OutputConfig: &tapconfigv3.OutputConfig{
+					Sinks: []*tapconfigv3.OutputSink{{
+						Format: tapconfigv3.OutputSink_JSON_BODY_AS_STRING,
+						OutputSinkType: &tapconfigv3.OutputSink_FilePerTap{
+							FilePerTap: &tapconfigv3.FilePerTapSink{PathPrefix: pathPrefix},
+						},
+					}},
+					Streaming: true,
 				},
 			},
 		}
+	default:
+		return nil, fmt.Errorf("unknown tap output %q (must be %q or %q)", opts.Output, TapOutputAdmin, TapOutputStreamed)
 	}
 
-	return l, nil
+	typed, err := anypb.New(&tapv3.Tap{CommonConfig: common, RecordHeadersReceivedTime: true})
+	if err != nil {
+		return nil, err
+	}
+	return &hcmv3.HttpFilter{
+		Name:       TapFilterName,
+		ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: typed},
+	}, nil
+}
+
+// tapMatch matches requests carrying every header in headers with its
+// exact value, or every request when headers is empty.
+func tapMatch(headers map[string]string) *matcherv3.MatchPredicate {
+	if len(headers) == 0 {
+		return &matcherv3.MatchPredicate{Rule: &matcherv3.MatchPredicate_AnyMatch{AnyMatch: true}}
+	}
+	match := &matcherv3.HttpHeadersMatch{}
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		match.Headers = append(match.Headers, &routev3.HeaderMatcher{
+			Name: name,
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+				StringMatch: &typematcherv3.StringMatcher{
+					MatchPattern: &typematcherv3.StringMatcher_Exact{Exact: headers[name]},
+				},
+			},
+		})
+	}
+	return &matcherv3.MatchPredicate{
+		Rule: &matcherv3.MatchPredicate_HttpRequestHeadersMatch{HttpRequestHeadersMatch: match},
+	}
 }
@@ -0,0 +1,464 @@
+package listener
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	filev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	streamv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/stream/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+func buildListener(t *testing.T, hostnames ...string) *listenerv3.Listener {
+	t.Helper()
+	chains := make([]*FilterChainConfig, 0, len(hostnames))
+	for _, h := range hostnames {
+		chains = append(chains, &FilterChainConfig{
+			Name:            h,
+			Hostname:        h,
+			RouteConfigName: fmt.Sprintf("route_l1_%s", h),
+			TLS:             &TLSConfig{CertPath: "/certs/tls.crt", KeyPath: "/certs/tls.key"},
+		})
+	}
+	l, err := CreateListenerWithFilterChains(&ListenerConfig{
+		Name:         "listener_8443",
+		Port:         8443,
+		FilterChains: chains,
+	})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	return l
+}
+
+func TestListenerUpdateOnlyChangesFilterChains(t *testing.T) {
+	before := buildListener(t, "staging.example.com")
+	after := buildListener(t, "staging.example.com", "prod.example.com")
+
+	if !after.GetEnableReusePort().GetValue() {
+		t.Error("expected enable_reuse_port to be set")
+	}
+
+	// Everything but the filter chains must be identical, or Envoy drains
+	// and recreates the listener instead of updating it in place.
+	b, a := proto.Clone(before).(*listenerv3.Listener), proto.Clone(after).(*listenerv3.Listener)
+	b.FilterChains, a.FilterChains = nil, nil
+	if !proto.Equal(b, a) {
+		t.Errorf("listener changed outside filter chains:\nbefore: %v\nafter:  %v", b, a)
+	}
+
+	if len(after.FilterChains) != 2 {
+		t.Fatalf("expected 2 filter chains after adding an environment, got %d", len(after.FilterChains))
+	}
+	// The existing environment's chain is untouched, so its connections
+	// are not drained.
+	if !proto.Equal(before.FilterChains[0], after.FilterChains[0]) {
+		t.Error("existing filter chain changed when an environment was added")
+	}
+	if got := after.FilterChains[1].GetName(); got != "prod.example.com" {
+		t.Errorf("new filter chain name = %q, want %q", got, "prod.example.com")
+	}
+}
+
+func TestListenerHCMClientAddressOptions(t *testing.T) {
+	l, err := CreateListenerWithFilterChains(&ListenerConfig{
+		Name: "listener_8080",
+		Port: 8080,
+		FilterChains: []*FilterChainConfig{
+			{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+		},
+		HCM: &HCMOptions{UseRemoteAddress: true, XFFNumTrustedHops: 2},
+	})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+
+	var hcm hcmv3.HttpConnectionManager
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	if got := hcm.GetXffNumTrustedHops(); got != 2 {
+		t.Errorf("xff_num_trusted_hops = %d, want 2", got)
+	}
+	if !hcm.GetUseRemoteAddress().GetValue() {
+		t.Error("expected use_remote_address to be set")
+	}
+}
+
+func TestListenerAuthFailureResponse(t *testing.T) {
+	body := `{"error":"unauthorized","docs":"https://example.com/auth"}`
+	l, err := CreateListenerWithFilterChains(&ListenerConfig{
+		Name: "listener_8080",
+		Port: 8080,
+		FilterChains: []*FilterChainConfig{
+			{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+		},
+		AuthFailureResponse: &AuthFailureResponse{ContentType: "application/json", Body: body},
+	})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if err := l.ValidateAll(); err != nil {
+		t.Fatalf("invalid listener: %v", err)
+	}
+
+	var hcm hcmv3.HttpConnectionManager
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	mappers := hcm.GetLocalReplyConfig().GetMappers()
+	var statuses []uint32
+	for _, m := range mappers {
+		statuses = append(statuses, m.GetFilter().GetStatusCodeFilter().GetComparison().GetValue().GetDefaultValue())
+		if got := m.GetBody().GetInlineString(); got != body {
+			t.Errorf("mapper body = %q, want %q", got, body)
+		}
+		if got := m.GetBodyFormatOverride().GetContentType(); got != "application/json" {
+			t.Errorf("mapper content type = %q, want application/json", got)
+		}
+		if m.GetStatusCode() != nil {
+			t.Errorf("mapper overrides status with %d, want it kept", m.GetStatusCode().GetValue())
+		}
+	}
+	if want := []uint32{401, 403}; !slices.Equal(statuses, want) {
+		t.Errorf("mapped statuses = %v, want %v", statuses, want)
+	}
+
+	_, err = CreateListenerWithFilterChains(&ListenerConfig{
+		Name:                "listener_8080",
+		Port:                8080,
+		AuthFailureResponse: &AuthFailureResponse{StatusCode: 99, Body: body},
+	})
+	if err == nil {
+		t.Error("expected an out-of-range status code to be rejected")
+	}
+}
+
+func TestListenerLocalReplyMappings(t *testing.T) {
+	body := `{"error":"%RESPONSE_CODE_DETAILS%","status":%RESPONSE_CODE%}`
+	l, err := CreateListenerWithFilterChains(&ListenerConfig{
+		Name: "listener_8080",
+		Port: 8080,
+		FilterChains: []*FilterChainConfig{
+			{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+		},
+		LocalReply: []LocalReplyMapping{
+			{Status: "503", ContentType: "application/json", Body: body},
+			{Status: "5xx", Body: "server error"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if err := l.ValidateAll(); err != nil {
+		t.Fatalf("invalid listener: %v", err)
+	}
+
+	var hcm hcmv3.HttpConnectionManager
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	mappers := hcm.GetLocalReplyConfig().GetMappers()
+	if len(mappers) != 2 {
+		t.Fatalf("got %d mappers, want 2", len(mappers))
+	}
+	// Envoy applies the first matching mapper, so a 503 gets the JSON
+	// body rather than the 5xx catch-all.
+	unavailable := mappers[0]
+	if got := unavailable.GetFilter().GetStatusCodeFilter().GetComparison().GetValue().GetDefaultValue(); got != 503 {
+		t.Errorf("first mapper matches status %d, want 503", got)
+	}
+	format := unavailable.GetBodyFormatOverride()
+	if got := format.GetTextFormatSource().GetInlineString(); got != body {
+		t.Errorf("503 body = %q, want %q", got, body)
+	}
+	if got := format.GetContentType(); got != "application/json" {
+		t.Errorf("503 content type = %q, want application/json", got)
+	}
+
+	var bounds []uint32
+	for _, f := range mappers[1].GetFilter().GetAndFilter().GetFilters() {
+		bounds = append(bounds, f.GetStatusCodeFilter().GetComparison().GetValue().GetDefaultValue())
+	}
+	if want := []uint32{500, 599}; !slices.Equal(bounds, want) {
+		t.Errorf("5xx mapper bounds = %v, want %v", bounds, want)
+	}
+
+	for _, status := range []string{"", "50x", "6xx", "99", "abc"} {
+		_, err := CreateListenerWithFilterChains(&ListenerConfig{
+			Name:       "listener_8080",
+			Port:       8080,
+			LocalReply: []LocalReplyMapping{{Status: status, Body: "x"}},
+		})
+		if err == nil {
+			t.Errorf("status %q: expected an error", status)
+		}
+	}
+}
+
+func TestListenerHTTP2WindowSizes(t *testing.T) {
+	newListener := func(opts *HTTP2Options) (*listenerv3.Listener, error) {
+		return CreateListenerWithFilterChains(&ListenerConfig{
+			Name: "listener_8443",
+			Port: 8443,
+			FilterChains: []*FilterChainConfig{
+				{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+			},
+			HTTP2Options: opts,
+		})
+	}
+
+	l, err := newListener(&HTTP2Options{InitialStreamWindowSize: 1 << 20, InitialConnectionWindowSize: 1 << 24})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	var hcm hcmv3.HttpConnectionManager
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	h2 := hcm.GetHttp2ProtocolOptions()
+	if got := h2.GetInitialStreamWindowSize().GetValue(); got != 1<<20 {
+		t.Errorf("initial_stream_window_size = %d, want %d", got, 1<<20)
+	}
+	if got := h2.GetInitialConnectionWindowSize().GetValue(); got != 1<<24 {
+		t.Errorf("initial_connection_window_size = %d, want %d", got, 1<<24)
+	}
+
+	for _, opts := range []*HTTP2Options{
+		{InitialStreamWindowSize: MinHTTP2WindowSize - 1},
+		{InitialConnectionWindowSize: MaxHTTP2WindowSize + 1},
+	} {
+		if _, err := newListener(opts); err == nil {
+			t.Errorf("window sizes %+v accepted, want out-of-range error", *opts)
+		}
+	}
+}
+
+func TestListenerDownstreamConnectionLifetime(t *testing.T) {
+	newListener := func(hcm *HCMOptions, h2 *HTTP2Options) (*listenerv3.Listener, error) {
+		return CreateListenerWithFilterChains(&ListenerConfig{
+			Name: "listener_8443",
+			Port: 8443,
+			FilterChains: []*FilterChainConfig{
+				{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+			},
+			HTTP2Options: h2,
+			HCM:          hcm,
+		})
+	}
+
+	l, err := newListener(
+		&HCMOptions{MaxConnectionDuration: "10m", MaxRequestsPerConnection: 1000},
+		&HTTP2Options{MaxConcurrentStreams: 100},
+	)
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	var hcm hcmv3.HttpConnectionManager
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	common := hcm.GetCommonHttpProtocolOptions()
+	if got := common.GetMaxConnectionDuration().AsDuration(); got != 10*time.Minute {
+		t.Errorf("max_connection_duration = %v, want 10m", got)
+	}
+	if got := common.GetMaxRequestsPerConnection().GetValue(); got != 1000 {
+		t.Errorf("max_requests_per_connection = %d, want 1000", got)
+	}
+	if got := hcm.GetHttp2ProtocolOptions().GetMaxConcurrentStreams().GetValue(); got != 100 {
+		t.Errorf("max_concurrent_streams = %d, want 100", got)
+	}
+
+	// Without limits the HCM keeps Envoy's defaults.
+	l, err = newListener(&HCMOptions{UseRemoteAddress: true}, nil)
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+		t.Fatalf("unmarshal HCM: %v", err)
+	}
+	if hcm.CommonHttpProtocolOptions != nil {
+		t.Errorf("common_http_protocol_options = %v, want unset", hcm.CommonHttpProtocolOptions)
+	}
+
+	for _, d := range []string{"forever", "-1m", "0s"} {
+		if _, err := newListener(&HCMOptions{MaxConnectionDuration: d}, nil); err == nil {
+			t.Errorf("max connection duration %q accepted, want error", d)
+		}
+	}
+}
+
+func listenerFilterNames(l *listenerv3.Listener) []string {
+	var names []string
+	for _, f := range l.ListenerFilters {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestListenerFilters(t *testing.T) {
+	// Every environment of a TLS listener is told apart by SNI, which
+	// only the tls_inspector can read.
+	l := buildListener(t, "staging.example.com", "prod.example.com")
+	if got, want := listenerFilterNames(l), []string{"envoy.filters.listener.tls_inspector"}; !slices.Equal(got, want) {
+		t.Errorf("TLS listener filters = %v, want %v", got, want)
+	}
+	for _, fc := range l.FilterChains {
+		if got := fc.GetFilterChainMatch().GetServerNames(); !slices.Equal(got, []string{fc.Name}) {
+			t.Errorf("chain %s server_names = %v, want [%s]", fc.Name, got, fc.Name)
+		}
+	}
+
+	plain := func(config *ListenerConfig) (*listenerv3.Listener, error) {
+		config.Name, config.Port = "listener_8080", 8080
+		if config.FilterChains == nil {
+			config.FilterChains = []*FilterChainConfig{{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"}}
+		}
+		return CreateListenerWithFilterChains(config)
+	}
+
+	l, err := plain(&ListenerConfig{})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if len(l.ListenerFilters) != 0 {
+		t.Errorf("plain listener filters = %v, want none", listenerFilterNames(l))
+	}
+
+	l, err = plain(&ListenerConfig{FilterChains: []*FilterChainConfig{
+		{Name: "h2c", Hostname: "*", RouteConfigName: "route_l1_*", ApplicationProtocols: []string{"h2c"}},
+	}})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if got, want := listenerFilterNames(l), []string{"envoy.filters.listener.http_inspector"}; !slices.Equal(got, want) {
+		t.Errorf("protocol-matching listener filters = %v, want %v", got, want)
+	}
+	if got := l.FilterChains[0].GetFilterChainMatch().GetApplicationProtocols(); !slices.Equal(got, []string{"h2c"}) {
+		t.Errorf("application_protocols = %v, want [h2c]", got)
+	}
+
+	// Configured filters run in a fixed order whatever order they are
+	// listed in, and duplicates collapse.
+	l, err = plain(&ListenerConfig{ListenerFilters: []string{
+		ListenerFilterHTTPInspector, ListenerFilterProxyProtocol, ListenerFilterHTTPInspector,
+	}})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	want := []string{"envoy.filters.listener.proxy_protocol", "envoy.filters.listener.http_inspector"}
+	if got := listenerFilterNames(l); !slices.Equal(got, want) {
+		t.Errorf("configured listener filters = %v, want %v", got, want)
+	}
+
+	if _, err := plain(&ListenerConfig{ListenerFilters: []string{"original_src"}}); err == nil {
+		t.Error("unknown listener filter accepted")
+	}
+}
+
+func TestWildcardEnvironmentPrecedence(t *testing.T) {
+	// A catch-all preview environment, one specific override, and the
+	// listener-wide default, listed in no particular order.
+	hostnames := []string{"*.preview.example.com", "*", "app.preview.example.com", "*.example.com"}
+	l := buildListener(t, hostnames...)
+
+	var names []string
+	for _, fc := range l.FilterChains {
+		names = append(names, fc.Name)
+	}
+	want := []string{"app.preview.example.com", "*.preview.example.com", "*.example.com", "*"}
+	if !slices.Equal(names, want) {
+		t.Errorf("filter chains = %v, want %v", names, want)
+	}
+
+	for host, want := range map[string]string{
+		"app.preview.example.com":   "app.preview.example.com",
+		"APP.preview.example.com":   "app.preview.example.com",
+		"pr-12.preview.example.com": "*.preview.example.com",
+		"preview.example.com":       "*.example.com",
+		"example.com":               "*",
+	} {
+		if got := MatchHostname(hostnames, host); got != want {
+			t.Errorf("MatchHostname(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if got := MatchHostname([]string{"*.preview.example.com"}, "example.com"); got != "" {
+		t.Errorf("MatchHostname without a match = %q, want none", got)
+	}
+}
+
+func TestListenerAccessLogFormats(t *testing.T) {
+	newHCM := func(opts *AccessLogOptions) (*hcmv3.HttpConnectionManager, error) {
+		l, err := CreateListenerWithFilterChains(&ListenerConfig{
+			Name: "listener_8443",
+			Port: 8443,
+			FilterChains: []*FilterChainConfig{
+				{Name: "*", Hostname: "*", RouteConfigName: "route_l1_*"},
+			},
+			AccessLog: opts,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var hcm hcmv3.HttpConnectionManager
+		if err := l.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(&hcm); err != nil {
+			t.Fatalf("unmarshal HCM: %v", err)
+		}
+		return &hcm, nil
+	}
+
+	hcm, err := newHCM(&AccessLogOptions{})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	if len(hcm.AccessLog) != 1 || hcm.AccessLog[0].Name != "envoy.access_loggers.stdout" {
+		t.Fatalf("access logs = %v, want one stdout log", hcm.AccessLog)
+	}
+	var stdout streamv3.StdoutAccessLog
+	if err := hcm.AccessLog[0].GetTypedConfig().UnmarshalTo(&stdout); err != nil {
+		t.Fatalf("unmarshal stdout access log: %v", err)
+	}
+	fields := stdout.GetLogFormat().GetJsonFormat().GetFields()
+	for key, operator := range map[string]string{
+		"upstream_cluster":  "%UPSTREAM_CLUSTER%",
+		"upstream_host":     "%UPSTREAM_HOST%",
+		"response_flags":    "%RESPONSE_FLAGS%",
+		"duration":          "%DURATION%",
+		"request_duration":  "%REQUEST_DURATION%",
+		"response_duration": "%RESPONSE_DURATION%",
+	} {
+		if got := fields[key].GetStringValue(); got != operator {
+			t.Errorf("JSON field %q = %q, want %q", key, got, operator)
+		}
+	}
+
+	hcm, err = newHCM(&AccessLogOptions{Format: AccessLogFormatText, Path: "/var/log/envoy/access.log"})
+	if err != nil {
+		t.Fatalf("CreateListenerWithFilterChains: %v", err)
+	}
+	var file filev3.FileAccessLog
+	if err := hcm.AccessLog[0].GetTypedConfig().UnmarshalTo(&file); err != nil {
+		t.Fatalf("unmarshal file access log: %v", err)
+	}
+	text := file.GetLogFormat().GetTextFormatSource().GetInlineString()
+	for _, operator := range []string{"%UPSTREAM_CLUSTER%", "%UPSTREAM_HOST%", "%RESPONSE_FLAGS%", "%REQUEST_DURATION%", "%RESPONSE_DURATION%"} {
+		if !strings.Contains(text, operator) {
+			t.Errorf("text format %q lacks %s", text, operator)
+		}
+	}
+	if file.Path != "/var/log/envoy/access.log" {
+		t.Errorf("path = %q", file.Path)
+	}
+
+	if hcm, _ := newHCM(nil); len(hcm.AccessLog) != 0 {
+		t.Errorf("access logs without options = %v, want none", hcm.AccessLog)
+	}
+	if _, err := newHCM(&AccessLogOptions{Format: "xml"}); err == nil {
+		t.Error("unknown access log format accepted")
+	}
+}
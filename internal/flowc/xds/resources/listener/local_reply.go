@@ -0,0 +1,165 @@
+package listener
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// authFailureStatuses are the statuses authentication (jwt_authn) and
+// authorization (ext_authz) filters reject requests with.
+var authFailureStatuses = []uint32{401, 403}
+
+// AuthFailureResponse replaces the local replies of rejected requests
+type AuthFailureResponse struct {
+	// StatusCode overrides the rejection's status; zero keeps 401 or 403
+	StatusCode uint32
+
+	// ContentType of Body (default "text/plain")
+	ContentType string
+
+	// Body is sent as is, without command operator substitution
+	Body string
+}
+
+// LocalReplyMapping rewrites the local replies with a status
+type LocalReplyMapping struct {
+	// Status is an exact status ("503") or a class of them ("5xx")
+	Status string
+
+	// ContentType of Body (default "text/plain")
+	ContentType string
+
+	// Body is a template: command operators such as %RESPONSE_CODE% and
+	// %LOCAL_REPLY_BODY% (Envoy's own body) are substituted
+	Body string
+}
+
+// buildLocalReplyConfig returns the HCM's local reply mappers, or nil when
+// config has none. Envoy applies the first matching mapper, so the auth
+// failure response takes precedence over the local reply mappings, which
+// are tried in order. Mappers only see replies Envoy generates itself, so
+// an upstream's own error responses pass through unchanged.
+func buildLocalReplyConfig(config *ListenerConfig) (*hcmv3.LocalReplyConfig, error) {
+	if config.AuthFailureResponse == nil && len(config.LocalReply) == 0 {
+		return nil, nil
+	}
+	lrc := &hcmv3.LocalReplyConfig{}
+	if auth := config.AuthFailureResponse; auth != nil {
+		mappers, err := authFailureMappers(auth)
+		if err != nil {
+			return nil, err
+		}
+		lrc.Mappers = append(lrc.Mappers, mappers...)
+	}
+	for i, m := range config.LocalReply {
+		mapper, err := localReplyMapper(m)
+		if err != nil {
+			return nil, fmt.Errorf("local reply mapping %d: %w", i, err)
+		}
+		lrc.Mappers = append(lrc.Mappers, mapper)
+	}
+	return lrc, nil
+}
+
+// authFailureMappers returns a mapper for each of authFailureStatuses.
+func authFailureMappers(auth *AuthFailureResponse) ([]*hcmv3.ResponseMapper, error) {
+	if auth.Body == "" {
+		return nil, fmt.Errorf("auth failure response: body is required")
+	}
+	if auth.StatusCode != 0 && (auth.StatusCode < 200 || auth.StatusCode > 599) {
+		return nil, fmt.Errorf("auth failure response: invalid status code %d (must be between 200 and 599)", auth.StatusCode)
+	}
+
+	mappers := make([]*hcmv3.ResponseMapper, 0, len(authFailureStatuses))
+	for _, status := range authFailureStatuses {
+		mapper := &hcmv3.ResponseMapper{
+			Filter: statusCodeFilter(status),
+			Body:   &corev3.DataSource{Specifier: &corev3.DataSource_InlineString{InlineString: auth.Body}},
+			// The body goes through %LOCAL_REPLY_BODY% so '%' in it is
+			// not taken for a command operator.
+			BodyFormatOverride: textFormat("%LOCAL_REPLY_BODY%", auth.ContentType),
+		}
+		if auth.StatusCode != 0 {
+			mapper.StatusCode = wrapperspb.UInt32(auth.StatusCode)
+		}
+		mappers = append(mappers, mapper)
+	}
+	return mappers, nil
+}
+
+// localReplyMapper builds the mapper of m.
+func localReplyMapper(m LocalReplyMapping) (*hcmv3.ResponseMapper, error) {
+	if m.Body == "" {
+		return nil, fmt.Errorf("body is required")
+	}
+	filter, err := statusMatchFilter(m.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &hcmv3.ResponseMapper{
+		Filter:             filter,
+		BodyFormatOverride: textFormat(m.Body, m.ContentType),
+	}, nil
+}
+
+// textFormat returns the body format rendering template as contentType.
+func textFormat(template, contentType string) *corev3.SubstitutionFormatString {
+	return &corev3.SubstitutionFormatString{
+		Format: &corev3.SubstitutionFormatString_TextFormatSource{
+			TextFormatSource: &corev3.DataSource{
+				Specifier: &corev3.DataSource_InlineString{InlineString: template},
+			},
+		},
+		ContentType: contentType,
+	}
+}
+
+// statusMatchFilter matches responses with status, an exact status code
+// or a class such as "5xx".
+func statusMatchFilter(status string) (*accesslogv3.AccessLogFilter, error) {
+	if class, ok := strings.CutSuffix(status, "xx"); ok && len(class) == 1 && class[0] >= '1' && class[0] <= '5' {
+		low := uint32(class[0]-'0') * 100
+		return &accesslogv3.AccessLogFilter{
+			FilterSpecifier: &accesslogv3.AccessLogFilter_AndFilter{
+				AndFilter: &accesslogv3.AndFilter{Filters: []*accesslogv3.AccessLogFilter{
+					statusComparisonFilter(accesslogv3.ComparisonFilter_GE, low),
+					statusComparisonFilter(accesslogv3.ComparisonFilter_LE, low+99),
+				}},
+			},
+		}, nil
+	}
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil || code < 100 || code > 599 {
+		return nil, fmt.Errorf("invalid status %q (must be a status code or a class such as 5xx)", status)
+	}
+	return statusCodeFilter(uint32(code)), nil
+}
+
+// statusCodeFilter matches responses with status.
+func statusCodeFilter(status uint32) *accesslogv3.AccessLogFilter {
+	return statusComparisonFilter(accesslogv3.ComparisonFilter_EQ, status)
+}
+
+// statusComparisonFilter matches responses whose status compares to
+// status with op.
+func statusComparisonFilter(op accesslogv3.ComparisonFilter_Op, status uint32) *accesslogv3.AccessLogFilter {
+	return &accesslogv3.AccessLogFilter{
+		FilterSpecifier: &accesslogv3.AccessLogFilter_StatusCodeFilter{
+			StatusCodeFilter: &accesslogv3.StatusCodeFilter{
+				Comparison: &accesslogv3.ComparisonFilter{
+					Op: op,
+					Value: &corev3.RuntimeUInt32{
+						DefaultValue: status,
+						RuntimeKey:   fmt.Sprintf("flowc.local_reply.status_%d", status),
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package listener
+
+import (
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// StatsListenerConfig contains configuration for a dedicated stats listener
+type StatsListenerConfig struct {
+	// Name of the listener
+	Name string
+
+	// Port to bind to
+	Port uint32
+
+	// Address to bind to (default: "0.0.0.0")
+	Address string
+
+	// ClusterName is the cluster serving /stats and /ready (Envoy's admin
+	// interface or a stats sink)
+	ClusterName string
+}
+
+// CreateStatsListener creates a listener exposing only /stats and /ready,
+// routed to config.ClusterName. It has a single filter chain with no SNI
+// matching and an inline route configuration, so it is independent of RDS
+// and of the environments' filter chains.
+func CreateStatsListener(config *StatsListenerConfig) (*listenerv3.Listener, error) {
+	if config.Address == "" {
+		config.Address = "0.0.0.0"
+	}
+
+	toCluster := &routev3.Route_Route{Route: &routev3.RouteAction{
+		ClusterSpecifier: &routev3.RouteAction_Cluster{Cluster: config.ClusterName},
+	}}
+	routerConfig, err := anypb.New(&routerv3.Router{})
+	if err != nil {
+		return nil, err
+	}
+	manager := &hcmv3.HttpConnectionManager{
+		CodecType:  hcmv3.HttpConnectionManager_AUTO,
+		StatPrefix: "stats",
+		RouteSpecifier: &hcmv3.HttpConnectionManager_RouteConfig{
+			RouteConfig: &routev3.RouteConfiguration{
+				Name: config.Name,
+				VirtualHosts: []*routev3.VirtualHost{{
+					Name:    "stats",
+					Domains: []string{"*"},
+					Routes: []*routev3.Route{
+						{
+							Name:   "stats",
+							Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/stats"}},
+							Action: toCluster,
+						},
+						{
+							Name:   "ready",
+							Match:  &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Path{Path: "/ready"}},
+							Action: toCluster,
+						},
+					},
+				}},
+			},
+		},
+		HttpFilters: []*hcmv3.HttpFilter{{
+			Name:       "http-router",
+			ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: routerConfig},
+		}},
+	}
+	pbst, err := anypb.New(manager)
+	if err != nil {
+		return nil, err
+	}
+
+	return &listenerv3.Listener{
+		Name: config.Name,
+		Address: &corev3.Address{
+			Address: &corev3.Address_SocketAddress{
+				SocketAddress: &corev3.SocketAddress{
+					Address: config.Address,
+					PortSpecifier: &corev3.SocketAddress_PortValue{
+						PortValue: config.Port,
+					},
+				},
+			},
+		},
+		FilterChains: []*listenerv3.FilterChain{{
+			Name: "stats",
+			Filters: []*listenerv3.Filter{{
+				Name:       "http_connection_manager",
+				ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: pbst},
+			}},
+		}},
+		EnableReusePort: wrapperspb.Bool(true),
+	}, nil
+}
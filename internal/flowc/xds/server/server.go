@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"time"
 
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
@@ -23,12 +25,48 @@ type XDSServer struct {
 	grpcServer *grpc.Server
 	cache      cachev3.SnapshotCache
 	server     serverv3.Server
+	streams    *StreamTracker
 	logger     *logger.EnvoyLogger
 	port       int
 }
 
+// Defaults for GRPCLimits fields left at zero.
+const (
+	// DefaultMaxSendMsgSize lets a snapshot of any size reach Envoy in a
+	// single DiscoveryResponse
+	DefaultMaxSendMsgSize = math.MaxInt32
+
+	// DefaultMaxRecvMsgSize is gRPC's own; DiscoveryRequests are small
+	DefaultMaxRecvMsgSize = 4 << 20
+)
+
+// GRPCLimits bounds the streams and message sizes of the gRPC server.
+// Zero sizes take the defaults above; a zero MaxConcurrentStreams places
+// no limit on the streams of a connection.
+type GRPCLimits struct {
+	MaxConcurrentStreams uint32
+	MaxSendMsgSize       int
+	MaxRecvMsgSize       int
+}
+
+// serverOptions returns the gRPC server options enforcing l.
+func (l GRPCLimits) serverOptions() []grpc.ServerOption {
+	send, recv := l.MaxSendMsgSize, l.MaxRecvMsgSize
+	if send == 0 {
+		send = DefaultMaxSendMsgSize
+	}
+	if recv == 0 {
+		recv = DefaultMaxRecvMsgSize
+	}
+	opts := []grpc.ServerOption{grpc.MaxSendMsgSize(send), grpc.MaxRecvMsgSize(recv)}
+	if l.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(l.MaxConcurrentStreams))
+	}
+	return opts
+}
+
 // NewXDSServer creates a new XDS server instance
-func NewXDSServer(port int, keepaliveTime, keepaliveTimeout, keepaliveMinTime time.Duration, keepalivePermitWithoutStream bool, envoyLogger *logger.EnvoyLogger) *XDSServer {
+func NewXDSServer(port int, keepaliveTime, keepaliveTimeout, keepaliveMinTime time.Duration, keepalivePermitWithoutStream bool, limits GRPCLimits, envoyLogger *logger.EnvoyLogger) *XDSServer {
 	// Create a snapshot cache
 	snapshotCache := cachev3.NewSnapshotCache(true, cachev3.IDHash{}, envoyLogger)
 
@@ -37,10 +75,38 @@ func NewXDSServer(port int, keepaliveTime, keepaliveTimeout, keepaliveMinTime ti
 	// /ready flips green on first connect instead of waiting out the full
 	// ADS initial-fetch timeout (and getting killed by the liveness probe
 	// in the chicken-and-egg startup case).
-	xdsServer := serverv3.NewServer(context.Background(), snapshotCache, seedEmptyOnConnect(snapshotCache, envoyLogger))
+	// They also track open streams so they can be listed and closed.
+	seed := seedEmptyOnConnect(snapshotCache, envoyLogger)
+	streams := NewStreamTracker()
+	callbacks := serverv3.CallbackFuncs{
+		StreamOpenFunc: func(ctx context.Context, id int64, _ string) error {
+			streams.onOpen(ctx, streamKey{id: id})
+			return nil
+		},
+		StreamClosedFunc: func(id int64, _ *corev3.Node) { streams.onClosed(streamKey{id: id}) },
+		StreamRequestFunc: func(id int64, req *discoveryv3.DiscoveryRequest) error {
+			if err := streams.onRequest(streamKey{id: id}, req.GetNode()); err != nil {
+				return err
+			}
+			return seed.OnStreamRequest(id, req)
+		},
+		DeltaStreamOpenFunc: func(ctx context.Context, id int64, _ string) error {
+			streams.onOpen(ctx, streamKey{id: id, delta: true})
+			return nil
+		},
+		DeltaStreamClosedFunc: func(id int64, _ *corev3.Node) { streams.onClosed(streamKey{id: id, delta: true}) },
+		StreamDeltaRequestFunc: func(id int64, req *discoveryv3.DeltaDiscoveryRequest) error {
+			if err := streams.onRequest(streamKey{id: id, delta: true}, req.GetNode()); err != nil {
+				return err
+			}
+			return seed.OnStreamDeltaRequest(id, req)
+		},
+	}
+	xdsServer := serverv3.NewServer(context.Background(), snapshotCache, callbacks)
 
-	// Configure gRPC server with keepalive settings
-	grpcServer := grpc.NewServer(
+	// Configure gRPC server with keepalive settings and limits
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.StreamInterceptor(streams.Interceptor()),
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			Time:    keepaliveTime,
 			Timeout: keepaliveTimeout,
@@ -49,12 +115,13 @@ func NewXDSServer(port int, keepaliveTime, keepaliveTimeout, keepaliveMinTime ti
 			MinTime:             keepaliveMinTime,
 			PermitWithoutStream: keepalivePermitWithoutStream,
 		}),
-	)
+	}, limits.serverOptions()...)...)
 
 	return &XDSServer{
 		grpcServer: grpcServer,
 		cache:      snapshotCache,
 		server:     xdsServer,
+		streams:    streams,
 		logger:     envoyLogger,
 		port:       port,
 	}
@@ -97,6 +164,11 @@ func (s *XDSServer) GetCache() cachev3.SnapshotCache {
 	return s.cache
 }
 
+// Streams returns the tracker of open xDS streams
+func (s *XDSServer) Streams() *StreamTracker {
+	return s.streams
+}
+
 // GetLogger returns the logger instance
 func (s *XDSServer) GetLogger() *logger.EnvoyLogger {
 	return s.logger
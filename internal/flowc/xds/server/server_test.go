@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/flowc-labs/flowc/pkg/logger"
+)
+
+// fetchListeners serves s on a loopback port and requests the listeners
+// of nodeID over ADS, returning the error of the first response.
+func fetchListeners(t *testing.T, s *XDSServer, nodeID string) error {
+	t.Helper()
+	s.RegisterServices()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = s.grpcServer.Serve(lis) }()
+	t.Cleanup(s.grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	stream, err := discoveryv3.NewAggregatedDiscoveryServiceClient(conn).StreamAggregatedResources(ctx)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if err := stream.Send(&discoveryv3.DiscoveryRequest{
+		Node:    &corev3.Node{Id: nodeID},
+		TypeUrl: resourcev3.ListenerType,
+	}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	_, err = stream.Recv()
+	return err
+}
+
+func TestXDSServerMaxSendMsgSize(t *testing.T) {
+	log := logger.NewJSONLoggerWithWriter(io.Discard, logger.ErrorLevel)
+	newServer := func(limits GRPCLimits) *XDSServer {
+		s := NewXDSServer(0, time.Minute, time.Minute, time.Second, true, limits, log)
+		if err := s.InitializeDefaultListener("node-1", 8080); err != nil {
+			t.Fatalf("InitializeDefaultListener: %v", err)
+		}
+		return s
+	}
+
+	if err := fetchListeners(t, newServer(GRPCLimits{}), "node-1"); err != nil {
+		t.Fatalf("default limits: %v", err)
+	}
+
+	// The default listener alone is larger than 64 bytes.
+	err := fetchListeners(t, newServer(GRPCLimits{MaxSendMsgSize: 64}), "node-1")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want ResourceExhausted", err)
+	}
+}
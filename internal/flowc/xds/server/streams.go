@@ -0,0 +1,167 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ErrStreamNotFound is returned when closing a stream that is not open on
+// the given node.
+var ErrStreamNotFound = errors.New("stream not found")
+
+// StreamInfo describes an open xDS stream.
+type StreamInfo struct {
+	// ID is assigned by the tracker: the state-of-the-world and delta
+	// servers number their streams independently.
+	ID int64 `json:"id"`
+	// NodeID is empty until the stream's first request identifies it.
+	NodeID      string    `json:"nodeId"`
+	PeerAddress string    `json:"peerAddress"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	Delta       bool      `json:"delta"`
+}
+
+// streamKey identifies a stream by the server that numbered it.
+type streamKey struct {
+	id    int64
+	delta bool
+}
+
+type trackedStream struct {
+	info    StreamInfo
+	cancel  context.CancelFunc
+	revoked bool
+}
+
+// StreamTracker records the open xDS streams from server callbacks and
+// can force-close them. Closing needs the stream to have been opened
+// through Interceptor, which makes its receive side cancellable; a stream
+// opened without it is closed on its next request instead.
+type StreamTracker struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	lastID  int64
+	streams map[streamKey]*trackedStream
+}
+
+// NewStreamTracker returns an empty tracker.
+func NewStreamTracker() *StreamTracker {
+	return &StreamTracker{now: time.Now, streams: make(map[streamKey]*trackedStream)}
+}
+
+// ListStreams returns the open streams of nodeID, oldest first.
+func (t *StreamTracker) ListStreams(nodeID string) []StreamInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := []StreamInfo{}
+	for _, s := range t.streams {
+		if s.info.NodeID == nodeID && !s.revoked {
+			out = append(out, s.info)
+		}
+	}
+	slices.SortFunc(out, func(a, b StreamInfo) int { return cmp.Compare(a.ID, b.ID) })
+	return out
+}
+
+// CloseStream force-disconnects stream id of nodeID. Envoy sees the
+// stream end and reconnects, getting a fresh stream.
+func (t *StreamTracker) CloseStream(nodeID string, id int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.streams {
+		if s.info.ID != id || s.info.NodeID != nodeID || s.revoked {
+			continue
+		}
+		s.revoked = true
+		if s.cancel != nil {
+			s.cancel()
+		}
+		return nil
+	}
+	return ErrStreamNotFound
+}
+
+func (t *StreamTracker) onOpen(ctx context.Context, key streamKey) {
+	s := &trackedStream{info: StreamInfo{ConnectedAt: t.now(), Delta: key.delta}}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		s.info.PeerAddress = p.Addr.String()
+	}
+	s.cancel, _ = ctx.Value(streamCancelKey{}).(context.CancelFunc)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastID++
+	s.info.ID = t.lastID
+	t.streams[key] = s
+}
+
+// onRequest records the node of stream id and fails the request, ending
+// the stream, once it has been closed.
+func (t *StreamTracker) onRequest(key streamKey, node *corev3.Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.streams[key]
+	if !ok {
+		return nil
+	}
+	if s.revoked {
+		return status.Error(codes.Aborted, "stream closed by control plane")
+	}
+	if s.info.NodeID == "" {
+		s.info.NodeID = node.GetId()
+	}
+	return nil
+}
+
+func (t *StreamTracker) onClosed(key streamKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.streams, key)
+}
+
+// streamCancelKey carries the cancel func of a revocable stream's context.
+type streamCancelKey struct{}
+
+// Interceptor makes every stream revocable: its context can be cancelled
+// through CloseStream, which also fails the pending receive so the xDS
+// server ends the stream.
+func (t *StreamTracker) Interceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		ctx = context.WithValue(ctx, streamCancelKey{}, cancel)
+		return handler(srv, &revocableStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type revocableStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *revocableStream) Context() context.Context { return s.ctx }
+
+// RecvMsg returns once a message arrives or the stream is revoked. A
+// revoked stream's pending receive finishes when gRPC tears the stream
+// down after the handler returns.
+func (s *revocableStream) RecvMsg(m any) error {
+	done := make(chan error, 1)
+	go func() { done <- s.ServerStream.RecvMsg(m) }()
+	select {
+	case err := <-done:
+		return err
+	case <-s.ctx.Done():
+		return status.Error(codes.Aborted, "stream closed by control plane")
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStream is a gRPC server stream whose receives block until the
+// client hangs up.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	hangup chan struct{}
+}
+
+func (f *fakeStream) Context() context.Context { return f.ctx }
+
+func (f *fakeStream) RecvMsg(any) error {
+	<-f.hangup
+	return errors.New("client hung up")
+}
+
+// openStream runs a stream through the tracker's interceptor the way the
+// xDS server does: it reports the open and first request, then receives
+// until the stream fails. It returns the stream's handler error.
+func openStream(t *testing.T, tracker *StreamTracker, key streamKey, nodeID, addr string) (<-chan error, chan struct{}) {
+	t.Helper()
+	hangup := make(chan struct{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 5000}})
+	opened := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- tracker.Interceptor()(nil, &fakeStream{ctx: ctx, hangup: hangup}, nil, func(_ any, ss grpc.ServerStream) error {
+			tracker.onOpen(ss.Context(), key)
+			defer tracker.onClosed(key)
+			if err := tracker.onRequest(key, &corev3.Node{Id: nodeID}); err != nil {
+				return err
+			}
+			close(opened)
+			for {
+				if err := ss.RecvMsg(nil); err != nil {
+					return err
+				}
+			}
+		})
+	}()
+	select {
+	case <-opened:
+	case err := <-done:
+		t.Fatalf("stream ended before opening: %v", err)
+	}
+	return done, hangup
+}
+
+func TestStreamTrackerListAndClose(t *testing.T) {
+	tracker := NewStreamTracker()
+	connectedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tracker.now = func() time.Time { return connectedAt }
+
+	// The state-of-the-world and delta servers both number from 1.
+	sotwDone, sotwHangup := openStream(t, tracker, streamKey{id: 1}, "edge-node", "10.0.0.1")
+	deltaDone, _ := openStream(t, tracker, streamKey{id: 1, delta: true}, "edge-node", "10.0.0.2")
+	_, otherHangup := openStream(t, tracker, streamKey{id: 2}, "other-node", "10.0.0.3")
+	defer close(otherHangup)
+
+	streams := tracker.ListStreams("edge-node")
+	if len(streams) != 2 {
+		t.Fatalf("edge-node streams = %+v, want 2", streams)
+	}
+	if s := streams[0]; s.PeerAddress != "10.0.0.1:5000" || !s.ConnectedAt.Equal(connectedAt) || s.Delta {
+		t.Errorf("first stream = %+v, want sotw stream from 10.0.0.1:5000", s)
+	}
+	if s := streams[1]; s.PeerAddress != "10.0.0.2:5000" || !s.Delta || s.ID == streams[0].ID {
+		t.Errorf("second stream = %+v, want delta stream from 10.0.0.2:5000 with its own ID", s)
+	}
+
+	if err := tracker.CloseStream("other-node", streams[1].ID); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("close via wrong node: err = %v, want ErrStreamNotFound", err)
+	}
+	if err := tracker.CloseStream("edge-node", streams[1].ID); err != nil {
+		t.Fatalf("CloseStream: %v", err)
+	}
+	select {
+	case err := <-deltaDone:
+		if status.Code(err) != codes.Aborted {
+			t.Errorf("closed stream ended with %v, want Aborted", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("closed stream is still receiving")
+	}
+	if err := tracker.CloseStream("edge-node", streams[1].ID); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("second close: err = %v, want ErrStreamNotFound", err)
+	}
+
+	// A stream whose client hangs up drops out of the listing on its own.
+	close(sotwHangup)
+	<-sotwDone
+	if got := tracker.ListStreams("edge-node"); len(got) != 0 {
+		t.Errorf("edge-node streams after disconnects = %+v, want none", got)
+	}
+	if got := tracker.ListStreams("other-node"); len(got) != 1 {
+		t.Errorf("other-node streams = %+v, want 1", got)
+	}
+}
@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"fmt"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	bufferv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// DefaultBufferMaxRequestBytes is the largest request body buffered when
+// filters.buffer_max_request_bytes is unset.
+const DefaultBufferMaxRequestBytes = 1 << 20
+
+// buildBufferFilter returns the buffer filter. It is disabled on the
+// filter chain, which other deployments on the listener share, and only
+// runs on the routes applyRequestBuffering enables it on; everything else
+// keeps streaming.
+func buildBufferFilter() (*hcmv3.HttpFilter, error) {
+	f, err := newHTTPFilter(BufferFilterName, &bufferv3.Buffer{
+		MaxRequestBytes: wrapperspb.UInt32(DefaultBufferMaxRequestBytes),
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.Disabled = true
+	return f, nil
+}
+
+// applyRequestBuffering enables the buffer filter on every route of a
+// deployment with filters.buffer set, so the upstream receives each
+// request only once its whole body has arrived. Routes that already
+// configure the filter keep their config: SSE routes stay unbuffered.
+func applyRequestBuffering(routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) error {
+	cfg := deployment.Metadata.Filters
+	if cfg == nil || !cfg.Buffer {
+		return nil
+	}
+	maxBytes := cfg.BufferMaxRequestBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultBufferMaxRequestBytes
+	}
+	typed, err := anypb.New(&bufferv3.BufferPerRoute{
+		Override: &bufferv3.BufferPerRoute_Buffer{
+			Buffer: &bufferv3.Buffer{MaxRequestBytes: wrapperspb.UInt32(maxBytes)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s config: %w", BufferFilterName, err)
+	}
+
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			for _, route := range vh.Routes {
+				if route.GetRoute() == nil || route.TypedPerFilterConfig[BufferFilterName] != nil {
+					continue
+				}
+				if route.TypedPerFilterConfig == nil {
+					route.TypedPerFilterConfig = make(map[string]*anypb.Any)
+				}
+				route.TypedPerFilterConfig[BufferFilterName] = typed
+			}
+		}
+	}
+	return nil
+}
@@ -3,12 +3,21 @@ package translator
 import (
 	"context"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
 	"github.com/flowc-labs/flowc/pkg/logger"
 )
 
@@ -140,6 +149,20 @@ func (t *CompositeTranslator) Translate(ctx context.Context, deployment *models.
 		}
 	}
 
+	// PHASE 2a: Generate clusters for the servers endpoints override the
+	// API's servers with. They are published after the fallback phase:
+	// like version routing, the fallback applies to the deployment's own
+	// upstream only.
+	serverClusters, err := endpointServerClusters(deployment, irAPI)
+	if err != nil {
+		return nil, fmt.Errorf("cluster generation failed: %w", err)
+	}
+	for _, cluster := range serverClusters {
+		if err := t.strategies.LoadBalancing.ConfigureCluster(cluster, deployment); err != nil {
+			return nil, fmt.Errorf("load balancing configuration failed for cluster %s: %w", cluster.Name, err)
+		}
+	}
+
 	// PHASE 3: Generate routes using IR
 	routes, err := t.generateRoutes(deployment, irAPI)
 	if err != nil {
@@ -152,6 +175,14 @@ func (t *CompositeTranslator) Translate(ctx context.Context, deployment *models.
 		}).Debug("Generated routes")
 	}
 
+	// PHASE 3a: Serve each route per API version when versions are
+	// selected by request header
+	t.applyVersionRouting(routes, deployment)
+
+	// PHASE 3b: Keep clients of a sticky split on the cluster they were
+	// first sent to
+	t.applyStickySplit(routes, deployment)
+
 	// PHASE 4: Apply retry strategy to routes
 	for _, routeConfig := range routes {
 		for _, vhost := range routeConfig.VirtualHosts {
@@ -163,12 +194,124 @@ func (t *CompositeTranslator) Translate(ctx context.Context, deployment *models.
 		}
 	}
 
+	// PHASE 4a: Send requests the primary upstream cannot serve to the
+	// fallback upstream
+	fallback, err := fallbackCluster(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("fallback cluster generation failed: %w", err)
+	}
+	if fallback != nil {
+		if err := t.strategies.LoadBalancing.ConfigureCluster(fallback, deployment); err != nil {
+			return nil, fmt.Errorf("load balancing configuration failed for cluster %s: %w", fallback.Name, err)
+		}
+		if clusters, err = applyFallback(clusters, fallback, routes, deployment); err != nil {
+			return nil, fmt.Errorf("fallback configuration failed: %w", err)
+		}
+	}
+
+	clusters = append(clusters, serverClusters...)
+
+	// Shadow a percentage of the requests to the mirror upstream
+	mirror, err := mirrorCluster(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("mirror cluster generation failed: %w", err)
+	}
+	if mirror != nil {
+		clusters = append(clusters, mirror)
+		applyMirror(routes, mirror.Name, deployment.Metadata.Mirror.Percentage)
+	}
+
+	// PHASE 4b: Let deployment strategies with their own HCM filters
+	// (dynamic forward proxy) configure the routes they serve
+	contributor, _ := t.strategies.Deployment.(HTTPFilterContributor)
+	if contributor != nil {
+		for _, routeConfig := range routes {
+			for _, vhost := range routeConfig.VirtualHosts {
+				for _, route := range vhost.Routes {
+					if err := contributor.ConfigureRoute(route, deployment); err != nil {
+						return nil, fmt.Errorf("route configuration failed: %w", err)
+					}
+				}
+			}
+		}
+	}
+
+	// PHASE 4c: Apply the API-wide rate limit to routes. Routes with an
+	// endpoint-level limit already carry their own override.
+	for _, routeConfig := range routes {
+		for _, vhost := range routeConfig.VirtualHosts {
+			if err := t.strategies.RateLimit.ConfigureRateLimit(vhost, deployment); err != nil {
+				if err := optionalStrategyFailed(t.options, t.logger, "rate_limit", deployment, fmt.Errorf("rate limit configuration failed: %w", err)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// PHASE 4d: Reject the methods the environment does not allow
+	if t.translationContext != nil && t.translationContext.Listener != nil {
+		if err := applyAllowedMethods(routes, t.translationContext.Listener.AllowedMethods); err != nil {
+			return nil, fmt.Errorf("method restriction failed: %w", err)
+		}
+	}
+
+	// PHASE 4e: Redirect plain HTTP requests to https
+	if t.translationContext != nil && t.translationContext.Listener != nil {
+		applyHTTPSRedirect(routes, t.translationContext.Listener.HTTPSRedirectPort)
+	}
+
+	// PHASE 5: Build the opt-in HTTP filters this deployment contributes
+	// to its listener's HTTP connection manager
+	httpFilters, err := BuildHTTPFilters(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("http filter generation failed: %w", err)
+	}
+	rateLimitFilter, err := buildLocalRateLimitFilter(routes)
+	if err != nil {
+		return nil, fmt.Errorf("http filter generation failed: %w", err)
+	}
+	if rateLimitFilter != nil {
+		httpFilters = append(httpFilters, rateLimitFilter)
+	}
+	corsFilter, err := buildCORSFilter(routes)
+	if err != nil {
+		if err := optionalStrategyFailed(t.options, t.logger, "cors", deployment, fmt.Errorf("http filter generation failed: %w", err)); err != nil {
+			return nil, err
+		}
+	}
+	if corsFilter != nil {
+		// CORS runs first so preflights are answered before any other
+		// filter sees them.
+		httpFilters = append([]*hcmv3.HttpFilter{corsFilter}, httpFilters...)
+	}
+	if contributor != nil {
+		strategyFilters, err := contributor.HTTPFilters(deployment)
+		if err != nil {
+			return nil, fmt.Errorf("http filter generation failed: %w", err)
+		}
+		httpFilters = append(httpFilters, strategyFilters...)
+	}
+	if err := ApplyCacheTTL(routes, deployment); err != nil {
+		return nil, fmt.Errorf("cache configuration failed: %w", err)
+	}
+	if err := applyRequestBuffering(routes, deployment); err != nil {
+		return nil, fmt.Errorf("buffer configuration failed: %w", err)
+	}
+	if err := applyRouteFilters(routes, deployment); err != nil {
+		return nil, fmt.Errorf("http filter configuration failed: %w", err)
+	}
+
+	// PHASE 6: Merge the deployment's raw Envoy overrides last, so they
+	// win over everything the strategies generated
+	if err := applyEnvoyOverrides(routes, clusters, deployment); err != nil {
+		return nil, fmt.Errorf("envoy override failed: %w", err)
+	}
+
 	// Listeners are gateway-scoped and built by the dispatch package's
 	// GatewayTranslator from Listener CRs. The per-deployment translation
-	// here only contributes clusters / endpoints / routes; rate-limit and
-	// observability strategies that operated on listeners no longer have
-	// a target at this layer and are skipped — they'll need to be
-	// reworked when actually implemented (today's strategies are no-ops).
+	// here only contributes clusters / endpoints / routes / HCM filters;
+	// the observability strategy, which operates on listeners, has no
+	// target at this layer and is skipped (today's strategies are no-ops).
 
 	if t.logger != nil {
 		t.logger.WithFields(map[string]any{
@@ -178,8 +321,9 @@ func (t *CompositeTranslator) Translate(ctx context.Context, deployment *models.
 	}
 
 	return &XDSResources{
-		Clusters: clusters,
-		Routes:   routes,
+		Clusters:    clusters,
+		Routes:      routes,
+		HTTPFilters: httpFilters,
 		// Listeners and Endpoints are unused at this layer; left nil.
 	}, nil
 }
@@ -200,11 +344,8 @@ func (t *CompositeTranslator) generateRoutes(deployment *models.APIDeployment, i
 		if basePath[0] != '/' {
 			basePath = "/" + basePath
 		}
-		routeAction := &routev3.RouteAction{
-			ClusterSpecifier: &routev3.RouteAction_Cluster{
-				Cluster: clusterNames[0],
-			},
-		}
+		routeAction := &routev3.RouteAction{}
+		t.setDestination(routeAction, deployment, clusterNames[0])
 		// Match: PathSeparatedPrefix matches at path-segment boundaries
 		// (so /httpbingo doesn't false-match /httpbin) and is invalid for
 		// basePath "/", so we fall back to Prefix at the root.
@@ -232,6 +373,7 @@ func (t *CompositeTranslator) generateRoutes(deployment *models.APIDeployment, i
 				Substitution: "/",
 			}
 		}
+		applyQueryMatchers(match, routeQueryParams(deployment, nil))
 		routeName := t.getRouteConfigName()
 		routeConfig := &routev3.RouteConfiguration{
 			Name: routeName,
@@ -260,11 +402,16 @@ func (t *CompositeTranslator) generateRoutes(deployment *models.APIDeployment, i
 	// Primary cluster is the first one (or only one for basic deployments)
 	primaryCluster := clusterNames[0]
 
-	var xdsRoutes []*routev3.Route
+	// Routes per virtual host group: the endpoint's first tag when
+	// grouping by tag, "" (the deployment's own virtual host) otherwise.
+	groupRoutes := map[string][]*routev3.Route{}
+	groupByTag := deployment.Metadata.Gateway.VirtualHost.GroupByTag && t.tagGroupsReachable()
 
 	// Get base path from metadata
 	basePath := t.getBasePath(deployment, irAPI)
 
+	corsPolicies := specCORSPolicies(deployment, irAPI)
+
 	// Create routes for each IR endpoint
 	for _, endpoint := range irAPI.Endpoints {
 		// Build the full path with gateway basepath prefix
@@ -272,44 +419,236 @@ func (t *CompositeTranslator) generateRoutes(deployment *models.APIDeployment, i
 
 		// Use route match strategy to create matcher
 		routeMatch := t.strategies.RouteMatch.CreateMatcher(fullPath, endpoint.Method, &endpoint)
+		applyQueryMatchers(routeMatch, routeQueryParams(deployment, &endpoint))
 
 		// Create route with primary cluster as destination.
 		// PrefixRewrite strips the basePath so the upstream sees the
 		// original API path (e.g., /httpbin/get → /get).
-		routeAction := &routev3.RouteAction{
-			ClusterSpecifier: &routev3.RouteAction_Cluster{
-				Cluster: primaryCluster,
-			},
+		// Endpoints with their own server are routed to its cluster,
+		// under the server URL's path.
+		server, err := resolveEndpointServer(&endpoint)
+		if err != nil {
+			return nil, err
+		}
+		routeAction := &routev3.RouteAction{}
+		upstreamPath := ""
+		if server != nil {
+			routeAction.ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: endpointServerClusterName(deployment, server)}
+			upstreamPath = server.BasePath
+		} else {
+			t.setDestination(routeAction, deployment, primaryCluster)
+			if subsets := deployment.Metadata.Upstream.Subsets; subsets != nil && len(subsets.Match) > 0 {
+				routeAction.MetadataMatch = cluster.LBMetadata(subsets.Match)
+			}
 		}
-		if basePath != "" && basePath != "/" {
-			routeAction.PrefixRewrite = TruncatePathParams(endpoint.Path.Pattern)
+		if (basePath != "" && basePath != "/") || upstreamPath != "" {
+			routeAction.PrefixRewrite = upstreamPath + TruncatePathParams(endpoint.Path.Pattern)
+		}
+		if isStreamingEndpoint(&endpoint) {
+			// Streams outlive any request timeout; only cut them off
+			// once they go quiet.
+			routeAction.Timeout = durationpb.New(0)
+			routeAction.IdleTimeout = durationpb.New(StreamIdleTimeout)
 		}
 
 		route := &routev3.Route{
 			Match:  routeMatch,
 			Action: &routev3.Route_Route{Route: routeAction},
 		}
+		if err := applyEndpointRateLimit(route, &endpoint); err != nil {
+			return nil, err
+		}
+		if isSSEEndpoint(&endpoint) {
+			if err := applySSERouteOptions(route); err != nil {
+				return nil, err
+			}
+		}
+		if policy := corsPolicies[endpoint.Path.Pattern]; policy != nil {
+			if err := applyCORSPolicy(route, policy); err != nil {
+				return nil, err
+			}
+		}
 
-		xdsRoutes = append(xdsRoutes, route)
+		group := ""
+		if groupByTag && len(endpoint.Tags) > 0 {
+			group = tagGroupName(endpoint.Tags[0])
+		}
+		// Requests missing a required header are rejected by guards
+		// ahead of the route.
+		guards := requiredHeaderGuards(route, specRequiredHeaders(deployment, &endpoint))
+		groupRoutes[group] = append(groupRoutes[group], append(guards, route)...)
+	}
+
+	// The deployment's own virtual host comes first, followed by one per
+	// tag group in name order. The default host is omitted when every
+	// endpoint is tagged.
+	groups := slices.Sorted(maps.Keys(groupRoutes))
+	vhostName := t.generateVirtualHostName(deployment)
+	domains := t.getDomains(deployment)
+	vhosts := make([]*routev3.VirtualHost, 0, len(groups))
+	for _, group := range groups {
+		xdsRoutes := groupRoutes[group]
+		// Envoy matches routes in order; put the most specific first so a
+		// broad prefix can't shadow a narrower path.
+		SortRoutesBySpecificity(xdsRoutes)
+
+		vhost := &routev3.VirtualHost{
+			Name:    vhostName,
+			Domains: domains,
+			Routes:  xdsRoutes,
+		}
+		if group != "" {
+			vhost.Name = vhostName + "-" + group
+			vhost.Domains = tagGroupDomains(group, domains)
+		}
+		vhosts = append(vhosts, vhost)
 	}
 
 	// Create route configuration with environment-aware name
 	// Route config name must match what the listener expects: route_{listenerID}_{environmentName}
 	routeName := t.getRouteConfigName()
 	routeConfig := &routev3.RouteConfiguration{
-		Name: routeName,
-		VirtualHosts: []*routev3.VirtualHost{
-			{
-				Name:    t.generateVirtualHostName(deployment),
-				Domains: t.getDomains(deployment),
-				Routes:  xdsRoutes,
-			},
-		},
+		Name:         routeName,
+		VirtualHosts: vhosts,
 	}
 
 	return []*routev3.RouteConfiguration{routeConfig}, nil
 }
 
+// applyVersionRouting expands every route into one route per API version
+// when the route match strategy selects versions by header and the
+// deployment strategy has versioned clusters. Requests without the
+// header go to the default version rather than being split by weight.
+func (t *CompositeTranslator) applyVersionRouting(routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) {
+	matcher, ok := t.strategies.RouteMatch.(VersionRouteMatcher)
+	if !ok {
+		return
+	}
+	versioned, ok := t.strategies.Deployment.(VersionedClusters)
+	if !ok {
+		return
+	}
+	versions := versioned.VersionClusters(deployment)
+	own := t.strategies.Deployment.GetClusterNames(deployment)
+	for _, rc := range routes {
+		for _, vhost := range rc.VirtualHosts {
+			expanded := make([]*routev3.Route, 0, len(vhost.Routes)*len(versions))
+			for _, route := range vhost.Routes {
+				// Routes to an endpoint's own server are not versioned.
+				if c := route.GetRoute().GetCluster(); c != "" && !slices.Contains(own, c) {
+					expanded = append(expanded, route)
+					continue
+				}
+				expanded = append(expanded, versionRoutes(route, matcher.VersionHeader(), versions)...)
+			}
+			// Version-matched routes carry one more header matcher, so
+			// they sort ahead of the default route for the same path.
+			SortRoutesBySpecificity(expanded)
+			vhost.Routes = expanded
+		}
+	}
+}
+
+// StreamIdleTimeout is the idle timeout for streaming endpoints' routes,
+// whose request timeout is disabled.
+const StreamIdleTimeout = 5 * time.Minute
+
+// isStreamingEndpoint reports whether the endpoint holds its response open
+// (SSE, or any response marked streaming).
+func isStreamingEndpoint(endpoint *ir.Endpoint) bool {
+	if isSSEEndpoint(endpoint) {
+		return true
+	}
+	return slices.ContainsFunc(endpoint.Responses, func(r ir.ResponseSpec) bool { return r.Streaming })
+}
+
+// tagGroupName turns an OpenAPI tag into a DNS label ("Admin Ops" →
+// "admin-ops") usable in virtual host names and domains.
+func tagGroupName(tag string) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, tag)
+	label = strings.Trim(label, "-")
+	if label == "" {
+		label = "untagged"
+	}
+	return label
+}
+
+// tagGroupsReachable reports whether tag group subdomains can reach this
+// deployment's listener. A TLS listener serving an exact hostname only
+// matches that name in its filter chain's SNI server names, so a
+// "<tag>." subdomain would never get through; tagged endpoints then stay
+// on the deployment's own virtual host.
+func (t *CompositeTranslator) tagGroupsReachable() bool {
+	tc := t.translationContext
+	if tc == nil || tc.Listener == nil || tc.Listener.TLS == nil || tc.VirtualHost == nil {
+		return true
+	}
+	host := tc.VirtualHost.Hostname
+	return host == "" || host == "*" || strings.HasPrefix(host, "*.")
+}
+
+// tagGroupDomains derives a tag group's domains from the deployment's.
+// Envoy rejects duplicate domains across the virtual hosts of a route
+// config, so each group is served on a subdomain named after the tag:
+// "*" becomes "admin.*", "*.example.com" becomes "admin.example.com" and
+// "api.example.com" becomes "admin.api.example.com".
+func tagGroupDomains(group string, domains []string) []string {
+	out := make([]string, 0, len(domains))
+	for _, d := range domains {
+		switch {
+		case d == "*":
+			out = append(out, group+".*")
+		case strings.HasPrefix(d, "*."):
+			out = append(out, group+d[1:])
+		default:
+			out = append(out, group+"."+d)
+		}
+	}
+	return out
+}
+
+// setDestination points action at the primary cluster, or splits it
+// across weighted clusters when the deployment strategy is a
+// TrafficSplitter. Zero-weight clusters are left out; a single remaining
+// cluster is routed to directly.
+func (t *CompositeTranslator) setDestination(action *routev3.RouteAction, deployment *models.APIDeployment, primary string) {
+	splitter, ok := t.strategies.Deployment.(TrafficSplitter)
+	if !ok {
+		action.ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: primary}
+		return
+	}
+
+	var weighted []*routev3.WeightedCluster_ClusterWeight
+	for _, cw := range splitter.ClusterWeights(deployment) {
+		if cw.Weight == 0 {
+			continue
+		}
+		weighted = append(weighted, &routev3.WeightedCluster_ClusterWeight{
+			Name:   cw.Name,
+			Weight: wrapperspb.UInt32(cw.Weight),
+		})
+	}
+	switch len(weighted) {
+	case 0:
+		action.ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: primary}
+	case 1:
+		action.ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: weighted[0].Name}
+	default:
+		action.ClusterSpecifier = &routev3.RouteAction_WeightedClusters{
+			WeightedClusters: &routev3.WeightedCluster{Clusters: weighted},
+		}
+	}
+}
+
 // getRouteConfigName returns the route configuration name. The naming
 // scheme `route_<listenerID>_<virtualHostName>` matches what
 // dispatch/gateway.go::buildListeners points its filter chains at, so
@@ -323,8 +662,13 @@ func (t *CompositeTranslator) getRouteConfigName() string {
 	return fmt.Sprintf("route_%s_%s", t.translationContext.Listener.ID, t.translationContext.VirtualHost.Name)
 }
 
-// generateVirtualHostName creates a virtual host name
+// generateVirtualHostName creates a virtual host name. A shared virtual
+// host (use_existing) is named the same by every deployment joining it,
+// which is what the gateway merges their routes by.
 func (t *CompositeTranslator) generateVirtualHostName(deployment *models.APIDeployment) string {
+	if shared := deployment.Metadata.Gateway.VirtualHost.UseExisting; shared != "" {
+		return shared
+	}
 	if deployment.Metadata.Gateway.VirtualHost.Name != "" {
 		return deployment.Metadata.Gateway.VirtualHost.Name
 	}
@@ -0,0 +1,186 @@
+package translator
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	aggregatev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/aggregate/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestTranslateGroupsRoutesByTag(t *testing.T) {
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}, Tags: []string{"pets"}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/admin/users"}, Tags: []string{"admin", "pets"}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/health"}},
+		},
+	}
+
+	t.Run("single virtual host by default", func(t *testing.T) {
+		xds, err := translate(t, makeDeployment("rest"), irAPI)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		if n := len(xds.Routes[0].VirtualHosts); n != 1 {
+			t.Fatalf("got %d virtual hosts, want 1", n)
+		}
+	})
+
+	t.Run("group by tag", func(t *testing.T) {
+		dep := makeDeployment("rest")
+		dep.Metadata.Gateway.VirtualHost.GroupByTag = true
+		xds, err := translate(t, dep, irAPI)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+
+		vhosts := xds.Routes[0].VirtualHosts
+		if len(vhosts) != 3 {
+			t.Fatalf("got %d virtual hosts, want 3", len(vhosts))
+		}
+		if got := routePaths(vhosts[0].Routes); !slices.Equal(got, []string{"/svc/health"}) {
+			t.Errorf("default vhost routes = %v", got)
+		}
+
+		admin := vhosts[1]
+		if admin.Name != "svc-v1-vhost-admin" {
+			t.Errorf("admin vhost name = %q", admin.Name)
+		}
+		if !slices.Equal(admin.Domains, []string{"admin.*"}) {
+			t.Errorf("admin vhost domains = %v, want [admin.*]", admin.Domains)
+		}
+		if got := routePaths(admin.Routes); !slices.Equal(got, []string{"/svc/admin/users"}) {
+			t.Errorf("admin vhost routes = %v, want only /svc/admin/users", got)
+		}
+		if got := routePaths(vhosts[2].Routes); !slices.Equal(got, []string{"/svc/pets"}) {
+			t.Errorf("pets vhost routes = %v", got)
+		}
+	})
+
+	t.Run("tls listener with exact hostname keeps one virtual host", func(t *testing.T) {
+		dep := makeDeployment("rest")
+		dep.Metadata.Gateway.VirtualHost.GroupByTag = true
+		strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(DefaultStrategyConfig(), dep)
+		if err != nil {
+			t.Fatalf("CreateStrategySet: %v", err)
+		}
+		composite, err := NewCompositeTranslator(strategies, nil, nil)
+		if err != nil {
+			t.Fatalf("NewCompositeTranslator: %v", err)
+		}
+		composite.SetTranslationContext(&TranslationContext{
+			Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+			Listener:    &models.Listener{ID: "l1", Port: 8443, TLS: &models.TLSConfig{}},
+			VirtualHost: &models.GatewayVirtualHost{ID: "api.example.com", ListenerID: "l1", Name: "api.example.com", Hostname: "api.example.com"},
+		})
+		xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+
+		vhosts := xds.Routes[0].VirtualHosts
+		if len(vhosts) != 1 {
+			t.Fatalf("got %d virtual hosts, want 1", len(vhosts))
+		}
+		if got := routePaths(vhosts[0].Routes); len(got) != 3 {
+			t.Errorf("routes = %v, want all three endpoints", got)
+		}
+	})
+}
+
+func TestTagGroupDomains(t *testing.T) {
+	got := tagGroupDomains(tagGroupName("Admin Ops"), []string{"*", "*.example.com", "api.example.com"})
+	want := []string{"admin-ops.*", "admin-ops.example.com", "admin-ops.api.example.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("domains = %v, want %v", got, want)
+	}
+}
+
+func TestTranslateDisablesTimeoutForStreams(t *testing.T) {
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/events"}, Type: ir.EndpointTypeSSE},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/feed"}, Responses: []ir.ResponseSpec{{StatusCode: 200, Streaming: true}}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}, Type: ir.EndpointTypeHTTP},
+		},
+	}
+	xds, err := translate(t, makeDeployment("rest"), irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+		action := route.GetRoute()
+		path := action.GetPrefixRewrite()
+		switch path {
+		case "/events", "/feed":
+			if action.Timeout == nil || action.Timeout.AsDuration() != 0 {
+				t.Errorf("%s: timeout = %v, want 0", path, action.Timeout)
+			}
+			if got := action.GetIdleTimeout().AsDuration(); got != StreamIdleTimeout {
+				t.Errorf("%s: idle timeout = %v, want %v", path, got, StreamIdleTimeout)
+			}
+		default:
+			// Request routes are bounded by the default (conservative)
+			// retry policy: two attempts of 5s each.
+			if got := action.GetTimeout().AsDuration(); got != 10*time.Second || action.IdleTimeout != nil {
+				t.Errorf("%s: timeout = %v, idle = %v, want 10s and no idle timeout", path, action.Timeout, action.IdleTimeout)
+			}
+		}
+	}
+}
+
+func TestTranslateFallbackUpstream(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Fallback = &types.UpstreamFallbackConfig{Host: "svc.backup", Port: 9090}
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}},
+	}
+	xds, err := translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	clusters := map[string]*clusterv3.Cluster{}
+	for _, c := range xds.Clusters {
+		clusters[c.Name] = c
+	}
+	if _, ok := clusters["svc-fallback-cluster"]; !ok {
+		t.Fatalf("clusters = %v, want svc-fallback-cluster", slices.Collect(maps.Keys(clusters)))
+	}
+	agg, ok := clusters["svc-v1-cluster-with-fallback"]
+	if !ok {
+		t.Fatalf("clusters = %v, want svc-v1-cluster-with-fallback", slices.Collect(maps.Keys(clusters)))
+	}
+	var cfg aggregatev3.ClusterConfig
+	if err := agg.GetClusterType().GetTypedConfig().UnmarshalTo(&cfg); err != nil {
+		t.Fatalf("aggregate config: %v", err)
+	}
+	if want := []string{"svc-v1-cluster", "svc-fallback-cluster"}; !slices.Equal(cfg.Clusters, want) {
+		t.Errorf("aggregate clusters = %v, want %v", cfg.Clusters, want)
+	}
+
+	action := xds.Routes[0].VirtualHosts[0].Routes[0].GetRoute()
+	if got := action.GetCluster(); got != agg.Name {
+		t.Errorf("route cluster = %q, want %q", got, agg.Name)
+	}
+	if got := action.GetRetryPolicy().GetRetryPriority().GetName(); got != PreviousPrioritiesName {
+		t.Errorf("retry priority = %q, want %q", got, PreviousPrioritiesName)
+	}
+}
+
+func TestTranslateFallbackUpstreamRequiresPort(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Fallback = &types.UpstreamFallbackConfig{Host: "svc.backup"}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Fatal("Translate succeeded, want an error for the fallback without a port")
+	}
+}
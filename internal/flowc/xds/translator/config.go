@@ -16,10 +16,11 @@ func DefaultStrategyConfig() *types.StrategyConfig {
 			Type:        "round-robin",
 			ChoiceCount: 2,
 		},
+		// Retry conditions are left to the preset, which picks them
+		// by API type.
 		Retry: &types.RetryStrategyConfig{
 			Type:          "conservative",
 			MaxRetries:    1,
-			RetryOn:       "5xx,reset",
 			PerTryTimeout: "5s",
 		},
 		RateLimit: &types.RateLimitStrategyConfig{
@@ -0,0 +1,146 @@
+package translator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	corsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// CORSFilterName is the HCM filter that answers CORS preflights and adds
+// CORS response headers. The HCM instance carries no policy of its own,
+// so it only acts on routes that supply one.
+const CORSFilterName = "envoy.filters.http.cors"
+
+// specCORSPolicies derives a CORS policy for every path that declares an
+// OPTIONS operation, keyed by path pattern, when the deployment opts in
+// with filters.cors_from_spec. The policy is read from the
+// Access-Control-* headers of the OPTIONS responses (their default or
+// example values). Allowed methods default to the other methods declared
+// on the path, allowed origins to any origin, and the preflight max age
+// to filters.cors_max_age.
+func specCORSPolicies(deployment *models.APIDeployment, irAPI *ir.API) map[string]*corsv3.CorsPolicy {
+	if deployment.Metadata.Filters == nil || !deployment.Metadata.Filters.CORSFromSpec {
+		return nil
+	}
+
+	methods := map[string][]string{}
+	var preflights []*ir.Endpoint
+	for i := range irAPI.Endpoints {
+		endpoint := &irAPI.Endpoints[i]
+		method := strings.ToUpper(endpoint.Method)
+		if method == "OPTIONS" {
+			preflights = append(preflights, endpoint)
+			continue
+		}
+		if !slices.Contains(methods[endpoint.Path.Pattern], method) {
+			methods[endpoint.Path.Pattern] = append(methods[endpoint.Path.Pattern], method)
+		}
+	}
+
+	policies := map[string]*corsv3.CorsPolicy{}
+	for _, endpoint := range preflights {
+		headers := map[string]string{}
+		for _, resp := range endpoint.Responses {
+			for _, h := range resp.Headers {
+				if v := headerValue(h); v != "" {
+					headers[strings.ToLower(h.Name)] = v
+				}
+			}
+		}
+
+		policy := &corsv3.CorsPolicy{
+			AllowMethods:  headers["access-control-allow-methods"],
+			AllowHeaders:  headers["access-control-allow-headers"],
+			ExposeHeaders: headers["access-control-expose-headers"],
+			MaxAge:        headers["access-control-max-age"],
+		}
+		if policy.MaxAge == "" && deployment.Metadata.Filters.CORSMaxAge > 0 {
+			policy.MaxAge = strconv.Itoa(deployment.Metadata.Filters.CORSMaxAge)
+		}
+		if policy.AllowMethods == "" {
+			declared := slices.Sorted(slices.Values(methods[endpoint.Path.Pattern]))
+			policy.AllowMethods = strings.Join(declared, ",")
+		}
+		if v := headers["access-control-allow-credentials"]; v != "" {
+			policy.AllowCredentials = wrapperspb.Bool(strings.EqualFold(v, "true"))
+		}
+		policy.AllowOriginStringMatch = originMatchers(headers["access-control-allow-origin"])
+		policies[endpoint.Path.Pattern] = policy
+	}
+	return policies
+}
+
+// headerValue returns the value a spec declares for a response header: its
+// default, else its example, else its only enum value.
+func headerValue(h ir.Parameter) string {
+	for _, v := range []any{h.Default, h.Example} {
+		if s, ok := v.(string); ok && s != "" {
+			return s
+		}
+	}
+	if h.Schema != nil && len(h.Schema.Enum) == 1 {
+		if s, ok := h.Schema.Enum[0].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// originMatchers turns an Access-Control-Allow-Origin value (a
+// comma-separated origin list, or "*" / empty for any origin) into
+// exact-match origin matchers.
+func originMatchers(value string) []*matcherv3.StringMatcher {
+	if value == "" || value == "*" {
+		return []*matcherv3.StringMatcher{{
+			MatchPattern: &matcherv3.StringMatcher_SafeRegex{SafeRegex: &matcherv3.RegexMatcher{Regex: ".*"}},
+		}}
+	}
+	var out []*matcherv3.StringMatcher
+	for origin := range strings.SplitSeq(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			out = append(out, &matcherv3.StringMatcher{
+				MatchPattern: &matcherv3.StringMatcher_Exact{Exact: origin},
+			})
+		}
+	}
+	return out
+}
+
+// applyCORSPolicy attaches policy to the route as its CORS filter config.
+func applyCORSPolicy(route *routev3.Route, policy *corsv3.CorsPolicy) error {
+	typed, err := anypb.New(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s config: %w", CORSFilterName, err)
+	}
+	if route.TypedPerFilterConfig == nil {
+		route.TypedPerFilterConfig = make(map[string]*anypb.Any)
+	}
+	route.TypedPerFilterConfig[CORSFilterName] = typed
+	return nil
+}
+
+// buildCORSFilter returns the HCM filter when any route in routes carries
+// a CORS policy, nil otherwise.
+func buildCORSFilter(routes []*routev3.RouteConfiguration) (*hcmv3.HttpFilter, error) {
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			for _, r := range vh.Routes {
+				if r.TypedPerFilterConfig[CORSFilterName] != nil {
+					return newHTTPFilter(CORSFilterName, &corsv3.Cors{})
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+package translator
+
+import (
+	"testing"
+
+	corsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestTranslateCORSFromSpecOptions(t *testing.T) {
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}},
+			{Method: "POST", Path: ir.PathInfo{Pattern: "/pets"}},
+			{Method: "OPTIONS", Path: ir.PathInfo{Pattern: "/pets"}, Responses: []ir.ResponseSpec{{
+				StatusCode: 204,
+				Headers: []ir.Parameter{
+					{Name: "Access-Control-Allow-Methods", Default: "GET, POST"},
+					{Name: "Access-Control-Allow-Headers", Example: "Content-Type, Authorization"},
+					{Name: "Access-Control-Allow-Origin", Schema: &ir.DataType{Enum: []any{"https://app.example.com"}}},
+				},
+			}}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/health"}},
+		},
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		xds, err := translate(t, makeDeployment("rest"), irAPI)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		if findHTTPFilter(xds.HTTPFilters, CORSFilterName) != nil {
+			t.Error("CORS filter emitted without cors_from_spec")
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		dep := makeDeployment("rest")
+		dep.Metadata.Filters = &types.HTTPFiltersConfig{CORSFromSpec: true}
+		xds, err := translate(t, dep, irAPI)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		if len(xds.HTTPFilters) == 0 || xds.HTTPFilters[0].Name != CORSFilterName {
+			t.Fatalf("HTTP filters = %v, want CORS first", xds.HTTPFilters)
+		}
+
+		for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+			path := route.GetRoute().GetPrefixRewrite()
+			typed := route.TypedPerFilterConfig[CORSFilterName]
+			if path == "/health" {
+				if typed != nil {
+					t.Errorf("/health has a CORS policy without an OPTIONS operation")
+				}
+				continue
+			}
+			if typed == nil {
+				t.Fatalf("%s %s: no CORS policy", routeMethod(route), path)
+			}
+			var policy corsv3.CorsPolicy
+			if err := typed.UnmarshalTo(&policy); err != nil {
+				t.Fatalf("unmarshal CORS policy: %v", err)
+			}
+			if policy.AllowMethods != "GET, POST" {
+				t.Errorf("allow methods = %q, want %q", policy.AllowMethods, "GET, POST")
+			}
+			if policy.AllowHeaders != "Content-Type, Authorization" {
+				t.Errorf("allow headers = %q", policy.AllowHeaders)
+			}
+			if origins := policy.AllowOriginStringMatch; len(origins) != 1 || origins[0].GetExact() != "https://app.example.com" {
+				t.Errorf("allow origins = %v, want exactly https://app.example.com", origins)
+			}
+		}
+	})
+}
+
+func TestSpecCORSPoliciesDefaults(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{CORSFromSpec: true}
+	policies := specCORSPolicies(dep, &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "PUT", Path: ir.PathInfo{Pattern: "/pets/{id}"}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets/{id}"}},
+			{Method: "OPTIONS", Path: ir.PathInfo{Pattern: "/pets/{id}"}},
+		},
+	})
+
+	policy := policies["/pets/{id}"]
+	if policy == nil {
+		t.Fatalf("no policy for /pets/{id}: %v", policies)
+	}
+	if policy.AllowMethods != "GET,PUT" {
+		t.Errorf("allow methods = %q, want the path's declared methods GET,PUT", policy.AllowMethods)
+	}
+	if origins := policy.AllowOriginStringMatch; len(origins) != 1 || origins[0].GetSafeRegex().GetRegex() != ".*" {
+		t.Errorf("allow origins = %v, want any origin", origins)
+	}
+}
+
+func TestSpecCORSPoliciesMaxAge(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{CORSFromSpec: true, CORSMaxAge: 600}
+	xds, err := translate(t, dep, &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}},
+			{Method: "OPTIONS", Path: ir.PathInfo{Pattern: "/pets"}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/owners"}},
+			{Method: "OPTIONS", Path: ir.PathInfo{Pattern: "/owners"}, Responses: []ir.ResponseSpec{{
+				StatusCode: 204,
+				Headers:    []ir.Parameter{{Name: "Access-Control-Max-Age", Default: "60"}},
+			}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	// The spec's own max age wins over the configured one.
+	want := map[string]string{"/pets": "600", "/owners": "60"}
+	routes := xds.Routes[0].VirtualHosts[0].Routes
+	if len(routes) != 4 {
+		t.Fatalf("got %d routes, want 4", len(routes))
+	}
+	for _, route := range routes {
+		path := route.GetRoute().GetPrefixRewrite()
+		var policy corsv3.CorsPolicy
+		if err := route.TypedPerFilterConfig[CORSFilterName].UnmarshalTo(&policy); err != nil {
+			t.Fatalf("%s %s: CORS policy: %v", routeMethod(route), path, err)
+		}
+		if policy.MaxAge != want[path] {
+			t.Errorf("%s %s: max age = %q, want %q", routeMethod(route), path, policy.MaxAge, want[path])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package translator
+
+import (
+	"fmt"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	previouspriorities "github.com/envoyproxy/go-control-plane/envoy/extensions/retry/priority/previous_priorities/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
+)
+
+// PreviousPrioritiesName is the retry priority that sends each retry to a
+// priority (for aggregate clusters, a cluster) not yet attempted.
+const PreviousPrioritiesName = "envoy.retry_priorities.previous_priorities"
+
+// fallbackClusterName names the cluster of the deployment's fallback upstream.
+func fallbackClusterName(deployment *models.APIDeployment) string {
+	return fmt.Sprintf("%s-fallback-cluster", clusterBaseName(deployment))
+}
+
+// withFallbackName names the aggregate cluster trying primary, then the
+// fallback.
+func withFallbackName(primary string) string {
+	return primary + "-with-fallback"
+}
+
+// fallbackCluster builds the cluster of the deployment's fallback
+// upstream, or returns nil when it has none.
+func fallbackCluster(deployment *models.APIDeployment) (*clusterv3.Cluster, error) {
+	fallback := deployment.Metadata.Upstream.Fallback
+	if fallback == nil {
+		return nil, nil
+	}
+	if fallback.Host == "" {
+		return nil, fmt.Errorf("upstream fallback: host is required")
+	}
+	if fallback.Port == 0 {
+		return nil, fmt.Errorf("upstream fallback: port is required")
+	}
+	if tls := fallback.TLS; tls != nil && (tls.CertPath == "") != (tls.KeyPath == "") {
+		return nil, fmt.Errorf("upstream fallback tls: cert_path and key_path must be set together")
+	}
+	scheme := fallback.Scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	endpoints := []cluster.Endpoint{{Host: fallback.Host, Port: fallback.Port}}
+	return cluster.CreateClusterWithTLS(fallbackClusterName(deployment), fallback.Host, endpoints, scheme, upstreamTLS(fallback.TLS)), nil
+}
+
+// applyFallback puts the fallback cluster behind every upstream cluster
+// of the deployment: each gets an aggregate cluster trying it first and
+// fallback second, and routes to it are pointed at the aggregate. Their
+// retry policies (a single retry when the retry strategy sets none) use
+// the previous_priorities retry priority, so a failed attempt on the
+// primary is retried on the fallback even while the primary still looks
+// healthy. Clusters without endpoints of their own (dynamic forward
+// proxy) are left alone. It returns the clusters to publish.
+func applyFallback(clusters []*clusterv3.Cluster, fallback *clusterv3.Cluster, routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) ([]*clusterv3.Cluster, error) {
+	aggregates := map[string]string{}
+	out := make([]*clusterv3.Cluster, 0, 2*len(clusters)+1)
+	out = append(out, clusters...)
+	for _, c := range clusters {
+		if c.LoadAssignment == nil {
+			continue
+		}
+		name := withFallbackName(c.Name)
+		agg, err := cluster.CreateAggregateCluster(name, []string{c.Name, fallback.Name})
+		if err != nil {
+			return nil, fmt.Errorf("aggregate cluster %s: %w", name, err)
+		}
+		aggregates[c.Name] = name
+		out = append(out, agg)
+	}
+	out = append(out, fallback)
+
+	retryPriority, err := anypb.New(&previouspriorities.PreviousPrioritiesConfig{UpdateFrequency: 1})
+	if err != nil {
+		return nil, err
+	}
+	for _, rc := range routes {
+		for _, vhost := range rc.VirtualHosts {
+			for _, route := range vhost.Routes {
+				action := route.GetRoute()
+				if action == nil || !redirectToAggregates(action, aggregates) {
+					continue
+				}
+				if action.RetryPolicy == nil {
+					action.RetryPolicy = &routev3.RetryPolicy{
+						RetryOn:    retryConditions(deployment, RESTRetryOn, GRPCRetryOn),
+						NumRetries: wrapperspb.UInt32(1),
+					}
+				}
+				action.RetryPolicy.RetryPriority = &routev3.RetryPolicy_RetryPriority{
+					Name:       PreviousPrioritiesName,
+					ConfigType: &routev3.RetryPolicy_RetryPriority_TypedConfig{TypedConfig: retryPriority},
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// redirectToAggregates points the clusters action routes to at their
+// aggregates and reports whether any was.
+func redirectToAggregates(action *routev3.RouteAction, aggregates map[string]string) bool {
+	redirected := false
+	switch spec := action.ClusterSpecifier.(type) {
+	case *routev3.RouteAction_Cluster:
+		if agg, ok := aggregates[spec.Cluster]; ok {
+			spec.Cluster = agg
+			redirected = true
+		}
+	case *routev3.RouteAction_WeightedClusters:
+		for _, cw := range spec.WeightedClusters.GetClusters() {
+			if agg, ok := aggregates[cw.Name]; ok {
+				cw.Name = agg
+				redirected = true
+			}
+		}
+	}
+	return redirected
+}
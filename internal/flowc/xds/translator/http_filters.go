@@ -0,0 +1,459 @@
+package translator
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	brotlidecompressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/brotli/decompressor/v3"
+	gzipdecompressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/compression/gzip/decompressor/v3"
+	admissioncontrolv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/admission_control/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cache/v3"
+	decompressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/decompressor/v3"
+	grpcjsontranscoderv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_json_transcoder/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	simplehttpcachev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/cache/simple_http_cache/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+// HTTP filter names emitted on the listener's HTTP connection manager.
+const (
+	GRPCJSONTranscoderFilterName = "envoy.filters.http.grpc_json_transcoder"
+	AdmissionControlFilterName   = "envoy.filters.http.admission_control"
+	CacheFilterName              = "envoy.filters.http.cache"
+	// DecompressorFilterName prefixes the per-algorithm decompressor
+	// filters, e.g. "envoy.filters.http.decompressor.gzip".
+	DecompressorFilterName = "envoy.filters.http.decompressor"
+)
+
+// BuildHTTPFilters returns the HTTP connection manager filters a deployment
+// contributes to its target listener, in execution order. The router filter
+// is not included; the listener builder always appends it last.
+//
+// Filters are opt-in via deployment.Metadata.Filters. A nil config yields no
+// filters.
+func BuildHTTPFilters(deployment *models.APIDeployment) ([]*hcmv3.HttpFilter, error) {
+	cfg := deployment.Metadata.Filters
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var filters []*hcmv3.HttpFilter
+
+	// Request bodies are decompressed before any other filter inspects
+	// them.
+	if cfg.Decompressor != nil {
+		fs, err := buildDecompressorFilters(cfg.Decompressor)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, fs...)
+	}
+
+	// Buffering follows decompression, so the filters after it see the
+	// whole decompressed body.
+	if cfg.Buffer {
+		f, err := buildBufferFilter()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	// The cache runs next so hits are served without transcoding and
+	// don't count against admission control.
+	if cfg.Cache != nil {
+		f, err := buildCacheFilter(cfg.Cache)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	if cfg.GRPCJSONTranscoder != nil {
+		f, err := buildGRPCJSONTranscoderFilter(deployment, cfg.GRPCJSONTranscoder)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	// Admission control runs after transcoding so it observes the
+	// upstream (gRPC or HTTP) outcome of each request.
+	if cfg.AdmissionControl != nil {
+		f, err := buildAdmissionControlFilter(cfg.AdmissionControl)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
+
+// buildGRPCJSONTranscoderFilter maps REST requests onto gRPC methods using
+// the supplied descriptor set. The incoming (pre-transcoding) path is used
+// for route matching so the deployment's context-path route still selects
+// the gRPC upstream cluster.
+func buildGRPCJSONTranscoderFilter(deployment *models.APIDeployment, cfg *types.GRPCJSONTranscoderConfig) (*hcmv3.HttpFilter, error) {
+	if deployment.Metadata.APIType != "grpc" {
+		return nil, fmt.Errorf("%w: grpc_json_transcoder requires api_type grpc, got %q",
+			ErrInvalidConfig, deployment.Metadata.APIType)
+	}
+	if len(cfg.ProtoDescriptorBin) == 0 {
+		return nil, fmt.Errorf("%w: grpc_json_transcoder requires proto_descriptor_bin", ErrMissingConfig)
+	}
+	if len(cfg.Services) == 0 {
+		return nil, fmt.Errorf("%w: grpc_json_transcoder requires at least one service", ErrMissingConfig)
+	}
+
+	transcoder := &grpcjsontranscoderv3.GrpcJsonTranscoder{
+		DescriptorSet: &grpcjsontranscoderv3.GrpcJsonTranscoder_ProtoDescriptorBin{
+			ProtoDescriptorBin: cfg.ProtoDescriptorBin,
+		},
+		Services:                     cfg.Services,
+		AutoMapping:                  cfg.AutoMapping,
+		IgnoreUnknownQueryParameters: cfg.IgnoreUnknownQueryParameters,
+		MatchIncomingRequestRoute:    true,
+		ConvertGrpcStatus:            true,
+	}
+
+	return newHTTPFilter(GRPCJSONTranscoderFilterName, transcoder)
+}
+
+// buildAdmissionControlFilter sheds load once the upstream success rate
+// over the sampling window drops below the threshold. Responses below 500
+// count as successes (Envoy's default HTTP criteria). Unset fields keep
+// Envoy's defaults (95% threshold, 30s window). The filter is disabled on
+// the filter chain and enabled on the deployment's routes by
+// applyRouteFilters, so other deployments on the listener are not shed for
+// this deployment's upstream failures.
+func buildAdmissionControlFilter(cfg *types.AdmissionControlConfig) (*hcmv3.HttpFilter, error) {
+	ac := &admissioncontrolv3.AdmissionControl{
+		Enabled: &corev3.RuntimeFeatureFlag{
+			DefaultValue: wrapperspb.Bool(true),
+			RuntimeKey:   "admission_control.enabled",
+		},
+		EvaluationCriteria: &admissioncontrolv3.AdmissionControl_SuccessCriteria_{
+			SuccessCriteria: &admissioncontrolv3.AdmissionControl_SuccessCriteria{
+				HttpCriteria: &admissioncontrolv3.AdmissionControl_SuccessCriteria_HttpCriteria{},
+			},
+		},
+	}
+
+	if cfg.SuccessRateThreshold != 0 {
+		if cfg.SuccessRateThreshold < 0 || cfg.SuccessRateThreshold > 100 {
+			return nil, fmt.Errorf("%w: admission_control success_rate_threshold must be in (0, 100], got %v",
+				ErrInvalidConfig, cfg.SuccessRateThreshold)
+		}
+		ac.SrThreshold = &corev3.RuntimePercent{
+			DefaultValue: &typev3.Percent{Value: cfg.SuccessRateThreshold},
+			RuntimeKey:   "admission_control.sr_threshold",
+		}
+	}
+
+	if cfg.SamplingWindow != "" {
+		window, err := time.ParseDuration(cfg.SamplingWindow)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("%w: admission_control sampling_window %q is not a positive duration",
+				ErrInvalidConfig, cfg.SamplingWindow)
+		}
+		ac.SamplingWindow = durationpb.New(window)
+	}
+
+	f, err := newHTTPFilter(AdmissionControlFilterName, ac)
+	if err != nil {
+		return nil, err
+	}
+	f.Disabled = true
+	return f, nil
+}
+
+// buildCacheFilter configures the cache filter with Envoy's in-memory
+// simple_http_cache backend. The filter has no TTL of its own; freshness
+// comes from the Cache-Control header set by ApplyCacheTTL. It is disabled
+// on the filter chain, which other deployments on the listener share, and
+// only runs on the routes ApplyCacheTTL enables it on.
+func buildCacheFilter(cfg *types.CacheConfig) (*hcmv3.HttpFilter, error) {
+	if _, err := cacheTTL(cfg); err != nil {
+		return nil, err
+	}
+	if _, err := cacheMethods(cfg); err != nil {
+		return nil, err
+	}
+
+	backend, err := anypb.New(&simplehttpcachev3.SimpleHttpCacheConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal simple_http_cache config: %w", err)
+	}
+	cache := &cachev3.CacheConfig{TypedConfig: backend}
+	for _, h := range cacheVaryHeaders(cfg) {
+		cache.AllowedVaryHeaders = append(cache.AllowedVaryHeaders, &matcherv3.StringMatcher{
+			MatchPattern: &matcherv3.StringMatcher_Exact{Exact: h},
+			IgnoreCase:   true,
+		})
+	}
+
+	f, err := newHTTPFilter(CacheFilterName, cache)
+	if err != nil {
+		return nil, err
+	}
+	f.Disabled = true
+	return f, nil
+}
+
+// buildDecompressorFilters returns one decompressor filter per configured
+// algorithm (default gzip). Each filter decompresses requests whose
+// Content-Encoding names its algorithm and strips the header; response
+// decompression is disabled so upstream responses reach the client as-is.
+// The filters are named per algorithm so a filter chain can hold several.
+// They are disabled on the filter chain and enabled on the deployment's
+// routes by applyRouteFilters.
+func buildDecompressorFilters(cfg *types.DecompressorConfig) ([]*hcmv3.HttpFilter, error) {
+	algorithms := decompressorAlgorithms(cfg)
+	filters := make([]*hcmv3.HttpFilter, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		var library proto.Message
+		switch algorithm {
+		case "gzip":
+			library = &gzipdecompressorv3.Gzip{}
+		case "brotli":
+			library = &brotlidecompressorv3.Brotli{}
+		default:
+			return nil, fmt.Errorf("%w: decompressor algorithm %q is not supported (gzip, brotli)", ErrInvalidConfig, algorithm)
+		}
+		typed, err := anypb.New(library)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s decompressor library: %w", algorithm, err)
+		}
+
+		f, err := newHTTPFilter(DecompressorFilterName+"."+algorithm, &decompressorv3.Decompressor{
+			DecompressorLibrary: &corev3.TypedExtensionConfig{
+				Name:        "envoy.compression." + algorithm + ".decompressor",
+				TypedConfig: typed,
+			},
+			ResponseDirectionConfig: &decompressorv3.Decompressor_ResponseDirectionConfig{
+				CommonConfig: &decompressorv3.Decompressor_CommonDirectionConfig{
+					Enabled: &corev3.RuntimeFeatureFlag{
+						DefaultValue: wrapperspb.Bool(false),
+						RuntimeKey:   "decompressor." + algorithm + ".response_enabled",
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		f.Disabled = true
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// decompressorAlgorithms returns the configured algorithms (default
+// gzip) lowercased and without duplicates, in configured order.
+func decompressorAlgorithms(cfg *types.DecompressorConfig) []string {
+	if len(cfg.Algorithms) == 0 {
+		return []string{"gzip"}
+	}
+	out := make([]string, 0, len(cfg.Algorithms))
+	for _, algorithm := range cfg.Algorithms {
+		algorithm = strings.ToLower(algorithm)
+		if !slices.Contains(out, algorithm) {
+			out = append(out, algorithm)
+		}
+	}
+	return out
+}
+
+// ApplyCacheTTL enables the cache filter and adds a Cache-Control max-age
+// response header on every route serving a cacheable method. The header
+// is only added when the upstream sent none, so responses the upstream
+// marks private, no-store or no-cache are still not cached or served to
+// other clients. Routes without a :method matcher (the catch-all route)
+// are treated as cacheable. With
+// vary headers configured, those routes also add them to the response's
+// Vary header: the cache keys responses on the headers Vary names, so
+// per-user or per-locale responses are not served to other requests even
+// when the upstream leaves them out. A nil cache config is a no-op.
+func ApplyCacheTTL(routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) error {
+	if deployment.Metadata.Filters == nil || deployment.Metadata.Filters.Cache == nil {
+		return nil
+	}
+	cfg := deployment.Metadata.Filters.Cache
+	ttl, err := cacheTTL(cfg)
+	if err != nil {
+		return err
+	}
+	methods, err := cacheMethods(cfg)
+	if err != nil {
+		return err
+	}
+
+	headers := []*corev3.HeaderValueOption{{
+		Header: &corev3.HeaderValue{
+			Key:   "cache-control",
+			Value: fmt.Sprintf("max-age=%d", int64(ttl/time.Second)),
+		},
+		AppendAction: corev3.HeaderValueOption_ADD_IF_ABSENT,
+	}}
+	if vary := cacheVaryHeaders(cfg); len(vary) > 0 {
+		headers = append(headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: "vary", Value: strings.Join(vary, ", ")},
+			AppendAction: corev3.HeaderValueOption_APPEND_IF_EXISTS_OR_ADD,
+		})
+	}
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			for _, route := range vh.Routes {
+				if m := routeMethod(route); m != "" && !methods[m] {
+					continue
+				}
+				if err := enableHTTPFilter(route, CacheFilterName); err != nil {
+					return err
+				}
+				route.ResponseHeadersToAdd = append(route.ResponseHeadersToAdd, headers...)
+			}
+		}
+	}
+	return nil
+}
+
+func cacheTTL(cfg *types.CacheConfig) (time.Duration, error) {
+	if cfg.TTL == "" {
+		return 0, fmt.Errorf("%w: cache requires ttl", ErrMissingConfig)
+	}
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil || ttl < time.Second {
+		return 0, fmt.Errorf("%w: cache ttl %q must be a duration of at least 1s", ErrInvalidConfig, cfg.TTL)
+	}
+	return ttl, nil
+}
+
+// cacheVaryHeaders returns the configured vary headers lowercased, sorted
+// and without duplicates.
+func cacheVaryHeaders(cfg *types.CacheConfig) []string {
+	out := make([]string, 0, len(cfg.VaryHeaders))
+	for _, h := range cfg.VaryHeaders {
+		out = append(out, strings.ToLower(h))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// CacheKey returns the key the cache configured by cfg stores a response
+// to a request for host and path under. Requests share a cached response
+// only when their keys are equal: the host and path match, and so do the
+// values of every configured vary header, compared case-sensitively and
+// in order. Other headers, and the case of header names, do not matter.
+func CacheKey(cfg *types.CacheConfig, host, path string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(host)
+	b.WriteString(path)
+	for _, name := range cacheVaryHeaders(cfg) {
+		fmt.Fprintf(&b, "\n%s: %q", name, header.Values(name))
+	}
+	return b.String()
+}
+
+// cacheMethods returns the configured cacheable methods (default GET).
+// Envoy's cache only stores GET and HEAD responses.
+func cacheMethods(cfg *types.CacheConfig) (map[string]bool, error) {
+	if len(cfg.Methods) == 0 {
+		return map[string]bool{"GET": true}, nil
+	}
+	methods := make(map[string]bool, len(cfg.Methods))
+	for _, m := range cfg.Methods {
+		m = strings.ToUpper(m)
+		if m != "GET" && m != "HEAD" {
+			return nil, fmt.Errorf("%w: cache method %q is not cacheable (GET, HEAD)", ErrInvalidConfig, m)
+		}
+		methods[m] = true
+	}
+	return methods, nil
+}
+
+// routeMethod returns the exact :method a route matches on, or "" if the
+// route matches any method.
+func routeMethod(route *routev3.Route) string {
+	for _, h := range route.GetMatch().GetHeaders() {
+		if h.GetName() == ":method" {
+			return h.GetStringMatch().GetExact()
+		}
+	}
+	return ""
+}
+
+// applyRouteFilters enables, on every route of the deployment, the
+// filters BuildHTTPFilters adds disabled to the filter chain for the
+// whole deployment. Filters scoped to some routes (cache, buffer) are
+// enabled where they are configured.
+func applyRouteFilters(routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) error {
+	cfg := deployment.Metadata.Filters
+	if cfg == nil {
+		return nil
+	}
+	var names []string
+	if cfg.Decompressor != nil {
+		for _, algorithm := range decompressorAlgorithms(cfg.Decompressor) {
+			names = append(names, DecompressorFilterName+"."+algorithm)
+		}
+	}
+	if cfg.AdmissionControl != nil {
+		names = append(names, AdmissionControlFilterName)
+	}
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			for _, route := range vh.Routes {
+				for _, name := range names {
+					if err := enableHTTPFilter(route, name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// enableHTTPFilter turns on, for route, a filter that is disabled on the
+// filter chain. Routes that already configure the filter keep their
+// config.
+func enableHTTPFilter(route *routev3.Route, name string) error {
+	if route.TypedPerFilterConfig[name] != nil {
+		return nil
+	}
+	typed, err := anypb.New(&routev3.FilterConfig{})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s route config: %w", name, err)
+	}
+	if route.TypedPerFilterConfig == nil {
+		route.TypedPerFilterConfig = make(map[string]*anypb.Any)
+	}
+	route.TypedPerFilterConfig[name] = typed
+	return nil
+}
+
+// newHTTPFilter wraps a typed filter config into an HCM HttpFilter.
+func newHTTPFilter(name string, config proto.Message) (*hcmv3.HttpFilter, error) {
+	typed, err := anypb.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s config: %w", name, err)
+	}
+	return &hcmv3.HttpFilter{
+		Name:       name,
+		ConfigType: &hcmv3.HttpFilter_TypedConfig{TypedConfig: typed},
+	}, nil
+}
@@ -0,0 +1,474 @@
+package translator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	admissioncontrolv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/admission_control/v3"
+	bufferv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cache/v3"
+	decompressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/decompressor/v3"
+	grpcjsontranscoderv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_json_transcoder/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+// makeDeployment returns a minimal deployment for an API served under
+// context "/svc" by upstream svc.local:8080.
+func makeDeployment(apiType string) *models.APIDeployment {
+	return &models.APIDeployment{
+		ID:      "dep-1",
+		Name:    "svc",
+		Version: "v1",
+		Context: "/svc",
+		Metadata: types.FlowCMetadata{
+			Name:    "svc",
+			Version: "v1",
+			Context: "/svc",
+			APIType: apiType,
+			Upstream: types.UpstreamConfig{
+				Host:   "svc.local",
+				Port:   8080,
+				Scheme: "http",
+			},
+		},
+	}
+}
+
+// translate runs the composite translator with the default strategies
+// against a single-listener, wildcard-hostname translation context.
+func translate(t *testing.T, dep *models.APIDeployment, irAPI *ir.API) (*XDSResources, error) {
+	t.Helper()
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(DefaultStrategyConfig(), dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	return composite.Translate(context.Background(), dep, irAPI, "node-1")
+}
+
+func findHTTPFilter(filters []*hcmv3.HttpFilter, name string) *hcmv3.HttpFilter {
+	for _, f := range filters {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestTranslateGRPCJSONTranscoder(t *testing.T) {
+	dep := makeDeployment("grpc")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		GRPCJSONTranscoder: &types.GRPCJSONTranscoderConfig{
+			ProtoDescriptorBin: []byte("descriptor-set"),
+			Services:           []string{"helloworld.Greeter"},
+		},
+	}
+
+	xds, err := translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	f := findHTTPFilter(xds.HTTPFilters, GRPCJSONTranscoderFilterName)
+	if f == nil {
+		t.Fatalf("expected %s filter, got %v", GRPCJSONTranscoderFilterName, xds.HTTPFilters)
+	}
+	var cfg grpcjsontranscoderv3.GrpcJsonTranscoder
+	if err := f.GetTypedConfig().UnmarshalTo(&cfg); err != nil {
+		t.Fatalf("unmarshal transcoder config: %v", err)
+	}
+	if got := string(cfg.GetProtoDescriptorBin()); got != "descriptor-set" {
+		t.Errorf("descriptor = %q, want %q", got, "descriptor-set")
+	}
+	if len(cfg.Services) != 1 || cfg.Services[0] != "helloworld.Greeter" {
+		t.Errorf("services = %v, want [helloworld.Greeter]", cfg.Services)
+	}
+	if !cfg.MatchIncomingRequestRoute {
+		t.Error("expected match_incoming_request_route to be set")
+	}
+}
+
+func TestTranslateWithoutFilters(t *testing.T) {
+	xds, err := translate(t, makeDeployment("grpc"), nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if len(xds.HTTPFilters) != 0 {
+		t.Errorf("expected no HTTP filters, got %d", len(xds.HTTPFilters))
+	}
+}
+
+func TestTranslateGRPCJSONTranscoderRequiresGRPC(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		GRPCJSONTranscoder: &types.GRPCJSONTranscoderConfig{
+			ProtoDescriptorBin: []byte("descriptor-set"),
+			Services:           []string{"helloworld.Greeter"},
+		},
+	}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Error("expected error for transcoding on a rest API")
+	}
+}
+
+func TestTranslateAdmissionControl(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		AdmissionControl: &types.AdmissionControlConfig{
+			SuccessRateThreshold: 90,
+			SamplingWindow:       "1m",
+		},
+	}
+
+	xds, err := translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	f := findHTTPFilter(xds.HTTPFilters, AdmissionControlFilterName)
+	if f == nil {
+		t.Fatalf("expected %s filter, got %v", AdmissionControlFilterName, xds.HTTPFilters)
+	}
+	var cfg admissioncontrolv3.AdmissionControl
+	if err := f.GetTypedConfig().UnmarshalTo(&cfg); err != nil {
+		t.Fatalf("unmarshal admission control config: %v", err)
+	}
+	if got := cfg.GetSrThreshold().GetDefaultValue().GetValue(); got != 90 {
+		t.Errorf("sr_threshold = %v, want 90", got)
+	}
+	if got := cfg.GetSamplingWindow().AsDuration(); got != time.Minute {
+		t.Errorf("sampling_window = %v, want 1m", got)
+	}
+	if cfg.GetSuccessCriteria().GetHttpCriteria() == nil {
+		t.Error("expected HTTP success criteria")
+	}
+
+	// Other deployments on the listener must not be shed for this
+	// deployment's upstream failures.
+	if !f.GetDisabled() {
+		t.Errorf("%s enabled on the filter chain, want it enabled per route", AdmissionControlFilterName)
+	}
+	for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+		if route.TypedPerFilterConfig[AdmissionControlFilterName] == nil {
+			t.Errorf("route %v does not enable %s", route.GetMatch(), AdmissionControlFilterName)
+		}
+	}
+}
+
+func TestTranslateAdmissionControlRejectsInvalidThreshold(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		AdmissionControl: &types.AdmissionControlConfig{SuccessRateThreshold: 150},
+	}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Error("expected error for threshold above 100")
+	}
+}
+
+func TestTranslateCache(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		Cache: &types.CacheConfig{
+			TTL:         "60s",
+			VaryHeaders: []string{"Accept-Encoding"},
+		},
+	}
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/items"}},
+			{Method: "POST", Path: ir.PathInfo{Pattern: "/items"}},
+		},
+	}
+
+	xds, err := translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	f := findHTTPFilter(xds.HTTPFilters, CacheFilterName)
+	if f == nil {
+		t.Fatalf("expected %s filter, got %v", CacheFilterName, xds.HTTPFilters)
+	}
+	var cfg cachev3.CacheConfig
+	if err := f.GetTypedConfig().UnmarshalTo(&cfg); err != nil {
+		t.Fatalf("unmarshal cache config: %v", err)
+	}
+	if got := cfg.GetTypedConfig().GetTypeUrl(); got != "type.googleapis.com/envoy.extensions.http.cache.simple_http_cache.v3.SimpleHttpCacheConfig" {
+		t.Errorf("cache backend = %q, want simple_http_cache", got)
+	}
+	if len(cfg.AllowedVaryHeaders) != 1 || cfg.AllowedVaryHeaders[0].GetExact() != "accept-encoding" {
+		t.Errorf("allowed vary headers = %v, want [accept-encoding]", cfg.AllowedVaryHeaders)
+	}
+	// The filter chain is shared with other deployments, so the cache
+	// only runs on this deployment's cacheable routes.
+	if !f.GetDisabled() {
+		t.Errorf("%s enabled on the filter chain, want it enabled per route", CacheFilterName)
+	}
+
+	seen := 0
+	for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+		var cacheControl, vary string
+		for _, h := range route.ResponseHeadersToAdd {
+			switch h.GetHeader().GetKey() {
+			case "cache-control":
+				cacheControl = h.GetHeader().GetValue()
+			case "vary":
+				vary = h.GetHeader().GetValue()
+				if h.GetAppendAction() != corev3.HeaderValueOption_APPEND_IF_EXISTS_OR_ADD {
+					t.Errorf("vary header replaces the upstream's: %v", h)
+				}
+			}
+		}
+		_, cached := route.TypedPerFilterConfig[CacheFilterName]
+		switch routeMethod(route) {
+		case "GET":
+			seen++
+			if !cached {
+				t.Errorf("GET route does not enable %s", CacheFilterName)
+			}
+			if cacheControl != "max-age=60" {
+				t.Errorf("GET route cache-control = %q, want %q", cacheControl, "max-age=60")
+			}
+			if vary != "accept-encoding" {
+				t.Errorf("GET route vary = %q, want accept-encoding", vary)
+			}
+		case "POST":
+			seen++
+			if cacheControl != "" || vary != "" {
+				t.Errorf("POST route cache-control = %q, vary = %q, want neither", cacheControl, vary)
+			}
+			if cached {
+				t.Errorf("POST route enables %s", CacheFilterName)
+			}
+		}
+	}
+	if seen != 2 {
+		t.Errorf("expected GET and POST routes, saw %d", seen)
+	}
+}
+
+func TestTranslateCacheKeepsUpstreamCacheControl(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{Cache: &types.CacheConfig{TTL: "60s"}}
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/me"}}}}
+
+	xds, err := translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	var route *routev3.Route
+	for _, r := range xds.Routes[0].VirtualHosts[0].Routes {
+		if routeMethod(r) == "GET" {
+			route = r
+		}
+	}
+	if route == nil {
+		t.Fatal("no GET route generated")
+	}
+
+	for _, tt := range []struct{ upstream, want string }{
+		{"private", "private"},
+		{"no-store", "no-store"},
+		{"", "max-age=60"},
+	} {
+		h := http.Header{}
+		if tt.upstream != "" {
+			h.Set("Cache-Control", tt.upstream)
+		}
+		applyResponseHeaders(h, route.ResponseHeadersToAdd)
+		if got := h.Values("Cache-Control"); len(got) != 1 || got[0] != tt.want {
+			t.Errorf("upstream cache-control %q: response has %q, want %q", tt.upstream, got, tt.want)
+		}
+	}
+}
+
+// applyResponseHeaders adds headers to an upstream response's headers h
+// the way Envoy applies each append action.
+func applyResponseHeaders(h http.Header, headers []*corev3.HeaderValueOption) {
+	for _, o := range headers {
+		key, value := o.GetHeader().GetKey(), o.GetHeader().GetValue()
+		switch o.GetAppendAction() {
+		case corev3.HeaderValueOption_APPEND_IF_EXISTS_OR_ADD:
+			h.Add(key, value)
+		case corev3.HeaderValueOption_ADD_IF_ABSENT:
+			if len(h.Values(key)) == 0 {
+				h.Set(key, value)
+			}
+		case corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD:
+			h.Set(key, value)
+		case corev3.HeaderValueOption_OVERWRITE_IF_EXISTS:
+			if len(h.Values(key)) > 0 {
+				h.Set(key, value)
+			}
+		}
+	}
+}
+
+func TestCacheKeyVaryHeaders(t *testing.T) {
+	cfg := &types.CacheConfig{TTL: "60s", VaryHeaders: []string{"Accept-Language", "authorization", "accept-language"}}
+	key := func(headers map[string]string) string {
+		h := http.Header{}
+		for k, v := range headers {
+			h.Set(k, v)
+		}
+		return CacheKey(cfg, "shop.example.com", "/items", h)
+	}
+
+	base := key(map[string]string{"Accept-Language": "en", "Authorization": "Bearer alice"})
+	tests := []struct {
+		name    string
+		headers map[string]string
+		same    bool
+	}{
+		{"identical", map[string]string{"Accept-Language": "en", "Authorization": "Bearer alice"}, true},
+		{"unconfigured header", map[string]string{"Accept-Language": "en", "Authorization": "Bearer alice", "X-Trace": "1"}, true},
+		{"header name case", map[string]string{"accept-language": "en", "AUTHORIZATION": "Bearer alice"}, true},
+		{"other locale", map[string]string{"Accept-Language": "fr", "Authorization": "Bearer alice"}, false},
+		{"other user", map[string]string{"Accept-Language": "en", "Authorization": "Bearer bob"}, false},
+		{"missing vary header", map[string]string{"Accept-Language": "en"}, false},
+	}
+	for _, tt := range tests {
+		if got := key(tt.headers) == base; got != tt.same {
+			t.Errorf("%s: key equal to base = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+
+	if CacheKey(cfg, "shop.example.com", "/other", nil) == CacheKey(cfg, "shop.example.com", "/items", nil) {
+		t.Error("different paths share a cache key")
+	}
+	noVary := &types.CacheConfig{TTL: "60s"}
+	if CacheKey(noVary, "shop.example.com", "/items", http.Header{"Authorization": {"a"}}) != CacheKey(noVary, "shop.example.com", "/items", nil) {
+		t.Error("headers change the key without vary headers configured")
+	}
+}
+
+func TestTranslateCacheRejectsUncacheableMethod(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		Cache: &types.CacheConfig{TTL: "60s", Methods: []string{"POST"}},
+	}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Error("expected error for caching POST responses")
+	}
+}
+
+func TestTranslateDecompressor(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		Decompressor: &types.DecompressorConfig{Algorithms: []string{"gzip", "brotli"}},
+		Cache:        &types.CacheConfig{TTL: "60s"},
+	}
+
+	xds, err := translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	wantLibraries := map[string]string{
+		DecompressorFilterName + ".gzip":   "type.googleapis.com/envoy.extensions.compression.gzip.decompressor.v3.Gzip",
+		DecompressorFilterName + ".brotli": "type.googleapis.com/envoy.extensions.compression.brotli.decompressor.v3.Brotli",
+	}
+	for name, libraryType := range wantLibraries {
+		f := findHTTPFilter(xds.HTTPFilters, name)
+		if f == nil {
+			t.Fatalf("expected %s filter, got %v", name, xds.HTTPFilters)
+		}
+		var cfg decompressorv3.Decompressor
+		if err := f.GetTypedConfig().UnmarshalTo(&cfg); err != nil {
+			t.Fatalf("unmarshal %s config: %v", name, err)
+		}
+		if got := cfg.GetDecompressorLibrary().GetTypedConfig().GetTypeUrl(); got != libraryType {
+			t.Errorf("%s library = %s, want %s", name, got, libraryType)
+		}
+		if cfg.GetResponseDirectionConfig().GetCommonConfig().GetEnabled().GetDefaultValue().GetValue() {
+			t.Errorf("%s: response decompression enabled, want request only", name)
+		}
+		// Other deployments on the listener keep their bodies as sent.
+		if !f.GetDisabled() {
+			t.Errorf("%s enabled on the filter chain, want it enabled per route", name)
+		}
+		for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+			if route.TypedPerFilterConfig[name] == nil {
+				t.Errorf("route %v does not enable %s", route.GetMatch(), name)
+			}
+		}
+	}
+
+	// Decompression must precede every filter that reads the body.
+	if len(xds.HTTPFilters) != 3 || xds.HTTPFilters[2].Name != CacheFilterName {
+		t.Errorf("filter order = %v, want decompressors before %s", xds.HTTPFilters, CacheFilterName)
+	}
+}
+
+func TestTranslateDecompressorRejectsUnknownAlgorithm(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{
+		Decompressor: &types.DecompressorConfig{Algorithms: []string{"deflate"}},
+	}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Error("expected error for an unsupported decompressor algorithm")
+	}
+}
+
+func TestTranslateRequestBuffering(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{Buffer: true, BufferMaxRequestBytes: 64 << 10}
+
+	xds, err := translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	// The filter chain is shared with other deployments, so the filter
+	// is only enabled on this deployment's routes.
+	f := findHTTPFilter(xds.HTTPFilters, BufferFilterName)
+	if f == nil {
+		t.Fatalf("expected %s filter, got %v", BufferFilterName, xds.HTTPFilters)
+	}
+	if !f.GetDisabled() {
+		t.Errorf("%s enabled on the filter chain, want it enabled per route", BufferFilterName)
+	}
+	var routes int
+	for _, rc := range xds.Routes {
+		for _, vh := range rc.VirtualHosts {
+			for _, route := range vh.Routes {
+				routes++
+				var perRoute bufferv3.BufferPerRoute
+				if err := route.TypedPerFilterConfig[BufferFilterName].UnmarshalTo(&perRoute); err != nil {
+					t.Fatalf("route %v: unmarshal buffer config: %v", route.GetMatch(), err)
+				}
+				if got := perRoute.GetBuffer().GetMaxRequestBytes().GetValue(); got != 64<<10 {
+					t.Errorf("route %v buffers %d bytes, want %d", route.GetMatch(), got, 64<<10)
+				}
+			}
+		}
+	}
+	if routes == 0 {
+		t.Fatal("no routes generated")
+	}
+
+	dep.Metadata.Filters.Buffer = false
+	xds, err = translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if f := findHTTPFilter(xds.HTTPFilters, BufferFilterName); f != nil {
+		t.Errorf("unexpected %s filter on a streaming deployment", BufferFilterName)
+	}
+}
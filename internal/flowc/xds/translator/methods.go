@@ -0,0 +1,84 @@
+package translator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpMethods are the methods an environment can allow.
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+// applyAllowedMethods makes routes answer requests with a method outside
+// allowed with 405 Method Not Allowed and an Allow header. A route that
+// matches a single method is turned into the 405 when that method is not
+// allowed; any other route gets a copy ahead of it matching the same
+// requests with a disallowed method. The copy matches one more header, so
+// SortRoutesBySpecificity keeps it ahead when route configs are merged.
+// An empty allowed leaves routes alone.
+func applyAllowedMethods(routes []*routev3.RouteConfiguration, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	methods := make([]string, 0, len(allowed))
+	for _, m := range allowed {
+		m = strings.ToUpper(m)
+		if !slices.Contains(httpMethods, m) {
+			return fmt.Errorf("%w: allowed method %q is not an HTTP method", ErrInvalidConfig, m)
+		}
+		if !slices.Contains(methods, m) {
+			methods = append(methods, m)
+		}
+	}
+
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			out := make([]*routev3.Route, 0, 2*len(vh.Routes))
+			for _, route := range vh.Routes {
+				if m := routeMethod(route); m != "" {
+					if !slices.Contains(methods, m) {
+						setMethodNotAllowed(route, methods)
+					}
+					out = append(out, route)
+					continue
+				}
+				guard := &routev3.Route{Match: proto.Clone(route.GetMatch()).(*routev3.RouteMatch)}
+				if route.Name != "" {
+					guard.Name = route.Name + "-method-not-allowed"
+				}
+				guard.Match.Headers = append(guard.Match.Headers, &routev3.HeaderMatcher{
+					Name: ":method",
+					HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+						StringMatch: &matcherv3.StringMatcher{
+							MatchPattern: &matcherv3.StringMatcher_SafeRegex{
+								SafeRegex: &matcherv3.RegexMatcher{Regex: "^(" + strings.Join(methods, "|") + ")$"},
+							},
+						},
+					},
+					InvertMatch: true,
+				})
+				setMethodNotAllowed(guard, methods)
+				out = append(out, guard, route)
+			}
+			vh.Routes = out
+		}
+	}
+	return nil
+}
+
+// setMethodNotAllowed makes route answer 405 itself, listing allowed.
+func setMethodNotAllowed(route *routev3.Route, allowed []string) {
+	route.Action = &routev3.Route_DirectResponse{
+		DirectResponse: &routev3.DirectResponseAction{Status: 405},
+	}
+	route.TypedPerFilterConfig = nil
+	route.ResponseHeadersToAdd = []*corev3.HeaderValueOption{{
+		Header:       &corev3.HeaderValue{Key: "allow", Value: strings.Join(allowed, ", ")},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}}
+}
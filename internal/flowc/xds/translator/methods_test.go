@@ -0,0 +1,97 @@
+package translator
+
+import (
+	"context"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// translateReadOnly translates dep onto a listener allowing only GET and
+// HEAD.
+func translateReadOnly(t *testing.T, dep *models.APIDeployment, irAPI *ir.API) []*routev3.Route {
+	t.Helper()
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(DefaultStrategyConfig(), dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080, AllowedMethods: []string{"GET", "head"}},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	for _, rc := range xds.Routes {
+		if err := rc.ValidateAll(); err != nil {
+			t.Fatalf("invalid route config: %v", err)
+		}
+	}
+	return xds.Routes[0].VirtualHosts[0].Routes
+}
+
+func TestReadOnlyEnvironmentRejectsWrites(t *testing.T) {
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{
+		{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}},
+		{Method: "POST", Path: ir.PathInfo{Pattern: "/pets"}},
+	}}
+	routes := translateReadOnly(t, makeDeployment("rest"), irAPI)
+	if len(routes) != 2 {
+		t.Fatalf("got %d routes, want 2", len(routes))
+	}
+	for _, r := range routes {
+		switch m := routeMethod(r); m {
+		case "GET":
+			if r.GetRoute() == nil {
+				t.Errorf("GET route does not forward: %v", r.GetAction())
+			}
+		case "POST":
+			if got := r.GetDirectResponse().GetStatus(); got != 405 {
+				t.Errorf("POST route status = %d, want 405", got)
+			}
+			if h := r.GetResponseHeadersToAdd(); len(h) != 1 || h[0].GetHeader().GetValue() != "GET, HEAD" {
+				t.Errorf("POST route headers = %v, want allow: GET, HEAD", h)
+			}
+		default:
+			t.Errorf("unexpected route for method %q", m)
+		}
+	}
+}
+
+func TestReadOnlyEnvironmentGuardsCatchAllRoute(t *testing.T) {
+	routes := translateReadOnly(t, makeDeployment("rest"), nil)
+	if len(routes) != 2 {
+		t.Fatalf("got %d routes, want the guard and the catch-all", len(routes))
+	}
+	guard, catchAll := routes[0], routes[1]
+	if got := guard.GetDirectResponse().GetStatus(); got != 405 {
+		t.Errorf("guard status = %d, want 405", got)
+	}
+	headers := guard.GetMatch().GetHeaders()
+	if len(headers) != 1 || headers[0].GetName() != ":method" || !headers[0].GetInvertMatch() ||
+		headers[0].GetStringMatch().GetSafeRegex().GetRegex() != "^(GET|HEAD)$" {
+		t.Errorf("guard headers = %v, want :method not matching ^(GET|HEAD)$", headers)
+	}
+	if guard.GetMatch().GetPathSeparatedPrefix() != catchAll.GetMatch().GetPathSeparatedPrefix() {
+		t.Errorf("guard path %v differs from catch-all path %v", guard.GetMatch(), catchAll.GetMatch())
+	}
+	if catchAll.GetRoute() == nil {
+		t.Error("catch-all route no longer forwards")
+	}
+
+	// The guard stays ahead of the route it protects when route configs
+	// are merged.
+	SortRoutesBySpecificity(routes)
+	if routes[0] != guard {
+		t.Error("sorting moved the guard behind the catch-all route")
+	}
+}
@@ -0,0 +1,67 @@
+package translator
+
+import (
+	"fmt"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
+)
+
+// mirrorClusterName names the cluster of the deployment's mirror upstream.
+func mirrorClusterName(deployment *models.APIDeployment) string {
+	return fmt.Sprintf("%s-mirror-cluster", clusterBaseName(deployment))
+}
+
+// mirrorCluster builds the cluster of the deployment's mirror upstream,
+// or returns nil when it has none.
+func mirrorCluster(deployment *models.APIDeployment) (*clusterv3.Cluster, error) {
+	mirror := deployment.Metadata.Mirror
+	if mirror == nil {
+		return nil, nil
+	}
+	if mirror.Host == "" {
+		return nil, fmt.Errorf("mirror: host is required")
+	}
+	if mirror.Port == 0 {
+		return nil, fmt.Errorf("mirror: port is required")
+	}
+	if mirror.Percentage < 0 || mirror.Percentage > 100 {
+		return nil, fmt.Errorf("mirror: percentage must be between 0 and 100, got %d", mirror.Percentage)
+	}
+	scheme := mirror.Scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	endpoints := []cluster.Endpoint{{Host: mirror.Host, Port: mirror.Port}}
+	return cluster.CreateClusterWithTLS(mirrorClusterName(deployment), mirror.Host, endpoints, scheme, nil), nil
+}
+
+// applyMirror shadows percentage percent of the requests of every route
+// to cluster mirror. The policy is kept at 0% so a mirror can be ramped up
+// from nothing without its cluster coming and going.
+func applyMirror(routes []*routev3.RouteConfiguration, mirror string, percentage int) {
+	for _, rc := range routes {
+		for _, vhost := range rc.VirtualHosts {
+			for _, route := range vhost.Routes {
+				action := route.GetRoute()
+				if action == nil {
+					continue
+				}
+				action.RequestMirrorPolicies = append(action.RequestMirrorPolicies, &routev3.RouteAction_RequestMirrorPolicy{
+					Cluster: mirror,
+					RuntimeFraction: &corev3.RuntimeFractionalPercent{
+						DefaultValue: &typev3.FractionalPercent{
+							Numerator:   uint32(percentage),
+							Denominator: typev3.FractionalPercent_HUNDRED,
+						},
+					},
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestTranslateMirrorPercentage(t *testing.T) {
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}}}
+	for _, percent := range []uint32{5, 20} {
+		dep := makeDeployment("rest")
+		dep.Metadata.Mirror = &types.MirrorConfig{Host: "shadow.local", Port: 9090, Percentage: int(percent)}
+
+		xds, err := translate(t, dep, irAPI)
+		if err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+		var found bool
+		for _, c := range xds.Clusters {
+			found = found || c.Name == mirrorClusterName(dep)
+		}
+		if !found {
+			t.Errorf("mirror cluster %s not generated", mirrorClusterName(dep))
+		}
+
+		policies := xds.Routes[0].GetVirtualHosts()[0].GetRoutes()[0].GetRoute().GetRequestMirrorPolicies()
+		if len(policies) != 1 {
+			t.Fatalf("got %d mirror policies, want 1", len(policies))
+		}
+		if got := policies[0].GetCluster(); got != mirrorClusterName(dep) {
+			t.Errorf("mirror cluster = %q, want %q", got, mirrorClusterName(dep))
+		}
+		if got := policies[0].GetRuntimeFraction().GetDefaultValue().GetNumerator(); got != percent {
+			t.Errorf("mirror percentage = %d, want %d", got, percent)
+		}
+	}
+}
+
+func TestTranslateMirrorRejectsBadPercentage(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Mirror = &types.MirrorConfig{Host: "shadow.local", Port: 9090, Percentage: 120}
+	if _, err := translate(t, dep, nil); err == nil {
+		t.Error("Translate accepted a mirror percentage of 120")
+	}
+}
@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// parseOverride decodes raw protobuf-JSON into msg. Unknown fields and
+// typed configs of unregistered types are errors, so a typo fails the
+// translation instead of being dropped.
+func parseOverride(raw map[string]any, msg proto.Message) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, msg)
+}
+
+// applyEnvoyOverrides merges the deployment's extra_route_config into
+// every route and its extra_cluster_config into every cluster, with
+// proto.Merge semantics: scalar fields are replaced, repeated fields
+// appended and map entries (typed_per_filter_config) added or replaced.
+func applyEnvoyOverrides(routes []*routev3.RouteConfiguration, clusters []*clusterv3.Cluster, deployment *models.APIDeployment) error {
+	if raw := deployment.Metadata.ExtraRouteConfig; raw != nil {
+		extra := &routev3.Route{}
+		if err := parseOverride(raw, extra); err != nil {
+			return fmt.Errorf("extra_route_config: %w", err)
+		}
+		for _, rc := range routes {
+			for _, vhost := range rc.VirtualHosts {
+				for _, route := range vhost.Routes {
+					proto.Merge(route, extra)
+				}
+			}
+		}
+	}
+	if raw := deployment.Metadata.ExtraClusterConfig; raw != nil {
+		extra := &clusterv3.Cluster{}
+		if err := parseOverride(raw, extra); err != nil {
+			return fmt.Errorf("extra_cluster_config: %w", err)
+		}
+		for _, cluster := range clusters {
+			proto.Merge(cluster, extra)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package translator
+
+import (
+	"testing"
+
+	corsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+func TestTranslateEnvoyOverrides(t *testing.T) {
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}}}
+	dep := makeDeployment("rest")
+	dep.Metadata.ExtraRouteConfig = map[string]any{
+		"typedPerFilterConfig": map[string]any{
+			CORSFilterName: map[string]any{
+				"@type":                  "type.googleapis.com/envoy.extensions.filters.http.cors.v3.CorsPolicy",
+				"allowMethods":           "GET",
+				"allowOriginStringMatch": []any{map[string]any{"exact": "https://pets.example.com"}},
+			},
+		},
+	}
+	dep.Metadata.ExtraClusterConfig = map[string]any{"connectTimeout": "7s"}
+
+	xds, err := translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	route := xds.Routes[0].GetVirtualHosts()[0].GetRoutes()[0]
+	if route.GetRoute().GetCluster() == "" && route.GetRoute().GetWeightedClusters() == nil {
+		t.Errorf("generated route action lost: %v", route.GetAction())
+	}
+	anyCfg := route.GetTypedPerFilterConfig()[CORSFilterName]
+	if anyCfg == nil {
+		t.Fatalf("typed_per_filter_config = %v, want %s", route.GetTypedPerFilterConfig(), CORSFilterName)
+	}
+	policy := &corsv3.CorsPolicy{}
+	if err := anyCfg.UnmarshalTo(policy); err != nil {
+		t.Fatalf("unmarshal cors policy: %v", err)
+	}
+	if policy.GetAllowMethods() != "GET" || policy.GetAllowOriginStringMatch()[0].GetExact() != "https://pets.example.com" {
+		t.Errorf("cors policy = %v", policy)
+	}
+	for _, c := range xds.Clusters {
+		if got := c.GetConnectTimeout().AsDuration().Seconds(); got != 7 {
+			t.Errorf("cluster %s connect timeout = %vs, want 7s", c.Name, got)
+		}
+	}
+
+	// An override that does not parse fails the translation.
+	dep.Metadata.ExtraRouteConfig = map[string]any{"noSuchField": true}
+	if _, err := translate(t, dep, irAPI); err == nil {
+		t.Error("Translate accepted an unknown route field")
+	}
+}
@@ -0,0 +1,52 @@
+package translator
+
+import (
+	"maps"
+	"slices"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// routeQueryParams returns the query parameters the route of endpoint
+// matches on: the deployment's (every route's) and the endpoint's routing
+// query parameters, the endpoint's winning on a name both set. endpoint
+// may be nil for routes not generated from one. An empty value matches
+// any value of a parameter that is present.
+func routeQueryParams(deployment *models.APIDeployment, endpoint *ir.Endpoint) map[string]string {
+	params := maps.Clone(deployment.Metadata.Gateway.VirtualHost.QueryParams)
+	if endpoint == nil || endpoint.Request == nil {
+		return params
+	}
+	for _, p := range endpoint.Request.QueryParameters {
+		if !p.Routing {
+			continue
+		}
+		if params == nil {
+			params = map[string]string{}
+		}
+		params[p.Name] = p.RoutingValue
+	}
+	return params
+}
+
+// applyQueryMatchers makes match require the query parameters params, in
+// name order.
+func applyQueryMatchers(match *routev3.RouteMatch, params map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		qm := &routev3.QueryParameterMatcher{Name: name}
+		if value := params[name]; value == "" {
+			qm.QueryParameterMatchSpecifier = &routev3.QueryParameterMatcher_PresentMatch{PresentMatch: true}
+		} else {
+			qm.QueryParameterMatchSpecifier = &routev3.QueryParameterMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{
+					MatchPattern: &matcherv3.StringMatcher_Exact{Exact: value},
+				},
+			}
+		}
+		match.QueryParameters = append(match.QueryParameters, qm)
+	}
+}
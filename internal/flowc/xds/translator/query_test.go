@@ -0,0 +1,118 @@
+package translator
+
+import (
+	"context"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+const versionedSearchSpec = `openapi: 3.0.0
+info:
+  title: Search
+  version: 2.0.0
+paths:
+  /search:
+    get:
+      parameters:
+        - name: version
+          in: query
+          required: true
+          x-flowc-route-match: "2"
+          schema:
+            type: string
+        - name: q
+          in: query
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+`
+
+// queryMatch returns the values route requires of its query parameters,
+// "*" for any value.
+func queryMatch(route *routev3.Route) map[string]string {
+	got := map[string]string{}
+	for _, qm := range route.GetMatch().GetQueryParameters() {
+		if qm.GetPresentMatch() {
+			got[qm.GetName()] = "*"
+		} else {
+			got[qm.GetName()] = qm.GetStringMatch().GetExact()
+		}
+	}
+	return got
+}
+
+func TestRouteMatchesRoutingQueryParameter(t *testing.T) {
+	irAPI, err := ir.NewOpenAPIParser().Parse(context.Background(), []byte(versionedSearchSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	xds, err := translate(t, makeDeployment("rest"), irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	routes := xds.Routes[0].VirtualHosts[0].Routes
+	if len(routes) != 1 {
+		t.Fatalf("got %d routes, want 1", len(routes))
+	}
+	// Only version=2 is required; q is an ordinary parameter.
+	got := queryMatch(routes[0])
+	if len(got) != 1 || got["version"] != "2" {
+		t.Errorf("query matchers = %v, want version=2 only", got)
+	}
+	if err := xds.Routes[0].ValidateAll(); err != nil {
+		t.Errorf("invalid route config: %v", err)
+	}
+}
+
+func TestRoutesMatchDeploymentQueryParams(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Gateway.VirtualHost.QueryParams = map[string]string{"version": "2", "beta": ""}
+
+	// The catch-all route of a deployment without a spec is constrained too.
+	xds, err := translate(t, dep, nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	got := queryMatch(xds.Routes[0].VirtualHosts[0].Routes[0])
+	if len(got) != 2 || got["version"] != "2" || got["beta"] != "*" {
+		t.Errorf("query matchers = %v, want version=2 and beta present", got)
+	}
+
+	// An endpoint's own routing parameter overrides the deployment's.
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{
+		Method: "GET",
+		Path:   ir.PathInfo{Pattern: "/search"},
+		Request: &ir.RequestSpec{QueryParameters: []ir.Parameter{
+			{Name: "version", In: ir.ParameterLocationQuery, Routing: true, RoutingValue: "3"},
+		}},
+	}}}
+	xds, err = translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	got = queryMatch(xds.Routes[0].VirtualHosts[0].Routes[0])
+	if len(got) != 2 || got["version"] != "3" || got["beta"] != "*" {
+		t.Errorf("query matchers = %v, want version=3 and beta present", got)
+	}
+}
+
+func TestQueryMatchedRouteSortsFirst(t *testing.T) {
+	plain := &routev3.Route{Match: &routev3.RouteMatch{
+		PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/search"},
+	}}
+	v2 := &routev3.Route{Match: &routev3.RouteMatch{
+		PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/search"},
+	}}
+	applyQueryMatchers(v2.Match, map[string]string{"version": "2"})
+
+	routes := []*routev3.Route{plain, v2}
+	SortRoutesBySpecificity(routes)
+	if routes[0] != v2 {
+		t.Error("the unconstrained route shadows the version=2 route")
+	}
+}
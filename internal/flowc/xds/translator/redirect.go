@@ -0,0 +1,57 @@
+package translator
+
+import (
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/proto"
+)
+
+// schemeMatcher matches requests made over scheme, "http" or "https".
+func schemeMatcher(scheme string) *routev3.HeaderMatcher {
+	return &routev3.HeaderMatcher{
+		Name: ":scheme",
+		HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+			StringMatch: &matcherv3.StringMatcher{
+				MatchPattern: &matcherv3.StringMatcher_Exact{Exact: scheme},
+			},
+		},
+	}
+}
+
+// applyHTTPSRedirect makes routes answer requests made over plain HTTP
+// with a 301 redirect to https on port. Like the 405 guards of
+// applyAllowedMethods, every forwarding route gets a copy ahead of it
+// matching the same requests with :scheme http, which stays ahead when
+// route configs are merged. Requests made over https, including those
+// reaching a plaintext listener through a TLS-terminating proxy that sets
+// the scheme, still reach the route. A zero port leaves routes alone.
+func applyHTTPSRedirect(routes []*routev3.RouteConfiguration, port uint32) {
+	if port == 0 {
+		return
+	}
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			out := make([]*routev3.Route, 0, 2*len(vh.Routes))
+			for _, route := range vh.Routes {
+				if route.GetRoute() == nil {
+					out = append(out, route)
+					continue
+				}
+				redirect := &routev3.Route{Match: proto.Clone(route.GetMatch()).(*routev3.RouteMatch)}
+				if route.Name != "" {
+					redirect.Name = route.Name + "-https-redirect"
+				}
+				redirect.Match.Headers = append(redirect.Match.Headers, schemeMatcher("http"))
+				redirect.Action = &routev3.Route_Redirect{
+					Redirect: &routev3.RedirectAction{
+						SchemeRewriteSpecifier: &routev3.RedirectAction_HttpsRedirect{HttpsRedirect: true},
+						PortRedirect:           port,
+						ResponseCode:           routev3.RedirectAction_MOVED_PERMANENTLY,
+					},
+				}
+				out = append(out, redirect, route)
+			}
+			vh.Routes = out
+		}
+	}
+}
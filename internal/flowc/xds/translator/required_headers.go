@@ -0,0 +1,63 @@
+package translator
+
+import (
+	"slices"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// specRequiredHeaders returns the header parameters the endpoint declares
+// required, lowercased, sorted and without duplicates, when the deployment
+// opts in with filters.required_headers_from_spec.
+func specRequiredHeaders(deployment *models.APIDeployment, endpoint *ir.Endpoint) []string {
+	if deployment.Metadata.Filters == nil || !deployment.Metadata.Filters.RequiredHeadersFromSpec {
+		return nil
+	}
+	if endpoint.Request == nil {
+		return nil
+	}
+	var out []string
+	for _, p := range endpoint.Request.HeaderParameters {
+		if p.Required && p.Name != "" {
+			out = append(out, strings.ToLower(p.Name))
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// requiredHeaderGuards returns, for each header in headers, a copy of
+// route matching the same requests when they lack the header, which
+// answers them with 400 Bad Request instead of reaching the upstream.
+// Each guard matches one more header than route, so
+// SortRoutesBySpecificity keeps it ahead of route.
+func requiredHeaderGuards(route *routev3.Route, headers []string) []*routev3.Route {
+	guards := make([]*routev3.Route, 0, len(headers))
+	for _, h := range headers {
+		guard := &routev3.Route{Match: proto.Clone(route.GetMatch()).(*routev3.RouteMatch)}
+		if route.Name != "" {
+			guard.Name = route.Name + "-missing-" + h
+		}
+		guard.Match.Headers = append(guard.Match.Headers, &routev3.HeaderMatcher{
+			Name:                 h,
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_PresentMatch{PresentMatch: true},
+			InvertMatch:          true,
+		})
+		guard.Action = &routev3.Route_DirectResponse{
+			DirectResponse: &routev3.DirectResponseAction{
+				Status: 400,
+				Body: &corev3.DataSource{
+					Specifier: &corev3.DataSource_InlineString{InlineString: "missing required header " + h + "\n"},
+				},
+			},
+		}
+		guards = append(guards, guard)
+	}
+	return guards
+}
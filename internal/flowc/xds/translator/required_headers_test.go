@@ -0,0 +1,71 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestTranslateRequiredHeadersFromSpec(t *testing.T) {
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}, Request: &ir.RequestSpec{
+				HeaderParameters: []ir.Parameter{
+					{Name: "X-Api-Key", In: ir.ParameterLocationHeader, Required: true},
+					{Name: "X-Trace", In: ir.ParameterLocationHeader},
+				},
+			}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/health"}},
+		},
+	}
+
+	xds, err := translate(t, makeDeployment("rest"), irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if n := len(xds.Routes[0].VirtualHosts[0].Routes); n != 2 {
+		t.Fatalf("routes = %d without required_headers_from_spec, want 2", n)
+	}
+
+	dep := makeDeployment("rest")
+	dep.Metadata.Filters = &types.HTTPFiltersConfig{RequiredHeadersFromSpec: true}
+	xds, err = translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	routes := xds.Routes[0].VirtualHosts[0].Routes
+	if len(routes) != 3 {
+		t.Fatalf("routes = %d, want a guard for X-Api-Key only", len(routes))
+	}
+
+	// The guard must come ahead of the /pets route it protects, and
+	// match the same requests when they lack the header.
+	guard, pets := -1, -1
+	for i, route := range routes {
+		if route.GetDirectResponse() != nil {
+			guard = i
+		} else if route.GetRoute().GetPrefixRewrite() == "/pets" {
+			pets = i
+		}
+	}
+	if guard < 0 || pets < 0 || guard > pets {
+		t.Fatalf("guard at %d, /pets route at %d, want the guard first", guard, pets)
+	}
+	g := routes[guard]
+	if status := g.GetDirectResponse().GetStatus(); status != 400 {
+		t.Errorf("guard status = %d, want 400", status)
+	}
+	var missing bool
+	for _, h := range g.GetMatch().GetHeaders() {
+		if h.GetName() == "x-api-key" && h.GetPresentMatch() && h.GetInvertMatch() {
+			missing = true
+		}
+	}
+	if !missing {
+		t.Errorf("guard headers = %v, want a match on a missing x-api-key", g.GetMatch().GetHeaders())
+	}
+	if routeMethod(g) != "GET" {
+		t.Errorf("guard method = %q, want the route's GET", routeMethod(g))
+	}
+}
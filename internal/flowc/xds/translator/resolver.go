@@ -189,16 +189,22 @@ func (f *StrategyFactory) CreateStrategySet(config *types.StrategyConfig, deploy
 		return nil, fmt.Errorf("failed to create retry strategy: %w", err)
 	}
 
-	// Create rate limit strategy
+	// Create rate limit strategy (optional)
 	rateLimitStrategy, err := f.createRateLimitStrategy(config.RateLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create rate limit strategy: %w", err)
+		if err := optionalStrategyFailed(f.options, f.logger, "rate_limit", deployment, fmt.Errorf("failed to create rate limit strategy: %w", err)); err != nil {
+			return nil, err
+		}
+		rateLimitStrategy = &NoOpRateLimitStrategy{}
 	}
 
-	// Create observability strategy
+	// Create observability strategy (optional)
 	observabilityStrategy, err := f.createObservabilityStrategy(config.Observability)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create observability strategy: %w", err)
+		if err := optionalStrategyFailed(f.options, f.logger, "observability", deployment, fmt.Errorf("failed to create observability strategy: %w", err)); err != nil {
+			return nil, err
+		}
+		observabilityStrategy = &NoOpObservabilityStrategy{}
 	}
 
 	return &StrategySet{
@@ -233,6 +239,9 @@ func (f *StrategyFactory) createDeploymentStrategy(config *types.DeploymentStrat
 		}
 		return NewBlueGreenDeploymentStrategy(config.BlueGreen, f.options, f.logger), nil
 
+	case "dynamic-forward-proxy":
+		return NewDynamicForwardProxyDeploymentStrategy(config.DynamicForwardProxy, f.options, f.logger), nil
+
 	default:
 		return nil, ErrInvalidStrategyType("deployment", config.Type)
 	}
@@ -264,6 +273,24 @@ func (f *StrategyFactory) createRouteMatchStrategy(config *types.RouteMatchStrat
 
 // createLoadBalancingStrategy creates a load balancing strategy from config
 func (f *StrategyFactory) createLoadBalancingStrategy(config *types.LoadBalancingStrategyConfig) (LoadBalancingStrategy, error) {
+	strategy, err := f.createLoadBalancingAlgorithm(config)
+	if err != nil || config == nil {
+		return strategy, err
+	}
+	if config.SlowStart != nil {
+		if strategy, err = NewSlowStartLoadBalancingStrategy(strategy, config.SlowStart); err != nil {
+			return nil, err
+		}
+	}
+	if config.HealthyPanicThreshold != nil {
+		return NewPanicThresholdLoadBalancingStrategy(strategy, *config.HealthyPanicThreshold)
+	}
+	return strategy, nil
+}
+
+// createLoadBalancingAlgorithm creates the strategy of the configured
+// load balancing algorithm
+func (f *StrategyFactory) createLoadBalancingAlgorithm(config *types.LoadBalancingStrategyConfig) (LoadBalancingStrategy, error) {
 	if config == nil {
 		config = &types.LoadBalancingStrategyConfig{Type: "round-robin"}
 	}
@@ -308,15 +335,42 @@ func (f *StrategyFactory) createRetryStrategy(config *types.RetryStrategyConfig)
 		config = &types.RetryStrategyConfig{Type: "conservative"}
 	}
 
-	switch config.Type {
-	case "none":
+	if config.Type == "none" {
 		return &NoOpRetryStrategy{}, nil
+	}
+	hosts, err := newHostSelection(config.HostPredicates, config.HostSelectionMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
 
+	switch config.Type {
 	case "conservative", "":
-		return NewConservativeRetryStrategy(), nil
+		strategy := NewConservativeRetryStrategy()
+		if config.PerTryTimeout != "" {
+			duration, err := parseDuration(config.PerTryTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid per_try_timeout: %w", err)
+			}
+			strategy.WithPerTryTimeout(duration)
+		}
+		if config.RetryOn != "" {
+			strategy.WithRetryOn(config.RetryOn)
+		}
+		return strategy.WithHostSelection(hosts), nil
 
 	case "aggressive":
-		return NewAggressiveRetryStrategy(), nil
+		strategy := NewAggressiveRetryStrategy()
+		if config.PerTryTimeout != "" {
+			duration, err := parseDuration(config.PerTryTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid per_try_timeout: %w", err)
+			}
+			strategy.WithPerTryTimeout(duration)
+		}
+		if config.RetryOn != "" {
+			strategy.WithRetryOn(config.RetryOn)
+		}
+		return strategy.WithHostSelection(hosts), nil
 
 	case "custom":
 		if config.PerTryTimeout == "" {
@@ -326,7 +380,7 @@ func (f *StrategyFactory) createRetryStrategy(config *types.RetryStrategyConfig)
 		if err != nil {
 			return nil, fmt.Errorf("invalid per_try_timeout: %w", err)
 		}
-		return NewCustomRetryStrategy(config.MaxRetries, config.RetryOn, duration), nil
+		return NewCustomRetryStrategy(config.MaxRetries, config.RetryOn, duration).WithHostSelection(hosts), nil
 
 	default:
 		return nil, ErrInvalidStrategyType("retry", config.Type)
@@ -334,8 +388,6 @@ func (f *StrategyFactory) createRetryStrategy(config *types.RetryStrategyConfig)
 }
 
 // createRateLimitStrategy creates a rate limiting strategy from config
-//
-//nolint:unparam // TODO: real implementations will surface construction errors
 func (f *StrategyFactory) createRateLimitStrategy(config *types.RateLimitStrategyConfig) (RateLimitStrategy, error) {
 	if config == nil {
 		config = &types.RateLimitStrategyConfig{Type: "none"}
@@ -345,7 +397,13 @@ func (f *StrategyFactory) createRateLimitStrategy(config *types.RateLimitStrateg
 	case "none", "":
 		return &NoOpRateLimitStrategy{}, nil
 
-	// TODO: Implement actual rate limiting strategies
+	case "global":
+		if config.RequestsPerMinute == 0 {
+			return nil, fmt.Errorf("%w: global rate limit requires requests_per_minute", ErrMissingConfig)
+		}
+		return NewGlobalRateLimitStrategy(config.RequestsPerMinute, config.BurstSize), nil
+
+	// TODO: Implement per-ip and per-user rate limiting strategies
 	default:
 		// For now, return no-op for unimplemented types
 		return &NoOpRateLimitStrategy{}, nil
@@ -365,6 +423,24 @@ func (f *StrategyFactory) createObservabilityStrategy(config *types.Observabilit
 	return &NoOpObservabilityStrategy{}, nil
 }
 
+// optionalStrategyFailed handles err, the failure of optional strategy
+// name. With strict strategies it returns err, failing the translation;
+// otherwise it logs a warning and returns nil so the caller can go on
+// without the strategy.
+func optionalStrategyFailed(options *TranslatorOptions, log *logger.EnvoyLogger, name string, deployment *models.APIDeployment, err error) error {
+	if options == nil || options.StrictStrategies {
+		return err
+	}
+	if log != nil {
+		fields := map[string]any{"strategy": name, "error": err.Error()}
+		if deployment != nil {
+			fields["deployment"] = deployment.ID
+		}
+		log.WithFields(fields).Warn("Skipping failed optional strategy")
+	}
+	return nil
+}
+
 // Helper functions
 
 func parseDuration(s string) (time.Duration, error) {
@@ -0,0 +1,54 @@
+package translator
+
+import (
+	jwtauthnv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+// securityJWTRequirement maps an endpoint's security requirements onto a
+// jwt_authn requirement whose providers are named after the security
+// schemes: the requirements become alternatives (requires_any) and the
+// schemes of each requirement must all verify (requires_all). A
+// requirement with no schemes lets requests without a token through.
+// Scopes are not checked by jwt_authn and are left out. It returns nil
+// when the endpoint has no security.
+func securityJWTRequirement(security []ir.SecurityRequirement) *jwtauthnv3.JwtRequirement {
+	alternatives := make([]*jwtauthnv3.JwtRequirement, 0, len(security))
+	for _, req := range security {
+		all := make([]*jwtauthnv3.JwtRequirement, 0, len(req.Schemes))
+		for _, scheme := range req.Schemes {
+			all = append(all, &jwtauthnv3.JwtRequirement{
+				RequiresType: &jwtauthnv3.JwtRequirement_ProviderName{ProviderName: scheme.Name},
+			})
+		}
+		switch len(all) {
+		case 0:
+			alternatives = append(alternatives, &jwtauthnv3.JwtRequirement{
+				RequiresType: &jwtauthnv3.JwtRequirement_AllowMissing{AllowMissing: &emptypb.Empty{}},
+			})
+		case 1:
+			alternatives = append(alternatives, all[0])
+		default:
+			alternatives = append(alternatives, &jwtauthnv3.JwtRequirement{
+				RequiresType: &jwtauthnv3.JwtRequirement_RequiresAll{
+					RequiresAll: &jwtauthnv3.JwtRequirementAndList{Requirements: all},
+				},
+			})
+		}
+	}
+
+	// The and/or lists take at least two requirements.
+	switch len(alternatives) {
+	case 0:
+		return nil
+	case 1:
+		return alternatives[0]
+	}
+	return &jwtauthnv3.JwtRequirement{
+		RequiresType: &jwtauthnv3.JwtRequirement_RequiresAny{
+			RequiresAny: &jwtauthnv3.JwtRequirementOrList{Requirements: alternatives},
+		},
+	}
+}
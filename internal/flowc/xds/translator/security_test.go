@@ -0,0 +1,46 @@
+package translator
+
+import (
+	"testing"
+
+	jwtauthnv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+func TestSecurityJWTRequirement(t *testing.T) {
+	provider := func(name string) *jwtauthnv3.JwtRequirement {
+		return &jwtauthnv3.JwtRequirement{RequiresType: &jwtauthnv3.JwtRequirement_ProviderName{ProviderName: name}}
+	}
+
+	// (oauth AND bearer) OR apiKey
+	security := []ir.SecurityRequirement{
+		{Schemes: []ir.SecuritySchemeRequirement{{Name: "bearer"}, {Name: "oauth", Scopes: []string{"reports:read"}}}},
+		{Schemes: []ir.SecuritySchemeRequirement{{Name: "apiKey"}}},
+	}
+	want := &jwtauthnv3.JwtRequirement{
+		RequiresType: &jwtauthnv3.JwtRequirement_RequiresAny{RequiresAny: &jwtauthnv3.JwtRequirementOrList{
+			Requirements: []*jwtauthnv3.JwtRequirement{
+				{RequiresType: &jwtauthnv3.JwtRequirement_RequiresAll{RequiresAll: &jwtauthnv3.JwtRequirementAndList{
+					Requirements: []*jwtauthnv3.JwtRequirement{provider("bearer"), provider("oauth")},
+				}}},
+				provider("apiKey"),
+			},
+		}},
+	}
+	got := securityJWTRequirement(security)
+	if !proto.Equal(got, want) {
+		t.Errorf("requirement = %v, want %v", got, want)
+	}
+	if err := got.ValidateAll(); err != nil {
+		t.Errorf("requirement is invalid: %v", err)
+	}
+
+	if got := securityJWTRequirement(security[1:]); !proto.Equal(got, provider("apiKey")) {
+		t.Errorf("single scheme requirement = %v, want provider apiKey", got)
+	}
+	if got := securityJWTRequirement(nil); got != nil {
+		t.Errorf("no security = %v, want nil", got)
+	}
+}
@@ -0,0 +1,105 @@
+package translator
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
+)
+
+// endpointServer is the upstream an endpoint's own servers point at.
+type endpointServer struct {
+	Scheme string
+	Host   string
+	Port   uint32
+
+	// BasePath is the server URL's path, prepended to the endpoint's
+	// path upstream ("" for none)
+	BasePath string
+}
+
+// resolveEndpointServer resolves the first of the endpoint's servers,
+// with URL variables set to their defaults. It returns nil when the
+// endpoint has no servers of its own or its server is relative to the
+// API's (no host), in which case the endpoint is served by the
+// deployment's upstream.
+func resolveEndpointServer(endpoint *ir.Endpoint) (*endpointServer, error) {
+	if len(endpoint.Servers) == 0 {
+		return nil, nil
+	}
+	server := endpoint.Servers[0]
+	raw := server.URL
+	for name, v := range server.Variables {
+		raw = strings.ReplaceAll(raw, "{"+name+"}", v.Default)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("endpoint %s: invalid server URL %q: %w", endpoint.ID, server.URL, err)
+	}
+	if u.Host == "" {
+		return nil, nil
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	var port uint32
+	switch scheme {
+	case "http":
+		port = 80
+	case "https":
+		port = 443
+	default:
+		return nil, fmt.Errorf("endpoint %s: server URL %q: unsupported scheme %q", endpoint.ID, server.URL, u.Scheme)
+	}
+	if p := u.Port(); p != "" {
+		n, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("endpoint %s: server URL %q: invalid port %q", endpoint.ID, server.URL, p)
+		}
+		port = uint32(n)
+	}
+	return &endpointServer{
+		Scheme:   scheme,
+		Host:     u.Hostname(),
+		Port:     port,
+		BasePath: strings.TrimSuffix(u.Path, "/"),
+	}, nil
+}
+
+// endpointServerClusterName names the cluster of an endpoint server.
+// Endpoints overriding the servers with the same one share it.
+func endpointServerClusterName(deployment *models.APIDeployment, server *endpointServer) string {
+	return fmt.Sprintf("%s-%s-server-%s-%s-%d-cluster", clusterBaseName(deployment), deployment.Version, server.Scheme, server.Host, server.Port)
+}
+
+// endpointServerClusters builds a cluster for each distinct server the
+// endpoints of irAPI override the API's servers with, in endpoint order.
+func endpointServerClusters(deployment *models.APIDeployment, irAPI *ir.API) ([]*clusterv3.Cluster, error) {
+	if irAPI == nil {
+		return nil, nil
+	}
+	var clusters []*clusterv3.Cluster
+	seen := map[string]bool{}
+	for i := range irAPI.Endpoints {
+		server, err := resolveEndpointServer(&irAPI.Endpoints[i])
+		if err != nil {
+			return nil, err
+		}
+		if server == nil {
+			continue
+		}
+		name := endpointServerClusterName(deployment, server)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		endpoints := []cluster.Endpoint{{Host: server.Host, Port: server.Port}}
+		clusters = append(clusters, cluster.CreateClusterWithTLS(name, server.Host, endpoints, server.Scheme, nil))
+	}
+	return clusters, nil
+}
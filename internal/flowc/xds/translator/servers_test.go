@@ -0,0 +1,49 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+func TestEndpointServerGetsOwnCluster(t *testing.T) {
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{
+		{ID: "list", Method: "GET", Path: ir.PathInfo{Pattern: "/orders"}},
+		{ID: "create", Method: "POST", Path: ir.PathInfo{Pattern: "/orders"},
+			Servers: []ir.Server{{URL: "https://writes.internal/v2/"}}},
+	}}
+	xds, err := translate(t, makeDeployment("rest"), irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	const serverCluster = "svc-v1-server-https-writes.internal-443-cluster"
+	var names []string
+	for _, c := range xds.Clusters {
+		if err := c.ValidateAll(); err != nil {
+			t.Fatalf("invalid cluster %s: %v", c.Name, err)
+		}
+		names = append(names, c.Name)
+	}
+	if len(names) != 2 || names[0] != "svc-v1-cluster" || names[1] != serverCluster {
+		t.Fatalf("clusters = %v, want the upstream's and %s", names, serverCluster)
+	}
+
+	for _, r := range xds.Routes[0].VirtualHosts[0].Routes {
+		action := r.GetRoute()
+		switch m := routeMethod(r); m {
+		case "GET":
+			if action.GetCluster() != "svc-v1-cluster" || action.GetPrefixRewrite() != "/orders" {
+				t.Errorf("GET route = %s rewriting to %q, want svc-v1-cluster rewriting to /orders",
+					action.GetCluster(), action.GetPrefixRewrite())
+			}
+		case "POST":
+			if action.GetCluster() != serverCluster || action.GetPrefixRewrite() != "/v2/orders" {
+				t.Errorf("POST route = %s rewriting to %q, want %s rewriting to /v2/orders",
+					action.GetCluster(), action.GetPrefixRewrite(), serverCluster)
+			}
+		default:
+			t.Errorf("unexpected route for method %q", m)
+		}
+	}
+}
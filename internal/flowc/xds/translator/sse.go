@@ -0,0 +1,67 @@
+package translator
+
+import (
+	"fmt"
+	"slices"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	bufferv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	compressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+// HTTP filters whose per-route config disables them on SSE routes.
+// The buffer filter is also enabled per route by applyRequestBuffering.
+const (
+	CompressorFilterName = "envoy.filters.http.compressor"
+	BufferFilterName     = "envoy.filters.http.buffer"
+)
+
+// EventStreamContentType is the media type of a server-sent events stream.
+const EventStreamContentType = "text/event-stream"
+
+// isSSEEndpoint reports whether the endpoint serves a server-sent events
+// stream.
+func isSSEEndpoint(endpoint *ir.Endpoint) bool {
+	if endpoint.Type == ir.EndpointTypeSSE {
+		return true
+	}
+	return slices.ContainsFunc(endpoint.Responses, func(r ir.ResponseSpec) bool {
+		return r.ContentType == EventStreamContentType
+	})
+}
+
+// applySSERouteOptions keeps events flowing to the client as they are
+// written: compression and request buffering are disabled on the route
+// (both would hold events back), and the response is labelled
+// text/event-stream if the upstream did not say so itself.
+func applySSERouteOptions(route *routev3.Route) error {
+	perRoute := map[string]proto.Message{
+		CompressorFilterName: &compressorv3.CompressorPerRoute{
+			Override: &compressorv3.CompressorPerRoute_Disabled{Disabled: true},
+		},
+		BufferFilterName: &bufferv3.BufferPerRoute{
+			Override: &bufferv3.BufferPerRoute_Disabled{Disabled: true},
+		},
+	}
+	for name, cfg := range perRoute {
+		typed, err := anypb.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s config: %w", name, err)
+		}
+		if route.TypedPerFilterConfig == nil {
+			route.TypedPerFilterConfig = make(map[string]*anypb.Any)
+		}
+		route.TypedPerFilterConfig[name] = typed
+	}
+
+	route.ResponseHeadersToAdd = append(route.ResponseHeadersToAdd, &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: "content-type", Value: EventStreamContentType},
+		AppendAction: corev3.HeaderValueOption_ADD_IF_ABSENT,
+	})
+	return nil
+}
@@ -0,0 +1,71 @@
+package translator
+
+import (
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	bufferv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	compressorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/compressor/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+)
+
+func TestTranslateSSERouteOptions(t *testing.T) {
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{
+				Method: "GET",
+				Path:   ir.PathInfo{Pattern: "/events"},
+				Type:   ir.EndpointTypeSSE,
+				Events: []ir.EventSpec{{Name: "pet-added"}, {Name: "pet-removed"}},
+			},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}, Type: ir.EndpointTypeHTTP},
+		},
+	}
+	xds, err := translate(t, makeDeployment("rest"), irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+		path := route.GetRoute().GetPrefixRewrite()
+		if path != "/events" {
+			if len(route.TypedPerFilterConfig) != 0 || len(route.ResponseHeadersToAdd) != 0 {
+				t.Errorf("%s: unexpected SSE options on a plain route", path)
+			}
+			continue
+		}
+
+		var compressor compressorv3.CompressorPerRoute
+		if err := route.TypedPerFilterConfig[CompressorFilterName].UnmarshalTo(&compressor); err != nil {
+			t.Fatalf("compressor per-route config: %v", err)
+		}
+		if !compressor.GetDisabled() {
+			t.Error("compression not disabled on the SSE route")
+		}
+		var buffer bufferv3.BufferPerRoute
+		if err := route.TypedPerFilterConfig[BufferFilterName].UnmarshalTo(&buffer); err != nil {
+			t.Fatalf("buffer per-route config: %v", err)
+		}
+		if !buffer.GetDisabled() {
+			t.Error("buffering not disabled on the SSE route")
+		}
+
+		if len(route.ResponseHeadersToAdd) != 1 {
+			t.Fatalf("response headers = %v, want content-type only", route.ResponseHeadersToAdd)
+		}
+		h := route.ResponseHeadersToAdd[0]
+		if h.GetHeader().GetKey() != "content-type" || h.GetHeader().GetValue() != EventStreamContentType ||
+			h.GetAppendAction() != corev3.HeaderValueOption_ADD_IF_ABSENT {
+			t.Errorf("response header = %v, want content-type %s if absent", h, EventStreamContentType)
+		}
+
+		action := route.GetRoute()
+		if action.Timeout == nil || action.Timeout.AsDuration() != 0 {
+			t.Errorf("timeout = %v, want 0", action.Timeout)
+		}
+		if got := action.GetIdleTimeout().AsDuration(); got != StreamIdleTimeout {
+			t.Errorf("idle timeout = %v, want %v", got, StreamIdleTimeout)
+		}
+	}
+}
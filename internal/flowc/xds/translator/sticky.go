@@ -0,0 +1,117 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// DefaultStickyCookie names the cookie pinning clients of a sticky canary
+// when canary.sticky_cookie is unset.
+const DefaultStickyCookie = "flowc-canary"
+
+// applyStickySplit pins the clients of a sticky traffic split to the
+// version they were first sent to. Every weighted route is expanded by
+// stickyRoutes; routes already sent to one cluster are left alone.
+func (t *CompositeTranslator) applyStickySplit(routes []*routev3.RouteConfiguration, deployment *models.APIDeployment) {
+	sticky, ok := t.strategies.Deployment.(StickySplitter)
+	if !ok {
+		return
+	}
+	cookie, ttl := sticky.StickyCookie()
+	if cookie == "" {
+		return
+	}
+	versioned, ok := t.strategies.Deployment.(VersionedClusters)
+	if !ok {
+		return
+	}
+	versions := versioned.VersionClusters(deployment)
+	for _, rc := range routes {
+		for _, vhost := range rc.VirtualHosts {
+			expanded := make([]*routev3.Route, 0, len(vhost.Routes))
+			for _, route := range vhost.Routes {
+				expanded = append(expanded, stickyRoutes(route, cookie, ttl, versions)...)
+			}
+			// Pinned routes carry one more header matcher, so they sort
+			// ahead of the weighted route for the same path.
+			SortRoutesBySpecificity(expanded)
+			vhost.Routes = expanded
+		}
+	}
+}
+
+// stickyRoutes returns route followed by one copy per split version that
+// matches requests carrying cookie=<version> and goes to that version's
+// cluster only. The weighted route, which serves clients without the
+// cookie, sets it to the version it picked on the response. All of them
+// hash on the cookie, so hash-based load balancers keep a client on the
+// same host too. Routes not split by weight are returned unchanged.
+func stickyRoutes(route *routev3.Route, cookie string, ttl time.Duration, versions []VersionCluster) []*routev3.Route {
+	split := route.GetRoute().GetWeightedClusters()
+	if split == nil {
+		return []*routev3.Route{route}
+	}
+	route.GetRoute().HashPolicy = append(route.GetRoute().HashPolicy, &routev3.RouteAction_HashPolicy{
+		PolicySpecifier: &routev3.RouteAction_HashPolicy_Cookie_{
+			Cookie: &routev3.RouteAction_HashPolicy_Cookie{Name: cookie},
+		},
+	})
+
+	out := []*routev3.Route{route}
+	for _, v := range versions {
+		var weighted *routev3.WeightedCluster_ClusterWeight
+		for _, cw := range split.Clusters {
+			if cw.Name == v.Name {
+				weighted = cw
+			}
+		}
+		// Versions taking no traffic are not pinned; their clients are
+		// split afresh.
+		if weighted == nil {
+			continue
+		}
+
+		pinned := proto.Clone(route).(*routev3.Route)
+		if route.Name != "" {
+			pinned.Name = route.Name + "-" + v.Version
+		}
+		pinned.GetRoute().ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: v.Name}
+		pinned.Match.Headers = append(pinned.Match.Headers, &routev3.HeaderMatcher{
+			Name: "cookie",
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{
+					MatchPattern: &matcherv3.StringMatcher_SafeRegex{
+						SafeRegex: &matcherv3.RegexMatcher{
+							Regex: `(.*;\s*)?` + regexp.QuoteMeta(cookie) + "=" + regexp.QuoteMeta(v.Version) + `\s*(;.*)?`,
+						},
+					},
+				},
+			},
+		})
+		out = append(out, pinned)
+
+		weighted.ResponseHeadersToAdd = append(weighted.ResponseHeadersToAdd, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: "set-cookie", Value: stickySetCookie(cookie, v.Version, ttl)},
+			AppendAction: corev3.HeaderValueOption_APPEND_IF_EXISTS_OR_ADD,
+		})
+	}
+	return out
+}
+
+// stickySetCookie returns the Set-Cookie value assigning version; a zero
+// ttl makes it a session cookie.
+func stickySetCookie(cookie, version string, ttl time.Duration) string {
+	v := fmt.Sprintf("%s=%s; Path=/; HttpOnly", cookie, version)
+	if ttl > 0 {
+		v += fmt.Sprintf("; Max-Age=%d", int64(ttl/time.Second))
+	}
+	return v
+}
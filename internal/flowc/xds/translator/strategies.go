@@ -2,6 +2,7 @@ package translator
 
 import (
 	"context"
+	"time"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
@@ -32,6 +33,39 @@ type DeploymentStrategy interface {
 	Validate(deployment *models.APIDeployment) error
 }
 
+// TrafficSplitter is implemented by deployment strategies that spread
+// each route's traffic across several of their clusters.
+type TrafficSplitter interface {
+	ClusterWeights(deployment *models.APIDeployment) []ClusterWeight
+}
+
+// ClusterWeight is one cluster's share of a split route.
+type ClusterWeight struct {
+	Name   string
+	Weight uint32
+}
+
+// StickySplitter is implemented by traffic splitters that can pin each
+// client to the cluster it was first sent to. StickyCookie returns the
+// cookie recording the client's version and how long it lasts (0 for the
+// browser session), or "" when clients are not pinned.
+type StickySplitter interface {
+	StickyCookie() (name string, ttl time.Duration)
+}
+
+// VersionedClusters is implemented by deployment strategies whose
+// clusters serve distinct API versions. The first entry is the default
+// version, served to requests that do not ask for one.
+type VersionedClusters interface {
+	VersionClusters(deployment *models.APIDeployment) []VersionCluster
+}
+
+// VersionCluster is the cluster serving one API version.
+type VersionCluster struct {
+	Version string
+	Name    string
+}
+
 // RouteMatchStrategy handles how routes are matched (prefix, exact, regex, etc.)
 type RouteMatchStrategy interface {
 	// CreateMatcher creates a route matcher for the given path and method
@@ -41,6 +75,13 @@ type RouteMatchStrategy interface {
 	Name() string
 }
 
+// VersionRouteMatcher is implemented by route match strategies that
+// select the API version from a request header. Each route is then
+// served per version of a VersionedClusters deployment strategy.
+type VersionRouteMatcher interface {
+	VersionHeader() string
+}
+
 // LoadBalancingStrategy handles load balancing configuration for clusters
 type LoadBalancingStrategy interface {
 	// ConfigureCluster applies load balancing settings to a cluster
@@ -61,8 +102,9 @@ type RetryStrategy interface {
 
 // RateLimitStrategy handles rate limiting configuration
 type RateLimitStrategy interface {
-	// ConfigureRateLimit applies rate limiting to listeners/routes
-	ConfigureRateLimit(listener *listenerv3.Listener, deployment *models.APIDeployment) error
+	// ConfigureRateLimit applies the API-wide rate limit to the
+	// deployment's routes in vhost
+	ConfigureRateLimit(vhost *routev3.VirtualHost, deployment *models.APIDeployment) error
 
 	// Name returns the strategy name
 	Name() string
@@ -134,7 +176,7 @@ func (s *NoOpRetryStrategy) Name() string {
 // NoOpRateLimitStrategy does nothing (no rate limiting)
 type NoOpRateLimitStrategy struct{}
 
-func (s *NoOpRateLimitStrategy) ConfigureRateLimit(listener *listenerv3.Listener, deployment *models.APIDeployment) error {
+func (s *NoOpRateLimitStrategy) ConfigureRateLimit(vhost *routev3.VirtualHost, deployment *models.APIDeployment) error {
 	return nil // No rate limiting
 }
 
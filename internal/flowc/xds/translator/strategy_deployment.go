@@ -3,6 +3,7 @@ package translator
 import (
 	"context"
 	"fmt"
+	"time"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	"github.com/flowc-labs/flowc/internal/flowc/models"
@@ -18,6 +19,102 @@ import (
 
 const defaultScheme = "http"
 
+// clusterBaseName is what the names of the deployment's clusters start
+// with: its API name behind its resource prefix.
+func clusterBaseName(deployment *models.APIDeployment) string {
+	return deployment.ResourcePrefix + deployment.Name
+}
+
+// validateUpstream checks that the upstream resolves to at least one
+// host:port. Weighted hosts without a port fall back to the upstream port.
+func validateUpstream(upstream types.UpstreamConfig) error {
+	if tls := upstream.TLS; tls != nil && (tls.CertPath == "") != (tls.KeyPath == "") {
+		return fmt.Errorf("upstream tls: cert_path and key_path must be set together")
+	}
+	if upstream.Subsets != nil {
+		if len(upstream.Hosts) == 0 {
+			return fmt.Errorf("upstream subsets require weighted hosts with metadata")
+		}
+		for i, keys := range upstream.Subsets.Selectors {
+			if len(keys) == 0 {
+				return fmt.Errorf("upstream subsets selectors[%d]: at least one key is required", i)
+			}
+		}
+	}
+	if len(upstream.Hosts) == 0 {
+		if upstream.Host == "" {
+			return fmt.Errorf("upstream host is required")
+		}
+		if upstream.Port == 0 {
+			return fmt.Errorf("upstream port is required")
+		}
+		return nil
+	}
+	for i, h := range upstream.Hosts {
+		if h.Host == "" {
+			return fmt.Errorf("upstream hosts[%d]: host is required", i)
+		}
+		if h.Port == 0 && upstream.Port == 0 {
+			return fmt.Errorf("upstream hosts[%d]: port is required", i)
+		}
+	}
+	return nil
+}
+
+// upstreamCluster builds a cluster for the upstream. Weighted hosts, when
+// present, replace host/port as the endpoints; host remains the TLS SNI.
+func upstreamCluster(name string, upstream types.UpstreamConfig) *clusterv3.Cluster {
+	scheme := upstream.Scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	tls := upstreamTLS(upstream.TLS)
+	if len(upstream.Hosts) == 0 {
+		endpoints := []cluster.Endpoint{{Host: upstream.Host, Port: upstream.Port}}
+		return withConnectionLimits(cluster.CreateClusterWithTLS(name, upstream.Host, endpoints, scheme, tls), upstream)
+	}
+
+	endpoints := make([]cluster.Endpoint, 0, len(upstream.Hosts))
+	for _, h := range upstream.Hosts {
+		port := h.Port
+		if port == 0 {
+			port = upstream.Port
+		}
+		endpoints = append(endpoints, cluster.Endpoint{Host: h.Host, Port: port, Weight: h.Weight, Metadata: h.Metadata})
+	}
+	sni := upstream.Host
+	if sni == "" {
+		sni = upstream.Hosts[0].Host
+	}
+	c := cluster.CreateClusterWithTLS(name, sni, endpoints, scheme, tls)
+	if upstream.Subsets != nil && len(upstream.Subsets.Selectors) > 0 {
+		cluster.SetSubsets(c, upstream.Subsets.Selectors)
+	}
+	return withConnectionLimits(c, upstream)
+}
+
+// withConnectionLimits applies the upstream's connection limits to c.
+func withConnectionLimits(c *clusterv3.Cluster, upstream types.UpstreamConfig) *clusterv3.Cluster {
+	if conn := upstream.Connection; conn != nil {
+		cluster.SetConnectionLimits(c, conn.MaxConnectionsPerHost, conn.MaxRequestsPerConnection)
+	}
+	return c
+}
+
+// upstreamTLS converts the upstream TLS config for the cluster builder.
+func upstreamTLS(cfg *types.UpstreamTLSConfig) *cluster.UpstreamTLS {
+	if cfg == nil {
+		return nil
+	}
+	return &cluster.UpstreamTLS{
+		CAPath:    cfg.CAPath,
+		CertPath:  cfg.CertPath,
+		KeyPath:   cfg.KeyPath,
+		SPIFFEIDs: cfg.SPIFFEIDs,
+		DNSNames:  cfg.DNSNames,
+	}
+}
+
 // BasicDeploymentStrategy implements basic 1:1 deployment
 type BasicDeploymentStrategy struct {
 	options *TranslatorOptions
@@ -48,13 +145,7 @@ func (s *BasicDeploymentStrategy) Validate(deployment *models.APIDeployment) err
 	if deployment.Version == "" {
 		return fmt.Errorf("deployment version is required")
 	}
-	if deployment.Metadata.Upstream.Host == "" {
-		return fmt.Errorf("upstream host is required")
-	}
-	if deployment.Metadata.Upstream.Port == 0 {
-		return fmt.Errorf("upstream port is required")
-	}
-	return nil
+	return validateUpstream(deployment.Metadata.Upstream)
 }
 
 func (s *BasicDeploymentStrategy) GenerateClusters(ctx context.Context, deployment *models.APIDeployment) ([]*clusterv3.Cluster, error) {
@@ -62,22 +153,23 @@ func (s *BasicDeploymentStrategy) GenerateClusters(ctx context.Context, deployme
 		return nil, err
 	}
 
-	upstream := deployment.Metadata.Upstream
-	scheme := upstream.Scheme
-	if scheme == "" {
-		scheme = defaultScheme
-	}
-
-	clusterName := s.generateClusterName(deployment.Name, deployment.Version)
+	clusterName := s.generateClusterName(clusterBaseName(deployment), deployment.Version)
 
 	return []*clusterv3.Cluster{
-		cluster.CreateClusterWithScheme(clusterName, upstream.Host, upstream.Port, scheme),
+		upstreamCluster(clusterName, deployment.Metadata.Upstream),
 	}, nil
 }
 
 func (s *BasicDeploymentStrategy) GetClusterNames(deployment *models.APIDeployment) []string {
 	return []string{
-		s.generateClusterName(deployment.Name, deployment.Version),
+		s.generateClusterName(clusterBaseName(deployment), deployment.Version),
+	}
+}
+
+// VersionClusters returns the deployment's only version.
+func (s *BasicDeploymentStrategy) VersionClusters(deployment *models.APIDeployment) []VersionCluster {
+	return []VersionCluster{
+		{Version: deployment.Version, Name: s.generateClusterName(clusterBaseName(deployment), deployment.Version)},
 	}
 }
 
@@ -128,6 +220,16 @@ func (s *CanaryDeploymentStrategy) Validate(deployment *models.APIDeployment) er
 	if s.canaryConfig.CanaryWeight < 0 || s.canaryConfig.CanaryWeight > 100 {
 		return fmt.Errorf("canary weight must be between 0 and 100")
 	}
+	if s.canaryConfig.Upstream == nil || s.canaryConfig.Upstream.Host == "" {
+		return fmt.Errorf("canary upstream host is required")
+	}
+	canary, baseline := s.canaryUpstream(deployment), deployment.Metadata.Upstream
+	if len(baseline.Hosts) == 0 && canary.Host == baseline.Host && canary.Port == baseline.Port {
+		return fmt.Errorf("canary upstream %s:%d is the baseline upstream", canary.Host, canary.Port)
+	}
+	if _, err := s.stickyTTL(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -137,34 +239,94 @@ func (s *CanaryDeploymentStrategy) GenerateClusters(ctx context.Context, deploym
 		return nil, err
 	}
 
-	upstream := deployment.Metadata.Upstream
-	scheme := upstream.Scheme
-	if scheme == "" {
-		scheme = defaultScheme
-	}
-
 	// Generate clusters for both baseline and canary
-	baselineCluster := cluster.CreateClusterWithScheme(
-		s.generateClusterName(deployment.Name, s.canaryConfig.BaselineVersion),
-		upstream.Host,
-		upstream.Port,
-		scheme,
+	baselineCluster := upstreamCluster(
+		s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.BaselineVersion),
+		deployment.Metadata.Upstream,
 	)
 
-	canaryCluster := cluster.CreateClusterWithScheme(
-		s.generateClusterName(deployment.Name, s.canaryConfig.CanaryVersion),
-		upstream.Host,
-		upstream.Port,
-		scheme,
+	canaryCluster := upstreamCluster(
+		s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.CanaryVersion),
+		s.canaryUpstream(deployment),
 	)
 
 	return []*clusterv3.Cluster{baselineCluster, canaryCluster}, nil
 }
 
+// canaryUpstream is the canary's upstream with the fields it leaves unset
+// taken from the deployment's upstream. Multi-host, subset and fallback
+// settings belong to the baseline and are not inherited.
+func (s *CanaryDeploymentStrategy) canaryUpstream(deployment *models.APIDeployment) types.UpstreamConfig {
+	up := *s.canaryConfig.Upstream
+	base := deployment.Metadata.Upstream
+	if up.Port == 0 {
+		up.Port = base.Port
+	}
+	if up.Scheme == "" {
+		up.Scheme = base.Scheme
+	}
+	if up.Timeout == "" {
+		up.Timeout = base.Timeout
+	}
+	if up.TLS == nil {
+		up.TLS = base.TLS
+	}
+	if up.Connection == nil {
+		up.Connection = base.Connection
+	}
+	up.Fallback = nil
+	return up
+}
+
+// ClusterWeights splits traffic between the baseline and canary clusters
+// by the configured canary weight.
+func (s *CanaryDeploymentStrategy) ClusterWeights(deployment *models.APIDeployment) []ClusterWeight {
+	canary := uint32(s.canaryConfig.CanaryWeight)
+	return []ClusterWeight{
+		{Name: s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.BaselineVersion), Weight: 100 - canary},
+		{Name: s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.CanaryVersion), Weight: canary},
+	}
+}
+
+// StickyCookie returns the cookie pinning clients to the version they
+// were first sent to, when the canary is sticky.
+func (s *CanaryDeploymentStrategy) StickyCookie() (string, time.Duration) {
+	if !s.canaryConfig.Sticky {
+		return "", 0
+	}
+	name := s.canaryConfig.StickyCookie
+	if name == "" {
+		name = DefaultStickyCookie
+	}
+	ttl, _ := s.stickyTTL()
+	return name, ttl
+}
+
+// stickyTTL parses canary.sticky_ttl; unset means a session cookie.
+func (s *CanaryDeploymentStrategy) stickyTTL() (time.Duration, error) {
+	if s.canaryConfig.StickyTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(s.canaryConfig.StickyTTL)
+	if err != nil || ttl < time.Second {
+		return 0, fmt.Errorf("%w: canary sticky ttl %q must be a duration of at least 1s", ErrInvalidConfig, s.canaryConfig.StickyTTL)
+	}
+	return ttl, nil
+}
+
 func (s *CanaryDeploymentStrategy) GetClusterNames(deployment *models.APIDeployment) []string {
 	return []string{
-		s.generateClusterName(deployment.Name, s.canaryConfig.BaselineVersion),
-		s.generateClusterName(deployment.Name, s.canaryConfig.CanaryVersion),
+		s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.BaselineVersion),
+		s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.CanaryVersion),
+	}
+}
+
+// VersionClusters returns the baseline version, the default, and the
+// canary version.
+func (s *CanaryDeploymentStrategy) VersionClusters(deployment *models.APIDeployment) []VersionCluster {
+	return []VersionCluster{
+		{Version: s.canaryConfig.BaselineVersion, Name: s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.BaselineVersion)},
+		{Version: s.canaryConfig.CanaryVersion, Name: s.generateClusterName(clusterBaseName(deployment), s.canaryConfig.CanaryVersion)},
 	}
 }
 
@@ -220,24 +382,16 @@ func (s *BlueGreenDeploymentStrategy) GenerateClusters(ctx context.Context, depl
 	}
 
 	upstream := deployment.Metadata.Upstream
-	scheme := upstream.Scheme
-	if scheme == "" {
-		scheme = defaultScheme
-	}
 
 	// Generate clusters for both active and standby
-	activeCluster := cluster.CreateClusterWithScheme(
-		s.generateClusterName(deployment.Name, s.blueGreenConfig.ActiveVersion, "active"),
-		upstream.Host,
-		upstream.Port,
-		scheme,
+	activeCluster := upstreamCluster(
+		s.generateClusterName(clusterBaseName(deployment), s.blueGreenConfig.ActiveVersion, "active"),
+		upstream,
 	)
 
-	standbyCluster := cluster.CreateClusterWithScheme(
-		s.generateClusterName(deployment.Name, s.blueGreenConfig.StandbyVersion, "standby"),
-		upstream.Host,
-		upstream.Port,
-		scheme,
+	standbyCluster := upstreamCluster(
+		s.generateClusterName(clusterBaseName(deployment), s.blueGreenConfig.StandbyVersion, "standby"),
+		upstream,
 	)
 
 	return []*clusterv3.Cluster{activeCluster, standbyCluster}, nil
@@ -246,8 +400,18 @@ func (s *BlueGreenDeploymentStrategy) GenerateClusters(ctx context.Context, depl
 func (s *BlueGreenDeploymentStrategy) GetClusterNames(deployment *models.APIDeployment) []string {
 	// Return active cluster first (primary)
 	return []string{
-		s.generateClusterName(deployment.Name, s.blueGreenConfig.ActiveVersion, "active"),
-		s.generateClusterName(deployment.Name, s.blueGreenConfig.StandbyVersion, "standby"),
+		s.generateClusterName(clusterBaseName(deployment), s.blueGreenConfig.ActiveVersion, "active"),
+		s.generateClusterName(clusterBaseName(deployment), s.blueGreenConfig.StandbyVersion, "standby"),
+	}
+}
+
+// VersionClusters returns the active version, the default, and the
+// standby version.
+func (s *BlueGreenDeploymentStrategy) VersionClusters(deployment *models.APIDeployment) []VersionCluster {
+	names := s.GetClusterNames(deployment)
+	return []VersionCluster{
+		{Version: s.blueGreenConfig.ActiveVersion, Name: names[0]},
+		{Version: s.blueGreenConfig.StandbyVersion, Name: names[1]},
 	}
 }
 
@@ -0,0 +1,343 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	httpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/internal/flowc/xds/resources/cluster"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestBasicDeploymentWeightedHosts(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Hosts = []types.UpstreamHost{
+		{Host: "svc-a.local", Weight: 3},
+		{Host: "svc-b.local", Port: 9090, Weight: 1},
+	}
+
+	clusters, err := NewBasicDeploymentStrategy(nil, nil).GenerateClusters(context.Background(), dep)
+	if err != nil {
+		t.Fatalf("GenerateClusters: %v", err)
+	}
+	c := clusters[0]
+	if got := c.GetType(); got != clusterv3.Cluster_STRICT_DNS {
+		t.Errorf("discovery type = %v, want STRICT_DNS", got)
+	}
+
+	eps := c.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()
+	if len(eps) != 2 {
+		t.Fatalf("got %d endpoints, want 2", len(eps))
+	}
+	want := []struct {
+		host   string
+		port   uint32
+		weight uint32
+	}{
+		{"svc-a.local", 8080, 3},
+		{"svc-b.local", 9090, 1},
+	}
+	for i, w := range want {
+		addr := eps[i].GetEndpoint().GetAddress().GetSocketAddress()
+		if addr.GetAddress() != w.host || addr.GetPortValue() != w.port {
+			t.Errorf("endpoint %d = %s:%d, want %s:%d", i, addr.GetAddress(), addr.GetPortValue(), w.host, w.port)
+		}
+		if got := eps[i].GetLoadBalancingWeight().GetValue(); got != w.weight {
+			t.Errorf("endpoint %d weight = %d, want %d", i, got, w.weight)
+		}
+	}
+}
+
+func TestBasicDeploymentSubsets(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Hosts = []types.UpstreamHost{
+		{Host: "svc-a.local", Metadata: map[string]string{"zone": "us-east-1a"}},
+		{Host: "svc-b.local", Metadata: map[string]string{"zone": "us-east-1b"}},
+	}
+	dep.Metadata.Upstream.Subsets = &types.UpstreamSubsetConfig{
+		Selectors: [][]string{{"zone"}},
+		Match:     map[string]string{"zone": "us-east-1a"},
+	}
+
+	xds, err := translate(t, dep, &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}}})
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	c := xds.Clusters[0]
+	selectors := c.GetLbSubsetConfig().GetSubsetSelectors()
+	if len(selectors) != 1 || len(selectors[0].GetKeys()) != 1 || selectors[0].GetKeys()[0] != "zone" {
+		t.Fatalf("subset selectors = %v, want [[zone]]", selectors)
+	}
+	eps := c.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()
+	for i, zone := range []string{"us-east-1a", "us-east-1b"} {
+		md := eps[i].GetMetadata().GetFilterMetadata()["envoy.lb"]
+		if got := md.GetFields()["zone"].GetStringValue(); got != zone {
+			t.Errorf("endpoint %d zone = %q, want %q", i, got, zone)
+		}
+	}
+
+	action := xds.Routes[0].GetVirtualHosts()[0].GetRoutes()[0].GetRoute()
+	match := action.GetMetadataMatch().GetFilterMetadata()["envoy.lb"]
+	if got := match.GetFields()["zone"].GetStringValue(); got != "us-east-1a" {
+		t.Errorf("route metadata match zone = %q, want us-east-1a", got)
+	}
+}
+
+func TestBasicDeploymentConnectionLimits(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Connection = &types.UpstreamConnectionConfig{
+		MaxConnectionsPerHost:    64,
+		MaxRequestsPerConnection: 100,
+	}
+
+	clusters, err := NewBasicDeploymentStrategy(nil, nil).GenerateClusters(context.Background(), dep)
+	if err != nil {
+		t.Fatalf("GenerateClusters: %v", err)
+	}
+	c := clusters[0]
+	thresholds := c.GetCircuitBreakers().GetPerHostThresholds()
+	if len(thresholds) != 1 || thresholds[0].GetMaxConnections().GetValue() != 64 {
+		t.Errorf("per-host thresholds = %v, want max_connections 64", thresholds)
+	}
+
+	var opts httpv3.HttpProtocolOptions
+	if err := c.GetTypedExtensionProtocolOptions()[cluster.HTTPProtocolOptionsName].UnmarshalTo(&opts); err != nil {
+		t.Fatalf("unmarshal HTTP protocol options: %v", err)
+	}
+	if got := opts.GetCommonHttpProtocolOptions().GetMaxRequestsPerConnection().GetValue(); got != 100 {
+		t.Errorf("max_requests_per_connection = %d, want 100", got)
+	}
+	if opts.GetExplicitHttpConfig().GetHttpProtocolOptions() == nil {
+		t.Error("upstream protocol is not explicit HTTP/1")
+	}
+}
+
+func TestBasicDeploymentUpstreamTLSValidation(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Scheme = "https"
+	dep.Metadata.Upstream.TLS = &types.UpstreamTLSConfig{
+		CAPath:    "/etc/flowc/ca/mesh.pem",
+		CertPath:  "/etc/flowc/tls/client.crt",
+		KeyPath:   "/etc/flowc/tls/client.key",
+		SPIFFEIDs: []string{"spiffe://example.org/ns/prod/sa/svc"},
+		DNSNames:  []string{"svc.local"},
+	}
+
+	clusters, err := NewBasicDeploymentStrategy(nil, nil).GenerateClusters(context.Background(), dep)
+	if err != nil {
+		t.Fatalf("GenerateClusters: %v", err)
+	}
+	var tlsCtx tlsv3.UpstreamTlsContext
+	if err := clusters[0].GetTransportSocket().GetTypedConfig().UnmarshalTo(&tlsCtx); err != nil {
+		t.Fatalf("unmarshal upstream TLS context: %v", err)
+	}
+
+	validation := tlsCtx.GetCommonTlsContext().GetValidationContext()
+	if got := validation.GetTrustedCa().GetFilename(); got != "/etc/flowc/ca/mesh.pem" {
+		t.Errorf("trusted CA = %q, want /etc/flowc/ca/mesh.pem", got)
+	}
+	sans := validation.GetMatchTypedSubjectAltNames()
+	if len(sans) != 2 {
+		t.Fatalf("got %d SAN matchers, want 2", len(sans))
+	}
+	if sans[0].GetSanType() != tlsv3.SubjectAltNameMatcher_URI ||
+		sans[0].GetMatcher().GetExact() != "spiffe://example.org/ns/prod/sa/svc" {
+		t.Errorf("SAN matcher 0 = %v, want URI spiffe://example.org/ns/prod/sa/svc", sans[0])
+	}
+	if sans[1].GetSanType() != tlsv3.SubjectAltNameMatcher_DNS || sans[1].GetMatcher().GetExact() != "svc.local" {
+		t.Errorf("SAN matcher 1 = %v, want DNS svc.local", sans[1])
+	}
+	certs := tlsCtx.GetCommonTlsContext().GetTlsCertificates()
+	if len(certs) != 1 || certs[0].GetCertificateChain().GetFilename() != "/etc/flowc/tls/client.crt" {
+		t.Errorf("client certificates = %v, want /etc/flowc/tls/client.crt", certs)
+	}
+}
+
+func TestCanaryWeightRamp(t *testing.T) {
+	for _, step := range []struct {
+		weight           int
+		baseline, canary uint32
+	}{
+		{10, 90, 10},
+		{50, 50, 50},
+	} {
+		dep := makeDeployment("rest")
+		config := DefaultStrategyConfig()
+		config.Deployment = &types.DeploymentStrategyConfig{
+			Type: "canary",
+			Canary: &types.CanaryConfig{
+				BaselineVersion: "v1",
+				CanaryVersion:   "v2",
+				CanaryWeight:    step.weight,
+				Upstream:        &types.UpstreamConfig{Host: "svc-canary.local"},
+			},
+		}
+		strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+		if err != nil {
+			t.Fatalf("CreateStrategySet: %v", err)
+		}
+		composite, err := NewCompositeTranslator(strategies, nil, nil)
+		if err != nil {
+			t.Fatalf("NewCompositeTranslator: %v", err)
+		}
+		composite.SetTranslationContext(&TranslationContext{
+			Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+			Listener:    &models.Listener{ID: "l1", Port: 8080},
+			VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+		})
+		irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}}}
+
+		xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+		if err != nil {
+			t.Fatalf("weight %d: Translate: %v", step.weight, err)
+		}
+		for _, route := range xds.Routes[0].VirtualHosts[0].Routes {
+			weighted := route.GetRoute().GetWeightedClusters().GetClusters()
+			if len(weighted) != 2 {
+				t.Fatalf("weight %d: route %s has %d weighted clusters, want 2", step.weight, route.Name, len(weighted))
+			}
+			got := map[string]uint32{}
+			for _, c := range weighted {
+				got[c.Name] = c.GetWeight().GetValue()
+			}
+			if got["svc-v1-cluster"] != step.baseline || got["svc-v2-cluster"] != step.canary {
+				t.Errorf("weight %d: route %s weights = %v, want baseline %d canary %d",
+					step.weight, route.Name, got, step.baseline, step.canary)
+			}
+		}
+	}
+}
+
+func TestCanaryClustersUseCanaryUpstream(t *testing.T) {
+	dep := makeDeployment("rest")
+	s := NewCanaryDeploymentStrategy(&types.CanaryConfig{
+		BaselineVersion: "v1",
+		CanaryVersion:   "v2",
+		CanaryWeight:    10,
+		Upstream:        &types.UpstreamConfig{Host: "svc-canary.local"},
+	}, nil, nil)
+
+	clusters, err := s.GenerateClusters(context.Background(), dep)
+	if err != nil {
+		t.Fatalf("GenerateClusters: %v", err)
+	}
+	want := map[string]string{
+		"svc-v1-cluster": "svc.local:8080",
+		"svc-v2-cluster": "svc-canary.local:8080",
+	}
+	for _, c := range clusters {
+		addr := c.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()[0].GetEndpoint().GetAddress().GetSocketAddress()
+		if got := fmt.Sprintf("%s:%d", addr.GetAddress(), addr.GetPortValue()); got != want[c.Name] {
+			t.Errorf("cluster %s endpoint = %s, want %s", c.Name, got, want[c.Name])
+		}
+	}
+
+	for name, upstream := range map[string]*types.UpstreamConfig{
+		"missing":  nil,
+		"no host":  {Port: 9090},
+		"baseline": {Host: "svc.local"},
+	} {
+		s := NewCanaryDeploymentStrategy(&types.CanaryConfig{BaselineVersion: "v1", CanaryVersion: "v2", Upstream: upstream}, nil, nil)
+		if err := s.Validate(dep); err == nil {
+			t.Errorf("%s canary upstream: Validate succeeded, want error", name)
+		}
+	}
+}
+
+func TestCanaryWeightZeroRoutesToBaseline(t *testing.T) {
+	dep := makeDeployment("rest")
+	s := NewCanaryDeploymentStrategy(&types.CanaryConfig{BaselineVersion: "v1", CanaryVersion: "v2"}, nil, nil)
+	composite := &CompositeTranslator{strategies: &StrategySet{Deployment: s}}
+
+	action := &routev3.RouteAction{}
+	composite.setDestination(action, dep, "svc-v1-cluster")
+	if got := action.GetCluster(); got != "svc-v1-cluster" {
+		t.Errorf("cluster = %q, want svc-v1-cluster", got)
+	}
+}
+
+func TestStickyCanaryPinsClients(t *testing.T) {
+	dep := makeDeployment("rest")
+	config := DefaultStrategyConfig()
+	config.Deployment = &types.DeploymentStrategyConfig{
+		Type: "canary",
+		Canary: &types.CanaryConfig{
+			BaselineVersion: "v1",
+			CanaryVersion:   "v2",
+			CanaryWeight:    20,
+			Upstream:        &types.UpstreamConfig{Host: "svc-canary.local"},
+			Sticky:          true,
+			StickyTTL:       "1h",
+		},
+	}
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets"}}}}
+
+	xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	routes := xds.Routes[0].VirtualHosts[0].Routes
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, want 2 pinned routes and the split", len(routes))
+	}
+	for _, route := range routes {
+		hash := route.GetRoute().GetHashPolicy()
+		if len(hash) != 1 || hash[0].GetCookie().GetName() != DefaultStickyCookie {
+			t.Errorf("route %s hash policy = %v, want cookie %s", route.Name, hash, DefaultStickyCookie)
+		}
+	}
+
+	pinned := map[string]string{}
+	for _, route := range routes[:2] {
+		for _, h := range route.GetMatch().GetHeaders() {
+			if h.GetName() == "cookie" {
+				pinned[route.GetRoute().GetCluster()] = h.GetStringMatch().GetSafeRegex().GetRegex()
+			}
+		}
+	}
+	for cluster, version := range map[string]string{"svc-v1-cluster": "v1", "svc-v2-cluster": "v2"} {
+		re, ok := pinned[cluster]
+		if !ok {
+			t.Errorf("no route pinned to %s", cluster)
+			continue
+		}
+		if !regexp.MustCompile("^(?:" + re + ")$").MatchString("session=abc; flowc-canary=" + version) {
+			t.Errorf("%s matcher %q does not match its cookie", cluster, re)
+		}
+	}
+
+	weighted := routes[2].GetRoute().GetWeightedClusters().GetClusters()
+	if len(weighted) != 2 {
+		t.Fatalf("split route has %d weighted clusters, want 2", len(weighted))
+	}
+	for _, cw := range weighted {
+		version := strings.TrimSuffix(strings.TrimPrefix(cw.Name, "svc-"), "-cluster")
+		want := "flowc-canary=" + version + "; Path=/; HttpOnly; Max-Age=3600"
+		headers := cw.GetResponseHeadersToAdd()
+		if len(headers) != 1 || headers[0].GetHeader().GetKey() != "set-cookie" || headers[0].GetHeader().GetValue() != want {
+			t.Errorf("%s response headers = %v, want set-cookie %q", cw.Name, headers, want)
+		}
+	}
+}
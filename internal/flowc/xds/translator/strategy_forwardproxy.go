@@ -0,0 +1,261 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	dfpclusterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/dynamic_forward_proxy/v3"
+	dfpcommonv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/dynamic_forward_proxy/v3"
+	dfpfilterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/dynamic_forward_proxy/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	httpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/logger"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+// DynamicForwardProxyFilterName is the HCM filter that resolves the
+// request's upstream host before the router hands it to the DFP cluster.
+const DynamicForwardProxyFilterName = "envoy.filters.http.dynamic_forward_proxy"
+
+// dynamicForwardProxyClusterType is the custom cluster type backing DFP.
+const dynamicForwardProxyClusterType = "envoy.clusters.dynamic_forward_proxy"
+
+// dynamicForwardProxyDNSCache names the DNS cache of every DFP cluster and
+// filter. A filter chain holds one DFP filter, the first deployment's, so
+// every DFP deployment on a listener must resolve through the same cache.
+const dynamicForwardProxyDNSCache = "flowc_dfp"
+
+// HTTPFilterContributor is implemented by deployment strategies that need
+// HCM filters and per-route configuration of their own. The composite
+// translator configures every generated route and appends the filters to
+// the deployment's HTTP filters.
+type HTTPFilterContributor interface {
+	HTTPFilters(deployment *models.APIDeployment) ([]*hcmv3.HttpFilter, error)
+	ConfigureRoute(route *routev3.Route, deployment *models.APIDeployment) error
+}
+
+// DynamicForwardProxyDeploymentStrategy proxies to the host the request
+// names instead of a fixed upstream, as long as it is one of the allowed
+// hosts. One DFP cluster resolves hosts through the DNS cache shared with
+// the DFP filter. Upstream host/port are ignored; upstream scheme selects
+// TLS.
+type DynamicForwardProxyDeploymentStrategy struct {
+	config  *types.DynamicForwardProxyConfig
+	options *TranslatorOptions
+	logger  *logger.EnvoyLogger
+}
+
+func NewDynamicForwardProxyDeploymentStrategy(config *types.DynamicForwardProxyConfig, options *TranslatorOptions, log *logger.EnvoyLogger) *DynamicForwardProxyDeploymentStrategy {
+	if config == nil {
+		config = &types.DynamicForwardProxyConfig{}
+	}
+	if options == nil {
+		options = DefaultTranslatorOptions()
+	}
+	return &DynamicForwardProxyDeploymentStrategy{
+		config:  config,
+		options: options,
+		logger:  log,
+	}
+}
+
+func (s *DynamicForwardProxyDeploymentStrategy) Name() string {
+	return "dynamic-forward-proxy"
+}
+
+func (s *DynamicForwardProxyDeploymentStrategy) Validate(deployment *models.APIDeployment) error {
+	if deployment == nil {
+		return fmt.Errorf("deployment is nil")
+	}
+	if deployment.Name == "" {
+		return fmt.Errorf("deployment name is required")
+	}
+	if deployment.Version == "" {
+		return fmt.Errorf("deployment version is required")
+	}
+	if h := s.config.HostHeader; h != "" && strings.ContainsAny(h, " :\t") {
+		return fmt.Errorf("%w: dynamic forward proxy host header %q is not a valid header name", ErrInvalidConfig, h)
+	}
+	if len(s.config.AllowedHosts) == 0 {
+		return fmt.Errorf("%w: dynamic forward proxy requires allowed_hosts", ErrMissingConfig)
+	}
+	for _, h := range s.config.AllowedHosts {
+		name := strings.TrimPrefix(h, "*.")
+		if name == "" || strings.ContainsAny(name, "*:/ \t") {
+			return fmt.Errorf("%w: dynamic forward proxy allowed host %q must be a hostname or *.<domain>", ErrInvalidConfig, h)
+		}
+	}
+	return nil
+}
+
+func (s *DynamicForwardProxyDeploymentStrategy) GenerateClusters(ctx context.Context, deployment *models.APIDeployment) ([]*clusterv3.Cluster, error) {
+	if err := s.Validate(deployment); err != nil {
+		return nil, err
+	}
+
+	typed, err := anypb.New(&dfpclusterv3.ClusterConfig{
+		ClusterImplementationSpecifier: &dfpclusterv3.ClusterConfig_DnsCacheConfig{
+			DnsCacheConfig: s.dnsCacheConfig(deployment),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dynamic forward proxy cluster config: %w", err)
+	}
+
+	c := &clusterv3.Cluster{
+		Name:           s.generateClusterName(clusterBaseName(deployment), deployment.Version),
+		ConnectTimeout: durationpb.New(5 * time.Second),
+		LbPolicy:       clusterv3.Cluster_CLUSTER_PROVIDED,
+		ClusterDiscoveryType: &clusterv3.Cluster_ClusterType{
+			ClusterType: &clusterv3.Cluster_CustomClusterType{
+				Name:        dynamicForwardProxyClusterType,
+				TypedConfig: typed,
+			},
+		},
+	}
+	if deployment.Metadata.Upstream.Scheme == "https" {
+		if err := configureDynamicTLS(c); err != nil {
+			return nil, err
+		}
+	}
+	return []*clusterv3.Cluster{c}, nil
+}
+
+func (s *DynamicForwardProxyDeploymentStrategy) GetClusterNames(deployment *models.APIDeployment) []string {
+	return []string{
+		s.generateClusterName(clusterBaseName(deployment), deployment.Version),
+	}
+}
+
+// HTTPFilters returns the DFP filter sharing the cluster's DNS cache.
+func (s *DynamicForwardProxyDeploymentStrategy) HTTPFilters(deployment *models.APIDeployment) ([]*hcmv3.HttpFilter, error) {
+	filter, err := newHTTPFilter(DynamicForwardProxyFilterName, &dfpfilterv3.FilterConfig{
+		ImplementationSpecifier: &dfpfilterv3.FilterConfig_DnsCacheConfig{
+			DnsCacheConfig: s.dnsCacheConfig(deployment),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*hcmv3.HttpFilter{filter}, nil
+}
+
+// ConfigureRoute limits the route to requests naming an allowed host, so
+// the deployment is not an open proxy, and makes it resolve its upstream
+// from the configured header, if any.
+func (s *DynamicForwardProxyDeploymentStrategy) ConfigureRoute(route *routev3.Route, deployment *models.APIDeployment) error {
+	header := s.config.HostHeader
+	if header == "" {
+		header = ":authority"
+	}
+	if route.Match != nil {
+		route.Match.Headers = append(route.Match.Headers, &routev3.HeaderMatcher{
+			Name: strings.ToLower(header),
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{
+					MatchPattern: &matcherv3.StringMatcher_SafeRegex{
+						SafeRegex: &matcherv3.RegexMatcher{Regex: allowedHostsRegex(s.config.AllowedHosts)},
+					},
+				},
+			},
+		})
+	}
+
+	if s.config.HostHeader == "" {
+		return nil
+	}
+	typed, err := anypb.New(&dfpfilterv3.PerRouteConfig{
+		HostRewriteSpecifier: &dfpfilterv3.PerRouteConfig_HostRewriteHeader{
+			HostRewriteHeader: s.config.HostHeader,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s route config: %w", DynamicForwardProxyFilterName, err)
+	}
+	if route.TypedPerFilterConfig == nil {
+		route.TypedPerFilterConfig = make(map[string]*anypb.Any)
+	}
+	route.TypedPerFilterConfig[DynamicForwardProxyFilterName] = typed
+	return nil
+}
+
+// dnsCacheConfig must be identical on the filter and the cluster, and
+// across deployments; Envoy keys DNS caches by name.
+func (s *DynamicForwardProxyDeploymentStrategy) dnsCacheConfig(deployment *models.APIDeployment) *dfpcommonv3.DnsCacheConfig {
+	return &dfpcommonv3.DnsCacheConfig{
+		Name:            dynamicForwardProxyDNSCache,
+		DnsLookupFamily: clusterv3.Cluster_V4_ONLY,
+	}
+}
+
+// allowedHostsRegex matches a host[:port] value naming one of hosts, case
+// insensitively. "*.example.com" matches any subdomain of example.com.
+func allowedHostsRegex(hosts []string) string {
+	alts := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if domain, ok := strings.CutPrefix(h, "*."); ok {
+			alts = append(alts, `[^.:/]+(\.[^.:/]+)*\.`+regexp.QuoteMeta(domain))
+			continue
+		}
+		alts = append(alts, regexp.QuoteMeta(h))
+	}
+	return "(?i)^(" + strings.Join(alts, "|") + ")(:[0-9]+)?$"
+}
+
+func (s *DynamicForwardProxyDeploymentStrategy) generateClusterName(name, version string) string {
+	return fmt.Sprintf("%s-%s-dfp-cluster", name, version)
+}
+
+// configureDynamicTLS originates TLS to each resolved host, sending it as
+// SNI and validating the certificate SAN against it.
+func configureDynamicTLS(c *clusterv3.Cluster) error {
+	tlsContext, err := anypb.New(&tlsv3.UpstreamTlsContext{
+		CommonTlsContext: &tlsv3.CommonTlsContext{
+			ValidationContextType: &tlsv3.CommonTlsContext_ValidationContext{
+				ValidationContext: &tlsv3.CertificateValidationContext{
+					TrustedCa: &corev3.DataSource{
+						Specifier: &corev3.DataSource_Filename{Filename: "/etc/ssl/certs/ca-certificates.crt"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal upstream TLS context: %w", err)
+	}
+	c.TransportSocket = &corev3.TransportSocket{
+		Name:       "envoy.transport_sockets.tls",
+		ConfigType: &corev3.TransportSocket_TypedConfig{TypedConfig: tlsContext},
+	}
+
+	protocolOptions, err := anypb.New(&httpv3.HttpProtocolOptions{
+		UpstreamHttpProtocolOptions: &corev3.UpstreamHttpProtocolOptions{
+			AutoSni:           true,
+			AutoSanValidation: true,
+		},
+		UpstreamProtocolOptions: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_{
+			ExplicitHttpConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig{
+				ProtocolConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_HttpProtocolOptions{},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal upstream HTTP protocol options: %w", err)
+	}
+	c.TypedExtensionProtocolOptions = map[string]*anypb.Any{
+		"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": protocolOptions,
+	}
+	return nil
+}
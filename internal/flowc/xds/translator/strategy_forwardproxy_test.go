@@ -0,0 +1,127 @@
+package translator
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	dfpclusterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/dynamic_forward_proxy/v3"
+	dfpfilterv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/dynamic_forward_proxy/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestTranslateDynamicForwardProxy(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream = types.UpstreamConfig{}
+
+	config := DefaultStrategyConfig()
+	config.Deployment = &types.DeploymentStrategyConfig{
+		Type: "dynamic-forward-proxy",
+		DynamicForwardProxy: &types.DynamicForwardProxyConfig{
+			AllowedHosts: []string{"api.partner.com", "*.internal.example.com"},
+			HostHeader:   "x-upstream-host",
+		},
+	}
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/anything"}}}}
+
+	xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	if len(xds.Clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(xds.Clusters))
+	}
+	custom := xds.Clusters[0].GetClusterType()
+	if custom.GetName() != dynamicForwardProxyClusterType {
+		t.Fatalf("cluster type = %q, want %q", custom.GetName(), dynamicForwardProxyClusterType)
+	}
+	var clusterCfg dfpclusterv3.ClusterConfig
+	if err := custom.GetTypedConfig().UnmarshalTo(&clusterCfg); err != nil {
+		t.Fatalf("unmarshal cluster config: %v", err)
+	}
+
+	filter := findHTTPFilter(xds.HTTPFilters, DynamicForwardProxyFilterName)
+	if filter == nil {
+		t.Fatalf("expected %s filter, got %v", DynamicForwardProxyFilterName, xds.HTTPFilters)
+	}
+	var filterCfg dfpfilterv3.FilterConfig
+	if err := filter.GetTypedConfig().UnmarshalTo(&filterCfg); err != nil {
+		t.Fatalf("unmarshal filter config: %v", err)
+	}
+	if got, want := filterCfg.GetDnsCacheConfig().GetName(), clusterCfg.GetDnsCacheConfig().GetName(); got != want {
+		t.Errorf("filter DNS cache %q != cluster DNS cache %q", got, want)
+	}
+
+	route := xds.Routes[0].VirtualHosts[0].Routes[0]
+	if got := route.GetRoute().GetCluster(); got != xds.Clusters[0].Name {
+		t.Errorf("route cluster = %q, want %q", got, xds.Clusters[0].Name)
+	}
+	var perRoute dfpfilterv3.PerRouteConfig
+	if err := route.TypedPerFilterConfig[DynamicForwardProxyFilterName].UnmarshalTo(&perRoute); err != nil {
+		t.Fatalf("unmarshal per-route config: %v", err)
+	}
+	if got := perRoute.GetHostRewriteHeader(); got != "x-upstream-host" {
+		t.Errorf("host rewrite header = %q, want x-upstream-host", got)
+	}
+
+	// Other DFP deployments on the listener share the filter, so they
+	// must share its DNS cache too.
+	if got := clusterCfg.GetDnsCacheConfig().GetName(); got != dynamicForwardProxyDNSCache {
+		t.Errorf("DNS cache = %q, want %q", got, dynamicForwardProxyDNSCache)
+	}
+
+	var allowed *regexp.Regexp
+	for _, h := range route.GetMatch().GetHeaders() {
+		if h.GetName() == "x-upstream-host" {
+			allowed = regexp.MustCompile(h.GetStringMatch().GetSafeRegex().GetRegex())
+		}
+	}
+	if allowed == nil {
+		t.Fatalf("route headers = %v, want an x-upstream-host matcher", route.GetMatch().GetHeaders())
+	}
+	for host, want := range map[string]bool{
+		"api.partner.com":             true,
+		"API.Partner.com:8443":        true,
+		"db.internal.example.com":     true,
+		"a.b.internal.example.com:80": true,
+		"internal.example.com":        false,
+		"evil.com":                    false,
+		"api.partner.com.evil.com":    false,
+		"169.254.169.254":             false,
+	} {
+		if got := allowed.MatchString(host); got != want {
+			t.Errorf("host %q allowed = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestDynamicForwardProxyRequiresAllowedHosts(t *testing.T) {
+	dep := makeDeployment("rest")
+	for _, cfg := range []*types.DynamicForwardProxyConfig{
+		nil,
+		{HostHeader: "x-upstream-host"},
+		{AllowedHosts: []string{"*"}},
+		{AllowedHosts: []string{"api.partner.com:443"}},
+	} {
+		if err := NewDynamicForwardProxyDeploymentStrategy(cfg, nil, nil).Validate(dep); err == nil {
+			t.Errorf("config %+v accepted", cfg)
+		}
+	}
+}
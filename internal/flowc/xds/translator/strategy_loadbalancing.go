@@ -1,8 +1,15 @@
 package translator
 
 import (
+	"fmt"
+
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -146,3 +153,109 @@ func (s *LocalityAwareLoadBalancingStrategy) ConfigureCluster(cluster *clusterv3
 func (s *LocalityAwareLoadBalancingStrategy) Name() string {
 	return "locality-aware"
 }
+
+// SlowStartLoadBalancingStrategy ramps traffic to newly added endpoints up
+// over a warm-up window, on top of a round-robin or least-request base
+// strategy
+type SlowStartLoadBalancingStrategy struct {
+	baseStrategy LoadBalancingStrategy
+	config       *clusterv3.Cluster_SlowStartConfig
+	aggression   float64
+}
+
+func NewSlowStartLoadBalancingStrategy(baseStrategy LoadBalancingStrategy, cfg *types.SlowStartConfig) (*SlowStartLoadBalancingStrategy, error) {
+	window, err := parseDuration(cfg.Window)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid slow_start window %q: must be a positive duration", cfg.Window)
+	}
+	if cfg.MinWeightPercent < 0 || cfg.MinWeightPercent > 100 {
+		return nil, fmt.Errorf("slow_start min_weight_percent must be between 0 and 100, got %v", cfg.MinWeightPercent)
+	}
+	if cfg.Aggression < 0 {
+		return nil, fmt.Errorf("slow_start aggression must be positive, got %v", cfg.Aggression)
+	}
+
+	config := &clusterv3.Cluster_SlowStartConfig{SlowStartWindow: durationpb.New(window)}
+	if cfg.MinWeightPercent > 0 {
+		config.MinWeightPercent = &typev3.Percent{Value: cfg.MinWeightPercent}
+	}
+	return &SlowStartLoadBalancingStrategy{
+		baseStrategy: baseStrategy,
+		config:       config,
+		aggression:   cfg.Aggression,
+	}, nil
+}
+
+func (s *SlowStartLoadBalancingStrategy) ConfigureCluster(cluster *clusterv3.Cluster, deployment *models.APIDeployment) error {
+	if err := s.baseStrategy.ConfigureCluster(cluster, deployment); err != nil {
+		return err
+	}
+
+	config := proto.Clone(s.config).(*clusterv3.Cluster_SlowStartConfig)
+	if s.aggression > 0 {
+		// Aggression is a runtime value, overridable per cluster.
+		config.Aggression = &corev3.RuntimeDouble{
+			DefaultValue: s.aggression,
+			RuntimeKey:   fmt.Sprintf("upstream.%s.slow_start.aggression", cluster.Name),
+		}
+	}
+
+	switch cluster.LbPolicy {
+	case clusterv3.Cluster_ROUND_ROBIN:
+		lb := cluster.GetRoundRobinLbConfig()
+		if lb == nil {
+			lb = &clusterv3.Cluster_RoundRobinLbConfig{}
+			cluster.LbConfig = &clusterv3.Cluster_RoundRobinLbConfig_{RoundRobinLbConfig: lb}
+		}
+		lb.SlowStartConfig = config
+	case clusterv3.Cluster_LEAST_REQUEST:
+		lb := cluster.GetLeastRequestLbConfig()
+		if lb == nil {
+			lb = &clusterv3.Cluster_LeastRequestLbConfig{}
+			cluster.LbConfig = &clusterv3.Cluster_LeastRequestLbConfig_{LeastRequestLbConfig: lb}
+		}
+		lb.SlowStartConfig = config
+	default:
+		return fmt.Errorf("slow start is not supported with the %s load balancing strategy", s.baseStrategy.Name())
+	}
+	return nil
+}
+
+func (s *SlowStartLoadBalancingStrategy) Name() string {
+	return s.baseStrategy.Name() + "+slow-start"
+}
+
+// PanicThresholdLoadBalancingStrategy sets the healthy panic threshold of
+// a base strategy's clusters: below this percentage of healthy hosts,
+// Envoy routes to all hosts regardless of health. A threshold of 0
+// disables panic routing so requests fail fast instead.
+type PanicThresholdLoadBalancingStrategy struct {
+	baseStrategy LoadBalancingStrategy
+	threshold    float64
+}
+
+func NewPanicThresholdLoadBalancingStrategy(baseStrategy LoadBalancingStrategy, threshold float64) (*PanicThresholdLoadBalancingStrategy, error) {
+	if threshold < 0 || threshold > 100 {
+		return nil, fmt.Errorf("healthy_panic_threshold must be between 0 and 100, got %v", threshold)
+	}
+	return &PanicThresholdLoadBalancingStrategy{
+		baseStrategy: baseStrategy,
+		threshold:    threshold,
+	}, nil
+}
+
+func (s *PanicThresholdLoadBalancingStrategy) ConfigureCluster(cluster *clusterv3.Cluster, deployment *models.APIDeployment) error {
+	if err := s.baseStrategy.ConfigureCluster(cluster, deployment); err != nil {
+		return err
+	}
+	// Kept alongside any locality config the base strategy set.
+	if cluster.CommonLbConfig == nil {
+		cluster.CommonLbConfig = &clusterv3.Cluster_CommonLbConfig{}
+	}
+	cluster.CommonLbConfig.HealthyPanicThreshold = &typev3.Percent{Value: s.threshold}
+	return nil
+}
+
+func (s *PanicThresholdLoadBalancingStrategy) Name() string {
+	return s.baseStrategy.Name() + "+panic-threshold"
+}
@@ -0,0 +1,131 @@
+package translator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func TestSlowStartReachesCluster(t *testing.T) {
+	tests := []struct {
+		lb   string
+		slow func(*clusterv3.Cluster) *clusterv3.Cluster_SlowStartConfig
+	}{
+		{"round-robin", func(c *clusterv3.Cluster) *clusterv3.Cluster_SlowStartConfig {
+			return c.GetRoundRobinLbConfig().GetSlowStartConfig()
+		}},
+		{"least-request", func(c *clusterv3.Cluster) *clusterv3.Cluster_SlowStartConfig {
+			return c.GetLeastRequestLbConfig().GetSlowStartConfig()
+		}},
+	}
+	for _, tt := range tests {
+		dep := makeDeployment("rest")
+		config := DefaultStrategyConfig()
+		config.LoadBalancing = &types.LoadBalancingStrategyConfig{
+			Type:      tt.lb,
+			SlowStart: &types.SlowStartConfig{Window: "45s", MinWeightPercent: 20, Aggression: 1.5},
+		}
+		strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+		if err != nil {
+			t.Fatalf("%s: CreateStrategySet: %v", tt.lb, err)
+		}
+		composite, err := NewCompositeTranslator(strategies, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: NewCompositeTranslator: %v", tt.lb, err)
+		}
+		composite.SetTranslationContext(&TranslationContext{
+			Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+			Listener:    &models.Listener{ID: "l1", Port: 8080},
+			VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+		})
+		xds, err := composite.Translate(context.Background(), dep, nil, "node-1")
+		if err != nil {
+			t.Fatalf("%s: Translate: %v", tt.lb, err)
+		}
+
+		for _, c := range xds.Clusters {
+			slow := tt.slow(c)
+			if got := slow.GetSlowStartWindow().AsDuration(); got != 45*time.Second {
+				t.Errorf("%s: cluster %s slow start window = %v, want 45s", tt.lb, c.Name, got)
+			}
+			if got := slow.GetMinWeightPercent().GetValue(); got != 20 {
+				t.Errorf("%s: min weight percent = %v, want 20", tt.lb, got)
+			}
+			if got := slow.GetAggression().GetDefaultValue(); got != 1.5 {
+				t.Errorf("%s: aggression = %v, want 1.5", tt.lb, got)
+			}
+			if err := c.ValidateAll(); err != nil {
+				t.Errorf("%s: cluster %s is invalid: %v", tt.lb, c.Name, err)
+			}
+		}
+	}
+}
+
+func TestSlowStartRejectsUnsupportedAlgorithm(t *testing.T) {
+	strategy, err := NewSlowStartLoadBalancingStrategy(NewRandomLoadBalancingStrategy(), &types.SlowStartConfig{Window: "30s"})
+	if err != nil {
+		t.Fatalf("NewSlowStartLoadBalancingStrategy: %v", err)
+	}
+	if err := strategy.ConfigureCluster(&clusterv3.Cluster{Name: "c"}, makeDeployment("rest")); err == nil {
+		t.Error("slow start accepted the random algorithm")
+	}
+	if _, err := NewSlowStartLoadBalancingStrategy(NewRoundRobinLoadBalancingStrategy(), &types.SlowStartConfig{Window: "soon"}); err == nil {
+		t.Error("slow start accepted window \"soon\"")
+	}
+}
+
+func TestHealthyPanicThresholdReachesCluster(t *testing.T) {
+	for _, tt := range []struct {
+		lb        string
+		threshold float64
+	}{
+		{"round-robin", 0},
+		{"locality-aware", 25},
+	} {
+		dep := makeDeployment("rest")
+		config := DefaultStrategyConfig()
+		config.LoadBalancing = &types.LoadBalancingStrategyConfig{Type: tt.lb, HealthyPanicThreshold: &tt.threshold}
+		strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+		if err != nil {
+			t.Fatalf("%s: CreateStrategySet: %v", tt.lb, err)
+		}
+		composite, err := NewCompositeTranslator(strategies, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: NewCompositeTranslator: %v", tt.lb, err)
+		}
+		composite.SetTranslationContext(&TranslationContext{
+			Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+			Listener:    &models.Listener{ID: "l1", Port: 8080},
+			VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+		})
+		xds, err := composite.Translate(context.Background(), dep, nil, "node-1")
+		if err != nil {
+			t.Fatalf("%s: Translate: %v", tt.lb, err)
+		}
+
+		for _, c := range xds.Clusters {
+			common := c.GetCommonLbConfig()
+			if common.GetHealthyPanicThreshold() == nil || common.GetHealthyPanicThreshold().GetValue() != tt.threshold {
+				t.Errorf("%s: cluster %s healthy panic threshold = %v, want %v", tt.lb, c.Name, common.GetHealthyPanicThreshold(), tt.threshold)
+			}
+			if tt.lb == "locality-aware" && common.GetLocalityWeightedLbConfig() == nil {
+				t.Errorf("%s: cluster %s lost its locality config", tt.lb, c.Name)
+			}
+			if err := c.ValidateAll(); err != nil {
+				t.Errorf("%s: cluster %s is invalid: %v", tt.lb, c.Name, err)
+			}
+		}
+	}
+
+	over := 150.0
+	config := DefaultStrategyConfig()
+	config.LoadBalancing = &types.LoadBalancingStrategyConfig{Type: "round-robin", HealthyPanicThreshold: &over}
+	if _, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, makeDeployment("rest")); err == nil {
+		t.Error("healthy panic threshold of 150 accepted")
+	}
+}
@@ -0,0 +1,140 @@
+package translator
+
+import (
+	"fmt"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	localratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+)
+
+// =============================================================================
+// RATE LIMIT STRATEGIES
+// =============================================================================
+
+// LocalRateLimitFilterName is the HCM filter that enforces per-vhost and
+// per-route token buckets. The HCM instance carries no bucket of its own,
+// so it only limits where a virtual host or route supplies one.
+const LocalRateLimitFilterName = "envoy.filters.http.local_ratelimit"
+
+// GlobalRateLimitStrategy applies the API-wide token bucket to each of
+// the deployment's routes. It is not put on the virtual host: deployments
+// sharing a host merge into one virtual host, which keeps only the first
+// deployment's per-filter config. Buckets are per route and per Envoy
+// instance.
+type GlobalRateLimitStrategy struct {
+	requestsPerMinute uint32
+	burstSize         uint32
+}
+
+func NewGlobalRateLimitStrategy(requestsPerMinute, burstSize uint32) *GlobalRateLimitStrategy {
+	return &GlobalRateLimitStrategy{
+		requestsPerMinute: requestsPerMinute,
+		burstSize:         burstSize,
+	}
+}
+
+func (s *GlobalRateLimitStrategy) ConfigureRateLimit(vhost *routev3.VirtualHost, deployment *models.APIDeployment) error {
+	cfg, err := localRateLimitConfig("api_"+deployment.Name, s.requestsPerMinute, s.burstSize, time.Minute)
+	if err != nil {
+		return err
+	}
+	for _, route := range vhost.Routes {
+		// Endpoint-level limits override the API-wide one.
+		if route.TypedPerFilterConfig[LocalRateLimitFilterName] != nil {
+			continue
+		}
+		if err := setPerFilterConfig(&route.TypedPerFilterConfig, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GlobalRateLimitStrategy) Name() string {
+	return "global"
+}
+
+// applyEndpointRateLimit gives a route its own token bucket from the IR
+// endpoint's rate limit, in place of the API-wide one.
+func applyEndpointRateLimit(route *routev3.Route, endpoint *ir.Endpoint) error {
+	rl := endpoint.RateLimit
+	if rl == nil {
+		return nil
+	}
+	if rl.Requests <= 0 || rl.Burst < 0 {
+		return fmt.Errorf("%w: endpoint %s rate limit requests must be positive", ErrInvalidConfig, endpoint.ID)
+	}
+	window, err := time.ParseDuration(rl.Window)
+	if err != nil || window <= 0 {
+		return fmt.Errorf("%w: endpoint %s rate limit window %q is not a positive duration",
+			ErrInvalidConfig, endpoint.ID, rl.Window)
+	}
+
+	cfg, err := localRateLimitConfig("route_"+endpoint.ID, uint32(rl.Requests), uint32(rl.Burst), window)
+	if err != nil {
+		return err
+	}
+	return setPerFilterConfig(&route.TypedPerFilterConfig, cfg)
+}
+
+// localRateLimitConfig builds an always-enforced token bucket refilling
+// requests tokens every window, with room for burst extra requests.
+func localRateLimitConfig(statPrefix string, requests, burst uint32, window time.Duration) (*localratelimitv3.LocalRateLimit, error) {
+	if requests == 0 {
+		return nil, fmt.Errorf("%w: rate limit requires a positive request count", ErrMissingConfig)
+	}
+	full := &corev3.RuntimeFractionalPercent{
+		DefaultValue: &typev3.FractionalPercent{Numerator: 100, Denominator: typev3.FractionalPercent_HUNDRED},
+	}
+	return &localratelimitv3.LocalRateLimit{
+		StatPrefix: statPrefix,
+		TokenBucket: &typev3.TokenBucket{
+			MaxTokens:     requests + burst,
+			TokensPerFill: wrapperspb.UInt32(requests),
+			FillInterval:  durationpb.New(window),
+		},
+		FilterEnabled:  full,
+		FilterEnforced: full,
+	}, nil
+}
+
+// buildLocalRateLimitFilter returns the HCM filter when any virtual host or
+// route in routes carries a local rate limit config, nil otherwise.
+func buildLocalRateLimitFilter(routes []*routev3.RouteConfiguration) (*hcmv3.HttpFilter, error) {
+	for _, rc := range routes {
+		for _, vh := range rc.VirtualHosts {
+			used := vh.TypedPerFilterConfig[LocalRateLimitFilterName] != nil
+			for _, r := range vh.Routes {
+				used = used || r.TypedPerFilterConfig[LocalRateLimitFilterName] != nil
+			}
+			if used {
+				return newHTTPFilter(LocalRateLimitFilterName, &localratelimitv3.LocalRateLimit{
+					StatPrefix: "http_local_rate_limiter",
+				})
+			}
+		}
+	}
+	return nil, nil
+}
+
+func setPerFilterConfig(target *map[string]*anypb.Any, cfg *localratelimitv3.LocalRateLimit) error {
+	typed, err := anypb.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s config: %w", LocalRateLimitFilterName, err)
+	}
+	if *target == nil {
+		*target = make(map[string]*anypb.Any)
+	}
+	(*target)[LocalRateLimitFilterName] = typed
+	return nil
+}
@@ -0,0 +1,126 @@
+package translator
+
+import (
+	"context"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	localratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func tokenBucket(t *testing.T, configs map[string]*anypb.Any) *localratelimitv3.LocalRateLimit {
+	t.Helper()
+	typed := configs[LocalRateLimitFilterName]
+	if typed == nil {
+		return nil
+	}
+	var cfg localratelimitv3.LocalRateLimit
+	if err := typed.UnmarshalTo(&cfg); err != nil {
+		t.Fatalf("unmarshal local rate limit: %v", err)
+	}
+	return &cfg
+}
+
+func TestTranslateEndpointRateLimitOverridesAPILimit(t *testing.T) {
+	dep := makeDeployment("rest")
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{ID: "search", Method: "GET", Path: ir.PathInfo{Pattern: "/search"},
+				RateLimit: &ir.RateLimit{Requests: 10, Window: "1m"}},
+			{ID: "items", Method: "GET", Path: ir.PathInfo{Pattern: "/items"}},
+		},
+	}
+
+	cfg := DefaultStrategyConfig()
+	cfg.RateLimit = &types.RateLimitStrategyConfig{Type: "global", RequestsPerMinute: 100}
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(cfg, dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	if findHTTPFilter(xds.HTTPFilters, LocalRateLimitFilterName) == nil {
+		t.Fatalf("expected %s filter, got %v", LocalRateLimitFilterName, xds.HTTPFilters)
+	}
+
+	// Virtual hosts are merged across deployments, keeping only the
+	// first one's per-filter config, so the API-wide limit is per route.
+	vhost := xds.Routes[0].VirtualHosts[0]
+	if vhLimit := tokenBucket(t, vhost.TypedPerFilterConfig); vhLimit != nil {
+		t.Fatalf("virtual host limit = %v, want none", vhLimit)
+	}
+
+	for _, route := range vhost.Routes {
+		limit := tokenBucket(t, route.TypedPerFilterConfig)
+		switch routePaths([]*routev3.Route{route})[0] {
+		case "/svc/search":
+			if limit == nil || limit.GetTokenBucket().GetTokensPerFill().GetValue() != 10 {
+				t.Errorf("/search limit = %v, want 10 tokens per fill", limit)
+			} else if limit.GetTokenBucket().GetFillInterval().AsDuration().Minutes() != 1 {
+				t.Errorf("/search fill interval = %v, want 1m", limit.GetTokenBucket().GetFillInterval().AsDuration())
+			}
+		case "/svc/items":
+			if limit == nil || limit.GetTokenBucket().GetTokensPerFill().GetValue() != 100 {
+				t.Errorf("/items limit = %v, want the API-wide 100 tokens per fill", limit)
+			}
+		default:
+			t.Errorf("unexpected route %v", routePaths([]*routev3.Route{route}))
+		}
+	}
+}
+
+func TestTranslateWithoutRateLimits(t *testing.T) {
+	xds, err := translate(t, makeDeployment("rest"), nil)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if findHTTPFilter(xds.HTTPFilters, LocalRateLimitFilterName) != nil {
+		t.Error("expected no local rate limit filter without any rate limits")
+	}
+}
+
+func TestLenientStrategiesSkipBadRateLimit(t *testing.T) {
+	dep := makeDeployment("rest")
+	config := DefaultStrategyConfig()
+	config.RateLimit = &types.RateLimitStrategyConfig{Type: "global"} // no requests_per_minute
+
+	if _, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep); err == nil {
+		t.Fatal("strict CreateStrategySet accepted a global rate limit without requests_per_minute")
+	}
+
+	options := DefaultTranslatorOptions()
+	options.StrictStrategies = false
+	strategies, err := NewStrategyFactory(options, nil).CreateStrategySet(config, dep)
+	if err != nil {
+		t.Fatalf("lenient CreateStrategySet: %v", err)
+	}
+	if _, ok := strategies.RateLimit.(*NoOpRateLimitStrategy); !ok {
+		t.Errorf("rate limit strategy = %s, want it dropped", strategies.RateLimit.Name())
+	}
+	if strategies.Deployment == nil || strategies.RouteMatch == nil || strategies.LoadBalancing == nil {
+		t.Error("required strategies missing from the lenient set")
+	}
+
+	// A bad route match config still fails the deploy.
+	config.RouteMatching = &types.RouteMatchStrategyConfig{Type: "fuzzy"}
+	if _, err := NewStrategyFactory(options, nil).CreateStrategySet(config, dep); err == nil {
+		t.Error("lenient CreateStrategySet accepted an unknown route match strategy")
+	}
+}
@@ -1,10 +1,14 @@
 package translator
 
 import (
+	"fmt"
 	"time"
 
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	previoushostsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/retry/host/previous_hosts/v3"
+	previousprioritiesv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/retry/priority/previous_priorities/v3"
 	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -13,33 +17,189 @@ import (
 // RETRY STRATEGIES
 // =============================================================================
 
+// DefaultRouteTimeout is Envoy's route timeout, used to cap retries when
+// the upstream sets no timeout of its own.
+const DefaultRouteTimeout = 15 * time.Second
+
+// boundRetryTimeout bounds the total time spent on a request and its
+// retries: the route timeout becomes attempts × per-try timeout, capped
+// at the upstream timeout (or DefaultRouteTimeout), and no single attempt
+// may outlast the cap. Routes whose timeout is disabled (streams) are
+// left alone.
+func boundRetryTimeout(action *routev3.RouteAction, deployment *models.APIDeployment) {
+	policy := action.RetryPolicy
+	if policy == nil || policy.PerTryTimeout == nil {
+		return
+	}
+	if action.Timeout != nil && action.Timeout.AsDuration() == 0 {
+		return
+	}
+
+	limit := DefaultRouteTimeout
+	if deployment != nil {
+		if d, err := parseDuration(deployment.Metadata.Upstream.Timeout); err == nil && d > 0 {
+			limit = d
+		}
+	}
+	if action.Timeout != nil {
+		limit = min(limit, action.Timeout.AsDuration())
+	}
+
+	perTry := min(policy.PerTryTimeout.AsDuration(), limit)
+	policy.PerTryTimeout = durationpb.New(perTry)
+	attempts := time.Duration(policy.GetNumRetries().GetValue()) + 1
+	action.Timeout = durationpb.New(min(attempts*perTry, limit))
+}
+
+// Retry host selection predicates. previous_hosts steers a retry away from
+// the hosts already tried; previous_priorities away from their priority
+// levels.
+const (
+	RetryPredicatePreviousHosts      = "previous_hosts"
+	RetryPredicatePreviousPriorities = "previous_priorities"
+)
+
+// DefaultHostSelectionMaxAttempts is how many hosts a retry may pick
+// before settling for one the predicates reject, unless configured.
+const DefaultHostSelectionMaxAttempts = 3
+
+// hostSelection configures how retries pick their host.
+type hostSelection struct {
+	predicates  []string
+	maxAttempts int64
+}
+
+// defaultHostSelection retries on a host other than the ones that failed.
+func defaultHostSelection() hostSelection {
+	return hostSelection{
+		predicates:  []string{RetryPredicatePreviousHosts},
+		maxAttempts: DefaultHostSelectionMaxAttempts,
+	}
+}
+
+// newHostSelection validates configured predicates and attempts; empty
+// predicates and a zero maxAttempts keep the defaults.
+func newHostSelection(predicates []string, maxAttempts int64) (hostSelection, error) {
+	sel := defaultHostSelection()
+	if len(predicates) > 0 {
+		for _, p := range predicates {
+			if p != RetryPredicatePreviousHosts && p != RetryPredicatePreviousPriorities {
+				return hostSelection{}, fmt.Errorf("%w: retry host predicate %q is not supported (%s, %s)",
+					ErrInvalidConfig, p, RetryPredicatePreviousHosts, RetryPredicatePreviousPriorities)
+			}
+		}
+		sel.predicates = predicates
+	}
+	if maxAttempts < 0 {
+		return hostSelection{}, fmt.Errorf("%w: retry host_selection_max_attempts must be positive, got %d", ErrInvalidConfig, maxAttempts)
+	}
+	if maxAttempts > 0 {
+		sel.maxAttempts = maxAttempts
+	}
+	return sel, nil
+}
+
+// apply sets the predicates and attempts on policy. previous_priorities
+// is an Envoy retry priority rather than a host predicate, but serves the
+// same end, so it is configured alongside.
+func (h hostSelection) apply(policy *routev3.RetryPolicy) error {
+	for _, p := range h.predicates {
+		switch p {
+		case RetryPredicatePreviousHosts:
+			typed, err := anypb.New(&previoushostsv3.PreviousHostsPredicate{})
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s predicate: %w", p, err)
+			}
+			policy.RetryHostPredicate = append(policy.RetryHostPredicate, &routev3.RetryPolicy_RetryHostPredicate{
+				Name:       "envoy.retry_host_predicates.previous_hosts",
+				ConfigType: &routev3.RetryPolicy_RetryHostPredicate_TypedConfig{TypedConfig: typed},
+			})
+		case RetryPredicatePreviousPriorities:
+			typed, err := anypb.New(&previousprioritiesv3.PreviousPrioritiesConfig{UpdateFrequency: 2})
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s priority: %w", p, err)
+			}
+			policy.RetryPriority = &routev3.RetryPolicy_RetryPriority{
+				Name:       "envoy.retry_priorities.previous_priorities",
+				ConfigType: &routev3.RetryPolicy_RetryPriority_TypedConfig{TypedConfig: typed},
+			}
+		}
+	}
+	policy.HostSelectionRetryMaxAttempts = h.maxAttempts
+	return nil
+}
+
+// Retry conditions of the presets by API type. gRPC reports failures in
+// the grpc-status trailer rather than the HTTP status, so it needs the
+// gRPC conditions to retry at all.
+const (
+	RESTRetryOn = "5xx,reset,connect-failure"
+	GRPCRetryOn = "cancelled,deadline-exceeded,resource-exhausted,unavailable"
+)
+
+// retryConditions picks the preset conditions for the deployment's API type.
+func retryConditions(deployment *models.APIDeployment, rest, grpc string) string {
+	if deployment != nil && deployment.Metadata.APIType == "grpc" {
+		return grpc
+	}
+	return rest
+}
+
 // ConservativeRetryStrategy implements conservative retry policy
 // Suitable for most APIs - retry only on clear failures
 type ConservativeRetryStrategy struct {
 	maxRetries    uint32
 	retryOn       string
+	grpcRetryOn   string
 	perTryTimeout time.Duration
+	hostSelection hostSelection
 }
 
 func NewConservativeRetryStrategy() *ConservativeRetryStrategy {
 	return &ConservativeRetryStrategy{
 		maxRetries:    1,
-		retryOn:       "5xx,reset",
+		retryOn:       RESTRetryOn,
+		grpcRetryOn:   GRPCRetryOn,
 		perTryTimeout: 5 * time.Second,
+		hostSelection: defaultHostSelection(),
 	}
 }
 
+// WithPerTryTimeout overrides the preset per-try timeout.
+func (s *ConservativeRetryStrategy) WithPerTryTimeout(d time.Duration) *ConservativeRetryStrategy {
+	s.perTryTimeout = d
+	return s
+}
+
+// WithRetryOn overrides the preset retry conditions for every API type.
+func (s *ConservativeRetryStrategy) WithRetryOn(retryOn string) *ConservativeRetryStrategy {
+	s.retryOn = retryOn
+	s.grpcRetryOn = retryOn
+	return s
+}
+
+// WithHostSelection overrides how retries pick their host.
+func (s *ConservativeRetryStrategy) WithHostSelection(h hostSelection) *ConservativeRetryStrategy {
+	s.hostSelection = h
+	return s
+}
+
 func (s *ConservativeRetryStrategy) ConfigureRetry(route *routev3.Route, deployment *models.APIDeployment) error {
 	routeAction, ok := route.Action.(*routev3.Route_Route)
 	if !ok {
 		return nil // Not a route action
 	}
 
-	routeAction.Route.RetryPolicy = &routev3.RetryPolicy{
-		RetryOn:       s.retryOn,
+	policy := &routev3.RetryPolicy{
+		RetryOn:       retryConditions(deployment, s.retryOn, s.grpcRetryOn),
 		NumRetries:    wrapperspb.UInt32(s.maxRetries),
 		PerTryTimeout: durationpb.New(s.perTryTimeout),
 	}
+	if err := s.hostSelection.apply(policy); err != nil {
+		return err
+	}
+	routeAction.Route.RetryPolicy = policy
+	boundRetryTimeout(routeAction.Route, deployment)
 
 	return nil
 }
@@ -53,35 +213,58 @@ func (s *ConservativeRetryStrategy) Name() string {
 type AggressiveRetryStrategy struct {
 	maxRetries    uint32
 	retryOn       string
+	grpcRetryOn   string
 	perTryTimeout time.Duration
+	hostSelection hostSelection
 }
 
 func NewAggressiveRetryStrategy() *AggressiveRetryStrategy {
 	return &AggressiveRetryStrategy{
 		maxRetries:    3,
-		retryOn:       "5xx,reset,connect-failure,refused-stream",
+		retryOn:       RESTRetryOn + ",refused-stream",
+		grpcRetryOn:   GRPCRetryOn + ",reset,connect-failure,refused-stream",
 		perTryTimeout: 2 * time.Second,
+		hostSelection: defaultHostSelection(),
 	}
 }
 
+// WithPerTryTimeout overrides the preset per-try timeout.
+func (s *AggressiveRetryStrategy) WithPerTryTimeout(d time.Duration) *AggressiveRetryStrategy {
+	s.perTryTimeout = d
+	return s
+}
+
+// WithRetryOn overrides the preset retry conditions for every API type.
+func (s *AggressiveRetryStrategy) WithRetryOn(retryOn string) *AggressiveRetryStrategy {
+	s.retryOn = retryOn
+	s.grpcRetryOn = retryOn
+	return s
+}
+
+// WithHostSelection overrides how retries pick their host.
+func (s *AggressiveRetryStrategy) WithHostSelection(h hostSelection) *AggressiveRetryStrategy {
+	s.hostSelection = h
+	return s
+}
+
 func (s *AggressiveRetryStrategy) ConfigureRetry(route *routev3.Route, deployment *models.APIDeployment) error {
 	routeAction, ok := route.Action.(*routev3.Route_Route)
 	if !ok {
 		return nil
 	}
 
-	routeAction.Route.RetryPolicy = &routev3.RetryPolicy{
-		RetryOn: s.retryOn,
+	policy := &routev3.RetryPolicy{
+		RetryOn: retryConditions(deployment, s.retryOn, s.grpcRetryOn),
 		NumRetries: &wrapperspb.UInt32Value{
 			Value: s.maxRetries,
 		},
 		PerTryTimeout: durationpb.New(s.perTryTimeout),
-		RetryHostPredicate: []*routev3.RetryPolicy_RetryHostPredicate{
-			{
-				Name: "envoy.retry_host_predicates.previous_hosts",
-			},
-		},
 	}
+	if err := s.hostSelection.apply(policy); err != nil {
+		return err
+	}
+	routeAction.Route.RetryPolicy = policy
+	boundRetryTimeout(routeAction.Route, deployment)
 
 	return nil
 }
@@ -97,6 +280,7 @@ type CustomRetryStrategy struct {
 	perTryTimeout        time.Duration
 	retriableStatusCodes []uint32
 	budgetPercent        float64
+	hostSelection        hostSelection
 }
 
 func NewCustomRetryStrategy(maxRetries uint32, retryOn string, perTryTimeout time.Duration) *CustomRetryStrategy {
@@ -105,6 +289,7 @@ func NewCustomRetryStrategy(maxRetries uint32, retryOn string, perTryTimeout tim
 		retryOn:       retryOn,
 		perTryTimeout: perTryTimeout,
 		budgetPercent: 20.0, // Default: allow 20% of requests to be retries
+		hostSelection: defaultHostSelection(),
 	}
 }
 
@@ -118,6 +303,12 @@ func (s *CustomRetryStrategy) WithBudgetPercent(percent float64) *CustomRetryStr
 	return s
 }
 
+// WithHostSelection overrides how retries pick their host.
+func (s *CustomRetryStrategy) WithHostSelection(h hostSelection) *CustomRetryStrategy {
+	s.hostSelection = h
+	return s
+}
+
 func (s *CustomRetryStrategy) ConfigureRetry(route *routev3.Route, deployment *models.APIDeployment) error {
 	routeAction, ok := route.Action.(*routev3.Route_Route)
 	if !ok {
@@ -145,7 +336,11 @@ func (s *CustomRetryStrategy) ConfigureRetry(route *routev3.Route, deployment *m
 		}
 	}
 
+	if err := s.hostSelection.apply(retryPolicy); err != nil {
+		return err
+	}
 	routeAction.Route.RetryPolicy = retryPolicy
+	boundRetryTimeout(routeAction.Route, deployment)
 
 	return nil
 }
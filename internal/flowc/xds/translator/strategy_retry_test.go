@@ -0,0 +1,126 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func retryRoute(t *testing.T, cfg *types.RetryStrategyConfig, upstreamTimeout string) *routev3.RouteAction {
+	t.Helper()
+	strategy, err := NewStrategyFactory(nil, nil).createRetryStrategy(cfg)
+	if err != nil {
+		t.Fatalf("createRetryStrategy: %v", err)
+	}
+	dep := makeDeployment("rest")
+	dep.Metadata.Upstream.Timeout = upstreamTimeout
+	route := &routev3.Route{Action: &routev3.Route_Route{Route: &routev3.RouteAction{}}}
+	if err := strategy.ConfigureRetry(route, dep); err != nil {
+		t.Fatalf("ConfigureRetry: %v", err)
+	}
+	return route.GetRoute()
+}
+
+func TestAggressiveRetryPerTryTimeout(t *testing.T) {
+	action := retryRoute(t, &types.RetryStrategyConfig{Type: "aggressive"}, "30s")
+	policy := action.GetRetryPolicy()
+	if policy.GetPerTryTimeout() == nil {
+		t.Fatal("aggressive retry policy has no per-try timeout")
+	}
+	if got := policy.GetPerTryTimeout().AsDuration(); got != 2*time.Second {
+		t.Errorf("per-try timeout = %v, want 2s", got)
+	}
+	// Four attempts (one try, three retries) of 2s each.
+	if got := action.GetTimeout().AsDuration(); got != 8*time.Second {
+		t.Errorf("route timeout = %v, want 8s", got)
+	}
+}
+
+func TestRetryTimeoutCappedByUpstreamTimeout(t *testing.T) {
+	action := retryRoute(t, &types.RetryStrategyConfig{Type: "aggressive", PerTryTimeout: "4s"}, "10s")
+	if got := action.GetRetryPolicy().GetPerTryTimeout().AsDuration(); got != 4*time.Second {
+		t.Errorf("per-try timeout = %v, want the 4s override", got)
+	}
+	if got := action.GetTimeout().AsDuration(); got != 10*time.Second {
+		t.Errorf("route timeout = %v, want the 10s upstream timeout", got)
+	}
+
+	action = retryRoute(t, &types.RetryStrategyConfig{Type: "custom", MaxRetries: 2, PerTryTimeout: "20s"}, "")
+	if got := action.GetRetryPolicy().GetPerTryTimeout().AsDuration(); got != DefaultRouteTimeout {
+		t.Errorf("per-try timeout = %v, want it capped at %v", got, DefaultRouteTimeout)
+	}
+	if got := action.GetTimeout().AsDuration(); got != DefaultRouteTimeout {
+		t.Errorf("route timeout = %v, want %v", got, DefaultRouteTimeout)
+	}
+}
+
+func TestRetryPresetConditionsByAPIType(t *testing.T) {
+	for _, preset := range []string{"conservative", "aggressive"} {
+		strategy, err := NewStrategyFactory(nil, nil).createRetryStrategy(&types.RetryStrategyConfig{Type: preset})
+		if err != nil {
+			t.Fatalf("%s: createRetryStrategy: %v", preset, err)
+		}
+		for apiType, want := range map[string]string{"grpc": GRPCRetryOn, "rest": RESTRetryOn} {
+			route := &routev3.Route{Action: &routev3.Route_Route{Route: &routev3.RouteAction{}}}
+			if err := strategy.ConfigureRetry(route, makeDeployment(apiType)); err != nil {
+				t.Fatalf("%s: ConfigureRetry: %v", preset, err)
+			}
+			if got := route.GetRoute().GetRetryPolicy().GetRetryOn(); !strings.HasPrefix(got, want) {
+				t.Errorf("%s %s: retry_on = %q, want the %s conditions %q", preset, apiType, got, apiType, want)
+			}
+		}
+	}
+
+	// A configured retry_on wins over the API type.
+	strategy, err := NewStrategyFactory(nil, nil).createRetryStrategy(&types.RetryStrategyConfig{Type: "conservative", RetryOn: "unavailable"})
+	if err != nil {
+		t.Fatalf("createRetryStrategy: %v", err)
+	}
+	route := &routev3.Route{Action: &routev3.Route_Route{Route: &routev3.RouteAction{}}}
+	if err := strategy.ConfigureRetry(route, makeDeployment("grpc")); err != nil {
+		t.Fatalf("ConfigureRetry: %v", err)
+	}
+	if got := route.GetRoute().GetRetryPolicy().GetRetryOn(); got != "unavailable" {
+		t.Errorf("retry_on = %q, want the configured unavailable", got)
+	}
+}
+
+func TestRetryHostPredicates(t *testing.T) {
+	// Every preset retries on another host by default.
+	for _, preset := range []string{"conservative", "aggressive", "custom"} {
+		policy := retryRoute(t, &types.RetryStrategyConfig{Type: preset, MaxRetries: 2}, "").GetRetryPolicy()
+		predicates := policy.GetRetryHostPredicate()
+		if len(predicates) != 1 || predicates[0].GetName() != "envoy.retry_host_predicates.previous_hosts" {
+			t.Errorf("%s: host predicates = %v, want previous_hosts", preset, predicates)
+		}
+		if got := policy.GetHostSelectionRetryMaxAttempts(); got != DefaultHostSelectionMaxAttempts {
+			t.Errorf("%s: host selection attempts = %d, want %d", preset, got, DefaultHostSelectionMaxAttempts)
+		}
+	}
+
+	policy := retryRoute(t, &types.RetryStrategyConfig{
+		Type:                     "conservative",
+		HostPredicates:           []string{RetryPredicatePreviousHosts, RetryPredicatePreviousPriorities},
+		HostSelectionMaxAttempts: 5,
+	}, "").GetRetryPolicy()
+	if len(policy.GetRetryHostPredicate()) != 1 {
+		t.Errorf("host predicates = %v, want previous_hosts", policy.GetRetryHostPredicate())
+	}
+	if got := policy.GetRetryPriority().GetName(); got != "envoy.retry_priorities.previous_priorities" {
+		t.Errorf("retry priority = %q, want previous_priorities", got)
+	}
+	if got := policy.GetHostSelectionRetryMaxAttempts(); got != 5 {
+		t.Errorf("host selection attempts = %d, want 5", got)
+	}
+
+	if _, err := NewStrategyFactory(nil, nil).createRetryStrategy(&types.RetryStrategyConfig{
+		Type:           "aggressive",
+		HostPredicates: []string{"omit_canary_hosts"},
+	}); err == nil {
+		t.Error("expected error for an unsupported host predicate")
+	}
+}
@@ -1,11 +1,13 @@
 package translator
 
 import (
+	"sort"
 	"strings"
 
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -127,7 +129,10 @@ func (s *RegexRouteMatchStrategy) Name() string {
 	return "regex"
 }
 
-// HeaderVersionedRouteMatchStrategy routes based on API version in header
+// HeaderVersionedRouteMatchStrategy routes based on API version in header.
+// It matches paths like the prefix strategy; the composite translator
+// then serves each route once per API version of the deployment strategy
+// (see VersionRouteMatcher), matching the version header.
 type HeaderVersionedRouteMatchStrategy struct {
 	versionHeader string
 	caseSensitive bool
@@ -146,7 +151,7 @@ func NewHeaderVersionedRouteMatchStrategy(versionHeader string, caseSensitive bo
 func (s *HeaderVersionedRouteMatchStrategy) CreateMatcher(path, method string, endpoint *ir.Endpoint) *routev3.RouteMatch {
 	return &routev3.RouteMatch{
 		PathSpecifier: &routev3.RouteMatch_Prefix{
-			Prefix: path,
+			Prefix: TruncatePathParams(path),
 		},
 		Headers: []*routev3.HeaderMatcher{
 			{
@@ -159,17 +164,47 @@ func (s *HeaderVersionedRouteMatchStrategy) CreateMatcher(path, method string, e
 					},
 				},
 			},
-			// Add version header matching if needed
-			// This can be customized based on the deployment
 		},
 		CaseSensitive: wrapperspb.Bool(s.caseSensitive),
 	}
 }
 
+// VersionHeader returns the request header carrying the API version.
+func (s *HeaderVersionedRouteMatchStrategy) VersionHeader() string {
+	return s.versionHeader
+}
+
 func (s *HeaderVersionedRouteMatchStrategy) Name() string {
 	return "header-versioned"
 }
 
+// versionRoutes serves route once per version: the route itself goes to
+// the default (first) version's cluster, and a copy matching header ==
+// version goes to each other version's cluster. Routes that do not
+// forward to clusters are returned unchanged.
+func versionRoutes(route *routev3.Route, header string, versions []VersionCluster) []*routev3.Route {
+	if route.GetRoute() == nil || len(versions) < 2 {
+		return []*routev3.Route{route}
+	}
+	route.GetRoute().ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: versions[0].Name}
+
+	out := []*routev3.Route{route}
+	for _, v := range versions[1:] {
+		versioned := proto.Clone(route).(*routev3.Route)
+		versioned.GetRoute().ClusterSpecifier = &routev3.RouteAction_Cluster{Cluster: v.Name}
+		versioned.Match.Headers = append(versioned.Match.Headers, &routev3.HeaderMatcher{
+			Name: header,
+			HeaderMatchSpecifier: &routev3.HeaderMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{
+					MatchPattern: &matcherv3.StringMatcher_Exact{Exact: v.Version},
+				},
+			},
+		})
+		out = append(out, versioned)
+	}
+	return out
+}
+
 // =============================================================================
 // HELPER FUNCTIONS
 // =============================================================================
@@ -214,3 +249,81 @@ func convertPathToRegex(path string) string {
 
 	return string(builder)
 }
+
+// =============================================================================
+// ROUTE ORDERING
+// =============================================================================
+
+// SortRoutesBySpecificity orders routes so that Envoy's first-match
+// evaluation picks the most specific one. Routes are compared by:
+//
+//  1. length of the literal path they pin down (longest first), so
+//     /api/v1/special precedes /api/v1
+//  2. matcher kind: exact path, then regex, then prefix
+//  3. number of header and query parameter matchers (more constrained
+//     first), so a route for ?version=2 precedes the same path without
+//
+// The sort is stable, so routes that tie keep their generated order.
+func SortRoutesBySpecificity(routes []*routev3.Route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		li, ki := routeSpecificity(routes[i].GetMatch())
+		lj, kj := routeSpecificity(routes[j].GetMatch())
+		if li != lj {
+			return li > lj
+		}
+		if ki != kj {
+			return ki > kj
+		}
+		return matcherCount(routes[i].GetMatch()) > matcherCount(routes[j].GetMatch())
+	})
+}
+
+// matcherCount returns the number of header and query parameter matchers
+// of a route match.
+func matcherCount(m *routev3.RouteMatch) int {
+	return len(m.GetHeaders()) + len(m.GetQueryParameters())
+}
+
+// Matcher kind ranks used by SortRoutesBySpecificity; higher is more specific.
+const (
+	matchKindPrefix = iota
+	matchKindRegex
+	matchKindExact
+)
+
+// routeSpecificity returns the literal path length and matcher kind rank
+// of a route match.
+func routeSpecificity(m *routev3.RouteMatch) (int, int) {
+	switch spec := m.GetPathSpecifier().(type) {
+	case *routev3.RouteMatch_Path:
+		return len(spec.Path), matchKindExact
+	case *routev3.RouteMatch_SafeRegex:
+		return len(regexLiteralPrefix(spec.SafeRegex.GetRegex())), matchKindRegex
+	case *routev3.RouteMatch_PathSeparatedPrefix:
+		return len(spec.PathSeparatedPrefix), matchKindPrefix
+	case *routev3.RouteMatch_Prefix:
+		return len(spec.Prefix), matchKindPrefix
+	default:
+		return 0, matchKindPrefix
+	}
+}
+
+// regexLiteralPrefix returns the literal text a regex must start with,
+// e.g. ^/users/[^/]+$ -> /users/. Escaped metacharacters count as literals.
+func regexLiteralPrefix(regex string) string {
+	regex = strings.TrimPrefix(regex, "^")
+	var b strings.Builder
+	for i := 0; i < len(regex); i++ {
+		ch := regex[i]
+		if ch == '\\' && i+1 < len(regex) {
+			i++
+			b.WriteByte(regex[i])
+			continue
+		}
+		if strings.IndexByte(`.[](){}*+?|$`, ch) >= 0 {
+			break
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
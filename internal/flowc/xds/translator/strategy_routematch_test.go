@@ -0,0 +1,149 @@
+package translator
+
+import (
+	"context"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+
+	"github.com/flowc-labs/flowc/internal/flowc/ir"
+	"github.com/flowc-labs/flowc/internal/flowc/models"
+	"github.com/flowc-labs/flowc/pkg/types"
+)
+
+func routePaths(routes []*routev3.Route) []string {
+	out := make([]string, 0, len(routes))
+	for _, r := range routes {
+		switch spec := r.GetMatch().GetPathSpecifier().(type) {
+		case *routev3.RouteMatch_Prefix:
+			out = append(out, spec.Prefix)
+		case *routev3.RouteMatch_Path:
+			out = append(out, spec.Path)
+		case *routev3.RouteMatch_SafeRegex:
+			out = append(out, spec.SafeRegex.GetRegex())
+		}
+	}
+	return out
+}
+
+func TestTranslateOrdersOverlappingPrefixes(t *testing.T) {
+	dep := makeDeployment("rest")
+	dep.Context = "/"
+	irAPI := &ir.API{
+		Endpoints: []ir.Endpoint{
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/api/v1"}},
+			{Method: "GET", Path: ir.PathInfo{Pattern: "/api/v1/special"}},
+		},
+	}
+
+	xds, err := translate(t, dep, irAPI)
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	got := routePaths(xds.Routes[0].VirtualHosts[0].Routes)
+	want := []string{"/api/v1/special", "/api/v1"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("route order = %v, want %v", got, want)
+	}
+}
+
+func TestSortRoutesBySpecificity(t *testing.T) {
+	prefix := func(p string) *routev3.Route {
+		return &routev3.Route{Match: &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: p}}}
+	}
+	exact := func(p string) *routev3.Route {
+		return &routev3.Route{Match: &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_Path{Path: p}}}
+	}
+	regex := func(r string) *routev3.Route {
+		return &routev3.Route{Match: &routev3.RouteMatch{PathSpecifier: &routev3.RouteMatch_SafeRegex{
+			SafeRegex: &matcherv3.RegexMatcher{Regex: r},
+		}}}
+	}
+
+	routes := []*routev3.Route{
+		prefix("/pets"),
+		regex("^/pets/[^/]+$"),
+		exact("/pets/mine"),
+		prefix("/pets/"),
+	}
+	SortRoutesBySpecificity(routes)
+
+	got := routePaths(routes)
+	want := []string{"/pets/mine", "^/pets/[^/]+$", "/pets/", "/pets"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestHeaderVersionedRoutes(t *testing.T) {
+	dep := makeDeployment("rest")
+	config := DefaultStrategyConfig()
+	config.Deployment = &types.DeploymentStrategyConfig{
+		Type: "canary",
+		Canary: &types.CanaryConfig{
+			BaselineVersion: "v1",
+			CanaryVersion:   "v2",
+			CanaryWeight:    20,
+			Upstream:        &types.UpstreamConfig{Host: "svc-canary.local"},
+		},
+	}
+	config.RouteMatching = &types.RouteMatchStrategyConfig{Type: "header-versioned", VersionHeader: "x-api-version"}
+	strategies, err := NewStrategyFactory(nil, nil).CreateStrategySet(config, dep)
+	if err != nil {
+		t.Fatalf("CreateStrategySet: %v", err)
+	}
+	composite, err := NewCompositeTranslator(strategies, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCompositeTranslator: %v", err)
+	}
+	composite.SetTranslationContext(&TranslationContext{
+		Gateway:     &models.Gateway{ID: "gw", NodeID: "node-1"},
+		Listener:    &models.Listener{ID: "l1", Port: 8080},
+		VirtualHost: &models.GatewayVirtualHost{ID: "*", ListenerID: "l1", Name: "*", Hostname: "*"},
+	})
+	irAPI := &ir.API{Endpoints: []ir.Endpoint{{Method: "GET", Path: ir.PathInfo{Pattern: "/pets/{id}"}}}}
+
+	xds, err := composite.Translate(context.Background(), dep, irAPI, "node-1")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if len(xds.Clusters) != 2 || xds.Clusters[0].Name != "svc-v1-cluster" || xds.Clusters[1].Name != "svc-v2-cluster" {
+		t.Fatalf("clusters = %v, want svc-v1-cluster and svc-v2-cluster", xds.Clusters)
+	}
+
+	routes := xds.Routes[0].VirtualHosts[0].Routes
+	if len(routes) != 2 {
+		t.Fatalf("got %d routes, want one per version", len(routes))
+	}
+	versionHeader := func(r *routev3.Route) string {
+		for _, h := range r.GetMatch().GetHeaders() {
+			if h.Name == "x-api-version" {
+				return h.GetStringMatch().GetExact()
+			}
+		}
+		return ""
+	}
+	// The version-matched route must come first: the default route
+	// matches the same path regardless of the header.
+	if got := versionHeader(routes[0]); got != "v2" {
+		t.Errorf("first route version header = %q, want v2", got)
+	}
+	if got := routes[0].GetRoute().GetCluster(); got != "svc-v2-cluster" {
+		t.Errorf("v2 route cluster = %q, want svc-v2-cluster", got)
+	}
+	if got := versionHeader(routes[1]); got != "" {
+		t.Errorf("default route matches version %q, want no version header", got)
+	}
+	if got := routes[1].GetRoute().GetCluster(); got != "svc-v1-cluster" {
+		t.Errorf("default route cluster = %q, want svc-v1-cluster", got)
+	}
+	for _, r := range routes {
+		if got := r.GetMatch().GetPrefix(); got != "/svc/pets/" {
+			t.Errorf("route prefix = %q, want /svc/pets/", got)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	"github.com/flowc-labs/flowc/internal/flowc/ir"
 	"github.com/flowc-labs/flowc/internal/flowc/models"
 )
@@ -17,6 +18,11 @@ type XDSResources struct {
 	Endpoints []*endpointv3.ClusterLoadAssignment
 	Listeners []*listenerv3.Listener
 	Routes    []*routev3.RouteConfiguration
+
+	// HTTPFilters are HTTP connection manager filters the deployment
+	// contributes to its listener. They are not standalone xDS resources;
+	// the listener builder inserts them ahead of the router filter.
+	HTTPFilters []*hcmv3.HttpFilter
 }
 
 // Translator is the interface that all xDS translators must implement
@@ -49,6 +55,19 @@ type TranslatorOptions struct {
 	// EnableMetrics enables metrics collection
 	EnableMetrics bool
 
+	// StrictStrategies fails a translation when an optional strategy (rate
+	// limit, observability, CORS) cannot be built or applied. When false,
+	// the failing strategy is dropped with a warning and the deployment is
+	// served without it. Deployment, route match, load balancing and
+	// retry strategies are always required.
+	StrictStrategies bool
+
+	// AllowEnvoyOverrides lets deployments merge raw Envoy route and
+	// cluster config (extra_route_config / extra_cluster_config) into the
+	// generated resources. Deployments that carry overrides fail to
+	// translate when it is off.
+	AllowEnvoyOverrides bool
+
 	// Additional custom options
 	CustomOptions map[string]any
 }
@@ -60,6 +79,7 @@ func DefaultTranslatorOptions() *TranslatorOptions {
 		EnableHTTPS:         false,
 		EnableTracing:       false,
 		EnableMetrics:       false,
+		StrictStrategies:    true,
 		CustomOptions:       make(map[string]any),
 	}
 }
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+type contextFieldsKey struct{}
+
+type contextLoggerKey struct{}
+
+// defaultLogger is returned by FromContext for contexts without a logger.
+var defaultLogger = sync.OnceValue(NewDefaultEnvoyLogger)
+
+// WithContext returns a copy of ctx carrying fields on top of the fields
+// ctx already carries, replacing those with the same keys. Loggers drawn
+// from the context with FromContext or EnvoyLogger.WithContext include
+// them on every message.
+func WithContext(ctx context.Context, fields map[string]any) context.Context {
+	merged := maps.Clone(contextFields(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(fields))
+	}
+	maps.Copy(merged, fields)
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// NewContext returns a copy of ctx carrying l, the logger FromContext
+// draws from it.
+func NewContext(ctx context.Context, l *EnvoyLogger) context.Context {
+	return context.WithValue(ctx, contextLoggerKey{}, l)
+}
+
+// FromContext returns the logger ctx carries, or a default INFO logger
+// when it carries none, with the fields of ctx attached.
+func FromContext(ctx context.Context) *EnvoyLogger {
+	l, ok := ctx.Value(contextLoggerKey{}).(*EnvoyLogger)
+	if !ok || l == nil {
+		l = defaultLogger()
+	}
+	return l.WithContext(ctx)
+}
+
+// contextFields returns the fields ctx carries; the map must not be
+// modified.
+func contextFields(ctx context.Context) map[string]any {
+	fields, _ := ctx.Value(contextFieldsKey{}).(map[string]any)
+	return fields
+}
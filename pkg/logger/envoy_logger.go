@@ -16,6 +16,7 @@ type EnvoyLogger struct {
 	logger   *slog.Logger
 	level    Level
 	levelVar *slog.LevelVar // For dynamic level changes
+	sampler  *sampler       // Shared with derived loggers; nil logs everything
 }
 
 // Level represents the logging level
@@ -47,6 +48,17 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel returns the Level named by s ("debug", "info", "warn",
+// "error" or "fatal"), ignoring case.
+func ParseLevel(s string) (Level, error) {
+	for l := DebugLevel; l <= FatalLevel; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q", s)
+}
+
 // ToSlogLevel converts custom Level to slog.Level
 func (l Level) ToSlogLevel() slog.Level {
 	switch l {
@@ -203,6 +215,9 @@ func (l *EnvoyLogger) logWithSource(ctx context.Context, level slog.Level, msg s
 	if !l.logger.Enabled(ctx, level) {
 		return
 	}
+	if l.sampler != nil && !l.sampler.allow(level, msg) {
+		return
+	}
 
 	// Callers(3): skip runtime.Callers, this frame, and the public logger method.
 	var pcs [1]uintptr
@@ -224,6 +239,7 @@ func (l *EnvoyLogger) WithField(key string, value any) *EnvoyLogger {
 		logger:   l.logger.With(key, value),
 		level:    l.level,
 		levelVar: l.levelVar,
+		sampler:  l.sampler,
 	}
 }
 
@@ -237,6 +253,7 @@ func (l *EnvoyLogger) WithFields(fields map[string]any) *EnvoyLogger {
 		logger:   l.logger.With(args...),
 		level:    l.level,
 		levelVar: l.levelVar,
+		sampler:  l.sampler,
 	}
 }
 
@@ -245,13 +262,14 @@ func (l *EnvoyLogger) WithError(err error) *EnvoyLogger {
 	return l.WithField("error", err.Error())
 }
 
-// WithContext adds context to the logger
-// Note: This is a basic implementation. Extend it to extract trace IDs,
-// request IDs, or other context values as needed.
+// WithContext adds the fields attached to ctx with the package-level
+// WithContext (request, deployment or gateway IDs) to the logger
 func (l *EnvoyLogger) WithContext(ctx context.Context) *EnvoyLogger {
-	// For now, just return the same logger
-	// In a more sophisticated implementation, you might extract values from context
-	return l
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.WithFields(fields)
 }
 
 // SetLevel sets the logging level dynamically
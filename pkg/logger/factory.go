@@ -20,6 +20,8 @@ type LoggerConfig struct {
 	Output     io.Writer
 	AddSource  bool
 	TimeFormat string
+	// Sampling, when set, drops repeats of identical messages
+	Sampling *SamplingConfig
 }
 
 // DefaultLoggerConfig returns a default logger configuration
@@ -38,7 +40,12 @@ func NewLogger(config *LoggerConfig) *EnvoyLogger {
 	if config == nil {
 		config = DefaultLoggerConfig()
 	}
+	l := newLogger(config)
+	l.sampler = newSampler(config.Sampling)
+	return l
+}
 
+func newLogger(config *LoggerConfig) *EnvoyLogger {
 	switch config.Type {
 	case JSONLogger:
 		if config.Output != nil {
@@ -2,10 +2,14 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestLoggerLevels(t *testing.T) {
@@ -535,3 +539,92 @@ func BenchmarkJSONLogging(b *testing.B) {
 		log.Info("benchmark message")
 	}
 }
+
+func TestLoggerSampling(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewLogger(&LoggerConfig{
+		Type:     JSONLogger,
+		Level:    InfoLevel,
+		Output:   &buf,
+		Sampling: &SamplingConfig{Interval: time.Minute, First: 2, Thereafter: 3},
+	})
+	now := time.Unix(0, 0)
+	log.sampler.now = func() time.Time { return now }
+
+	lines := func() int {
+		n := strings.Count(buf.String(), "\n")
+		buf.Reset()
+		return n
+	}
+
+	// Derived loggers share the sampler, so fields don't dodge it.
+	for i := 0; i < 10; i++ {
+		log.WithField("attempt", i).Info("Snapshot updated")
+	}
+	// The first 2, then the 5th and 8th.
+	if got := lines(); got != 4 {
+		t.Errorf("logged %d of 10 identical messages, want 4", got)
+	}
+
+	log.Info("Deployment translated")
+	log.Warn("Snapshot updated")
+	if got := lines(); got != 2 {
+		t.Errorf("logged %d distinct messages, want 2", got)
+	}
+
+	now = now.Add(time.Minute)
+	log.Info("Snapshot updated")
+	if got := lines(); got != 1 {
+		t.Errorf("logged %d messages in a new interval, want 1", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.sampler.allow(slog.LevelInfo, "concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allocs := testing.AllocsPerRun(100, func() { log.sampler.allow(slog.LevelInfo, "Snapshot updated") }); allocs != 0 {
+		t.Errorf("sampler allocates %v times per message, want 0", allocs)
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewJSONLoggerWithWriter(&buf, InfoLevel)
+
+	ctx := NewContext(context.Background(), log)
+	ctx = WithContext(ctx, map[string]any{"request_id": "req-1"})
+	ctx = WithContext(ctx, map[string]any{"deployment": "petstore"})
+
+	// A deeper call only has the context.
+	applyDeployment := func(ctx context.Context) {
+		FromContext(ctx).Info("Deployment applied")
+	}
+	applyDeployment(ctx)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log entry %q: %v", buf.String(), err)
+	}
+	if entry["request_id"] != "req-1" || entry["deployment"] != "petstore" {
+		t.Errorf("log entry = %v, want request_id req-1 and deployment petstore", entry)
+	}
+
+	buf.Reset()
+	log.WithContext(ctx).Warn("Slow apply")
+	if !strings.Contains(buf.String(), `"request_id":"req-1"`) {
+		t.Errorf("EnvoyLogger.WithContext dropped the context fields: %s", buf.String())
+	}
+
+	// Contexts without fields leave the logger as is.
+	if got := log.WithContext(context.Background()); got != log {
+		t.Error("WithContext on a bare context returned a different logger")
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets is the number of message counters a sampler keeps.
+// Messages are hashed onto them, so distinct messages may share one.
+const samplerBuckets = 4096
+
+// SamplingConfig limits how often identical messages are logged: within
+// each Interval, the First occurrences of a message at a level are
+// logged, then every Thereafter-th one (none when Thereafter is 0).
+type SamplingConfig struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+// sampler implements SamplingConfig with lock-free counters.
+type sampler struct {
+	interval   int64
+	first      uint64
+	thereafter uint64
+	now        func() time.Time
+	counters   [samplerBuckets]sampleCounter
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// newSampler returns the sampler of cfg, or nil (no sampling) when cfg is
+// nil. A zero Interval counts per second.
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &sampler{
+		interval:   int64(interval),
+		first:      uint64(max(cfg.First, 0)),
+		thereafter: uint64(max(cfg.Thereafter, 0)),
+		now:        time.Now,
+	}
+}
+
+// allow counts an occurrence of msg at level and reports whether it is
+// to be logged.
+func (s *sampler) allow(level slog.Level, msg string) bool {
+	n := s.counters[sampleKey(level, msg)%samplerBuckets].inc(s.now().UnixNano(), s.interval)
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// inc counts one occurrence at now, starting a new interval when the
+// current one is over, and returns the count within the interval.
+func (c *sampleCounter) inc(now, interval int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if now >= resetAt && c.resetAt.CompareAndSwap(resetAt, now+interval) {
+		c.count.Store(1)
+		return 1
+	}
+	return c.count.Add(1)
+}
+
+// sampleKey hashes level and msg with FNV-1a, without allocating.
+func sampleKey(level slog.Level, msg string) uint32 {
+	const prime = 16777619
+	h := uint32(2166136261)
+	h = (h ^ uint32(level)) * prime
+	for i := 0; i < len(msg); i++ {
+		h = (h ^ uint32(msg[i])) * prime
+	}
+	return h
+}
@@ -51,6 +51,22 @@ type CanaryConfig struct {
 	// CanaryWeight is the percentage of traffic to canary (0-100)
 	CanaryWeight int
 
+	// Upstream serving the canary version (required). Host must be set;
+	// unset port, scheme, timeout, TLS and connection limits are taken
+	// from the deployment's upstream
+	Upstream *UpstreamConfig
+
+	// Sticky pins each client to the version it was first sent to with a
+	// cookie set on the first response
+	Sticky bool
+
+	// StickyCookie names the pinning cookie (default "flowc-canary")
+	StickyCookie string
+
+	// StickyTTL is how long the pinning cookie lasts (e.g. "24h"); unset
+	// means the browser session
+	StickyTTL string
+
 	// MatchCriteria for header-based routing
 	MatchCriteria *MatchCriteria
 }
@@ -69,7 +85,7 @@ type MatchCriteria struct {
 
 // DeploymentStrategyConfig configures the deployment strategy (cluster generation)
 type DeploymentStrategyConfig struct {
-	// Type: basic, canary, blue-green
+	// Type: basic, canary, blue-green, dynamic-forward-proxy
 	Type string `yaml:"type" json:"type"`
 
 	// Canary configuration (if type is "canary")
@@ -77,6 +93,20 @@ type DeploymentStrategyConfig struct {
 
 	// Blue-green configuration (if type is "blue-green")
 	BlueGreen *BlueGreenConfig `yaml:"blue_green,omitempty" json:"blue_green,omitempty"`
+
+	// Dynamic forward proxy configuration (if type is "dynamic-forward-proxy")
+	DynamicForwardProxy *DynamicForwardProxyConfig `yaml:"dynamic_forward_proxy,omitempty" json:"dynamic_forward_proxy,omitempty"`
+}
+
+// DynamicForwardProxyConfig configures a deployment that proxies to
+// upstream hosts resolved per request
+type DynamicForwardProxyConfig struct {
+	// Upstream hosts requests may be proxied to: exact hostnames, or
+	// "*.example.com" for any subdomain (required)
+	AllowedHosts []string `yaml:"allowed_hosts" json:"allowed_hosts"`
+
+	// Request header holding the upstream host[:port] (default: Host)
+	HostHeader string `yaml:"host_header,omitempty" json:"host_header,omitempty"`
 }
 
 // RouteMatchStrategyConfig configures how routes are matched
@@ -106,6 +136,21 @@ type LoadBalancingStrategyConfig struct {
 
 	// Health check settings
 	HealthCheck *HealthCheckConfig `yaml:"health_check,omitempty" json:"health_check,omitempty"`
+
+	// Percentage of healthy hosts below which Envoy ignores health and
+	// routes to all hosts (Envoy's default 50); 0 fails fast instead
+	HealthyPanicThreshold *float64 `yaml:"healthy_panic_threshold,omitempty" json:"healthy_panic_threshold,omitempty"`
+
+	// Ramp traffic to newly added endpoints up gradually (round-robin and
+	// least-request only)
+	SlowStart *SlowStartConfig `yaml:"slow_start,omitempty" json:"slow_start,omitempty"`
+}
+
+// SlowStartConfig configures the warm-up window of new endpoints
+type SlowStartConfig struct {
+	Window           string  `yaml:"window" json:"window"`                                             // e.g., "60s"
+	MinWeightPercent float64 `yaml:"min_weight_percent,omitempty" json:"min_weight_percent,omitempty"` // Weight a new endpoint starts at (default 10)
+	Aggression       float64 `yaml:"aggression,omitempty" json:"aggression,omitempty"`                 // Ramp shape; 1.0 (default) is linear, higher sends more traffic early
 }
 
 // HealthCheckConfig configures health checking
@@ -136,6 +181,12 @@ type RetryStrategyConfig struct {
 
 	// Retry budget — max % of requests that can be retried
 	BudgetPercent float64 `yaml:"budget_percent,omitempty" json:"budget_percent,omitempty"`
+
+	// Host selection predicates for retries: previous_hosts (the default),
+	// previous_priorities
+	HostPredicates []string `yaml:"host_predicates,omitempty" json:"host_predicates,omitempty"`
+	// Hosts a retry may pick before ignoring the predicates (default 3)
+	HostSelectionMaxAttempts int64 `yaml:"host_selection_max_attempts,omitempty" json:"host_selection_max_attempts,omitempty"`
 }
 
 // RateLimitStrategyConfig configures rate limiting
@@ -203,8 +254,20 @@ type VirtualHostConfig struct {
 	// Domains this virtual host should match
 	Domains []string `yaml:"domains,omitempty" json:"domains,omitempty"`
 
-	// Use existing virtual host by name (for grouping APIs)
+	// Name of a virtual host shared with other APIs on the environment.
+	// Deployments naming the same one compose into a single virtual host
+	// instead of each getting its own
 	UseExisting string `yaml:"use_existing,omitempty" json:"use_existing,omitempty"`
+
+	// Split endpoints into one virtual host per first OpenAPI tag, each
+	// served on a "<tag>." subdomain of Domains. Untagged endpoints stay
+	// in this virtual host, as do all endpoints on a TLS listener with an
+	// exact hostname, whose SNI filter chain would not match the subdomains
+	GroupByTag bool `yaml:"group_by_tag,omitempty" json:"group_by_tag,omitempty"`
+
+	// Query parameters every route matches on, by name. An empty value
+	// matches any value of a parameter that is present
+	QueryParams map[string]string `yaml:"query_params,omitempty" json:"query_params,omitempty"`
 }
 
 // GatewayConfig represents gateway targeting configuration in flowc.yaml.
@@ -239,6 +302,95 @@ type UpstreamConfig struct {
 
 	// Timeout of the upstream service
 	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Weighted hosts to balance across. When set, these replace Host/Port as
+	// the cluster endpoints; Host is still used for TLS SNI if present
+	Hosts []UpstreamHost `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+
+	// TLS verification and client certificate settings, used when the
+	// scheme is https
+	TLS *UpstreamTLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+	// Subset load balancing over the metadata of the weighted hosts
+	Subsets *UpstreamSubsetConfig `yaml:"subsets,omitempty" json:"subsets,omitempty"`
+
+	// Secondary upstream requests go to when this one has no healthy
+	// hosts or a request to it fails
+	Fallback *UpstreamFallbackConfig `yaml:"fallback,omitempty" json:"fallback,omitempty"`
+
+	// Limits on the connections opened to the upstream hosts
+	Connection *UpstreamConnectionConfig `yaml:"connection,omitempty" json:"connection,omitempty"`
+}
+
+// UpstreamConnectionConfig limits the connections to the upstream hosts
+type UpstreamConnectionConfig struct {
+	// Maximum number of connections opened to each host (default: Envoy's 1024)
+	MaxConnectionsPerHost uint32 `yaml:"max_connections_per_host,omitempty" json:"max_connections_per_host,omitempty"`
+
+	// Number of requests after which a connection is closed and replaced
+	// (default: unlimited)
+	MaxRequestsPerConnection uint32 `yaml:"max_requests_per_connection,omitempty" json:"max_requests_per_connection,omitempty"`
+}
+
+// UpstreamFallbackConfig is the secondary upstream of an API
+type UpstreamFallbackConfig struct {
+	// Host of the fallback service
+	Host string `yaml:"host" json:"host"`
+
+	// Port of the fallback service
+	Port uint32 `yaml:"port" json:"port"`
+
+	// Scheme of the fallback service (default: http)
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+
+	// TLS verification and client certificate settings, used when the
+	// scheme is https
+	TLS *UpstreamTLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// UpstreamSubsetConfig splits the upstream hosts into subsets by their
+// metadata and pins the deployment's routes to one of them
+type UpstreamSubsetConfig struct {
+	// Metadata key sets to build subsets for, e.g. [["zone"], ["version", "zone"]]
+	Selectors [][]string `yaml:"selectors" json:"selectors"`
+
+	// Metadata the deployment's routes select hosts by. Requests fall back
+	// to any host when no host matches
+	Match map[string]string `yaml:"match,omitempty" json:"match,omitempty"`
+}
+
+// UpstreamTLSConfig configures how upstream certificates are verified and
+// which client certificate is presented for mTLS
+type UpstreamTLSConfig struct {
+	// Path to the CA bundle to trust (default: the system CA bundle)
+	CAPath string `yaml:"ca_path,omitempty" json:"ca_path,omitempty"`
+
+	// Path to the client certificate presented to the upstream
+	CertPath string `yaml:"cert_path,omitempty" json:"cert_path,omitempty"`
+
+	// Path to the client certificate's private key
+	KeyPath string `yaml:"key_path,omitempty" json:"key_path,omitempty"`
+
+	// SPIFFE IDs accepted as the upstream's URI SAN
+	SPIFFEIDs []string `yaml:"spiffe_ids,omitempty" json:"spiffe_ids,omitempty"`
+
+	// DNS names accepted as the upstream's DNS SAN
+	DNSNames []string `yaml:"dns_names,omitempty" json:"dns_names,omitempty"`
+}
+
+// UpstreamHost is one weighted instance of a multi-host upstream
+type UpstreamHost struct {
+	// Host of the instance
+	Host string `yaml:"host" json:"host"`
+
+	// Port of the instance (default: the upstream port)
+	Port uint32 `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Relative load balancing weight (default: 1)
+	Weight uint32 `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// Metadata used by subset load balancing, e.g. zone: us-east-1a
+	Metadata map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
 }
 
 // HTTPFilter represents an HTTP filter to apply to the gateway
@@ -250,6 +402,90 @@ type HTTPFilter struct {
 	Config map[string]any `yaml:"config" json:"config"`
 }
 
+// HTTPFiltersConfig holds the opt-in HTTP filters a deployment contributes
+// to the HTTP connection manager of its target listener
+type HTTPFiltersConfig struct {
+	// gRPC-JSON transcoding (api_type: grpc only)
+	GRPCJSONTranscoder *GRPCJSONTranscoderConfig `yaml:"grpc_json_transcoder,omitempty" json:"grpc_json_transcoder,omitempty"`
+
+	// Admission control (load shedding on upstream success rate)
+	AdmissionControl *AdmissionControlConfig `yaml:"admission_control,omitempty" json:"admission_control,omitempty"`
+
+	// Response caching with an in-memory cache
+	Cache *CacheConfig `yaml:"cache,omitempty" json:"cache,omitempty"`
+
+	// Per-route CORS policies derived from the spec's OPTIONS operations
+	CORSFromSpec bool `yaml:"cors_from_spec,omitempty" json:"cors_from_spec,omitempty"`
+
+	// Access-Control-Max-Age, in seconds, of the CORS policies whose OPTIONS
+	// responses declare none, so browsers cache preflight results
+	CORSMaxAge int `yaml:"cors_max_age,omitempty" json:"cors_max_age,omitempty"`
+
+	// Rejection, with 400, of requests missing a header parameter the
+	// spec declares required
+	RequiredHeadersFromSpec bool `yaml:"required_headers_from_spec,omitempty" json:"required_headers_from_spec,omitempty"`
+
+	// Decompression of compressed request bodies before they reach the upstream
+	Decompressor *DecompressorConfig `yaml:"decompressor,omitempty" json:"decompressor,omitempty"`
+
+	// Buffering of whole request bodies before they are forwarded; requests
+	// are streamed when unset
+	Buffer bool `yaml:"buffer,omitempty" json:"buffer,omitempty"`
+
+	// Largest request body buffered, in bytes (default 1 MiB)
+	BufferMaxRequestBytes uint32 `yaml:"buffer_max_request_bytes,omitempty" json:"buffer_max_request_bytes,omitempty"`
+}
+
+// GRPCJSONTranscoderConfig configures the grpc_json_transcoder filter
+type GRPCJSONTranscoderConfig struct {
+	// Serialized FileDescriptorSet for the gRPC services (base64 in JSON)
+	ProtoDescriptorBin []byte `yaml:"proto_descriptor_bin" json:"proto_descriptor_bin"`
+
+	// Fully-qualified service names to transcode (e.g., "pkg.v1.Greeter")
+	Services []string `yaml:"services" json:"services"`
+
+	// Map POST /<package>.<Service>/<Method> for methods without google.api.http annotations
+	AutoMapping bool `yaml:"auto_mapping,omitempty" json:"auto_mapping,omitempty"`
+
+	// Ignore query parameters that don't map to request message fields
+	IgnoreUnknownQueryParameters bool `yaml:"ignore_unknown_query_parameters,omitempty" json:"ignore_unknown_query_parameters,omitempty"`
+}
+
+// AdmissionControlConfig configures the admission_control filter, which
+// probabilistically rejects requests once the upstream success rate drops
+// below the threshold
+type AdmissionControlConfig struct {
+	// Success rate percentage (0-100] below which requests are shed (Envoy default: 95)
+	SuccessRateThreshold float64 `yaml:"success_rate_threshold,omitempty" json:"success_rate_threshold,omitempty"`
+
+	// Sliding window over which the success rate is measured (e.g., "30s")
+	SamplingWindow string `yaml:"sampling_window,omitempty" json:"sampling_window,omitempty"`
+}
+
+// CacheConfig configures the HTTP cache filter backed by Envoy's simple
+// in-memory cache. The TTL is applied as a Cache-Control max-age response
+// header on the deployment's routes for the cacheable methods, unless the
+// upstream sends its own Cache-Control
+type CacheConfig struct {
+	// How long responses are cached (e.g., "60s")
+	TTL string `yaml:"ttl" json:"ttl"`
+
+	// Methods whose responses may be cached: GET, HEAD (default: GET)
+	Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+
+	// Request headers responses may vary on, added to the Vary header of
+	// cacheable responses; responses with any other Vary header are not cached
+	VaryHeaders []string `yaml:"vary_headers,omitempty" json:"vary_headers,omitempty"`
+}
+
+// DecompressorConfig configures request body decompression. One decompressor
+// filter is emitted per algorithm; each only acts on requests whose
+// Content-Encoding matches it. Responses are passed through untouched
+type DecompressorConfig struct {
+	// Content encodings to decompress: gzip, brotli (default: gzip)
+	Algorithms []string `yaml:"algorithms,omitempty" json:"algorithms,omitempty"`
+}
+
 // APIDeployment represents a complete API deployment
 type APIDeploymentInfo struct {
 	ID        string    `yaml:"id" json:"id"`
@@ -312,4 +548,34 @@ type FlowCMetadata struct {
 
 	// Labels for the API
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// HTTP filters this deployment contributes to the listener
+	Filters *HTTPFiltersConfig `yaml:"filters,omitempty" json:"filters,omitempty"`
+
+	// Upstream a percentage of the requests is shadowed to
+	Mirror *MirrorConfig `yaml:"mirror,omitempty" json:"mirror,omitempty"`
+
+	// Raw Envoy Route protobuf-JSON merged into every generated route.
+	// Only honoured when the envoy_overrides feature is enabled
+	ExtraRouteConfig map[string]any `yaml:"extra_route_config,omitempty" json:"extra_route_config,omitempty"`
+
+	// Raw Envoy Cluster protobuf-JSON merged into every generated cluster.
+	// Only honoured when the envoy_overrides feature is enabled
+	ExtraClusterConfig map[string]any `yaml:"extra_cluster_config,omitempty" json:"extra_cluster_config,omitempty"`
+}
+
+// MirrorConfig shadows a percentage of the requests to a second upstream.
+// Mirrored requests are fire-and-forget; their responses are discarded
+type MirrorConfig struct {
+	// Host of the mirror upstream
+	Host string `yaml:"host" json:"host"`
+
+	// Port of the mirror upstream
+	Port uint32 `yaml:"port" json:"port"`
+
+	// Scheme of the mirror upstream (default: http)
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+
+	// Percentage of requests mirrored, 0-100
+	Percentage int `yaml:"percentage,omitempty" json:"percentage,omitempty"`
 }